@@ -36,6 +36,10 @@ type assertionsApplied struct {
 	failures  []Failure
 	systemout string
 	systemerr string
+	// failedAssertions are the assertions of the failures
+	failedAssertions []string
+	// assertions are the assertions applied, the default assertions of the executor when the step has none
+	assertions []string
 }
 
 // applyChecks apply checks on result, return true if all assertions are OK, false otherwise
@@ -59,6 +63,7 @@ func applyAssertions(executorResult ExecutorResult, tc TestCase, stepNumber int,
 	var errors []Failure
 	var failures []Failure
 	var systemerr, systemout string
+	var failedAssertions []string
 
 	if err := mapstructure.Decode(step, &sa); err != nil {
 		return assertionsApplied{
@@ -67,6 +72,8 @@ func applyAssertions(executorResult ExecutorResult, tc TestCase, stepNumber int,
 			failures,
 			systemout,
 			systemerr,
+			nil,
+			nil,
 		}
 	}
 
@@ -83,6 +90,7 @@ func applyAssertions(executorResult ExecutorResult, tc TestCase, stepNumber int,
 		}
 		if fails != nil {
 			failures = append(failures, *fails)
+			failedAssertions = append(failedAssertions, assertion)
 			isOK = false
 		}
 	}
@@ -95,7 +103,7 @@ func applyAssertions(executorResult ExecutorResult, tc TestCase, stepNumber int,
 		systemout = fmt.Sprintf("%v", executorResult["result.systemout"])
 	}
 
-	return assertionsApplied{isOK, errors, failures, systemout, systemerr}
+	return assertionsApplied{isOK, errors, failures, systemout, systemerr, failedAssertions, sa.Assertions}
 }
 
 func check(tc TestCase, stepNumber int, assertion string, executorResult ExecutorResult) (*Failure, *Failure) {
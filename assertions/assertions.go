@@ -51,11 +51,15 @@ var assertMap = map[string]AssertFunc{
 	"ShouldContainSubstring":       ShouldContainSubstring,
 	"ShouldNotContainSubstring":    ShouldNotContainSubstring,
 	"ShouldEqualTrimSpace":         ShouldEqualTrimSpace,
+	"ShouldEqualXML":               ShouldEqualXML,
 	"ShouldHappenBefore":           ShouldHappenBefore,
 	"ShouldHappenOnOrBefore":       ShouldHappenOnOrBefore,
 	"ShouldHappenAfter":            ShouldHappenAfter,
 	"ShouldHappenOnOrAfter":        ShouldHappenOnOrAfter,
 	"ShouldHappenBetween":          ShouldHappenBetween,
+	"ShouldBeValidJWT":             ShouldBeValidJWT,
+	"ShouldNotBeExpiredJWT":        ShouldNotBeExpiredJWT,
+	"ShouldHaveJWTClaim":           ShouldHaveJWTClaim,
 }
 
 func Get(s string) (AssertFunc, bool) {
@@ -71,6 +75,9 @@ func ShouldEqual(actual interface{}, expected ...interface{}) error {
 	if reflect.DeepEqual(actual, expected[0]) {
 		return nil
 	}
+	if d := diff(expected[0], actual); d != "" {
+		return fmt.Errorf("expected and got values differ:\n%s", d)
+	}
 	return fmt.Errorf("expected: %v got: %v", expected[0], actual)
 }
 
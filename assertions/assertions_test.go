@@ -1,8 +1,11 @@
 package assertions
 
 import (
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/fatih/color"
 )
 
 func TestShouldEqual(t *testing.T) {
@@ -46,6 +49,23 @@ func TestShouldEqual(t *testing.T) {
 	}
 }
 
+func TestShouldEqual_diff(t *testing.T) {
+	color.NoColor = true
+	err := ShouldEqual(`{"id": 1, "name": "foo", "tags": ["a", "b"]}`, `{"id": 1, "name": "bar", "tags": ["a", "b"]}`)
+	if err == nil {
+		t.Fatal("ShouldEqual() expected an error")
+	}
+	for _, l := range []string{"--- expected", "+++ got", `-  "name": "bar",`, `+  "name": "foo",`, `   "id": 1,`} {
+		if !strings.Contains(err.Error(), l) {
+			t.Errorf("ShouldEqual() error = %v, want a diff with %q", err, l)
+		}
+	}
+
+	if err := ShouldEqual("a", "b"); err == nil || err.Error() != "expected: b got: a" {
+		t.Errorf("ShouldEqual() error = %v, want the values on one line", err)
+	}
+}
+
 func TestShouldNotEqual(t *testing.T) {
 	type args struct {
 		actual   interface{}
@@ -0,0 +1,66 @@
+package assertions
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// diffMinLength is the length from which two values are compared with a diff instead of being printed on one line
+const diffMinLength = 80
+
+// diff returns a colored unified diff of two long or multiline strings, JSON values are indented before
+// being compared. It returns an empty string for the other values.
+func diff(expected, actual interface{}) string {
+	e, ok1 := expected.(string)
+	a, ok2 := actual.(string)
+	if !ok1 || !ok2 {
+		return ""
+	}
+	e, a = indentJSON(e), indentJSON(a)
+	if len(e) < diffMinLength && len(a) < diffMinLength && !strings.Contains(e+a, "\n") {
+		return ""
+	}
+
+	d, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(e),
+		B:        difflib.SplitLines(a),
+		FromFile: "expected",
+		ToFile:   "got",
+		Context:  3,
+	})
+	if err != nil || d == "" {
+		return ""
+	}
+
+	lines := strings.Split(strings.TrimRight(d, "\n"), "\n")
+	for i, l := range lines {
+		switch {
+		case strings.HasPrefix(l, "---"), strings.HasPrefix(l, "+++"):
+			lines[i] = color.New(color.Bold).Sprint(l)
+		case strings.HasPrefix(l, "@@"):
+			lines[i] = color.CyanString(l)
+		case strings.HasPrefix(l, "-"):
+			lines[i] = color.RedString(l)
+		case strings.HasPrefix(l, "+"):
+			lines[i] = color.GreenString(l)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// indentJSON returns s indented if it's a JSON object or array, s otherwise
+func indentJSON(s string) string {
+	t := strings.TrimSpace(s)
+	if !strings.HasPrefix(t, "{") && !strings.HasPrefix(t, "[") {
+		return s
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(t), "", "  "); err != nil {
+		return s
+	}
+	return buf.String() + "\n"
+}
@@ -0,0 +1,179 @@
+package assertions
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type jwtToken struct {
+	header       map[string]interface{}
+	claims       map[string]interface{}
+	signingInput string
+	signature    []byte
+}
+
+func parseJWT(actual interface{}) (*jwtToken, error) {
+	s, ok := actual.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected a JWT string, got %T", actual)
+	}
+	parts := strings.Split(strings.TrimPrefix(strings.TrimSpace(s), "Bearer "), ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%q is not a JWT", s)
+	}
+	t := &jwtToken{signingInput: parts[0] + "." + parts[1]}
+	for i, target := range []*map[string]interface{}{&t.header, &t.claims} {
+		btes, err := base64.RawURLEncoding.DecodeString(parts[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWT encoding: %v", err)
+		}
+		if err := json.Unmarshal(btes, target); err != nil {
+			return nil, fmt.Errorf("invalid JWT content: %v", err)
+		}
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT signature encoding: %v", err)
+	}
+	t.signature = sig
+	return t, nil
+}
+
+func (t *jwtToken) verify(key string) error {
+	alg, _ := t.header["alg"].(string)
+	if len(alg) != 5 {
+		return fmt.Errorf("unsupported JWT algorithm %q", alg)
+	}
+	var h crypto.Hash
+	switch alg[2:] {
+	case "256":
+		h = crypto.SHA256
+	case "384":
+		h = crypto.SHA384
+	case "512":
+		h = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported JWT algorithm %q", alg)
+	}
+
+	switch alg[:2] {
+	case "HS":
+		mac := hmac.New(h.New, []byte(key))
+		mac.Write([]byte(t.signingInput))
+		if !hmac.Equal(mac.Sum(nil), t.signature) {
+			return fmt.Errorf("JWT signature is invalid")
+		}
+		return nil
+	case "RS":
+		block, _ := pem.Decode([]byte(key))
+		if block == nil {
+			return fmt.Errorf("invalid PEM public key")
+		}
+		var pub interface{}
+		if cert, err := x509.ParseCertificate(block.Bytes); err == nil {
+			pub = cert.PublicKey
+		} else if pub, err = x509.ParsePKIXPublicKey(block.Bytes); err != nil {
+			return fmt.Errorf("invalid public key: %v", err)
+		}
+		rsaPub, ok := pub.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("public key is not a RSA key")
+		}
+		hash := h.New()
+		hash.Write([]byte(t.signingInput))
+		if err := rsa.VerifyPKCS1v15(rsaPub, h, hash.Sum(nil), t.signature); err != nil {
+			return fmt.Errorf("JWT signature is invalid: %v", err)
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported JWT algorithm %q", alg)
+}
+
+func (t *jwtToken) checkTimes(now time.Time) error {
+	if exp, ok := t.claims["exp"].(float64); ok && now.Unix() >= int64(exp) {
+		return fmt.Errorf("JWT expired at %s", time.Unix(int64(exp), 0).Format(time.RFC3339))
+	}
+	if nbf, ok := t.claims["nbf"].(float64); ok && now.Unix() < int64(nbf) {
+		return fmt.Errorf("JWT is not valid before %s", time.Unix(int64(nbf), 0).Format(time.RFC3339))
+	}
+	return nil
+}
+
+// ShouldBeValidJWT receives exactly one parameter, the HMAC secret or the PEM public key (or certificate),
+// and checks that actual is a JWT with a valid signature, not expired and not before its nbf claim.
+func ShouldBeValidJWT(actual interface{}, expected ...interface{}) error {
+	if err := need(1, expected); err != nil {
+		return err
+	}
+	t, err := parseJWT(actual)
+	if err != nil {
+		return err
+	}
+	if err := t.verify(fmt.Sprintf("%v", expected[0])); err != nil {
+		return err
+	}
+	return t.checkTimes(time.Now())
+}
+
+// ShouldNotBeExpiredJWT decodes a JWT, without checking its signature, and checks it is not expired.
+// An optional parameter is a duration, to check that the JWT is still valid after this duration.
+func ShouldNotBeExpiredJWT(actual interface{}, expected ...interface{}) error {
+	if err := atMost(1, expected); err != nil {
+		return err
+	}
+	t, err := parseJWT(actual)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if len(expected) == 1 {
+		d, err := time.ParseDuration(fmt.Sprintf("%v", expected[0]))
+		if err != nil {
+			return err
+		}
+		now = now.Add(d)
+	}
+	return t.checkTimes(now)
+}
+
+// ShouldHaveJWTClaim receives exactly two parameters, a claim name and its expected value.
+// The JWT is decoded without checking its signature.
+func ShouldHaveJWTClaim(actual interface{}, expected ...interface{}) error {
+	if err := need(2, expected); err != nil {
+		return err
+	}
+	t, err := parseJWT(actual)
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%v", expected[0])
+	v, ok := t.claims[name]
+	if !ok {
+		return fmt.Errorf("expected JWT to have claim %q", name)
+	}
+	var value string
+	switch c := v.(type) {
+	case string:
+		value = c
+	case float64:
+		value = fmt.Sprintf("%v", int64(c))
+		if float64(int64(c)) != c {
+			value = fmt.Sprintf("%v", c)
+		}
+	default:
+		btes, _ := json.Marshal(c)
+		value = string(btes)
+	}
+	if value != fmt.Sprintf("%v", expected[1]) {
+		return fmt.Errorf("expected JWT claim %q: %v got: %v", name, expected[1], value)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package assertions
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+// CanonicalXML returns a canonical form of the XML document s: declarations,
+// comments and processing instructions are removed, attributes are sorted,
+// empty elements are expanded and text nodes are trimmed.
+func CanonicalXML(s string) (string, error) {
+	d := xml.NewDecoder(strings.NewReader(s))
+	d.Strict = true
+	out := &bytes.Buffer{}
+	depth := 0
+	for {
+		tok, err := d.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("invalid XML: %v", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			depth++
+			attrs := make([]xml.Attr, len(t.Attr))
+			copy(attrs, t.Attr)
+			sort.Slice(attrs, func(i, j int) bool {
+				return qualifiedName(attrs[i].Name) < qualifiedName(attrs[j].Name)
+			})
+			out.WriteString("<" + qualifiedName(t.Name))
+			for _, a := range attrs {
+				out.WriteString(" " + qualifiedName(a.Name) + `="`)
+				xml.EscapeText(out, []byte(a.Value))
+				out.WriteString(`"`)
+			}
+			out.WriteString(">")
+		case xml.EndElement:
+			depth--
+			out.WriteString("</" + qualifiedName(t.Name) + ">")
+		case xml.CharData:
+			if depth == 0 {
+				continue
+			}
+			xml.EscapeText(out, bytes.TrimSpace(t))
+		}
+	}
+	if out.Len() == 0 {
+		return "", fmt.Errorf("invalid XML: no element found")
+	}
+	return out.String(), nil
+}
+
+func qualifiedName(n xml.Name) string {
+	if n.Space == "" {
+		return n.Local
+	}
+	return n.Space + ":" + n.Local
+}
+
+// ShouldEqualXML receives exactly 2 XML documents and ensures that they are equal once canonicalized
+// (ie. ignoring formatting, comments and attributes order).
+func ShouldEqualXML(actual interface{}, expected ...interface{}) error {
+	if err := need(1, expected); err != nil {
+		return err
+	}
+	actualS, err := cast.ToStringE(actual)
+	if err != nil {
+		return err
+	}
+	expectedS, err := cast.ToStringE(expected[0])
+	if err != nil {
+		return err
+	}
+	a, err := CanonicalXML(actualS)
+	if err != nil {
+		return err
+	}
+	e, err := CanonicalXML(expectedS)
+	if err != nil {
+		return fmt.Errorf("expected value: %v", err)
+	}
+	if a != e {
+		return fmt.Errorf("expected: %v got: %v", e, a)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package assertions
+
+import (
+	"testing"
+)
+
+func TestShouldEqualXML(t *testing.T) {
+	type args struct {
+		actual   interface{}
+		expected []interface{}
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{
+			name: "same document formatted differently",
+			args: args{
+				actual: `<?xml version="1.0"?>
+<!-- a comment -->
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Body>
+    <price currency="EUR" id="1">  10 </price>
+    <empty/>
+  </soap:Body>
+</soap:Envelope>`,
+				expected: []interface{}{`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><price id="1" currency="EUR">10</price><empty></empty></soap:Body></soap:Envelope>`},
+			},
+		},
+		{
+			name: "different text",
+			args: args{
+				actual:   `<a><b>1</b></a>`,
+				expected: []interface{}{`<a><b>2</b></a>`},
+			},
+			wantErr: true,
+		},
+		{
+			name: "different attribute",
+			args: args{
+				actual:   `<a x="1"/>`,
+				expected: []interface{}{`<a x="2"/>`},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid XML",
+			args: args{
+				actual:   `<a><b></a>`,
+				expected: []interface{}{`<a><b></b></a>`},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ShouldEqualXML(tt.args.actual, tt.args.expected...); (err != nil) != tt.wantErr {
+				t.Errorf("ShouldEqualXML() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package venom
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ovh/venom/openapi"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// attachmentsDir is the directory of the attachments, in the output directory
+const attachmentsDir = "attachments"
+
+// writeStepAttachments writes the rendered request of a failed step and its response (or the result of
+// the executor) in the output directory, and the snapshot of the context when it takes one. The files are
+// referenced in the system-out of the testcase, with the [[ATTACHMENT|path]] syntax of the JUnit attachments
+// plugins, and in the HTML report.
+func (v *Venom) writeStepAttachments(tcc TestCaseContext, e *ExecutorWrap, ts *TestSuite, tc *TestCase, stepNumber int, step TestStep, result ExecutorResult) {
+	if v.OutputDir == "" {
+		return
+	}
+	dir := filepath.Join(v.OutputDir, attachmentsDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		v.PrintFunc("Error while creating directory %s: %v\n", dir, err)
+		return
+	}
+	base := fmt.Sprintf("%s.%s.step%d", slug(ts.ShortName), slug(tc.Name), stepNumber)
+
+	files := map[string][]byte{}
+	if btes, err := yaml.Marshal(step); err == nil {
+		files[base+".request.yml"] = btes
+	}
+	if c, ok := httpCall(e, result); ok {
+		files[base+".response.http"] = []byte(rawHTTPResponse(c))
+	} else if result != nil {
+		if btes, err := json.MarshalIndent(result, "", "  "); err == nil {
+			files[base+".result.json"] = btes
+		}
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		filename := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(filename, files[name], 0644); err != nil {
+			v.PrintFunc("Error while creating file %s: %v\n", filename, err)
+			continue
+		}
+		if abs, err := filepath.Abs(filename); err == nil {
+			filename = abs
+		}
+		tc.Attachments = append(tc.Attachments, attachmentsDir+"/"+name)
+		tc.Systemout.Value += fmt.Sprintf("[[ATTACHMENT|%s]]\n", filename)
+	}
+
+	if s, ok := tcc.(testCaseContextWithSnapshot); ok {
+		snapshots, err := s.Snapshot(filepath.Join(dir, base))
+		if err != nil {
+			v.PrintFunc("Error while taking a snapshot of step %d of %s: %v\n", stepNumber, tc.Name, err)
+		}
+		for _, filename := range snapshots {
+			if abs, err := filepath.Abs(filename); err == nil {
+				filename = abs
+			}
+			tc.Attachments = append(tc.Attachments, attachmentsDir+"/"+filepath.Base(filename))
+			tc.Systemout.Value += fmt.Sprintf("[[ATTACHMENT|%s]]\n", filename)
+		}
+	}
+}
+
+// collectStepArtifacts copies the files matching the artifacts of a step, `artifacts: [./output/*.log]` relative
+// to the working directory, in the output directory once the step is run. They are referenced like the attachments.
+func (v *Venom) collectStepArtifacts(ts *TestSuite, tc *TestCase, stepNumber int, step TestStep) {
+	patterns, _ := step["artifacts"].([]interface{})
+	if len(patterns) == 0 || v.OutputDir == "" {
+		return
+	}
+	name := fmt.Sprintf("%s.%s.step%d.artifacts", slug(ts.ShortName), slug(tc.Name), stepNumber)
+	dir := filepath.Join(v.OutputDir, attachmentsDir, name)
+
+	for _, p := range patterns {
+		pattern := filepath.FromSlash(fmt.Sprintf("%v", p))
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(ts.WorkDir, pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			v.PrintFunc("Invalid artifacts pattern %s: %v\n", p, err)
+			continue
+		}
+		for _, m := range matches {
+			if fi, err := os.Stat(m); err != nil || fi.IsDir() {
+				continue
+			}
+			// the files keep their path in the working directory
+			rel, err := filepath.Rel(ts.WorkDir, m)
+			if err != nil || strings.HasPrefix(rel, "..") {
+				rel = filepath.Base(m)
+			}
+			filename := filepath.Join(dir, rel)
+			btes, err := ioutil.ReadFile(m)
+			if err == nil {
+				err = os.MkdirAll(filepath.Dir(filename), 0755)
+			}
+			if err == nil {
+				err = ioutil.WriteFile(filename, btes, 0644)
+			}
+			if err != nil {
+				v.PrintFunc("Error while collecting the artifact %s: %v\n", m, err)
+				continue
+			}
+			if abs, err := filepath.Abs(filename); err == nil {
+				filename = abs
+			}
+			tc.Attachments = append(tc.Attachments, attachmentsDir+"/"+name+"/"+filepath.ToSlash(rel))
+			tc.Systemout.Value += fmt.Sprintf("[[ATTACHMENT|%s]]\n", filename)
+		}
+	}
+}
+
+// rawHTTPResponse returns the response of an http call: status line, headers and body
+func rawHTTPResponse(c openapi.Call) string {
+	names := make([]string, 0, len(c.ResponseHeaders))
+	for name := range c.ResponseHeaders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "HTTP/1.1 %d\n", c.StatusCode)
+	for _, name := range names {
+		fmt.Fprintf(&sb, "%s: %s\n", name, c.ResponseHeaders[name])
+	}
+	sb.WriteString("\n")
+	sb.WriteString(c.ResponseBody)
+	return sb.String()
+}
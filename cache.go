@@ -0,0 +1,65 @@
+package venom
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// these step attributes do not change the result of the executor
+var stepCacheIgnoredKeys = map[string]bool{
+	"cache":      true,
+	"assertions": true,
+	"extracts":   true,
+	"vars":       true,
+	"retry":      true,
+	"retry_if":   true,
+	"artifacts":  true,
+	"delay":      true,
+	"timeout":    true,
+}
+
+// stepCacheKey returns the key of a step in the cache: a hash of the executor name and of the rendered step
+func stepCacheKey(e *ExecutorWrap, step TestStep) string {
+	inputs := make(map[string]interface{}, len(step))
+	for k, v := range step {
+		if !stepCacheIgnoredKeys[k] {
+			inputs[k] = v
+		}
+	}
+	// maps are printed with sorted keys
+	h := sha256.Sum256([]byte(e.name + "\n" + fmt.Sprintf("%v", inputs)))
+	return hex.EncodeToString(h[:])
+}
+
+// cachedResult returns the result of a step with `cache: true` if an identical step was run before
+func (v *Venom) cachedResult(e *ExecutorWrap, step TestStep) (ExecutorResult, bool) {
+	if !e.cache {
+		return nil, false
+	}
+	v.stepCacheMutex.Lock()
+	defer v.stepCacheMutex.Unlock()
+	result, ok := v.stepCache[stepCacheKey(e, step)]
+	if !ok {
+		return nil, false
+	}
+	return copyExecutorResult(result), true
+}
+
+// cacheResult memoizes the result of a step with `cache: true`, for the whole run
+func (v *Venom) cacheResult(e *ExecutorWrap, step TestStep, result ExecutorResult) {
+	if !e.cache {
+		return
+	}
+	v.stepCacheMutex.Lock()
+	defer v.stepCacheMutex.Unlock()
+	v.stepCache[stepCacheKey(e, step)] = result
+}
+
+func copyExecutorResult(result ExecutorResult) ExecutorResult {
+	c := make(ExecutorResult, len(result))
+	for k, v := range result {
+		c[k] = v
+	}
+	return c
+}
@@ -0,0 +1,76 @@
+package diff
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/ovh/venom"
+)
+
+var (
+	threshold float64
+	minDelta  float64
+)
+
+func init() {
+	Cmd.Flags().Float64VarP(&threshold, "duration-threshold", "", 0.5, "--duration-threshold 0.5 : report the testcases whose duration changed by more than 50%")
+	Cmd.Flags().Float64VarP(&minDelta, "duration-min-delta", "", 0.1, "--duration-min-delta 0.1 : ignore the duration changes lower than 0.1 second")
+}
+
+// Cmd diff
+var Cmd = &cobra.Command{
+	Use:   "diff old.json new.json",
+	Short: "Compare two result files: venom diff old.json new.json",
+	Long: `
+$ venom diff results-v1/test_results.json results-v2/test_results.json
+
+# reports the newly failing, newly passing, added and removed testcases
+# and the testcases whose duration changed. The result files are written
+# by venom run --format json (or xml, yaml). The exit code is 1 when
+# testcases are failing since the old run.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		before, err := venom.ReadTests(args[0])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		after, err := venom.ReadTests(args[1])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+
+		d := venom.DiffTests(*before, *after, threshold, minDelta)
+		red := color.New(color.FgRed).SprintFunc()
+		green := color.New(color.FgGreen).SprintFunc()
+		list := func(title string, names []string) {
+			if len(names) == 0 {
+				return
+			}
+			fmt.Printf("%s (%d):\n", title, len(names))
+			for _, name := range names {
+				fmt.Printf("  %s\n", name)
+			}
+		}
+		list(red("Newly failing"), d.NewlyFailing)
+		list(green("Newly passing"), d.NewlyPassing)
+		list("Added", d.Added)
+		list("Removed", d.Removed)
+		if len(d.DurationChanged) > 0 {
+			fmt.Printf("Duration changed (%d):\n", len(d.DurationChanged))
+			for _, c := range d.DurationChanged {
+				fmt.Printf("  %s: %.3fs -> %.3fs\n", c.Name, c.Old, c.New)
+			}
+		}
+		if len(d.NewlyFailing) == 0 && len(d.NewlyPassing) == 0 && len(d.Added) == 0 && len(d.Removed) == 0 && len(d.DurationChanged) == 0 {
+			fmt.Println("No difference")
+		}
+		if len(d.NewlyFailing) > 0 {
+			os.Exit(1)
+		}
+	},
+}
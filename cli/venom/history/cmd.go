@@ -0,0 +1,78 @@
+package history
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	// SQL drivers of the history store
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/spf13/cobra"
+
+	"github.com/ovh/venom/history"
+)
+
+var (
+	store string
+	limit int
+)
+
+func init() {
+	Cmd.Flags().StringVarP(&store, "history", "", "history.db", "SQLite file or Postgres database of the history, given to venom run --history")
+	Cmd.Flags().IntVarP(&limit, "limit", "", 20, "Number of runs displayed")
+}
+
+// Cmd history
+var Cmd = &cobra.Command{
+	Use:   "history <testcase>",
+	Short: "Display the trend of a testcase: venom history \"my testsuite / my testcase\"",
+	Long: `
+$ venom run tests/ --history history.db --history-env staging
+$ venom history --history history.db "my testcase"
+
+# displays the status and the duration of the testcase during the last
+# runs saved with venom run --history. The testcase is its name, or
+# "<testsuite> / <testcase>" to only display the runs of a testsuite.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := history.Open(store)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer s.Close()
+
+		entries, err := s.History(args[0], limit)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if len(entries) == 0 {
+			fmt.Printf("No run of %s\n", args[0])
+			return
+		}
+
+		red := color.New(color.FgRed).SprintFunc()
+		green := color.New(color.FgGreen).SprintFunc()
+		var passed int
+		var total float64
+		for _, e := range entries {
+			status := e.Status
+			switch status {
+			case "SUCCESS":
+				passed++
+				status = green(status)
+			case "FAILURE":
+				status = red(status)
+			}
+			total += e.Duration
+			sha := e.GitSHA
+			if len(sha) > 8 {
+				sha = sha[:8]
+			}
+			fmt.Printf("%s  %-8s  %-12s  %s  %8.3fs  %s\n", e.Start.Local().Format("2006-01-02 15:04:05"), sha, e.Environment, status, e.Duration, e.TestSuite)
+		}
+		fmt.Printf("\n%d/%d runs passed, average duration %.3fs\n", passed, len(entries), total/float64(len(entries)))
+	},
+}
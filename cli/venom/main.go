@@ -4,6 +4,9 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
+	"github.com/ovh/venom/cli/venom/diff"
+	"github.com/ovh/venom/cli/venom/history"
+	"github.com/ovh/venom/cli/venom/migrate"
 	"github.com/ovh/venom/cli/venom/run"
 	"github.com/ovh/venom/cli/venom/update"
 	"github.com/ovh/venom/cli/venom/version"
@@ -25,6 +28,10 @@ func main() {
 //AddCommands adds child commands to the root command rootCmd.
 func addCommands() {
 	rootCmd.AddCommand(run.Cmd)
+	rootCmd.AddCommand(run.MonitorCmd)
+	rootCmd.AddCommand(diff.Cmd)
+	rootCmd.AddCommand(history.Cmd)
+	rootCmd.AddCommand(migrate.Cmd)
 	rootCmd.AddCommand(version.Cmd)
 	rootCmd.AddCommand(update.Cmd)
 }
@@ -0,0 +1,65 @@
+package migrate
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ovh/venom"
+)
+
+var (
+	workdir string
+	dryRun  bool
+)
+
+func init() {
+	Cmd.Flags().StringVarP(&workdir, "workdir", "", ".", "--workdir tests : directory venom was run from, the relative paths of the version 1 testsuites are relative to it")
+	Cmd.Flags().BoolVarP(&dryRun, "dry-run", "", false, "Print the testsuites migrated instead of rewriting them")
+}
+
+// Cmd migrate
+var Cmd = &cobra.Command{
+	Use:   "migrate testsuite.yml...",
+	Short: "Rewrite testsuites to the latest version of the format: venom migrate tests/*.yml",
+	Long: `
+$ venom migrate tests/*.yml
+
+# rewrites the testsuites to the latest version of the format. The relative
+# paths of the version 1 testsuites are rebased on the directory of the
+# testsuite. The comments of the testsuites are not kept.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var failed bool
+		for _, f := range args {
+			out, from, warnings, err := venom.MigrateTestSuite(f, workdir)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				failed = true
+				continue
+			}
+			if from == venom.LatestVersion {
+				fmt.Printf("%s is already at version %s\n", f, from)
+				continue
+			}
+			if dryRun {
+				fmt.Printf("# %s\n%s", f, out)
+			} else {
+				if err := ioutil.WriteFile(f, out, 0644); err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					failed = true
+					continue
+				}
+				fmt.Printf("Migrated %s from version %s to %s\n", f, from, venom.LatestVersion)
+			}
+			for _, w := range warnings {
+				fmt.Printf("  %s: %s\n", f, w)
+			}
+		}
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
@@ -0,0 +1,95 @@
+package run
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"github.com/spf13/pflag"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// configFiles are the run configuration files looked up in the current directory
+var configFiles = []string{".venomrc", "venom.yml"}
+
+// findConfigFile returns the run configuration file of the current directory, or an empty string
+func findConfigFile() string {
+	for _, f := range configFiles {
+		if _, err := os.Stat(f); err == nil {
+			return f
+		}
+	}
+	return ""
+}
+
+// loadConfig sets the flags which are not given on the command line with the values of the run
+// configuration file. Keys of the file are the names of the flags. Variables of the var key can
+// be a map, they are overridden by the variables given on the command line.
+func loadConfig(flags *pflag.FlagSet, file string) error {
+	btes, err := ioutil.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("unable to read configuration file: %v", err)
+	}
+	var config map[string]interface{}
+	if err := yaml.Unmarshal(btes, &config); err != nil {
+		return fmt.Errorf("invalid configuration file %s: %v", file, err)
+	}
+
+	keys := make([]string, 0, len(config))
+	for k := range config {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		f := flags.Lookup(k)
+		if f == nil || k == "config" {
+			return fmt.Errorf("invalid configuration file %s: unknown key %s", file, k)
+		}
+		values, err := configValues(config[k])
+		if err != nil {
+			return fmt.Errorf("invalid configuration file %s: %s: %v", file, k, err)
+		}
+
+		slice, isSlice := f.Value.(pflag.SliceValue)
+		switch {
+		case isSlice && k == "var" && f.Changed:
+			err = slice.Replace(append(values, slice.GetSlice()...))
+		case f.Changed:
+			continue
+		case isSlice:
+			err = slice.Replace(values)
+		case len(values) == 1:
+			err = f.Value.Set(values[0])
+		default:
+			err = fmt.Errorf("a single value is expected")
+		}
+		if err != nil {
+			return fmt.Errorf("invalid configuration file %s: %s: %v", file, k, err)
+		}
+		f.Changed = true
+	}
+	return nil
+}
+
+// configValues returns the values of a key of the configuration file: a scalar, a list or a map of variables
+func configValues(v interface{}) ([]string, error) {
+	switch t := v.(type) {
+	case []interface{}:
+		values := make([]string, 0, len(t))
+		for _, i := range t {
+			values = append(values, fmt.Sprintf("%v", i))
+		}
+		return values, nil
+	case map[interface{}]interface{}:
+		values := make([]string, 0, len(t))
+		for k, i := range t {
+			values = append(values, fmt.Sprintf("%v=%v", k, i))
+		}
+		sort.Strings(values)
+		return values, nil
+	case nil:
+		return nil, fmt.Errorf("a value is expected")
+	}
+	return []string{fmt.Sprintf("%v", v)}, nil
+}
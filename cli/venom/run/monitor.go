@@ -0,0 +1,235 @@
+package run
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/ovh/venom"
+)
+
+var (
+	interval time.Duration
+	listen   string
+)
+
+func init() {
+	MonitorCmd.Flags().DurationVarP(&interval, "interval", "", 5*time.Minute, "--interval 5m : time between the start of two runs of the testsuites")
+	MonitorCmd.Flags().StringVarP(&listen, "listen", "", ":9090", "--listen :9090 : address of the HTTP server exposing the Prometheus /metrics")
+}
+
+// MonitorCmd runs the testsuites at regular intervals, as a synthetic monitoring agent
+var MonitorCmd = &cobra.Command{
+	Use:   "monitor",
+	Short: "Run Tests at regular intervals and expose Prometheus metrics",
+	Long: `
+$ venom monitor --interval 5m --listen :9090 monitors/*.yml
+
+# the flags of venom run can be used, a failed run opens
+# the alerts configured with --pagerduty-routing-key or
+# --opsgenie-api-key. The metrics of the last run are
+# exposed on http://localhost:9090/metrics, its state on
+# /status and its liveness on /healthz`,
+	PreRun: Cmd.PreRun,
+	Run: func(cmd *cobra.Command, args []string) {
+		// the monitor stops on SIGINT or SIGTERM, once the report of the current run is written
+		ctx := interruptContext()
+		setup(ctx, cmd)
+		v.Monitoring = true
+		if interval <= 0 {
+			log.Fatal("--interval must be positive")
+		}
+
+		m := &monitor{}
+		mux := http.NewServeMux()
+		mux.HandleFunc("/metrics", m.metrics)
+		mux.HandleFunc("/healthz", m.healthz)
+		mux.HandleFunc("/status", m.status)
+		go func() {
+			log.Fatal(http.ListenAndServe(listen, mux))
+		}()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			m.run(ctx)
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	},
+}
+
+// monitor keeps the results of the last run of the testsuites
+type monitor struct {
+	mutex        sync.Mutex
+	running      bool
+	runStart     time.Time
+	runs         int
+	failedRuns   int
+	lastRun      time.Time
+	lastDuration time.Duration
+	lastSuccess  bool
+	lastTests    *venom.Tests
+}
+
+func (m *monitor) run(ctx context.Context) {
+	start := time.Now()
+	m.mutex.Lock()
+	m.running = true
+	m.runStart = start
+	m.mutex.Unlock()
+
+	tests, elapsed, err := process(ctx)
+	if err == nil {
+		err = v.OutputResult(*tests, elapsed)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.running = false
+	m.runs++
+	m.lastRun = start
+	m.lastDuration = time.Since(start)
+	m.lastSuccess = err == nil && tests.TotalKO == 0
+	if !m.lastSuccess {
+		m.failedRuns++
+	}
+	if tests != nil {
+		m.lastTests = tests
+	}
+}
+
+// healthz fails when the current run lasts more than 3 intervals: the monitor is stuck
+func (m *monitor) healthz(w http.ResponseWriter, r *http.Request) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.running && time.Since(m.runStart) > 3*interval {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintf(w, "run started at %s is stuck\n", m.runStart.Format(time.RFC3339))
+		return
+	}
+	fmt.Fprintf(w, "ok\n")
+}
+
+type monitorStatus struct {
+	Running         bool            `json:"running"`
+	CurrentRunStart *time.Time      `json:"current_run_start,omitempty"`
+	Interval        string          `json:"interval"`
+	Runs            int             `json:"runs"`
+	FailedRuns      int             `json:"failed_runs"`
+	LastRun         *monitorLastRun `json:"last_run,omitempty"`
+}
+
+type monitorLastRun struct {
+	Start      time.Time                `json:"start"`
+	Duration   float64                  `json:"duration_seconds"`
+	Success    bool                     `json:"success"`
+	Total      int                      `json:"total"`
+	OK         int                      `json:"ok"`
+	KO         int                      `json:"ko"`
+	Skipped    int                      `json:"skipped"`
+	TestSuites []monitorTestSuiteStatus `json:"testsuites,omitempty"`
+}
+
+type monitorTestSuiteStatus struct {
+	Name    string `json:"name"`
+	Success bool   `json:"success"`
+	Time    string `json:"time"`
+}
+
+// status writes the state of the monitor and the results of the last run in JSON
+func (m *monitor) status(w http.ResponseWriter, r *http.Request) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	s := monitorStatus{Running: m.running, Interval: interval.String(), Runs: m.runs, FailedRuns: m.failedRuns}
+	if m.running {
+		start := m.runStart
+		s.CurrentRunStart = &start
+	}
+	if m.runs > 0 {
+		s.LastRun = &monitorLastRun{Start: m.lastRun, Duration: m.lastDuration.Seconds(), Success: m.lastSuccess}
+		if m.lastTests != nil {
+			s.LastRun.Total = m.lastTests.Total
+			s.LastRun.OK = m.lastTests.TotalOK
+			s.LastRun.KO = m.lastTests.TotalKO
+			s.LastRun.Skipped = m.lastTests.TotalSkipped
+			for _, ts := range m.lastTests.TestSuites {
+				s.LastRun.TestSuites = append(s.LastRun.TestSuites, monitorTestSuiteStatus{Name: ts.Name, Success: ts.Failures == 0 && ts.Errors == 0, Time: ts.Time})
+			}
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(s); err != nil {
+		log.Errorf("unable to write the status: %v", err)
+	}
+}
+
+// metrics writes the metrics of the last run in the Prometheus text format
+func (m *monitor) metrics(w http.ResponseWriter, r *http.Request) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	metric := func(name, kind, help string) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	}
+	metric("venom_runs_total", "counter", "Number of runs of the testsuites.")
+	fmt.Fprintf(w, "venom_runs_total %d\n", m.runs)
+	metric("venom_failed_runs_total", "counter", "Number of runs with failed testcases or errors.")
+	fmt.Fprintf(w, "venom_failed_runs_total %d\n", m.failedRuns)
+	if m.runs == 0 {
+		return
+	}
+	metric("venom_last_run_success", "gauge", "1 if all the testcases of the last run succeeded.")
+	fmt.Fprintf(w, "venom_last_run_success %d\n", boolMetric(m.lastSuccess))
+	metric("venom_last_run_timestamp_seconds", "gauge", "Start time of the last run.")
+	fmt.Fprintf(w, "venom_last_run_timestamp_seconds %d\n", m.lastRun.Unix())
+	metric("venom_last_run_duration_seconds", "gauge", "Duration of the last run.")
+	fmt.Fprintf(w, "venom_last_run_duration_seconds %.3f\n", m.lastDuration.Seconds())
+	if m.lastTests == nil {
+		return
+	}
+
+	metric("venom_testsuite_success", "gauge", "1 if all the testcases of the testsuite succeeded during the last run.")
+	for _, ts := range m.lastTests.TestSuites {
+		fmt.Fprintf(w, "venom_testsuite_success{testsuite=\"%s\"} %d\n", labelValue(ts.Name), boolMetric(ts.Failures == 0 && ts.Errors == 0))
+	}
+	metric("venom_testsuite_duration_seconds", "gauge", "Duration of the testsuite during the last run.")
+	for _, ts := range m.lastTests.TestSuites {
+		fmt.Fprintf(w, "venom_testsuite_duration_seconds{testsuite=\"%s\"} %s\n", labelValue(ts.Name), ts.Time)
+	}
+	metric("venom_testcase_success", "gauge", "1 if the testcase succeeded during the last run.")
+	for _, ts := range m.lastTests.TestSuites {
+		for _, tc := range ts.TestCases {
+			fmt.Fprintf(w, "venom_testcase_success{testsuite=\"%s\",testcase=\"%s\"} %d\n", labelValue(ts.Name), labelValue(tc.Name), boolMetric(len(tc.Failures) == 0 && len(tc.Errors) == 0))
+		}
+	}
+}
+
+func boolMetric(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// labelValue escapes a label value of the Prometheus text format
+func labelValue(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
@@ -0,0 +1,27 @@
+package run
+
+import (
+	"net"
+	"net/http"
+	"net/http/pprof"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// servePprof exposes the pprof endpoints of the run on /debug/pprof/, the profiles of a run still in progress
+func servePprof(addr string) {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Fatalf("unable to serve pprof on %s: %v", addr, err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	log.Infof("Serving pprof on http://%s/debug/pprof/", lis.Addr())
+	go func() {
+		log.Error(http.Serve(lis, mux))
+	}()
+}
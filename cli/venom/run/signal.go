@@ -0,0 +1,28 @@
+package run
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// exitInterrupted is the exit code of a run interrupted, 128 + SIGINT as the shells do
+const exitInterrupted = 130
+
+// interruptContext returns a context done on the first SIGINT or SIGTERM: the running steps are stopped and the
+// report of the testcases run is written. The second signal exits at once.
+func interruptContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		fmt.Fprintln(os.Stderr, "\nInterrupted, stopping the running steps. Interrupt again to exit at once.")
+		cancel()
+		<-signals
+		os.Exit(exitInterrupted)
+	}()
+	return ctx
+}
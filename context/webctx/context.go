@@ -2,6 +2,7 @@ package webctx
 
 import (
 	"fmt"
+	"io/ioutil"
 	"time"
 
 	"github.com/sclevine/agouti"
@@ -21,6 +22,7 @@ const (
 	Prefs   = "prefs"
 	Timeout = "timeout"
 	Debug   = "debug"
+	HAR     = "har" // Capture the network requests of the page, with the chrome driver
 )
 
 // New returns a new TestCaseContext
@@ -35,6 +37,10 @@ type WebTestCaseContext struct {
 	venom.CommonTestCaseContext
 	wd   *agouti.WebDriver
 	Page *agouti.Page
+	// HAR is true when the network requests of the page are captured, HARRequests is the number of requests
+	// already counted by the previous steps
+	HAR         bool
+	HARRequests int
 }
 
 // Init build context of type web.
@@ -68,17 +74,34 @@ func (tcc *WebTestCaseContext) Init() error {
 		}
 	}
 
+	if v, exist := tcc.TestCase.Context[HAR]; exist {
+		har, ok := v.(bool)
+		if !ok {
+			return fmt.Errorf("%s is not an boolean: %v", HAR, v)
+		}
+		tcc.HAR = har
+	}
+
 	switch driver {
 	case "chrome":
-		tcc.wd = agouti.ChromeDriver(
+		options := []agouti.Option{
 			agouti.ChromeOptions("args", args),
 			agouti.ChromeOptions("prefs", prefs),
-		)
+		}
+		if tcc.HAR {
+			// the network requests are read from the performance log of chrome
+			logging := map[string]string{"performance": "ALL"}
+			options = append(options, agouti.Desired(agouti.Capabilities{"goog:loggingPrefs": logging, "loggingPrefs": logging}))
+		}
+		tcc.wd = agouti.ChromeDriver(options...)
 	case "gecko":
 		tcc.wd = agouti.GeckoDriver()
 	default:
 		tcc.wd = agouti.PhantomJS()
 	}
+	if tcc.HAR && driver != "chrome" {
+		return fmt.Errorf("%s is only supported by the chrome driver", HAR)
+	}
 
 	timeout, existTimeout, errTimeout := isIntInContext(tcc.TestCase, Timeout)
 	if errTimeout != nil {
@@ -146,6 +169,26 @@ func isIntInContext(t venom.TestCase, n string) (int, bool, error) {
 	}
 }
 
+// Snapshot takes a screenshot of the page, prefix.png, and saves its HTML, prefix.html
+func (tcc *WebTestCaseContext) Snapshot(prefix string) ([]string, error) {
+	if tcc.Page == nil {
+		return nil, nil
+	}
+	var files []string
+	if err := tcc.Page.Screenshot(prefix + ".png"); err != nil {
+		return files, fmt.Errorf("Cannot take screenshot: %s", err)
+	}
+	files = append(files, prefix+".png")
+	html, err := tcc.Page.HTML()
+	if err != nil {
+		return files, fmt.Errorf("Cannot get HTML: %s", err)
+	}
+	if err := ioutil.WriteFile(prefix+".html", []byte(html), 0644); err != nil {
+		return files, err
+	}
+	return append(files, prefix+".html"), nil
+}
+
 // Close web driver
 func (tcc *WebTestCaseContext) Close() error {
 	return tcc.wd.Stop()
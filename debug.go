@@ -0,0 +1,183 @@
+package venom
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+const debugHelp = `Commands:
+  result [key]              print the result of the step, or a key of the result
+  vars [prefix]             print the variables
+  set <variable> <value>    set a variable, used when the step is run again
+  assert <assertion>        evaluate an assertion on the result: assert result.statuscode ShouldEqual 200
+  retry                     run the step again, with the variables set
+  continue                  continue the run, the testcase fails
+  abort                     abort the run, the next testcases are skipped
+`
+
+// debugOnFailure pauses the run on a failed step, with --debug-on-failure, and prompts the user to inspect
+// its result and run it again, until the step succeeds or the user continues or aborts the run
+func (v *Venom) debugOnFailure(tcc TestCaseContext, ts *TestSuite, tc *TestCase, stepNumber int, stepIn TestStep, result ExecutorResult, nbFailures, nbErrors int, l Logger) {
+	// the testsuites run in parallel share the console
+	v.debugMutex.Lock()
+	defer v.debugMutex.Unlock()
+	if v.debugAborted {
+		return
+	}
+	if v.debugReader == nil {
+		v.debugReader = bufio.NewReader(v.DebugInput)
+	}
+
+	for {
+		if len(tc.Failures) == nbFailures && len(tc.Errors) == nbErrors {
+			v.PrintFunc("Step %d of testcase %q succeeded, the run continues\n", stepNumber, tc.Name)
+			return
+		}
+		v.PrintFunc("Step %d of testcase %q of %s failed:\n", stepNumber, tc.Name, ts.Filename)
+		for _, f := range tc.Errors[nbErrors:] {
+			v.PrintFunc("%s\n", f.Value)
+		}
+		for _, f := range tc.Failures[nbFailures:] {
+			v.PrintFunc("%s\n", f.Value)
+		}
+
+		for retry := false; !retry; {
+			v.PrintFunc("(debug) ")
+			line, err := v.debugReader.ReadString('\n')
+			if err != nil && (err != io.EOF || line == "") {
+				v.PrintFunc("\n")
+				return
+			}
+			cmd, args := splitDebugCommand(line)
+			switch cmd {
+			case "":
+			case "help", "h":
+				v.PrintFunc(debugHelp)
+			case "result", "r":
+				printDebugValues(v, stringifyExecutorResult(result), args, true)
+			case "vars":
+				printDebugValues(v, ts.Templater.Values, args, false)
+			case "set":
+				kv := strings.SplitN(args, " ", 2)
+				if kv[0] == "" {
+					v.PrintFunc("usage: set <variable> <value>\n")
+					continue
+				}
+				value := ""
+				if len(kv) == 2 {
+					value = strings.TrimSpace(kv[1])
+				}
+				ts.Templater.Add("", map[string]string{kv[0]: value})
+			case "assert", "a":
+				_, failure := check(*tc, stepNumber, args, result)
+				if failure != nil {
+					v.PrintFunc("%s\n", failure.Value)
+				} else {
+					v.PrintFunc("OK\n")
+				}
+			case "retry":
+				retry = true
+			case "continue", "c":
+				return
+			case "abort", "q":
+				v.debugAborted = true
+				return
+			default:
+				v.PrintFunc("unknown command %q, type help for the commands\n", cmd)
+			}
+		}
+
+		tc.Failures, tc.Errors = tc.Failures[:nbFailures], tc.Errors[:nbErrors]
+		step, err := ts.Templater.ApplyOnStep(stepNumber, stepIn)
+		if err != nil {
+			tc.Errors = append(tc.Errors, Failure{Value: RemoveNotPrintableChar(err.Error())})
+			continue
+		}
+		e, err := v.WrapExecutor(step, tcc)
+		if err != nil {
+			tc.Errors = append(tc.Errors, Failure{Value: RemoveNotPrintableChar(err.Error())})
+			continue
+		}
+		result = v.RunTestStep(tcc, e, ts, tc, stepNumber, step, l)
+	}
+}
+
+// stepPrompt prompts before running a step, with --step: it returns false when the step is skipped or the run aborted
+func (v *Venom) stepPrompt(ts *TestSuite, tc *TestCase, stepNumber int, step TestStep) bool {
+	v.debugMutex.Lock()
+	defer v.debugMutex.Unlock()
+	if v.debugAborted {
+		return false
+	}
+	if !v.StepByStep {
+		return true
+	}
+	if v.debugReader == nil {
+		v.debugReader = bufio.NewReader(v.DebugInput)
+	}
+
+	input, err := yaml.Marshal(step)
+	if err != nil {
+		input = []byte(err.Error())
+	}
+	v.PrintFunc("Step %d of testcase %q of %s:\n%s", stepNumber, tc.Name, ts.Filename, input)
+	for {
+		v.PrintFunc("Run the step? [Y]es, [s]kip, [c]ontinue without prompting, [a]bort: ")
+		line, err := v.debugReader.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			v.PrintFunc("\n")
+			return true
+		}
+		switch strings.ToLower(strings.TrimSpace(line)) {
+		case "", "y", "yes":
+			return true
+		case "s", "skip":
+			return false
+		case "c", "continue":
+			v.StepByStep = false
+			return true
+		case "a", "abort":
+			v.debugAborted = true
+			return false
+		}
+	}
+}
+
+// debugRunAborted returns true when the run has been aborted from the debug or the step prompt
+func (v *Venom) debugRunAborted() bool {
+	v.debugMutex.Lock()
+	defer v.debugMutex.Unlock()
+	return v.debugAborted
+}
+
+// splitDebugCommand returns the command of a line of the debug prompt and its arguments
+func splitDebugCommand(line string) (string, string) {
+	line = strings.TrimSpace(line)
+	if i := strings.IndexAny(line, " \t"); i >= 0 {
+		return line[:i], strings.TrimSpace(line[i+1:])
+	}
+	return line, ""
+}
+
+// printDebugValues prints the values sorted by key: the value of the key if exact, or the values of the keys
+// starting with the prefix
+func printDebugValues(v *Venom, values map[string]string, prefix string, exact bool) {
+	if value, ok := values[prefix]; ok && exact {
+		v.PrintFunc("%s\n", value)
+		return
+	}
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v.PrintFunc("%s: %s\n", k, values[k])
+	}
+}
@@ -0,0 +1,88 @@
+package venom
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDebugOnFailure(t *testing.T) {
+	v := New()
+	var out strings.Builder
+	v.PrintFunc = func(format string, a ...interface{}) (int, error) {
+		return fmt.Fprintf(&out, format, a...)
+	}
+	v.DebugInput = strings.NewReader("result result.runs\nassert result.runs ShouldEqual 1\nfoo\nset expected 2\nretry\n")
+	v.RegisterExecutor("counting", &countingExecutor{})
+	ts := &TestSuite{Filename: "suite.yml", Templater: newTemplater(map[string]string{"expected": "3"})}
+	tcc := &CommonTestCaseContext{Name: "default"}
+
+	stepIn := TestStep{"type": "counting", "assertions": []interface{}{"result.runs ShouldEqual {{.expected}}"}}
+	step, err := ts.Templater.ApplyOnStep(0, stepIn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := v.WrapExecutor(step, tcc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tc := &TestCase{Name: "tc"}
+	result := v.RunTestStep(tcc, e, ts, tc, 0, step, TestLogger{t})
+	if len(tc.Failures) == 0 {
+		t.Fatal("expected a failure of the step")
+	}
+
+	v.debugOnFailure(tcc, ts, tc, 0, stepIn, result, 0, 0, TestLogger{t})
+	if len(tc.Failures) > 0 || len(tc.Errors) > 0 {
+		t.Errorf("expected the step to succeed when run again, got %v %v", tc.Failures, tc.Errors)
+	}
+	for _, s := range []string{"(debug) 1\n", "(debug) OK\n", `unknown command "foo"`, `Step 0 of testcase "tc" succeeded`} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("expected %q in the output:\n%s", s, out.String())
+		}
+	}
+	if v.debugRunAborted() {
+		t.Error("the run should not be aborted")
+	}
+
+	v.DebugInput, v.debugReader = strings.NewReader("abort\n"), nil
+	tc = &TestCase{Name: "tc2", Failures: []Failure{{Value: "failure"}}}
+	v.debugOnFailure(tcc, ts, tc, 0, stepIn, result, 0, 0, TestLogger{t})
+	if !v.debugRunAborted() {
+		t.Error("the run should be aborted")
+	}
+}
+
+func TestStepPrompt(t *testing.T) {
+	v := New()
+	var out strings.Builder
+	v.PrintFunc = func(format string, a ...interface{}) (int, error) {
+		return fmt.Fprintf(&out, format, a...)
+	}
+	v.StepByStep = true
+	v.DebugInput = strings.NewReader("\nfoo\ns\nc\n")
+	ts := &TestSuite{Filename: "suite.yml"}
+	tc := &TestCase{Name: "tc"}
+	step := TestStep{"type": "http", "url": "http://localhost/pets"}
+
+	for i, want := range []bool{true, false, true, true} {
+		if got := v.stepPrompt(ts, tc, i, step); got != want {
+			t.Errorf("stepPrompt() of step %d = %v, want %v", i, got, want)
+		}
+	}
+	if v.StepByStep {
+		t.Error("continue should stop prompting")
+	}
+	if n := strings.Count(out.String(), "Run the step?"); n != 4 {
+		t.Errorf("expected 4 prompts, got %d:\n%s", n, out.String())
+	}
+	if !strings.Contains(out.String(), "url: http://localhost/pets") {
+		t.Errorf("expected the input of the step in the output:\n%s", out.String())
+	}
+
+	v.StepByStep = true
+	v.DebugInput, v.debugReader = strings.NewReader("a\n"), nil
+	if v.stepPrompt(ts, tc, 0, step) || !v.debugRunAborted() {
+		t.Error("the run should be aborted")
+	}
+}
@@ -0,0 +1,109 @@
+package venom
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// TestsDiff is the comparison of the results of two runs, testcases are named "<testsuite> / <testcase>"
+type TestsDiff struct {
+	NewlyFailing    []string
+	NewlyPassing    []string
+	Added           []string
+	Removed         []string
+	DurationChanged []DurationChange
+}
+
+// DurationChange is a testcase whose duration changed between two runs, in seconds
+type DurationChange struct {
+	Name string
+	Old  float64
+	New  float64
+}
+
+// ReadTests reads a result file written with --format json, xml or yaml
+func ReadTests(filename string) (*Tests, error) {
+	btes, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	tests := &Tests{}
+	switch filepath.Ext(filename) {
+	case ".json":
+		err = json.Unmarshal(btes, tests)
+	case ".xml":
+		err = xml.Unmarshal(btes, tests)
+	case ".yml", ".yaml":
+		err = yaml.Unmarshal(btes, tests)
+	default:
+		return nil, fmt.Errorf("unsupported result file %s, must be json, xml or yaml", filename)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read result file %s: %v", filename, err)
+	}
+	return tests, nil
+}
+
+// DiffTests compares the results of two runs: the testcases which fail or pass since the run before, and the
+// testcases whose duration changed by more than threshold (0.2 for 20%) and more than minDelta seconds
+func DiffTests(before, after Tests, threshold, minDelta float64) TestsDiff {
+	oldCases, oldNames := indexTestCases(before)
+	newCases, newNames := indexTestCases(after)
+
+	var d TestsDiff
+	for _, name := range newNames {
+		tc := newCases[name]
+		oldTc, ok := oldCases[name]
+		if !ok {
+			d.Added = append(d.Added, name)
+			continue
+		}
+		oldStatus, newStatus := testCaseStatus(oldTc), testCaseStatus(tc)
+		switch {
+		case newStatus == "FAILURE" && oldStatus != "FAILURE":
+			d.NewlyFailing = append(d.NewlyFailing, name)
+		case newStatus == "SUCCESS" && oldStatus == "FAILURE":
+			d.NewlyPassing = append(d.NewlyPassing, name)
+		}
+
+		oldTime, errOld := strconv.ParseFloat(oldTc.Time, 64)
+		newTime, errNew := strconv.ParseFloat(tc.Time, 64)
+		if errOld != nil || errNew != nil {
+			continue
+		}
+		delta := math.Abs(newTime - oldTime)
+		if delta > minDelta && delta > threshold*oldTime {
+			d.DurationChanged = append(d.DurationChanged, DurationChange{Name: name, Old: oldTime, New: newTime})
+		}
+	}
+	for _, name := range oldNames {
+		if _, ok := newCases[name]; !ok {
+			d.Removed = append(d.Removed, name)
+		}
+	}
+	return d
+}
+
+func indexTestCases(tests Tests) (map[string]TestCase, []string) {
+	cases := map[string]TestCase{}
+	var names []string
+	for _, ts := range tests.TestSuites {
+		for _, tc := range ts.TestCases {
+			name := ts.Name + " / " + tc.Name
+			if _, ok := cases[name]; !ok {
+				names = append(names, name)
+			}
+			cases[name] = tc
+		}
+	}
+	sort.Strings(names)
+	return cases, names
+}
@@ -0,0 +1,35 @@
+package venom
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffTests(t *testing.T) {
+	before := Tests{TestSuites: []TestSuite{{Name: "suite", TestCases: []TestCase{
+		{Name: "fixed", Time: "1.0", Failures: []Failure{{Value: "boom"}}},
+		{Name: "broken", Time: "1.0"},
+		{Name: "slower", Time: "1.0"},
+		{Name: "faster", Time: "0.05"},
+		{Name: "removed", Time: "1.0"},
+	}}}}
+	after := Tests{TestSuites: []TestSuite{{Name: "suite", TestCases: []TestCase{
+		{Name: "fixed", Time: "1.1"},
+		{Name: "broken", Time: "1.0", Errors: []Failure{{Value: "boom"}}},
+		{Name: "slower", Time: "2.0"},
+		{Name: "faster", Time: "0.01"},
+		{Name: "added", Time: "1.0"},
+	}}}}
+
+	d := DiffTests(before, after, 0.5, 0.1)
+	expected := TestsDiff{
+		NewlyFailing:    []string{"suite / broken"},
+		NewlyPassing:    []string{"suite / fixed"},
+		Added:           []string{"suite / added"},
+		Removed:         []string{"suite / removed"},
+		DurationChanged: []DurationChange{{Name: "suite / slower", Old: 1, New: 2}},
+	}
+	if !reflect.DeepEqual(expected, d) {
+		t.Errorf("expected %+v, got %+v", expected, d)
+	}
+}
@@ -0,0 +1,146 @@
+package venom
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+const maskedValue = "********"
+
+// regexpSecret matches the names of the variables and of the step attributes holding secrets
+var regexpSecret = regexp.MustCompile(`(?i)(password|passwd|secret|token|api_?key|authorization|credential|private_?key)`)
+
+// DryRun reads the testsuites and prints their steps once the variables are interpolated, with the secrets masked,
+// without running the executors. The variables extracted from the results of the steps are not interpolated.
+func (v *Venom) DryRun(path []string, exclude []string) error {
+	v.runMutex.Lock()
+	defer v.runMutex.Unlock()
+	if err := v.init(); err != nil {
+		return err
+	}
+	path, err := v.resolveRemoteSources(path)
+	if err != nil {
+		return err
+	}
+	filesPath, err := getFilesPath(path, exclude)
+	if err != nil {
+		return err
+	}
+	if err := v.readFiles(filesPath); err != nil {
+		return err
+	}
+	return v.printRenderedSteps()
+}
+
+// printRenderedSteps prints the steps of the testsuites read, the setup, the teardown and the steps run around the
+// steps of each testcase included, interpolated and with the secrets masked
+func (v *Venom) printRenderedSteps() error {
+	for i := range v.testsuites {
+		ts := &v.testsuites[i]
+		initTestSuiteTemplater(ts)
+		secrets := secretValues(ts.Templater.Values)
+
+		v.PrintFunc("Testsuite %s\n", ts.Name)
+		if len(ts.Setup) > 0 {
+			v.PrintFunc("  Setup\n")
+			ts.Templater.Add("", map[string]string{"venom.testcase": "setup"})
+			if err := v.printSteps(ts, "setup", "Step", ts.Setup, secrets); err != nil {
+				return err
+			}
+		}
+		for _, tc := range ts.TestCases {
+			if len(tc.Skipped) > 0 {
+				v.PrintFunc("  Testcase %s: skipped\n", tc.Name)
+				continue
+			}
+			v.PrintFunc("  Testcase %s\n", tc.Name)
+			ts.Templater.Add("", map[string]string{"venom.testcase": tc.Name})
+			for _, s := range []struct {
+				label string
+				steps []TestStep
+			}{{"Before each step", ts.BeforeEach}, {"Step", tc.TestSteps}, {"After each step", ts.AfterEach}} {
+				if err := v.printSteps(ts, "testcase "+tc.Name, s.label, s.steps, secrets); err != nil {
+					return err
+				}
+			}
+		}
+		if len(ts.Teardown) > 0 {
+			v.PrintFunc("  Teardown\n")
+			ts.Templater.Add("", map[string]string{"venom.testcase": "teardown"})
+			if err := v.printSteps(ts, "teardown", "Step", ts.Teardown, secrets); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// printSteps prints the steps interpolated and with the secrets masked, each one prefixed by the label and its number
+func (v *Venom) printSteps(ts *TestSuite, name, label string, steps []TestStep, secrets []string) error {
+	for stepNumber, stepIn := range steps {
+		step, err := ts.Templater.ApplyOnStep(stepNumber, stepIn)
+		if err != nil {
+			return fmt.Errorf("%s of %s: %v", name, ts.Filename, err)
+		}
+		btes, err := yaml.Marshal(maskSecrets(map[string]interface{}(step)))
+		if err != nil {
+			return err
+		}
+		rendered := string(btes)
+		for _, s := range secrets {
+			rendered = strings.Replace(rendered, s, maskedValue, -1)
+		}
+		v.PrintFunc("    %s %d:\n      %s\n", label, stepNumber, strings.Replace(strings.TrimSpace(rendered), "\n", "\n      ", -1))
+	}
+	return nil
+}
+
+// secretValues returns the values of the variables holding secrets, the longest first. The values shorter
+// than 4 characters are not masked, they would mask anything.
+func secretValues(vars map[string]string) []string {
+	var secrets []string
+	for k, val := range vars {
+		if len(val) >= 4 && regexpSecret.MatchString(k) {
+			secrets = append(secrets, val)
+		}
+	}
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+	return secrets
+}
+
+// maskSecrets returns a copy of the value, the attributes of the maps named like secrets are masked
+func maskSecrets(value interface{}) interface{} {
+	switch t := value.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, val := range t {
+			if regexpSecret.MatchString(k) {
+				m[k] = maskedValue
+				continue
+			}
+			m[k] = maskSecrets(val)
+		}
+		return m
+	case map[interface{}]interface{}:
+		m := make(map[interface{}]interface{}, len(t))
+		for k, val := range t {
+			if regexpSecret.MatchString(fmt.Sprintf("%v", k)) {
+				m[k] = maskedValue
+				continue
+			}
+			m[k] = maskSecrets(val)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(t))
+		for i, val := range t {
+			l[i] = maskSecrets(val)
+		}
+		return l
+	}
+	return value
+}
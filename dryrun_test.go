@@ -0,0 +1,69 @@
+package venom
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestPrintRenderedSteps(t *testing.T) {
+	f, err := ioutil.TempFile("", "dryrun*.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	suite := `name: dry run
+vars:
+  api_token: s3cr3t-token
+setup:
+- script: echo setup {{.venom.testcase}}
+teardown:
+- script: echo teardown {{.url}}
+before_each:
+- script: echo before {{.venom.testcase}}
+after_each:
+- script: echo after {{.venom.testcase}} {{.api_token}}
+testcases:
+- name: create
+  steps:
+  - type: http
+    method: POST
+    url: "{{.url}}/pets"
+    headers:
+      Authorization: "Bearer {{.api_token}}"
+      X-Trace: "{{.api_token}}"
+    body: '{"name": "{{.venom.testcase}}"}'
+  - script: echo {{.create.result.bodyjson.id}}
+`
+	if _, err := f.WriteString(suite); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	v := New()
+	var out strings.Builder
+	v.PrintFunc = func(format string, a ...interface{}) (int, error) {
+		return fmt.Fprintf(&out, format, a...)
+	}
+	v.AddVariables(map[string]string{"url": "http://localhost:8080"})
+	if err := v.readFiles([]string{f.Name()}); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.printRenderedSteps(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []string{"Testsuite dry run", "  Testcase create", "    Step 0:", "url: http://localhost:8080/pets",
+		`body: '{"name": "create"}'`, "Authorization: '********'", "X-Trace: ********", "{{.create.result.bodyjson.id}}",
+		"  Setup\n    Step 0:\n      script: echo setup setup", "    Before each step 0:\n      script: echo before create",
+		"    After each step 0:\n      script: echo after create ********", "  Teardown\n    Step 0:\n      script: echo teardown http://localhost:8080"} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("expected %q in the output:\n%s", s, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "s3cr3t") {
+		t.Errorf("the secrets should be masked:\n%s", out.String())
+	}
+}
@@ -0,0 +1,82 @@
+package venom
+
+import "fmt"
+
+// applyExecutorConfig merges the config of the testsuite, keyed by executor name, into the steps of these
+// executors. The attributes of the steps override the config, the maps are merged: the headers for instance.
+func applyExecutorConfig(ts *TestSuite) {
+	if len(ts.Config) == 0 {
+		return
+	}
+	testCases := ts.withHooks()
+	for i := range testCases {
+		tc := &testCases[i]
+		for j, step := range tc.TestSteps {
+			config, ok := ts.Config[stepExecutorName(tc, step)]
+			if !ok {
+				continue
+			}
+			tc.TestSteps[j] = TestStep(mergeConfig(config, map[string]interface{}(step)).(map[string]interface{}))
+		}
+	}
+}
+
+// stepExecutorName returns the name of the executor of a step, as WrapExecutor does
+func stepExecutorName(tc *TestCase, step TestStep) string {
+	if itype, ok := step["type"]; ok && fmt.Sprintf("%s", itype) != "" {
+		return fmt.Sprintf("%s", itype)
+	}
+	if itype, ok := tc.Context["type"]; ok && fmt.Sprintf("%s", itype) != "" && fmt.Sprintf("%s", itype) != "default" {
+		return fmt.Sprintf("%s", itype)
+	}
+	return "exec"
+}
+
+// mergeConfig returns the value of a step merged with the value of the config, the maps of the config are copied
+func mergeConfig(config, value interface{}) interface{} {
+	switch c := config.(type) {
+	case map[string]interface{}:
+		m := map[string]interface{}{}
+		for k, v := range c {
+			m[k] = mergeConfig(v, nil)
+		}
+		if value == nil {
+			return m
+		}
+		v, ok := value.(map[string]interface{})
+		if !ok {
+			if v, ok = stringKeys(value); !ok {
+				return value
+			}
+		}
+		for k, val := range v {
+			m[k] = mergeConfig(m[k], val)
+		}
+		return m
+	case map[interface{}]interface{}:
+		m, _ := stringKeys(c)
+		return mergeConfig(m, value)
+	case []interface{}:
+		if value != nil {
+			return value
+		}
+		return append([]interface{}{}, c...)
+	}
+	if value != nil {
+		return value
+	}
+	return config
+}
+
+// stringKeys converts a map decoded from yaml to a map of strings
+func stringKeys(value interface{}) (map[string]interface{}, bool) {
+	m, ok := value.(map[interface{}]interface{})
+	if !ok {
+		return nil, false
+	}
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		out[fmt.Sprintf("%v", k)] = v
+	}
+	return out, true
+}
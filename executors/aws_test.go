@@ -0,0 +1,120 @@
+package executors
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSignAWSRequest checks the signatures of the AWS Signature Version 4 test suite
+func TestSignAWSRequest(t *testing.T) {
+	creds := AWSCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", Region: "us-east-1"}
+	date := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+	tests := []struct {
+		name          string
+		method        string
+		url           string
+		header        map[string]string
+		body          string
+		service       string
+		authorization string
+	}{
+		{
+			name:          "get-vanilla",
+			method:        "GET",
+			url:           "https://example.amazonaws.com/",
+			service:       "service",
+			authorization: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name:          "get-vanilla-query-order-key-case",
+			method:        "GET",
+			url:           "https://example.amazonaws.com/?Param2=value2&Param1=value1",
+			service:       "service",
+			authorization: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+		},
+		{
+			name:          "post-vanilla",
+			method:        "POST",
+			url:           "https://example.amazonaws.com/",
+			service:       "service",
+			authorization: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b",
+		},
+		{
+			name:          "post-x-www-form-urlencoded",
+			method:        "POST",
+			url:           "https://example.amazonaws.com/",
+			header:        map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+			body:          "Param1=value1",
+			service:       "service",
+			authorization: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=content-type;host;x-amz-date, Signature=ff11897932ad3f4e8b18135d722051e5ac45fc38421b1da7b9d196a0fe09473a",
+		},
+		{
+			// the example of the documentation of the signature
+			name:          "iam ListUsers",
+			method:        "GET",
+			url:           "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08",
+			header:        map[string]string{"Content-Type": "application/x-www-form-urlencoded; charset=utf-8"},
+			service:       "iam",
+			authorization: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, SignedHeaders=content-type;host;x-amz-date, Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			require.NoError(t, err)
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			SignAWSRequest(req, []byte(tt.body), tt.service, creds, date)
+			assert.Equal(t, "20150830T123600Z", req.Header.Get("X-Amz-Date"))
+			assert.Equal(t, tt.authorization, req.Header.Get("Authorization"))
+		})
+	}
+
+	// the session token is signed
+	req, err := http.NewRequest("GET", "https://example.amazonaws.com/", nil)
+	require.NoError(t, err)
+	creds.SessionToken = "token"
+	SignAWSRequest(req, nil, "service", creds, date)
+	assert.Equal(t, "token", req.Header.Get("X-Amz-Security-Token"))
+	assert.Contains(t, req.Header.Get("Authorization"), "SignedHeaders=host;x-amz-date;x-amz-security-token,")
+}
+
+func TestAWSQuery(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		switch {
+		case !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/"):
+			w.WriteHeader(http.StatusForbidden)
+		case string(body) == "Action=Wait":
+			<-r.Context().Done()
+		default:
+			w.Write([]byte("<Response>" + string(body) + "</Response>"))
+		}
+	}))
+	defer srv.Close()
+	creds := AWSCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret", Region: "eu-west-1"}
+
+	btes, err := AWSQuery(context.Background(), srv.URL, "sqs", creds, url.Values{"Action": {"SendMessage"}})
+	require.NoError(t, err)
+	assert.Equal(t, "<Response>Action=SendMessage</Response>", string(btes))
+
+	_, err = AWSQuery(context.Background(), srv.URL, "sqs", AWSCredentials{}, url.Values{"Action": {"SendMessage"}})
+	assert.EqualError(t, err, "sqs 403 Forbidden: ")
+
+	// the request is cancelled with its context
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = AWSQuery(ctx, srv.URL, "sqs", creds, url.Values{"Action": {"Wait"}})
+	assert.Error(t, err)
+	assert.Equal(t, context.DeadlineExceeded, ctx.Err())
+}
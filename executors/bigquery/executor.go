@@ -0,0 +1,305 @@
+package bigquery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/ovh/venom"
+	"github.com/ovh/venom/executors"
+)
+
+// Name of executor
+const Name = "bigquery"
+
+const scope = "https://www.googleapis.com/auth/bigquery"
+
+// New returns a new Executor
+func New() venom.Executor {
+	return &Executor{}
+}
+
+// Executor runs a query on Google BigQuery
+type Executor struct {
+	Project         string `json:"project,omitempty" yaml:"project,omitempty"`
+	Query           string `json:"query,omitempty" yaml:"query,omitempty"`
+	Location        string `json:"location,omitempty" yaml:"location,omitempty"`
+	UseLegacySQL    bool   `json:"use_legacy_sql,omitempty" yaml:"use_legacy_sql,omitempty" mapstructure:"use_legacy_sql"`
+	CredentialsFile string `json:"credentials_file,omitempty" yaml:"credentials_file,omitempty" mapstructure:"credentials_file"`
+	Token           string `json:"token,omitempty" yaml:"token,omitempty"`
+	Endpoint        string `json:"endpoint,omitempty" yaml:"endpoint,omitempty"`
+	WaitTimeout     int    `json:"wait_timeout,omitempty" yaml:"wait_timeout,omitempty" mapstructure:"wait_timeout"`
+}
+
+// Column describes a column of the query result
+type Column struct {
+	Name string `json:"name" yaml:"name"`
+	Type string `json:"type" yaml:"type"`
+}
+
+// Result represents a step result
+type Result struct {
+	Executor    Executor                 `json:"executor,omitempty" yaml:"executor,omitempty"`
+	JobID       string                   `json:"jobid,omitempty" yaml:"jobid,omitempty"`
+	Columns     []Column                 `json:"columns,omitempty" yaml:"columns,omitempty"`
+	Rows        []map[string]interface{} `json:"rows,omitempty" yaml:"rows,omitempty"`
+	RowCount    int64                    `json:"rowcount" yaml:"rowcount"`
+	Err         string                   `json:"err,omitempty" yaml:"err,omitempty"`
+	TimeSeconds float64                  `json:"timeseconds,omitempty" yaml:"timeseconds,omitempty"`
+	TimeHuman   string                   `json:"timehuman,omitempty" yaml:"timehuman,omitempty"`
+}
+
+type field struct {
+	Name   string  `json:"name"`
+	Type   string  `json:"type"`
+	Mode   string  `json:"mode"`
+	Fields []field `json:"fields"`
+}
+
+type cell struct {
+	V interface{} `json:"v"`
+}
+
+type row struct {
+	F []cell `json:"f"`
+}
+
+// queryResponse is the response of jobs.query and jobs.getQueryResults
+type queryResponse struct {
+	JobReference struct {
+		JobID    string `json:"jobId"`
+		Location string `json:"location"`
+	} `json:"jobReference"`
+	Schema struct {
+		Fields []field `json:"fields"`
+	} `json:"schema"`
+	Rows        []row  `json:"rows"`
+	TotalRows   string `json:"totalRows"`
+	PageToken   string `json:"pageToken"`
+	JobComplete bool   `json:"jobComplete"`
+	Error       *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// ZeroValueResult return an empty implemtation of this executor result
+func (Executor) ZeroValueResult() venom.ExecutorResult {
+	r, _ := executors.Dump(Result{})
+	return r
+}
+
+// GetDefaultAssertions return default assertions for this executor
+func (Executor) GetDefaultAssertions() *venom.StepAssertions {
+	return &venom.StepAssertions{Assertions: []string{"result.err ShouldBeEmpty"}}
+}
+
+// Run execute TestStep
+func (Executor) Run(testCaseContext venom.TestCaseContext, l venom.Logger, step venom.TestStep, workdir string) (venom.ExecutorResult, error) {
+	var e Executor
+	if err := mapstructure.Decode(step, &e); err != nil {
+		return nil, err
+	}
+	if e.Query == "" {
+		return nil, fmt.Errorf("query is mandatory")
+	}
+	if e.Endpoint == "" {
+		e.Endpoint = "https://bigquery.googleapis.com"
+	}
+	if e.WaitTimeout <= 0 {
+		e.WaitTimeout = 60
+	}
+	if e.Token == "" || e.Project == "" {
+		creds, err := executors.ReadGoogleCredentials(e.CredentialsFile)
+		if err != nil {
+			return nil, err
+		}
+		if e.Project == "" {
+			e.Project = creds.ProjectID
+		}
+		if e.Token == "" {
+			if e.Token, err = creds.AccessToken(testCaseContext.Context(), scope); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	start := time.Now()
+	result := Result{Executor: e}
+	if err := e.query(testCaseContext.Context(), l, &result); err != nil {
+		result.Err = err.Error()
+	}
+	result.Executor.Token = "****hidden****" // do not output token
+
+	elapsed := time.Since(start)
+	result.TimeSeconds = elapsed.Seconds()
+	result.TimeHuman = elapsed.String()
+
+	return executors.Dump(result)
+}
+
+// query runs the query, waits for the job to complete and reads all the pages of results
+func (e Executor) query(ctx context.Context, l venom.Logger, result *Result) error {
+	timeoutMs := e.WaitTimeout * 1000
+	body := map[string]interface{}{
+		"query":        e.Query,
+		"useLegacySql": e.UseLegacySQL,
+		"timeoutMs":    timeoutMs,
+	}
+	if e.Location != "" {
+		body["location"] = e.Location
+	}
+	var resp queryResponse
+	if err := e.do(ctx, l, http.MethodPost, "/queries", body, &resp); err != nil {
+		return err
+	}
+	result.JobID = resp.JobReference.JobID
+
+	deadline := time.Now().Add(time.Duration(e.WaitTimeout) * time.Second)
+	for {
+		if resp.JobComplete {
+			if result.Columns == nil {
+				for _, f := range resp.Schema.Fields {
+					result.Columns = append(result.Columns, Column{Name: f.Name, Type: f.Type})
+				}
+				result.RowCount, _ = strconv.ParseInt(resp.TotalRows, 10, 64)
+			}
+			for _, r := range resp.Rows {
+				result.Rows = append(result.Rows, convertRow(resp.Schema.Fields, r))
+			}
+			if resp.PageToken == "" {
+				return nil
+			}
+		} else if time.Now().After(deadline) {
+			return fmt.Errorf("job %s is not complete after %d second(s)", result.JobID, e.WaitTimeout)
+		}
+
+		params := url.Values{"timeoutMs": {strconv.Itoa(timeoutMs)}}
+		if resp.PageToken != "" {
+			params.Set("pageToken", resp.PageToken)
+		}
+		if resp.JobReference.Location != "" {
+			params.Set("location", resp.JobReference.Location)
+		}
+		path := "/queries/" + url.PathEscape(result.JobID) + "?" + params.Encode()
+		resp = queryResponse{}
+		if err := e.do(ctx, l, http.MethodGet, path, nil, &resp); err != nil {
+			return err
+		}
+	}
+}
+
+func (e Executor) do(ctx context.Context, l venom.Logger, method, path string, body interface{}, target *queryResponse) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return err
+		}
+	}
+
+	u := strings.TrimSuffix(e.Endpoint, "/") + "/bigquery/v2/projects/" + url.PathEscape(e.Project) + path
+	l.Debugf("bigquery> %s %s", method, u)
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+e.Token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	btes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(btes, target); err != nil {
+		return fmt.Errorf("unable to read response (%s): %v", resp.Status, err)
+	}
+	if target.Error != nil {
+		return fmt.Errorf("%s: %s", resp.Status, target.Error.Message)
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s: %s", resp.Status, string(btes))
+	}
+	return nil
+}
+
+// convertRow converts a row into a map column name -> typed value
+func convertRow(fields []field, r row) map[string]interface{} {
+	res := make(map[string]interface{}, len(fields))
+	for i, f := range fields {
+		if i < len(r.F) {
+			res[f.Name] = convertValue(f, r.F[i].V)
+		}
+	}
+	return res
+}
+
+func convertValue(f field, v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	if f.Mode == "REPEATED" {
+		values, _ := v.([]interface{})
+		res := make([]interface{}, 0, len(values))
+		item := f
+		item.Mode = ""
+		for _, value := range values {
+			if c, ok := value.(map[string]interface{}); ok {
+				value = c["v"]
+			}
+			res = append(res, convertValue(item, value))
+		}
+		return res
+	}
+
+	switch f.Type {
+	case "RECORD", "STRUCT":
+		m, _ := v.(map[string]interface{})
+		cells, _ := m["f"].([]interface{})
+		var r row
+		for _, c := range cells {
+			cm, _ := c.(map[string]interface{})
+			r.F = append(r.F, cell{V: cm["v"]})
+		}
+		return convertRow(f.Fields, r)
+	}
+
+	s, ok := v.(string)
+	if !ok {
+		return v
+	}
+	switch f.Type {
+	case "INTEGER", "INT64":
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return i
+		}
+	case "FLOAT", "FLOAT64", "NUMERIC", "BIGNUMERIC":
+		if n, err := strconv.ParseFloat(s, 64); err == nil {
+			return n
+		}
+	case "BOOLEAN", "BOOL":
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b
+		}
+	case "TIMESTAMP":
+		// timestamps are returned as a number of seconds since epoch
+		if n, err := strconv.ParseFloat(s, 64); err == nil {
+			return time.Unix(0, int64(n*1e9)).UTC().Format(time.RFC3339Nano)
+		}
+	}
+	return s
+}
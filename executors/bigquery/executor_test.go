@@ -0,0 +1,134 @@
+package bigquery
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/venom"
+)
+
+const schema = `{"fields": [
+	{"name": "id", "type": "INTEGER"},
+	{"name": "amount", "type": "NUMERIC"},
+	{"name": "paid", "type": "BOOLEAN"},
+	{"name": "created", "type": "TIMESTAMP"},
+	{"name": "tags", "type": "STRING", "mode": "REPEATED"},
+	{"name": "customer", "type": "RECORD", "fields": [{"name": "name", "type": "STRING"}, {"name": "age", "type": "INT64"}]}
+]}`
+
+// newBigQueryServer starts a fake token endpoint and a fake BigQuery API: the job of the query completes at its
+// first poll, and its results have two pages
+func newBigQueryServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"access_token": "bq-token", "expires_in": 3600}`)
+	})
+	mux.HandleFunc("/bigquery/v2/projects/project/queries", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		switch {
+		case r.Header.Get("Authorization") != "Bearer bq-token":
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error": {"code": 401, "message": "Request had invalid authentication credentials."}}`)
+		case body["query"] == "SELECT sleep()":
+			<-r.Context().Done()
+		case body["query"] == "SELECT * FROM orders" && body["location"] == "EU" && body["useLegacySql"] == false:
+			fmt.Fprint(w, `{"jobReference": {"jobId": "job_1", "location": "EU"}, "jobComplete": false}`)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, `{"error": {"code": 400, "message": "Syntax error: %s"}}`, body["query"])
+		}
+	})
+	mux.HandleFunc("/bigquery/v2/projects/project/queries/job_1", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("location") != "EU" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error": {"code": 404, "message": "Not found: Job project:job_1"}}`)
+			return
+		}
+		if r.URL.Query().Get("pageToken") == "" {
+			fmt.Fprintf(w, `{"jobReference": {"jobId": "job_1", "location": "EU"}, "jobComplete": true, "totalRows": "2", "pageToken": "page2", "schema": %s,
+				"rows": [{"f": [{"v": "1"}, {"v": "12.5"}, {"v": "true"}, {"v": "1.709287200E9"}, {"v": [{"v": "new"}, {"v": "web"}]}, {"v": {"f": [{"v": "Ada"}, {"v": "36"}]}}]}]}`, schema)
+			return
+		}
+		fmt.Fprintf(w, `{"jobReference": {"jobId": "job_1", "location": "EU"}, "jobComplete": true, "totalRows": "2", "schema": %s,
+			"rows": [{"f": [{"v": "2"}, {"v": null}, {"v": "false"}, {"v": null}, {"v": []}, {"v": null}]}]}`, schema)
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestRun(t *testing.T) {
+	srv := newBigQueryServer()
+	defer srv.Close()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	dir, err := ioutil.TempDir("", "bigquery")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	credentials, _ := json.Marshal(map[string]string{
+		"project_id":   "project",
+		"client_email": "venom@project.iam.gserviceaccount.com",
+		"private_key":  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"token_uri":    srv.URL + "/token",
+	})
+	file := filepath.Join(dir, "credentials.json")
+	require.NoError(t, ioutil.WriteFile(file, credentials, 0600))
+
+	run := func(ctx context.Context, step venom.TestStep) venom.ExecutorResult {
+		step["endpoint"] = srv.URL
+		step["credentials_file"] = file
+		tcc := &venom.CommonTestCaseContext{}
+		tcc.SetContext(ctx)
+		res, err := Executor{}.Run(tcc, logrus.New(), step, "")
+		require.NoError(t, err)
+		return res
+	}
+
+	res := run(context.Background(), venom.TestStep{"query": "SELECT * FROM orders", "location": "EU"})
+	assert.Empty(t, res["result.err"])
+	assert.Equal(t, "job_1", res["result.jobid"])
+	assert.Equal(t, int64(2), res["result.rowcount"])
+	assert.Equal(t, "****hidden****", res["result.executor.token"])
+	assert.Equal(t, "amount", res["result.columns.columns1.name"])
+	assert.Equal(t, "NUMERIC", res["result.columns.columns1.type"])
+	assert.Equal(t, int64(1), res["result.rows.rows0.id"])
+	assert.Equal(t, 12.5, res["result.rows.rows0.amount"])
+	assert.Equal(t, true, res["result.rows.rows0.paid"])
+	assert.Equal(t, "2024-03-01T10:00:00Z", res["result.rows.rows0.created"])
+	assert.Equal(t, "web", res["result.rows.rows0.tags.tags1"])
+	assert.Equal(t, "Ada", res["result.rows.rows0.customer.name"])
+	assert.Equal(t, int64(36), res["result.rows.rows0.customer.age"])
+	assert.Equal(t, int64(2), res["result.rows.rows1.id"])
+	assert.Equal(t, false, res["result.rows.rows1.paid"])
+	assert.Empty(t, res["result.rows.rows1.amount"])
+
+	res = run(context.Background(), venom.TestStep{"query": "SELEC 1"})
+	assert.Equal(t, "400 Bad Request: Syntax error: SELEC 1", res["result.err"])
+
+	res = run(context.Background(), venom.TestStep{"query": "SELECT 1", "token": "expired", "project": "project"})
+	assert.Equal(t, "401 Unauthorized: Request had invalid authentication credentials.", res["result.err"])
+
+	// the query stops with the context of the step
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	res = run(ctx, venom.TestStep{"query": "SELECT sleep()"})
+	assert.Contains(t, res["result.err"], "context deadline exceeded")
+}
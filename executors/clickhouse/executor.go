@@ -0,0 +1,295 @@
+package clickhouse
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/ovh/venom"
+	"github.com/ovh/venom/executors"
+)
+
+// Name of the executor.
+const Name = "clickhouse"
+
+// New returns a new executor that can execute ClickHouse queries
+func New() venom.Executor {
+	return &Executor{}
+}
+
+// Executor is a venom executor can execute ClickHouse queries, using the ClickHouse native protocol
+type Executor struct {
+	Addr               string            `json:"addr,omitempty" yaml:"addr,omitempty"`
+	User               string            `json:"user,omitempty" yaml:"user,omitempty"`
+	Password           string            `json:"password,omitempty" yaml:"password,omitempty"`
+	Database           string            `json:"database,omitempty" yaml:"database,omitempty"`
+	Secure             bool              `json:"secure,omitempty" yaml:"secure,omitempty"`
+	IgnoreVerifySSL    bool              `json:"ignore_verify_ssl,omitempty" yaml:"ignore_verify_ssl,omitempty" mapstructure:"ignore_verify_ssl"`
+	File               string            `json:"file,omitempty" yaml:"file,omitempty"`
+	Commands           []string          `json:"commands,omitempty" yaml:"commands,omitempty"`
+	Settings           map[string]string `json:"settings,omitempty" yaml:"settings,omitempty"`
+	AsyncInsert        bool              `json:"async_insert,omitempty" yaml:"async_insert,omitempty" mapstructure:"async_insert"`
+	FlushAsyncInserts  bool              `json:"flush_async_inserts,omitempty" yaml:"flush_async_inserts,omitempty" mapstructure:"flush_async_inserts"`
+	NoWaitAsyncInserts bool              `json:"no_wait_async_inserts,omitempty" yaml:"no_wait_async_inserts,omitempty" mapstructure:"no_wait_async_inserts"`
+}
+
+// Column describes a column returned by a query.
+type Column struct {
+	Name string `json:"name" yaml:"name"`
+	Type string `json:"type" yaml:"type"`
+}
+
+// Row represents a row returned by a query.
+type Row map[string]interface{}
+
+// QueryResult represents the result of a query execution.
+type QueryResult struct {
+	Columns     []Column `json:"columns,omitempty" yaml:"columns,omitempty"`
+	Rows        []Row    `json:"rows,omitempty" yaml:"rows,omitempty"`
+	RowCount    int64    `json:"rowcount" yaml:"rowcount"`
+	Elapsed     float64  `json:"elapsed,omitempty" yaml:"elapsed,omitempty"`
+	RowsRead    uint64   `json:"rowsread,omitempty" yaml:"rowsread,omitempty"`
+	BytesRead   uint64   `json:"bytesread,omitempty" yaml:"bytesread,omitempty"`
+	RowsWritten uint64   `json:"rowswritten,omitempty" yaml:"rowswritten,omitempty"`
+}
+
+// Result represents a step result.
+type Result struct {
+	Executor Executor      `json:"executor,omitempty" yaml:"executor,omitempty"`
+	Queries  []QueryResult `json:"queries,omitempty" yaml:"queries,omitempty"`
+}
+
+// selectQuery matches the queries returning rows, the other ones are executed without reading rows
+var selectQuery = regexp.MustCompile(`(?is)^[\s(]*(SELECT|WITH|SHOW|DESC|DESCRIBE|EXISTS|EXPLAIN)\b`)
+
+// comments matches the comments before a query
+var comments = regexp.MustCompile(`^\s*(--[^\n]*\n|/\*.*?\*/)`)
+
+// ZeroValueResult return an empty implemtation of this executor result
+func (Executor) ZeroValueResult() venom.ExecutorResult {
+	r, _ := executors.Dump(Result{})
+	return r
+}
+
+// Run implements the venom.Executor interface for Executor.
+func (e Executor) Run(testCaseContext venom.TestCaseContext, l venom.Logger, step venom.TestStep, workdir string) (venom.ExecutorResult, error) {
+	if err := mapstructure.Decode(step, &e); err != nil {
+		return nil, err
+	}
+	if e.Addr == "" {
+		e.Addr = "localhost:9000"
+	}
+
+	commands := e.Commands
+	if len(commands) == 0 && e.File != "" {
+		l.Debugf("loading SQL file from folder %s\n", e.File)
+		file := filepath.Join(workdir, e.File)
+		btes, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		commands = []string{string(btes)}
+	}
+
+	opts := &clickhouse.Options{
+		Addr: strings.Split(e.Addr, ","),
+		Auth: clickhouse.Auth{Database: e.Database, Username: e.User, Password: e.Password},
+	}
+	if e.Secure {
+		opts.TLS = &tls.Config{InsecureSkipVerify: e.IgnoreVerifySSL}
+	}
+	if executors.SOCKS5Proxy != nil {
+		opts.DialContext = func(ctx context.Context, addr string) (net.Conn, error) {
+			return executors.DialContext(ctx, "tcp", addr)
+		}
+	}
+	conn, err := clickhouse.Open(opts)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	settings := clickhouse.Settings{}
+	for k, v := range e.Settings {
+		settings[k] = v
+	}
+	if e.AsyncInsert {
+		settings["async_insert"] = 1
+		settings["wait_for_async_insert"] = 1
+		if e.NoWaitAsyncInserts {
+			settings["wait_for_async_insert"] = 0
+		}
+	}
+
+	ctx := testCaseContext.Context()
+	if e.FlushAsyncInserts {
+		l.Debugf("flushing async insert queue")
+		if err := conn.Exec(ctx, "SYSTEM FLUSH ASYNC INSERT QUEUE"); err != nil {
+			return nil, fmt.Errorf("failed to flush async insert queue: %v", err)
+		}
+	}
+
+	results := []QueryResult{}
+	for i, s := range commands {
+		l.Debugf("Executing command number %d\n", i)
+		r, err := query(ctx, conn, settings, s)
+		if err != nil {
+			return nil, fmt.Errorf("failed to exec command number %d : %v", i, err)
+		}
+		results = append(results, r)
+	}
+
+	r := Result{Executor: e, Queries: results}
+	r.Executor.Password = "****hidden****" // do not output password
+	return executors.Dump(r)
+}
+
+// query executes a query, the rows of a select query are read with the types of their columns
+func query(ctx context.Context, conn clickhouse.Conn, settings clickhouse.Settings, q string) (QueryResult, error) {
+	var r QueryResult
+	ctx = clickhouse.Context(ctx,
+		clickhouse.WithSettings(settings),
+		clickhouse.WithProgress(func(p *clickhouse.Progress) {
+			r.RowsRead += p.Rows
+			r.BytesRead += p.Bytes
+			r.RowsWritten += p.WroteRows
+		}),
+	)
+	start := time.Now()
+	if !isSelect(q) {
+		err := conn.Exec(ctx, q)
+		r.Elapsed = time.Since(start).Seconds()
+		return r, err
+	}
+
+	rows, err := conn.Query(ctx, q)
+	if err != nil {
+		return r, err
+	}
+	defer rows.Close()
+	types := rows.ColumnTypes()
+	r.Columns = make([]Column, 0, len(types))
+	r.Rows = []Row{}
+	for _, t := range types {
+		r.Columns = append(r.Columns, Column{Name: t.Name(), Type: t.DatabaseTypeName()})
+	}
+	for rows.Next() {
+		values := make([]interface{}, len(types))
+		for i, t := range types {
+			// the Nothing type, SELECT NULL, has no scan type
+			values[i] = new(interface{})
+			if t.ScanType() != nil {
+				values[i] = reflect.New(t.ScanType()).Interface()
+			}
+		}
+		if err := rows.Scan(values...); err != nil {
+			return r, err
+		}
+		row := make(Row, len(types))
+		for i, t := range types {
+			row[t.Name()] = typedValue(t.DatabaseTypeName(), reflect.ValueOf(values[i]).Elem().Interface())
+		}
+		r.Rows = append(r.Rows, row)
+	}
+	if err := rows.Err(); err != nil {
+		return r, err
+	}
+	r.RowCount = int64(len(r.Rows))
+	r.Elapsed = time.Since(start).Seconds()
+	return r, nil
+}
+
+// isSelect tells if a query returns rows
+func isSelect(q string) bool {
+	for {
+		stripped := comments.ReplaceAllString(q, "")
+		if stripped == q {
+			return selectQuery.MatchString(q)
+		}
+		q = stripped
+	}
+}
+
+// typedValue converts a value scanned from a column: the integers are int64 or uint64, the floats float64, the
+// dates strings in the ClickHouse format, and the values which don't fit in them, the Int128, the UInt256 or the
+// Decimal, strings keeping their exact value
+func typedValue(t string, v interface{}) interface{} {
+	t = baseType(t)
+	switch value := v.(type) {
+	case nil:
+		return nil
+	case time.Time:
+		switch {
+		case strings.HasPrefix(t, "DateTime64("):
+			precision, _ := strconv.Atoi(strings.TrimSpace(strings.SplitN(strings.TrimPrefix(t, "DateTime64("), ",", 2)[0]))
+			layout := "2006-01-02 15:04:05"
+			if precision > 0 {
+				layout += "." + strings.Repeat("0", precision)
+			}
+			return value.Format(layout)
+		case strings.HasPrefix(t, "Date") && !strings.HasPrefix(t, "DateTime"):
+			return value.Format("2006-01-02")
+		}
+		return value.Format("2006-01-02 15:04:05")
+	case float32:
+		f, _ := strconv.ParseFloat(strconv.FormatFloat(float64(value), 'g', -1, 32), 64)
+		return f
+	case string, bool, float64:
+		return v
+	case fmt.Stringer:
+		// big.Int, decimal.Decimal, net.IP, UUID
+		return value.String()
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return nil
+		}
+		return typedValue(t, rv.Elem().Interface())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return rv.Uint()
+	case reflect.Slice, reflect.Array:
+		elem := ""
+		if strings.HasPrefix(t, "Array(") {
+			elem = strings.TrimSuffix(strings.TrimPrefix(t, "Array("), ")")
+		}
+		res := make([]interface{}, rv.Len())
+		for i := range res {
+			res[i] = typedValue(elem, rv.Index(i).Interface())
+		}
+		return res
+	case reflect.Map:
+		res := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			res[fmt.Sprint(typedValue("", iter.Key().Interface()))] = typedValue("", iter.Value().Interface())
+		}
+		return res
+	}
+	return v
+}
+
+// baseType removes the Nullable and LowCardinality wrappers of a type
+func baseType(t string) string {
+	for _, wrapper := range []string{"Nullable(", "LowCardinality("} {
+		if strings.HasPrefix(t, wrapper) {
+			return baseType(strings.TrimSuffix(strings.TrimPrefix(t, wrapper), ")"))
+		}
+	}
+	return t
+}
@@ -0,0 +1,75 @@
+package clickhouse
+
+import (
+	"math"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypedValue(t *testing.T) {
+	uint128, _ := new(big.Int).SetString("340282366920938463463374607431768211455", 10)
+	int256, _ := new(big.Int).SetString("-57896044618658097711785492504343953926634992332820282019728792003956564819968", 10)
+	date := time.Date(2024, 3, 1, 10, 30, 15, 123456789, time.UTC)
+	var null *int32
+	one := int32(1)
+
+	tests := []struct {
+		name     string
+		chType   string
+		value    interface{}
+		expected interface{}
+	}{
+		{name: "Int8", chType: "Int8", value: int8(-5), expected: int64(-5)},
+		{name: "UInt64 above the int64 range", chType: "UInt64", value: uint64(math.MaxUint64), expected: uint64(math.MaxUint64)},
+		{name: "UInt128", chType: "UInt128", value: uint128, expected: "340282366920938463463374607431768211455"},
+		{name: "Int256", chType: "Int256", value: int256, expected: "-57896044618658097711785492504343953926634992332820282019728792003956564819968"},
+		{name: "Decimal", chType: "Decimal(38, 18)", value: decimal.RequireFromString("12345678901234567890.123456789012345678"), expected: "12345678901234567890.123456789012345678"},
+		{name: "Float32", chType: "Float32", value: float32(0.1), expected: 0.1},
+		{name: "Float64", chType: "Float64", value: 0.1, expected: 0.1},
+		{name: "Date", chType: "Date", value: date, expected: "2024-03-01"},
+		{name: "DateTime", chType: "DateTime('UTC')", value: date, expected: "2024-03-01 10:30:15"},
+		{name: "DateTime64", chType: "DateTime64(3, 'UTC')", value: date, expected: "2024-03-01 10:30:15.123"},
+		{name: "Nullable null", chType: "Nullable(Int32)", value: null, expected: nil},
+		{name: "Nullable", chType: "Nullable(Int32)", value: &one, expected: int64(1)},
+		{name: "LowCardinality", chType: "LowCardinality(Nullable(String))", value: "signup", expected: "signup"},
+		{name: "UUID", chType: "UUID", value: uuid.MustParse("6ba7b810-9dad-11d1-80b4-00c04fd430c8"), expected: "6ba7b810-9dad-11d1-80b4-00c04fd430c8"},
+		{name: "IPv4", chType: "IPv4", value: net.ParseIP("10.0.0.1"), expected: "10.0.0.1"},
+		{name: "Array", chType: "Array(Date)", value: []time.Time{date}, expected: []interface{}{"2024-03-01"}},
+		{name: "Map", chType: "Map(String, UInt64)", value: map[string]uint64{"a": 1}, expected: map[string]interface{}{"a": uint64(1)}},
+		{name: "Tuple", chType: "Tuple(String, Int16)", value: []interface{}{"a", int16(2)}, expected: []interface{}{"a", int64(2)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, typedValue(tt.chType, tt.value))
+		})
+	}
+}
+
+func TestIsSelect(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected bool
+	}{
+		{query: "SELECT 1", expected: true},
+		{query: "  with t AS (SELECT 1) SELECT * FROM t", expected: true},
+		{query: "(SELECT 1) UNION ALL (SELECT 2)", expected: true},
+		{query: "-- count the events\nSELECT count() FROM events", expected: true},
+		{query: "/* events */ SHOW TABLES", expected: true},
+		{query: "DESCRIBE TABLE events", expected: true},
+		{query: "INSERT INTO events (id, name) VALUES (1, 'signup')"},
+		{query: "CREATE TABLE events (id UInt64) ENGINE = Memory"},
+		{query: "SYSTEM FLUSH ASYNC INSERT QUEUE"},
+		{query: "-- SELECT\nDROP TABLE events"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			assert.Equal(t, tt.expected, isSelect(tt.query))
+		})
+	}
+}
@@ -4,7 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"io/ioutil"
-	"path"
+	"path/filepath"
 
 	fixtures "github.com/go-testfixtures/testfixtures/v3"
 	"github.com/mitchellh/mapstructure"
@@ -68,7 +68,7 @@ func (e Executor) Run(testCaseContext venom.TestCaseContext, l venom.Logger, ste
 	if len(e.Schemas) != 0 {
 		for _, s := range e.Schemas {
 			l.Debugf("loading schema from file %s\n", s)
-			s = path.Join(workdir, s)
+			s = filepath.Join(workdir, s)
 			sbytes, errs := ioutil.ReadFile(s)
 			if errs != nil {
 				return nil, errs
@@ -84,7 +84,7 @@ func (e Executor) Run(testCaseContext venom.TestCaseContext, l venom.Logger, ste
 			migrate.SetTable(e.MigrationsTable)
 		}
 
-		dir := path.Join(workdir, e.Migrations)
+		dir := filepath.Join(workdir, e.Migrations)
 		migrations := &migrate.FileMigrationSource{
 			Dir: dir,
 		}
@@ -120,27 +120,27 @@ func (e Executor) GetDefaultAssertions() venom.StepAssertions {
 // and switch to the list of files if no folder was specified.
 func loadFixtures(db *sql.DB, files []string, folder string, dialect func(*fixtures.Loader) error, l venom.Logger, workdir string) error {
 	if folder != "" {
-		l.Debugf("loading fixtures from folder %s\n", path.Join(workdir, folder))
+		l.Debugf("loading fixtures from folder %s\n", filepath.Join(workdir, folder))
 		loader, err := fixtures.New(
 			// By default the package refuse to load if the database
 			// does not contains "test" to avoid wiping a production db.
 			fixtures.DangerousSkipTestDatabaseCheck(),
 			fixtures.Database(db),
-			fixtures.Directory(path.Join(workdir, folder)),
+			fixtures.Directory(filepath.Join(workdir, folder)),
 			dialect)
 
 		if err != nil {
 			return fmt.Errorf("failed to create folder loader: %v", err)
 		}
 		if err = loader.Load(); err != nil {
-			return fmt.Errorf("failed to load fixtures from folder %s: %v", path.Join(workdir, folder), err)
+			return fmt.Errorf("failed to load fixtures from folder %s: %v", filepath.Join(workdir, folder), err)
 		}
 		return nil
 	}
 	if len(files) != 0 {
 		l.Debugf("loading fixtures from files: %v\n", files)
 		for i := range files {
-			files[i] = path.Join(workdir, files[i])
+			files[i] = filepath.Join(workdir, files[i])
 		}
 		loader, err := fixtures.New(
 			// By default the package refuse to load if the database
@@ -0,0 +1,159 @@
+package document
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/ovh/venom"
+	"github.com/ovh/venom/executors"
+)
+
+// Name of executor
+const Name = "document"
+
+// New returns a new Executor
+func New() venom.Executor {
+	return &Executor{}
+}
+
+// Executor extracts the text, the metadata and the cells of a pdf or a xlsx document
+type Executor struct {
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+	// Format is pdf or xlsx, it defaults to the extension of the path, then to the content of the document
+	Format string `json:"format,omitempty" yaml:"format,omitempty"`
+}
+
+// Result represents a step result
+type Result struct {
+	Executor    Executor          `json:"executor,omitempty" yaml:"executor,omitempty"`
+	Format      string            `json:"format" yaml:"format"`
+	Size        int               `json:"size" yaml:"size"`
+	Metadata    map[string]string `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	PageCount   int               `json:"pagecount,omitempty" yaml:"pagecount,omitempty"`
+	Pages       []string          `json:"pages,omitempty" yaml:"pages,omitempty"`
+	Text        string            `json:"text,omitempty" yaml:"text,omitempty"`
+	SheetNames  []string          `json:"sheetnames,omitempty" yaml:"sheetnames,omitempty"`
+	Sheets      map[string]Sheet  `json:"sheets,omitempty" yaml:"sheets,omitempty"`
+	TimeSeconds float64           `json:"timeseconds,omitempty" yaml:"timeseconds,omitempty"`
+	TimeHuman   string            `json:"timehuman,omitempty" yaml:"timehuman,omitempty"`
+}
+
+// Sheet is a sheet of a xlsx document: its rowcount, its columncount, its cells by reference and its rows. It's a map
+// rather than a struct for the variables of the result to be result.sheets.<sheet>.cells.<cell>.
+type Sheet map[string]interface{}
+
+// ZeroValueResult return an empty implemtation of this executor result
+func (Executor) ZeroValueResult() venom.ExecutorResult {
+	r, _ := executors.Dump(Result{})
+	return r
+}
+
+// GetDefaultAssertions return default assertions for this executor
+func (Executor) GetDefaultAssertions() *venom.StepAssertions {
+	return &venom.StepAssertions{Assertions: []string{"result.size ShouldBeGreaterThan 0"}}
+}
+
+// Run execute TestStep
+func (Executor) Run(testCaseContext venom.TestCaseContext, l venom.Logger, step venom.TestStep, workdir string) (venom.ExecutorResult, error) {
+	var e Executor
+	if err := mapstructure.Decode(step, &e); err != nil {
+		return nil, err
+	}
+	if e.Path == "" {
+		return nil, fmt.Errorf("path is mandatory")
+	}
+
+	start := time.Now()
+	btes, err := readDocument(e.Path, workdir)
+	if err != nil {
+		return nil, err
+	}
+	format := strings.ToLower(e.Format)
+	if format == "" {
+		format = detectFormat(e.Path, btes)
+	}
+
+	result := Result{Executor: e, Format: format, Size: len(btes)}
+	switch format {
+	case "pdf":
+		doc, err := readPDF(btes)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the pdf %s: %v", e.Path, err)
+		}
+		result.Metadata = doc.metadata
+		result.PageCount = len(doc.pages)
+		result.Pages = doc.pages
+		result.Text = strings.Join(doc.pages, "\n")
+	case "xlsx":
+		doc, err := readXLSX(btes)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the xlsx %s: %v", e.Path, err)
+		}
+		result.Metadata = doc.metadata
+		result.SheetNames = doc.names
+		result.Sheets = doc.sheets
+	default:
+		return nil, fmt.Errorf("unsupported format %q, the formats are pdf and xlsx", format)
+	}
+	l.Debugf("document %s: %s of %d bytes", e.Path, format, result.Size)
+
+	elapsed := time.Since(start)
+	result.TimeSeconds = elapsed.Seconds()
+	result.TimeHuman = elapsed.String()
+	return executors.Dump(result)
+}
+
+// detectFormat returns the format of the document from the extension of its path, or from its first bytes
+func detectFormat(path string, btes []byte) string {
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		if i := strings.IndexAny(path, "?#"); i >= 0 {
+			path = path[:i]
+		}
+	}
+	switch ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), ".")); ext {
+	case "pdf", "xlsx":
+		return ext
+	}
+	switch {
+	case bytes.HasPrefix(btes, []byte("%PDF-")):
+		return "pdf"
+	case bytes.HasPrefix(btes, []byte("PK\x03\x04")):
+		return "xlsx"
+	}
+	return ""
+}
+
+// readDocument reads the document from an url, or from a file relative to the workdir
+func readDocument(src, workdir string) ([]byte, error) {
+	if !strings.HasPrefix(src, "http://") && !strings.HasPrefix(src, "https://") {
+		if !filepath.IsAbs(src) {
+			src = filepath.Join(workdir, src)
+		}
+		btes, err := ioutil.ReadFile(src)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the document: %v", err)
+		}
+		return btes, nil
+	}
+	tr := &http.Transport{Proxy: http.ProxyFromEnvironment}
+	if executors.SOCKS5Proxy != nil {
+		tr.Proxy = nil
+		tr.DialContext = executors.DialContext
+	}
+	resp, err := (&http.Client{Transport: tr}).Get(src)
+	if err != nil {
+		return nil, fmt.Errorf("unable to download the document: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to download the document %s: %s", src, resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
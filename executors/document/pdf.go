@@ -0,0 +1,935 @@
+package document
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/ascii85"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// pdfDocument is the content of a pdf document
+type pdfDocument struct {
+	metadata map[string]string
+	pages    []string
+}
+
+// the objects of a pdf file
+type (
+	pdfName    string
+	pdfKeyword string
+	pdfString  string
+	pdfDict    map[pdfName]interface{}
+	pdfRef     struct{ num, gen int }
+	pdfStream  struct {
+		dict pdfDict
+		data []byte
+	}
+)
+
+var regexpPDFObject = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+
+// pdfFile is a pdf file indexed by object number. The objects are found by scanning the file rather than by its
+// cross-reference table, the last definition of an object wins: the incremental updates and the damaged tables are
+// supported.
+type pdfFile struct {
+	objects map[int]interface{}
+	trailer pdfDict
+}
+
+// readPDF reads the metadata and the text of the pages of a pdf document. The text is extracted from the content
+// streams, in the order it's drawn, a line by line of the page. The encrypted documents are not supported.
+func readPDF(btes []byte) (*pdfDocument, error) {
+	if !bytes.HasPrefix(btes, []byte("%PDF-")) {
+		return nil, fmt.Errorf("not a pdf file")
+	}
+	f, err := parsePDFFile(btes)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := f.trailer["Encrypt"]; ok {
+		return nil, fmt.Errorf("the encrypted documents are not supported")
+	}
+
+	doc := &pdfDocument{metadata: map[string]string{}}
+	if info, ok := f.resolve(f.trailer["Info"]).(pdfDict); ok {
+		for k, v := range info {
+			if s, ok := f.resolve(v).(pdfString); ok && s != "" {
+				doc.metadata[strings.ToLower(string(k))] = decodeTextString(s)
+			}
+		}
+	}
+
+	root, ok := f.resolve(f.trailer["Root"]).(pdfDict)
+	if !ok {
+		return nil, fmt.Errorf("the catalog is not found")
+	}
+	for _, page := range f.pages(root["Pages"], nil, map[int]bool{}) {
+		text, err := f.pageText(page)
+		if err != nil {
+			return nil, fmt.Errorf("page %d: %v", len(doc.pages)+1, err)
+		}
+		doc.pages = append(doc.pages, text)
+	}
+	return doc, nil
+}
+
+func parsePDFFile(btes []byte) (*pdfFile, error) {
+	f := &pdfFile{objects: map[int]interface{}{}}
+	type trailer struct {
+		offset int
+		dict   pdfDict
+	}
+	var trailers []trailer
+	for offset := 0; offset < len(btes); {
+		loc := regexpPDFObject.FindSubmatchIndex(btes[offset:])
+		if loc == nil {
+			break
+		}
+		num, _ := strconv.Atoi(string(btes[offset+loc[2] : offset+loc[3]]))
+		start := offset + loc[1]
+		offset += loc[1]
+		lex := &pdfLexer{data: btes, pos: start}
+		obj, err := lex.object()
+		if err != nil {
+			continue
+		}
+		if dict, ok := obj.(pdfDict); ok {
+			if data, ok := lex.stream(dict); ok {
+				stream := pdfStream{dict: dict, data: data}
+				obj = stream
+				switch dict["Type"] {
+				case pdfName("ObjStm"):
+					f.readObjectStream(stream)
+				case pdfName("XRef"):
+					trailers = append(trailers, trailer{offset: start, dict: dict})
+				}
+			}
+		}
+		f.objects[num] = obj
+		offset = lex.pos
+	}
+
+	for offset := 0; ; {
+		i := bytes.Index(btes[offset:], []byte("trailer"))
+		if i < 0 {
+			break
+		}
+		offset += i + len("trailer")
+		lex := &pdfLexer{data: btes, pos: offset}
+		if dict, ok := mustObject(lex).(pdfDict); ok {
+			trailers = append(trailers, trailer{offset: offset, dict: dict})
+		}
+	}
+	sort.SliceStable(trailers, func(i, j int) bool { return trailers[i].offset < trailers[j].offset })
+	f.trailer = pdfDict{}
+	for _, t := range trailers {
+		for _, k := range []pdfName{"Root", "Info", "Encrypt"} {
+			if v, ok := t.dict[k]; ok {
+				f.trailer[k] = v
+			}
+		}
+	}
+	if _, ok := f.trailer["Root"]; !ok {
+		// without trailer, the catalog is searched
+		for num, obj := range f.objects {
+			if dict, ok := obj.(pdfDict); ok && dict["Type"] == pdfName("Catalog") {
+				f.trailer["Root"] = pdfRef{num: num}
+			}
+		}
+	}
+	if len(f.objects) == 0 {
+		return nil, fmt.Errorf("no object found")
+	}
+	return f, nil
+}
+
+func mustObject(lex *pdfLexer) interface{} {
+	obj, err := lex.object()
+	if err != nil {
+		return nil
+	}
+	return obj
+}
+
+// readObjectStream adds the objects of an object stream
+func (f *pdfFile) readObjectStream(stream pdfStream) {
+	data, err := f.decodeStream(stream)
+	if err != nil {
+		return
+	}
+	n, _ := stream.dict["N"].(float64)
+	first, _ := stream.dict["First"].(float64)
+	lex := &pdfLexer{data: data}
+	for i := 0; i < int(n); i++ {
+		num, ok1 := mustObject(lex).(float64)
+		offset, ok2 := mustObject(lex).(float64)
+		if !ok1 || !ok2 {
+			return
+		}
+		obj := &pdfLexer{data: data, pos: int(first) + int(offset)}
+		if int(first)+int(offset) < len(data) {
+			f.objects[int(num)] = mustObject(obj)
+		}
+	}
+}
+
+// resolve returns the object referenced
+func (f *pdfFile) resolve(v interface{}) interface{} {
+	for i := 0; i < 32; i++ {
+		ref, ok := v.(pdfRef)
+		if !ok {
+			return v
+		}
+		v = f.objects[ref.num]
+	}
+	return nil
+}
+
+// pdfPage is a page and its resources, inherited from the page tree
+type pdfPage struct {
+	dict      pdfDict
+	resources pdfDict
+}
+
+// pages returns the pages of the page tree, in order
+func (f *pdfFile) pages(node interface{}, resources pdfDict, visited map[int]bool) []pdfPage {
+	if ref, ok := node.(pdfRef); ok {
+		if visited[ref.num] {
+			return nil
+		}
+		visited[ref.num] = true
+	}
+	dict, ok := f.resolve(node).(pdfDict)
+	if !ok {
+		return nil
+	}
+	if r, ok := f.resolve(dict["Resources"]).(pdfDict); ok {
+		resources = r
+	}
+	kids, ok := f.resolve(dict["Kids"]).([]interface{})
+	if !ok || dict["Type"] == pdfName("Page") {
+		if dict["Type"] == pdfName("Pages") {
+			return nil
+		}
+		return []pdfPage{{dict: dict, resources: resources}}
+	}
+	var pages []pdfPage
+	for _, kid := range kids {
+		pages = append(pages, f.pages(kid, resources, visited)...)
+	}
+	return pages
+}
+
+// decodeStream returns the data of the stream, decoded by its filters
+func (f *pdfFile) decodeStream(stream pdfStream) ([]byte, error) {
+	var filters []interface{}
+	switch filter := f.resolve(stream.dict["Filter"]).(type) {
+	case pdfName:
+		filters = []interface{}{filter}
+	case []interface{}:
+		filters = filter
+	}
+	data := stream.data
+	for _, filter := range filters {
+		switch name, _ := f.resolve(filter).(pdfName); name {
+		case "FlateDecode", "Fl":
+			r, err := zlib.NewReader(bytes.NewReader(data))
+			if err != nil {
+				return nil, err
+			}
+			// the data read before a truncated end of stream is kept
+			decoded, err := ioutil.ReadAll(r)
+			if err != nil && len(decoded) == 0 {
+				return nil, err
+			}
+			data = decoded
+		case "ASCIIHexDecode", "AHx":
+			s := strings.Map(func(r rune) rune {
+				if strings.ContainsRune(" \t\r\n\f\x00", r) {
+					return -1
+				}
+				return r
+			}, strings.TrimSuffix(strings.TrimSpace(string(data)), ">"))
+			if len(s)%2 == 1 {
+				s += "0"
+			}
+			decoded, err := hex.DecodeString(s)
+			if err != nil {
+				return nil, err
+			}
+			data = decoded
+		case "ASCII85Decode", "A85":
+			s := strings.TrimPrefix(strings.TrimSpace(string(data)), "<~")
+			if i := strings.Index(s, "~>"); i >= 0 {
+				s = s[:i]
+			}
+			decoded := make([]byte, 4*len(s)+4)
+			n, _, err := ascii85.Decode(decoded, []byte(s), true)
+			if err != nil {
+				return nil, err
+			}
+			data = decoded[:n]
+		default:
+			return nil, fmt.Errorf("unsupported filter %s", name)
+		}
+	}
+	return data, nil
+}
+
+// contents returns the decoded content streams of a page or a form
+func (f *pdfFile) contents(v interface{}) ([]byte, error) {
+	var streams []interface{}
+	switch c := f.resolve(v).(type) {
+	case pdfStream:
+		streams = []interface{}{c}
+	case []interface{}:
+		streams = c
+	}
+	var data []byte
+	for _, s := range streams {
+		stream, ok := f.resolve(s).(pdfStream)
+		if !ok {
+			continue
+		}
+		decoded, err := f.decodeStream(stream)
+		if err != nil {
+			return nil, err
+		}
+		data = append(append(data, decoded...), '\n')
+	}
+	return data, nil
+}
+
+// pageText returns the text of a page
+func (f *pdfFile) pageText(page pdfPage) (string, error) {
+	data, err := f.contents(page.dict["Contents"])
+	if err != nil {
+		return "", err
+	}
+	w := &pdfTextWriter{}
+	if err := f.writeText(w, data, page.resources, 0); err != nil {
+		return "", err
+	}
+	lines := strings.Split(w.String(), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(strings.Join(strings.Fields(lines[i]), " "))
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n")), nil
+}
+
+// pdfTextWriter writes the text shown, a line by line of text
+type pdfTextWriter struct {
+	strings.Builder
+	lineY, lastY float64
+	moved        bool
+}
+
+func (w *pdfTextWriter) show(s string) {
+	if s == "" {
+		return
+	}
+	switch {
+	case w.Len() > 0 && w.lineY != w.lastY:
+		w.WriteString("\n")
+	case w.moved:
+		w.WriteString(" ")
+	}
+	w.WriteString(s)
+	w.lastY, w.moved = w.lineY, false
+}
+
+// writeText writes the text of a content stream, the forms drawn are written in place
+func (f *pdfFile) writeText(w *pdfTextWriter, data []byte, resources pdfDict, depth int) error {
+	fonts, _ := f.resolve(resources["Font"]).(pdfDict)
+	xobjects, _ := f.resolve(resources["XObject"]).(pdfDict)
+	decoders := map[pdfName]*pdfFont{}
+	var font *pdfFont
+	number := func(v interface{}) float64 {
+		n, _ := v.(float64)
+		return n
+	}
+
+	lex := &pdfLexer{data: data}
+	var operands []interface{}
+	for {
+		obj, err := lex.object()
+		if err != nil {
+			break
+		}
+		op, ok := obj.(pdfKeyword)
+		if !ok {
+			operands = append(operands, obj)
+			continue
+		}
+		arg := func(i int) interface{} {
+			if i < len(operands) {
+				return operands[len(operands)-1-i]
+			}
+			return nil
+		}
+		switch op {
+		case "BT":
+			w.lineY, w.moved = 0, true
+		case "Tf":
+			name, _ := arg(1).(pdfName)
+			if _, ok := decoders[name]; !ok {
+				decoders[name] = f.font(fonts[name])
+			}
+			font = decoders[name]
+		case "Td", "TD":
+			if ty := number(arg(0)); ty != 0 {
+				w.lineY += ty
+			}
+			w.moved = true
+		case "Tm":
+			w.lineY, w.moved = number(arg(0)), true
+		case "T*":
+			w.lineY, w.moved = w.lineY-1, true
+		case "Tj":
+			if s, ok := arg(0).(pdfString); ok {
+				w.show(font.decode(s))
+			}
+		case "'", "\"":
+			w.lineY, w.moved = w.lineY-1, true
+			if s, ok := arg(0).(pdfString); ok {
+				w.show(font.decode(s))
+			}
+		case "TJ":
+			array, _ := arg(0).([]interface{})
+			var text strings.Builder
+			for _, item := range array {
+				switch v := item.(type) {
+				case pdfString:
+					text.WriteString(font.decode(v))
+				case float64:
+					// a large negative adjustment is a space between words, in thousandths of em
+					if v < -250 {
+						text.WriteString(" ")
+					}
+				}
+			}
+			w.show(text.String())
+		case "Do":
+			name, _ := arg(0).(pdfName)
+			form, ok := f.resolve(xobjects[name]).(pdfStream)
+			if !ok || form.dict["Subtype"] != pdfName("Form") || depth > 8 {
+				break
+			}
+			content, err := f.decodeStream(form)
+			if err != nil {
+				return err
+			}
+			formResources, ok := f.resolve(form.dict["Resources"]).(pdfDict)
+			if !ok {
+				formResources = resources
+			}
+			if err := f.writeText(w, content, formResources, depth+1); err != nil {
+				return err
+			}
+		case "ID":
+			lex.skipInlineImage()
+		}
+		operands = operands[:0]
+	}
+	return nil
+}
+
+// pdfFont decodes the strings shown with a font, by its ToUnicode cmap or its encoding
+type pdfFont struct {
+	cmap      map[string]string
+	lengths   []int // the lengths of the codes of the cmap, the longest first
+	encoding  map[byte]rune
+	composite bool
+}
+
+func (f *pdfFile) font(v interface{}) *pdfFont {
+	dict, ok := f.resolve(v).(pdfDict)
+	if !ok {
+		return nil
+	}
+	font := &pdfFont{composite: dict["Subtype"] == pdfName("Type0")}
+	if stream, ok := f.resolve(dict["ToUnicode"]).(pdfStream); ok {
+		if data, err := f.decodeStream(stream); err == nil {
+			font.parseCMap(data)
+		}
+	}
+	font.encoding = map[byte]rune{}
+	switch enc := f.resolve(dict["Encoding"]).(type) {
+	case pdfDict:
+		code := 0
+		differences, _ := f.resolve(enc["Differences"]).([]interface{})
+		for _, d := range differences {
+			switch v := d.(type) {
+			case float64:
+				code = int(v)
+			case pdfName:
+				if r, ok := glyphRune(string(v)); ok && code < 256 {
+					font.encoding[byte(code)] = r
+				}
+				code++
+			}
+		}
+	}
+	return font
+}
+
+// parseCMap reads the bfchar and bfrange mappings of a ToUnicode cmap
+func (font *pdfFont) parseCMap(data []byte) {
+	font.cmap = map[string]string{}
+	lengths := map[int]bool{}
+	add := func(code []byte, text string) {
+		font.cmap[string(code)] = text
+		lengths[len(code)] = true
+	}
+	lex := &pdfLexer{data: data}
+	var operands []interface{}
+	for {
+		obj, err := lex.object()
+		if err != nil {
+			break
+		}
+		op, ok := obj.(pdfKeyword)
+		if !ok {
+			operands = append(operands, obj)
+			continue
+		}
+		switch op {
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, ok1 := operands[i].(pdfString)
+				dst, ok2 := operands[i+1].(pdfString)
+				if ok1 && ok2 {
+					add([]byte(src), decodeUTF16(dst))
+				}
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				lo, ok1 := operands[i].(pdfString)
+				hi, ok2 := operands[i+1].(pdfString)
+				if !ok1 || !ok2 || len(lo) != len(hi) || len(lo) == 0 || len(lo) > 4 {
+					continue
+				}
+				start, end := codeValue(lo), codeValue(hi)
+				if end < start || end-start > 0xffff {
+					continue
+				}
+				for c := start; c <= end; c++ {
+					code := make([]byte, len(lo))
+					for j, v := len(code)-1, c; j >= 0; j, v = j-1, v>>8 {
+						code[j] = byte(v)
+					}
+					switch dst := operands[i+2].(type) {
+					case pdfString:
+						// the last byte of the destination is incremented
+						runes := []uint16{}
+						for j := 0; j+1 < len(dst); j += 2 {
+							runes = append(runes, uint16(dst[j])<<8|uint16(dst[j+1]))
+						}
+						if len(runes) > 0 {
+							runes[len(runes)-1] += uint16(c - start)
+						}
+						add(code, string(utf16.Decode(runes)))
+					case []interface{}:
+						if int(c-start) < len(dst) {
+							if s, ok := dst[c-start].(pdfString); ok {
+								add(code, decodeUTF16(s))
+							}
+						}
+					}
+				}
+			}
+		}
+		operands = operands[:0]
+	}
+	for l := range lengths {
+		font.lengths = append(font.lengths, l)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(font.lengths)))
+}
+
+func codeValue(s pdfString) uint32 {
+	var v uint32
+	for i := 0; i < len(s); i++ {
+		v = v<<8 | uint32(s[i])
+	}
+	return v
+}
+
+// decode returns the text of a string shown with the font. Without cmap, the codes of the composite fonts are
+// unknown and the simple fonts are decoded as WinAnsiEncoding, the most common.
+func (font *pdfFont) decode(s pdfString) string {
+	if font == nil {
+		return decodeWinAnsi(string(s), nil)
+	}
+	if font.cmap == nil {
+		if font.composite {
+			return ""
+		}
+		return decodeWinAnsi(string(s), font.encoding)
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		found := false
+		for _, l := range font.lengths {
+			if i+l <= len(s) {
+				if text, ok := font.cmap[string(s[i:i+l])]; ok {
+					b.WriteString(text)
+					i += l
+					found = true
+					break
+				}
+			}
+		}
+		if !found {
+			if font.composite {
+				i += 2
+			} else {
+				b.WriteString(decodeWinAnsi(string(s[i]), font.encoding))
+				i++
+			}
+		}
+	}
+	return b.String()
+}
+
+// winAnsi is the part of WinAnsiEncoding which differs from latin1
+var winAnsi = map[byte]rune{
+	0x80: '€', 0x82: '‚', 0x83: 'ƒ', 0x84: '„', 0x85: '…', 0x86: '†', 0x87: '‡', 0x88: 'ˆ', 0x89: '‰', 0x8a: 'Š',
+	0x8b: '‹', 0x8c: 'Œ', 0x8e: 'Ž', 0x91: '‘', 0x92: '’', 0x93: '“', 0x94: '”', 0x95: '•', 0x96: '–', 0x97: '—',
+	0x98: '˜', 0x99: '™', 0x9a: 'š', 0x9b: '›', 0x9c: 'œ', 0x9e: 'ž', 0x9f: 'Ÿ',
+}
+
+func decodeWinAnsi(s string, differences map[byte]rune) string {
+	runes := make([]rune, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if r, ok := differences[s[i]]; ok {
+			runes = append(runes, r)
+		} else if r, ok := winAnsi[s[i]]; ok {
+			runes = append(runes, r)
+		} else {
+			runes = append(runes, rune(s[i]))
+		}
+	}
+	return string(runes)
+}
+
+// glyphNames are the names of the glyphs which are not a letter or uniXXXX, used in the Differences of the encodings
+var glyphNames = map[string]rune{
+	"space": ' ', "exclam": '!', "quotedbl": '"', "numbersign": '#', "dollar": '$', "percent": '%', "ampersand": '&',
+	"quotesingle": '\'', "quoteright": '’', "quoteleft": '‘', "parenleft": '(', "parenright": ')', "asterisk": '*',
+	"plus": '+', "comma": ',', "hyphen": '-', "minus": '−', "period": '.', "slash": '/', "zero": '0', "one": '1',
+	"two": '2', "three": '3', "four": '4', "five": '5', "six": '6', "seven": '7', "eight": '8', "nine": '9',
+	"colon": ':', "semicolon": ';', "less": '<', "equal": '=', "greater": '>', "question": '?', "at": '@',
+	"bracketleft": '[', "backslash": '\\', "bracketright": ']', "underscore": '_', "braceleft": '{', "bar": '|',
+	"braceright": '}', "asciitilde": '~', "bullet": '•', "endash": '–', "emdash": '—', "quotedblleft": '“',
+	"quotedblright": '”', "ellipsis": '…', "Euro": '€', "eacute": 'é', "egrave": 'è', "ecircumflex": 'ê',
+	"agrave": 'à', "acircumflex": 'â', "ccedilla": 'ç', "ugrave": 'ù', "ocircumflex": 'ô', "icircumflex": 'î',
+	"edieresis": 'ë', "idieresis": 'ï', "udieresis": 'ü', "odieresis": 'ö', "adieresis": 'ä', "germandbls": 'ß',
+	"Eacute": 'É', "degree": '°', "fi": 'ﬁ', "fl": 'ﬂ', "nbspace": ' ',
+}
+
+func glyphRune(name string) (rune, bool) {
+	if len(name) == 1 {
+		return rune(name[0]), true
+	}
+	if r, ok := glyphNames[name]; ok {
+		return r, true
+	}
+	if strings.HasPrefix(name, "uni") && len(name) == 7 {
+		if v, err := strconv.ParseUint(name[3:], 16, 16); err == nil {
+			return rune(v), true
+		}
+	}
+	return 0, false
+}
+
+// decodeTextString decodes a text string of the document, the metadata for instance: UTF-16BE with a byte order
+// mark, UTF-8 with a byte order mark or PDFDocEncoding, read as latin1
+func decodeTextString(s pdfString) string {
+	switch {
+	case strings.HasPrefix(string(s), "\xfe\xff"):
+		return decodeUTF16(s[2:])
+	case strings.HasPrefix(string(s), "\xef\xbb\xbf"):
+		return string(s[3:])
+	}
+	return decodeWinAnsi(string(s), nil)
+}
+
+func decodeUTF16(s pdfString) string {
+	units := make([]uint16, 0, len(s)/2)
+	for i := 0; i+1 < len(s); i += 2 {
+		units = append(units, uint16(s[i])<<8|uint16(s[i+1]))
+	}
+	return string(utf16.Decode(units))
+}
+
+// pdfLexer reads the objects of a pdf file or of a content stream
+type pdfLexer struct {
+	data []byte
+	pos  int
+}
+
+func isPDFSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f' || c == 0
+}
+
+func isPDFDelimiter(c byte) bool {
+	return strings.IndexByte("()<>[]{}/%", c) >= 0
+}
+
+func (l *pdfLexer) skipSpaces() {
+	for l.pos < len(l.data) {
+		switch c := l.data[l.pos]; {
+		case isPDFSpace(c):
+			l.pos++
+		case c == '%':
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// object reads the next object, a keyword for the operators of the content streams
+func (l *pdfLexer) object() (interface{}, error) {
+	l.skipSpaces()
+	if l.pos >= len(l.data) {
+		return nil, fmt.Errorf("unexpected end of data")
+	}
+	switch c := l.data[l.pos]; c {
+	case '/':
+		l.pos++
+		start := l.pos
+		for l.pos < len(l.data) && !isPDFSpace(l.data[l.pos]) && !isPDFDelimiter(l.data[l.pos]) {
+			l.pos++
+		}
+		name := string(l.data[start:l.pos])
+		if strings.Contains(name, "#") {
+			var b strings.Builder
+			for i := 0; i < len(name); i++ {
+				if name[i] == '#' && i+2 < len(name) {
+					if v, err := strconv.ParseUint(name[i+1:i+3], 16, 8); err == nil {
+						b.WriteByte(byte(v))
+						i += 2
+						continue
+					}
+				}
+				b.WriteByte(name[i])
+			}
+			name = b.String()
+		}
+		return pdfName(name), nil
+	case '(':
+		return l.literalString()
+	case '<':
+		if l.pos+1 < len(l.data) && l.data[l.pos+1] == '<' {
+			l.pos += 2
+			dict := pdfDict{}
+			for {
+				l.skipSpaces()
+				if l.pos+1 < len(l.data) && l.data[l.pos] == '>' && l.data[l.pos+1] == '>' {
+					l.pos += 2
+					return dict, nil
+				}
+				key, err := l.object()
+				if err != nil {
+					return nil, err
+				}
+				name, ok := key.(pdfName)
+				if !ok {
+					return nil, fmt.Errorf("invalid key %v of dictionary", key)
+				}
+				value, err := l.object()
+				if err != nil {
+					return nil, err
+				}
+				dict[name] = value
+			}
+		}
+		l.pos++
+		start := l.pos
+		for l.pos < len(l.data) && l.data[l.pos] != '>' {
+			l.pos++
+		}
+		s := strings.Map(func(r rune) rune {
+			if r < 128 && isPDFSpace(byte(r)) {
+				return -1
+			}
+			return r
+		}, string(l.data[start:l.pos]))
+		l.pos++
+		if len(s)%2 == 1 {
+			s += "0"
+		}
+		btes, err := hex.DecodeString(s)
+		if err != nil {
+			return nil, err
+		}
+		return pdfString(btes), nil
+	case '[':
+		l.pos++
+		var array []interface{}
+		for {
+			l.skipSpaces()
+			if l.pos < len(l.data) && l.data[l.pos] == ']' {
+				l.pos++
+				if array == nil {
+					array = []interface{}{}
+				}
+				return array, nil
+			}
+			v, err := l.object()
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, v)
+		}
+	case ')', '>', ']', '{', '}':
+		l.pos++
+		return pdfKeyword(string(rune(c))), nil
+	}
+
+	token := l.token()
+	switch token {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+	n, err := strconv.ParseFloat(token, 64)
+	if err != nil {
+		return pdfKeyword(token), nil
+	}
+	// an integer followed by an integer and R is a reference
+	if !strings.ContainsAny(token, ".-+") {
+		pos := l.pos
+		l.skipSpaces()
+		if gen, err := strconv.Atoi(l.token()); err == nil {
+			l.skipSpaces()
+			if l.token() == "R" {
+				return pdfRef{num: int(n), gen: gen}, nil
+			}
+		}
+		l.pos = pos
+	}
+	return n, nil
+}
+
+// token reads a regular token, a number or a keyword
+func (l *pdfLexer) token() string {
+	start := l.pos
+	for l.pos < len(l.data) && !isPDFSpace(l.data[l.pos]) && !isPDFDelimiter(l.data[l.pos]) {
+		l.pos++
+	}
+	return string(l.data[start:l.pos])
+}
+
+func (l *pdfLexer) literalString() (interface{}, error) {
+	l.pos++
+	var b []byte
+	depth := 1
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return pdfString(b), nil
+			}
+		case '\\':
+			if l.pos >= len(l.data) {
+				continue
+			}
+			c = l.data[l.pos]
+			l.pos++
+			switch c {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+				continue
+			case '\n':
+				continue
+			default:
+				if c >= '0' && c <= '7' {
+					v := int(c - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						v = v*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					c = byte(v)
+				}
+			}
+		}
+		b = append(b, c)
+	}
+	return nil, fmt.Errorf("unterminated string")
+}
+
+// stream reads the data of the stream following its dictionary, if any
+func (l *pdfLexer) stream(dict pdfDict) ([]byte, bool) {
+	pos := l.pos
+	l.skipSpaces()
+	if !bytes.HasPrefix(l.data[l.pos:], []byte("stream")) {
+		l.pos = pos
+		return nil, false
+	}
+	l.pos += len("stream")
+	if bytes.HasPrefix(l.data[l.pos:], []byte("\r\n")) {
+		l.pos += 2
+	} else if l.pos < len(l.data) && (l.data[l.pos] == '\n' || l.data[l.pos] == '\r') {
+		l.pos++
+	}
+	start := l.pos
+	// the length is used when it's direct and followed by endstream, else endstream is searched
+	if length, ok := dict["Length"].(float64); ok && length >= 0 && start+int(length) <= len(l.data) {
+		end := &pdfLexer{data: l.data, pos: start + int(length)}
+		end.skipSpaces()
+		if bytes.HasPrefix(l.data[end.pos:], []byte("endstream")) {
+			l.pos = end.pos + len("endstream")
+			return l.data[start : start+int(length)], true
+		}
+	}
+	i := bytes.Index(l.data[start:], []byte("endstream"))
+	if i < 0 {
+		l.pos = len(l.data)
+		return l.data[start:], true
+	}
+	l.pos = start + i + len("endstream")
+	return bytes.TrimRight(l.data[start:start+i], "\r\n"), true
+}
+
+// skipInlineImage skips the data of an inline image, up to the EI operator
+func (l *pdfLexer) skipInlineImage() {
+	for i := l.pos + 1; i+1 < len(l.data); i++ {
+		if isPDFSpace(l.data[i-1]) && l.data[i] == 'E' && l.data[i+1] == 'I' && (i+2 == len(l.data) || isPDFSpace(l.data[i+2])) {
+			l.pos = i + 2
+			return
+		}
+	}
+	l.pos = len(l.data)
+}
@@ -0,0 +1,195 @@
+package document
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pdfFixture returns a pdf file with the objects, numbered from 1, their cross-reference table and the trailer
+func pdfFixture(trailer string, objects ...string) []byte {
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n%s\nstartxref\n%d\n%%%%EOF\n", trailer, xref)
+	return b.Bytes()
+}
+
+// pdfStreamObject returns a stream object, compressed with FlateDecode if flate is true
+func pdfStreamObject(dict, data string, flate bool) string {
+	content := []byte(data)
+	if flate {
+		var b bytes.Buffer
+		w := zlib.NewWriter(&b)
+		w.Write(content)
+		w.Close()
+		content = b.Bytes()
+		dict += " /Filter /FlateDecode"
+	}
+	return fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", dict, len(content), content)
+}
+
+// pdfCompressedFixture returns a pdf 1.5 file: the objects are in a compressed object stream, indexed by a
+// compressed cross-reference stream, without trailer
+func pdfCompressedFixture(content string, objects ...string) []byte {
+	var header, body bytes.Buffer
+	for i, obj := range objects {
+		fmt.Fprintf(&header, "%d %d ", i+1, body.Len())
+		body.WriteString(obj + "\n")
+	}
+	n := len(objects)
+	objStm := pdfStreamObject(fmt.Sprintf("/Type /ObjStm /N %d /First %d", n, header.Len()), header.String()+body.String(), true)
+	contents := pdfStreamObject("", content, true)
+	xref := pdfStreamObject(fmt.Sprintf("/Type /XRef /Size %d /W [1 2 1] /Root 1 0 R", n+4), "\x00\x00\x00\x00", true)
+
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.5\n")
+	fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", n+1, objStm)
+	fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", n+2, contents)
+	start := b.Len()
+	fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", n+3, xref)
+	fmt.Fprintf(&b, "startxref\n%d\n%%%%EOF\n", start)
+	return b.Bytes()
+}
+
+const pdfHelvetica = "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>"
+
+func TestReadPDF(t *testing.T) {
+	tests := []struct {
+		name     string
+		pdf      []byte
+		metadata map[string]string
+		pages    []string
+	}{
+		{
+			name: "text and metadata",
+			pdf: pdfFixture("<< /Size 6 /Root 1 0 R /Info 2 0 R >>",
+				"<< /Type /Catalog /Pages 3 0 R >>",
+				// the title is UTF-16BE, the author PDFDocEncoding
+				"<< /Title <FEFF0046006100630074007500720065> /Author (Ren\\351) >>",
+				"<< /Type /Pages /Kids [4 0 R] /Count 1 >>",
+				"<< /Type /Page /Parent 3 0 R /Resources << /Font << /F1 6 0 R >> >> /Contents 5 0 R >>",
+				pdfStreamObject("", "BT /F1 12 Tf 72 720 Td (Invoice 42) Tj 0 -14 Td [(Total:) -300 (12.50 \\200)] TJ ET", false),
+				pdfHelvetica,
+			),
+			metadata: map[string]string{"title": "Facture", "author": "René"},
+			pages:    []string{"Invoice 42\nTotal: 12.50 €"},
+		},
+		{
+			name: "pages tree with inherited resources",
+			pdf: pdfFixture("<< /Size 8 /Root 1 0 R >>",
+				"<< /Type /Catalog /Pages 2 0 R >>",
+				"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 3 /Resources << /Font << /F1 8 0 R >> >> >>",
+				"<< /Type /Page /Parent 2 0 R /Contents 6 0 R >>",
+				"<< /Type /Pages /Parent 2 0 R /Kids [5 0 R] /Count 1 >>",
+				"<< /Type /Page /Parent 4 0 R /Contents [7 0 R 6 0 R] >>",
+				pdfStreamObject("", "BT /F1 10 Tf (first) Tj T* (line) ' ET", false),
+				pdfStreamObject("", "BT /F1 10 Tf <7365636F6E64> Tj ET", false),
+				pdfHelvetica,
+			),
+			metadata: map[string]string{},
+			// the text of the content streams drawn at the same height is on the same line
+			pages: []string{"first\nline", "second first\nline"},
+		},
+		{
+			name: "compressed object and cross-reference streams",
+			pdf: pdfCompressedFixture("BT /F1 12 Tf 72 720 Td (compressed) Tj ET",
+				"<< /Type /Catalog /Pages 2 0 R >>",
+				"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+				"<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 4 0 R >> >> /Contents 6 0 R >>",
+				pdfHelvetica,
+			),
+			metadata: map[string]string{},
+			pages:    []string{"compressed"},
+		},
+		{
+			name: "ToUnicode cmap of a composite font",
+			pdf: pdfFixture("<< /Size 6 /Root 1 0 R >>",
+				"<< /Type /Catalog /Pages 2 0 R >>",
+				"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+				"<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 5 0 R >> >> /Contents 4 0 R >>",
+				pdfStreamObject("", "BT /F1 12 Tf <000100020003> Tj ET", true),
+				"<< /Type /Font /Subtype /Type0 /BaseFont /NotoSans /ToUnicode 6 0 R >>",
+				pdfStreamObject("", "begincmap 1 begincodespacerange <0000> <FFFF> endcodespacerange\n"+
+					"1 beginbfchar <0001> <00C9> endbfchar\n1 beginbfrange <0002> <0003> <0074> endbfrange endcmap", false),
+			),
+			metadata: map[string]string{},
+			pages:    []string{"Étu"},
+		},
+		{
+			name: "Differences of the encoding and form",
+			pdf: pdfFixture("<< /Size 6 /Root 1 0 R >>",
+				"<< /Type /Catalog /Pages 2 0 R >>",
+				"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+				"<< /Type /Page /Parent 2 0 R /Resources << /XObject << /X1 5 0 R >> >> /Contents 4 0 R >>",
+				pdfStreamObject("", "q /X1 Do Q", false),
+				pdfStreamObject("/Type /XObject /Subtype /Form /Resources << /Font << /F1 6 0 R >> >>", "BT /F1 9 Tf (caf\\001 ok) Tj ET", false),
+				"<< /Type /Font /Subtype /Type1 /Encoding << /Differences [1 /eacute] >> >>",
+			),
+			metadata: map[string]string{},
+			pages:    []string{"café ok"},
+		},
+		{
+			name: "incremental update",
+			pdf: append(pdfFixture("<< /Size 5 /Root 1 0 R >>",
+				"<< /Type /Catalog /Pages 2 0 R >>",
+				"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+				"<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 5 0 R >> >> /Contents 4 0 R >>",
+				pdfStreamObject("", "BT /F1 12 Tf (draft) Tj ET", false),
+				pdfHelvetica,
+			), []byte("4 0 obj\n"+pdfStreamObject("", "BT /F1 12 Tf (final) Tj ET", false)+"\nendobj\ntrailer\n<< /Size 5 /Root 1 0 R /Info 6 0 R >>\n6 0 obj\n<< /Title (v2) >>\nendobj\n")...),
+			metadata: map[string]string{"title": "v2"},
+			pages:    []string{"final"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := readPDF(tt.pdf)
+			require.NoError(t, err)
+			assert.Equal(t, tt.metadata, doc.metadata)
+			assert.Equal(t, tt.pages, doc.pages)
+		})
+	}
+}
+
+func TestReadPDF_errors(t *testing.T) {
+	page := func(content string) []byte {
+		return pdfFixture("<< /Size 4 /Root 1 0 R >>",
+			"<< /Type /Catalog /Pages 2 0 R >>",
+			"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+			"<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>",
+			content,
+		)
+	}
+	tests := []struct {
+		name string
+		pdf  []byte
+		err  string
+	}{
+		{name: "not a pdf", pdf: []byte("\x89PNG\r\n\x1a\n"), err: "not a pdf file"},
+		{name: "truncated", pdf: []byte("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n"), err: "no object found"},
+		{name: "encrypted", pdf: pdfFixture("<< /Size 2 /Root 1 0 R /Encrypt 2 0 R >>", "<< /Type /Catalog >>", "<< /Filter /Standard >>"), err: "the encrypted documents are not supported"},
+		{name: "no catalog", pdf: pdfFixture("<< /Size 1 >>", "<< /Type /Pages /Kids [] >>"), err: "the catalog is not found"},
+		{name: "corrupted stream", pdf: page("<< /Length 8 /Filter /FlateDecode >>\nstream\nnot zlib\nendstream"), err: "page 1: zlib: invalid header"},
+		{name: "unsupported filter", pdf: page(pdfStreamObject("/Filter /JBIG2Decode", "data", false)), err: "page 1: unsupported filter JBIG2Decode"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := readPDF(tt.pdf)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,256 @@
+package document
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// xlsxDocument is the content of a xlsx document
+type xlsxDocument struct {
+	metadata map[string]string
+	names    []string
+	sheets   map[string]Sheet
+}
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		Name  string     `xml:"name,attr"`
+		Attrs []xml.Attr `xml:",any,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// xlsxText is a rich text, a shared string or an inline string
+type xlsxText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	s := t.T
+	for _, r := range t.Runs {
+		s += r.T
+	}
+	return s
+}
+
+type xlsxWorksheet struct {
+	Rows []struct {
+		Cells []struct {
+			Ref    string   `xml:"r,attr"`
+			Type   string   `xml:"t,attr"`
+			Value  string   `xml:"v"`
+			Inline xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+type xlsxCoreProperties struct {
+	Title          string `xml:"title"`
+	Subject        string `xml:"subject"`
+	Creator        string `xml:"creator"`
+	Keywords       string `xml:"keywords"`
+	Description    string `xml:"description"`
+	LastModifiedBy string `xml:"lastModifiedBy"`
+	Created        string `xml:"created"`
+	Modified       string `xml:"modified"`
+}
+
+// readXLSX reads the sheets and the properties of a xlsx document. The values of the cells are the values stored
+// in the document: the numbers and the dates are not formatted, the formulas are replaced by their last result.
+func readXLSX(btes []byte) (*xlsxDocument, error) {
+	z, err := zip.NewReader(bytes.NewReader(btes), int64(len(btes)))
+	if err != nil {
+		return nil, err
+	}
+	files := map[string]*zip.File{}
+	for _, f := range z.File {
+		files[strings.TrimPrefix(f.Name, "/")] = f
+	}
+	readXML := func(name string, v interface{}) error {
+		f, ok := files[name]
+		if !ok {
+			return fmt.Errorf("%s not found", name)
+		}
+		r, err := f.Open()
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+		content, err := ioutil.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		if err := xml.Unmarshal(content, v); err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		return nil
+	}
+
+	var workbook xlsxWorkbook
+	if err := readXML("xl/workbook.xml", &workbook); err != nil {
+		return nil, err
+	}
+	var rels xlsxRelationships
+	if err := readXML("xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return nil, err
+	}
+	targets := map[string]string{}
+	for _, r := range rels.Relationships {
+		if strings.HasPrefix(r.Target, "/") {
+			targets[r.ID] = strings.TrimPrefix(r.Target, "/")
+		} else {
+			targets[r.ID] = path.Join("xl", r.Target)
+		}
+	}
+
+	var shared []string
+	if _, ok := files["xl/sharedStrings.xml"]; ok {
+		var sst struct {
+			Items []xlsxText `xml:"si"`
+		}
+		if err := readXML("xl/sharedStrings.xml", &sst); err != nil {
+			return nil, err
+		}
+		for _, si := range sst.Items {
+			shared = append(shared, si.String())
+		}
+	}
+
+	doc := &xlsxDocument{metadata: map[string]string{}, sheets: map[string]Sheet{}}
+	for _, s := range workbook.Sheets {
+		var target string
+		for _, a := range s.Attrs {
+			if a.Name.Local == "id" && a.Name.Space != "" {
+				target = targets[a.Value]
+			}
+		}
+		if target == "" {
+			return nil, fmt.Errorf("the worksheet of the sheet %q is not found", s.Name)
+		}
+		var ws xlsxWorksheet
+		if err := readXML(target, &ws); err != nil {
+			return nil, err
+		}
+		sheet, err := newSheet(ws, shared)
+		if err != nil {
+			return nil, fmt.Errorf("sheet %q: %v", s.Name, err)
+		}
+		doc.names = append(doc.names, s.Name)
+		doc.sheets[s.Name] = sheet
+	}
+
+	if _, ok := files["docProps/core.xml"]; ok {
+		var core xlsxCoreProperties
+		if err := readXML("docProps/core.xml", &core); err != nil {
+			return nil, err
+		}
+		for k, v := range map[string]string{
+			"title":          core.Title,
+			"subject":        core.Subject,
+			"creator":        core.Creator,
+			"keywords":       core.Keywords,
+			"description":    core.Description,
+			"lastmodifiedby": core.LastModifiedBy,
+			"created":        core.Created,
+			"modified":       core.Modified,
+		} {
+			if v != "" {
+				doc.metadata[k] = v
+			}
+		}
+	}
+	return doc, nil
+}
+
+// newSheet returns the cells of a worksheet, by reference and by row
+func newSheet(ws xlsxWorksheet, shared []string) (Sheet, error) {
+	var rowCount, columnCount int
+	values := map[string]string{}
+	type cell struct {
+		row, col int
+		value    string
+	}
+	var cells []cell
+	for i, row := range ws.Rows {
+		for j, c := range row.Cells {
+			value := c.Value
+			switch c.Type {
+			case "s":
+				n, err := strconv.Atoi(c.Value)
+				if err != nil || n < 0 || n >= len(shared) {
+					return nil, fmt.Errorf("invalid shared string %q of the cell %s", c.Value, c.Ref)
+				}
+				value = shared[n]
+			case "inlineStr":
+				value = c.Inline.String()
+			case "b":
+				value = strconv.FormatBool(c.Value == "1")
+			}
+			// the reference of the cells is optional, they follow each other
+			r, col := i+1, j+1
+			if c.Ref != "" {
+				var err error
+				if r, col, err = parseCellRef(c.Ref); err != nil {
+					return nil, err
+				}
+			}
+			if value == "" {
+				continue
+			}
+			cells = append(cells, cell{row: r, col: col, value: value})
+			values[cellRef(r, col)] = value
+			if r > rowCount {
+				rowCount = r
+			}
+			if col > columnCount {
+				columnCount = col
+			}
+		}
+	}
+	rows := make([][]string, rowCount)
+	for i := range rows {
+		rows[i] = make([]string, columnCount)
+	}
+	for _, c := range cells {
+		rows[c.row-1][c.col-1] = c.value
+	}
+	return Sheet{"rowcount": rowCount, "columncount": columnCount, "cells": values, "rows": rows}, nil
+}
+
+// parseCellRef returns the row and the column, from 1, of a reference like B12
+func parseCellRef(ref string) (int, int, error) {
+	var col int
+	i := 0
+	for ; i < len(ref) && ref[i] >= 'A' && ref[i] <= 'Z'; i++ {
+		col = col*26 + int(ref[i]-'A') + 1
+	}
+	row, err := strconv.Atoi(ref[i:])
+	if i == 0 || err != nil || row < 1 {
+		return 0, 0, fmt.Errorf("invalid cell reference %q", ref)
+	}
+	return row, col, nil
+}
+
+// cellRef returns the reference of the cell at the row and the column, from 1
+func cellRef(row, col int) string {
+	var letters string
+	for ; col > 0; col = (col - 1) / 26 {
+		letters = string(rune('A'+(col-1)%26)) + letters
+	}
+	return letters + strconv.Itoa(row)
+}
@@ -0,0 +1,149 @@
+package document
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// xlsxFixture returns a zip file with the files
+func xlsxFixture(t *testing.T, files map[string]string) []byte {
+	var b bytes.Buffer
+	z := zip.NewWriter(&b)
+	for name, content := range files {
+		w, err := z.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, z.Close())
+	return b.Bytes()
+}
+
+const (
+	xlsxWorkbookXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Orders" sheetId="1" r:id="rId2"/><sheet name="Totals" sheetId="2" r:id="rId1"/></sheets>
+</workbook>`
+	xlsxRelsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet2.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="/xl/worksheets/sheet1.xml"/>
+</Relationships>`
+	xlsxSharedStringsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" count="3" uniqueCount="3">
+<si><t>id</t></si><si><t>customer</t></si><si><r><t>Ada </t></r><r><t>Lovelace</t></r></si>
+</sst>`
+	xlsxOrdersXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="inlineStr"><is><t>paid</t></is></c></row>
+<row r="2"><c r="A2"><v>42</v></c><c r="B2" t="s"><v>2</v></c><c r="C2" t="b"><v>1</v></c></row>
+<row r="4"><c r="A4"><v>43</v></c><c r="C4" t="b"><v>0</v></c></row>
+</sheetData></worksheet>`
+	// the cells of the second sheet have no reference, and a formula with its last result
+	xlsxTotalsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row><c t="inlineStr"><is><t>total</t></is></c><c><f>SUM(Orders!A2:A4)</f><v>85</v></c></row>
+</sheetData></worksheet>`
+	xlsxCoreXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:dcterms="http://purl.org/dc/terms/">
+<dc:title>Orders export</dc:title><dc:creator>billing</dc:creator><dcterms:created>2024-03-01T10:00:00Z</dcterms:created>
+</cp:coreProperties>`
+)
+
+func xlsxFiles(overrides map[string]string) map[string]string {
+	files := map[string]string{
+		"xl/workbook.xml":            xlsxWorkbookXML,
+		"xl/_rels/workbook.xml.rels": xlsxRelsXML,
+		"xl/sharedStrings.xml":       xlsxSharedStringsXML,
+		"xl/worksheets/sheet1.xml":   xlsxOrdersXML,
+		"xl/worksheets/sheet2.xml":   xlsxTotalsXML,
+		"docProps/core.xml":          xlsxCoreXML,
+	}
+	for k, v := range overrides {
+		if v == "" {
+			delete(files, k)
+		} else {
+			files[k] = v
+		}
+	}
+	return files
+}
+
+func TestReadXLSX(t *testing.T) {
+	doc, err := readXLSX(xlsxFixture(t, xlsxFiles(nil)))
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{"title": "Orders export", "creator": "billing", "created": "2024-03-01T10:00:00Z"}, doc.metadata)
+	assert.Equal(t, []string{"Orders", "Totals"}, doc.names)
+	assert.Equal(t, Sheet{
+		"rowcount":    4,
+		"columncount": 3,
+		"cells": map[string]string{
+			"A1": "id", "B1": "customer", "C1": "paid",
+			"A2": "42", "B2": "Ada Lovelace", "C2": "true",
+			"A4": "43", "C4": "false",
+		},
+		"rows": [][]string{{"id", "customer", "paid"}, {"42", "Ada Lovelace", "true"}, {"", "", ""}, {"43", "", "false"}},
+	}, doc.sheets["Orders"])
+	assert.Equal(t, Sheet{
+		"rowcount":    1,
+		"columncount": 2,
+		"cells":       map[string]string{"A1": "total", "B1": "85"},
+		"rows":        [][]string{{"total", "85"}},
+	}, doc.sheets["Totals"])
+
+	// the shared strings and the properties are optional
+	doc, err = readXLSX(xlsxFixture(t, xlsxFiles(map[string]string{
+		"xl/sharedStrings.xml":     "",
+		"docProps/core.xml":        "",
+		"xl/worksheets/sheet1.xml": xlsxTotalsXML,
+	})))
+	require.NoError(t, err)
+	assert.Empty(t, doc.metadata)
+	assert.Equal(t, doc.sheets["Totals"], doc.sheets["Orders"])
+}
+
+func TestReadXLSX_errors(t *testing.T) {
+	sheet := func(cells string) string {
+		return `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData><row>` + cells + `</row></sheetData></worksheet>`
+	}
+	tests := []struct {
+		name  string
+		files map[string]string
+		err   string
+	}{
+		{name: "no workbook", files: xlsxFiles(map[string]string{"xl/workbook.xml": ""}), err: "xl/workbook.xml not found"},
+		{name: "broken workbook", files: xlsxFiles(map[string]string{"xl/workbook.xml": "<workbook><sheets>"}), err: "xl/workbook.xml: XML syntax error on line 1: unexpected EOF"},
+		{name: "no relationships", files: xlsxFiles(map[string]string{"xl/_rels/workbook.xml.rels": ""}), err: "xl/_rels/workbook.xml.rels not found"},
+		{name: "unknown relationship", files: xlsxFiles(map[string]string{"xl/workbook.xml": `<workbook xmlns:r="rels"><sheets><sheet name="Orders" r:id="rId9"/></sheets></workbook>`}), err: `the worksheet of the sheet "Orders" is not found`},
+		{name: "missing worksheet", files: xlsxFiles(map[string]string{"xl/worksheets/sheet2.xml": ""}), err: "xl/worksheets/sheet2.xml not found"},
+		{name: "invalid shared string", files: xlsxFiles(map[string]string{"xl/worksheets/sheet1.xml": sheet(`<c r="A1" t="s"><v>7</v></c>`)}), err: `sheet "Orders": invalid shared string "7" of the cell A1`},
+		{name: "invalid cell reference", files: xlsxFiles(map[string]string{"xl/worksheets/sheet1.xml": sheet(`<c r="1A"><v>1</v></c>`)}), err: `sheet "Orders": invalid cell reference "1A"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := readXLSX(xlsxFixture(t, tt.files))
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+
+	_, err := readXLSX([]byte("PK\x03\x04 truncated"))
+	assert.Error(t, err)
+}
+
+func TestCellRef(t *testing.T) {
+	for ref, expected := range map[string][2]int{"A1": {1, 1}, "Z9": {9, 26}, "AA10": {10, 27}, "XFD1048576": {1048576, 16384}} {
+		row, col, err := parseCellRef(ref)
+		require.NoError(t, err)
+		assert.Equal(t, expected, [2]int{row, col}, ref)
+		assert.Equal(t, ref, cellRef(row, col))
+	}
+	for _, ref := range []string{"", "A", "12", "A0", "a1"} {
+		_, _, err := parseCellRef(ref)
+		assert.Error(t, err, ref)
+	}
+}
@@ -2,6 +2,7 @@ package exec
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -133,6 +134,7 @@ func (Executor) Run(testCaseContext venom.TestCaseContext, l venom.Logger, step
 	start := time.Now()
 
 	cmd := exec.Command(shell, opts...)
+	setProcessGroup(cmd)
 	l.Debugf("teststep exec '%s %s'", shell, strings.Join(opts, " "))
 	cmd.Dir = workdir
 	stdout, err := cmd.StdoutPipe()
@@ -189,6 +191,8 @@ func (Executor) Run(testCaseContext venom.TestCaseContext, l venom.Logger, step
 		l.Debugf(err.Error())
 		return dump.ToMap(e, nil, dump.WithDefaultLowerCaseFormatter())
 	}
+	stop := killOnDone(testCaseContext.Context(), cmd)
+	defer stop()
 
 	_ = <-outchan
 	_ = <-errchan
@@ -231,3 +235,17 @@ func (Executor) Run(testCaseContext venom.TestCaseContext, l venom.Logger, step
 
 	return executors.Dump(result)
 }
+
+// killOnDone kills the script and the processes it started when ctx is done, the step is stopped, until the
+// returned func is called
+func killOnDone(ctx context.Context, cmd *exec.Cmd) func() {
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			killProcessGroup(cmd)
+		case <-done:
+		}
+	}()
+	return func() { close(done) }
+}
@@ -0,0 +1,11 @@
+package exec
+
+import "os/exec"
+
+// setProcessGroup does nothing on Windows, only the script is killed
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// killProcessGroup kills the script
+func killProcessGroup(cmd *exec.Cmd) {
+	_ = cmd.Process.Kill()
+}
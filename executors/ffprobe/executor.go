@@ -0,0 +1,224 @@
+package ffprobe
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/ovh/venom"
+	"github.com/ovh/venom/executors"
+)
+
+// Name of executor
+const Name = "ffprobe"
+
+const ffprobeCommand = "ffprobe"
+
+// New returns a new Executor
+func New() venom.Executor {
+	return &Executor{}
+}
+
+// Executor probes a media file or stream with ffprobe
+type Executor struct {
+	// Input is a file, relative to the directory of the testsuite, or the url of a stream: http, hls, rtmp...
+	Input string   `json:"input,omitempty" yaml:"input,omitempty"`
+	Args  []string `json:"args,omitempty" yaml:"args,omitempty"`
+}
+
+// Result represents a step result
+type Result struct {
+	Executor    Executor          `json:"executor,omitempty" yaml:"executor,omitempty"`
+	Format      string            `json:"format" yaml:"format"`
+	Duration    float64           `json:"duration" yaml:"duration"`
+	Size        int64             `json:"size" yaml:"size"`
+	BitRate     int64             `json:"bitrate" yaml:"bitrate"`
+	StreamCount int               `json:"streamcount" yaml:"streamcount"`
+	Tags        map[string]string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Video       Stream            `json:"video" yaml:"video"`
+	Audio       Stream            `json:"audio" yaml:"audio"`
+	Streams     []Stream          `json:"streams,omitempty" yaml:"streams,omitempty"`
+	Systemerr   string            `json:"systemerr,omitempty" yaml:"systemerr,omitempty"`
+	Code        int               `json:"code" yaml:"code"`
+	TimeSeconds float64           `json:"timeseconds,omitempty" yaml:"timeseconds,omitempty"`
+	TimeHuman   string            `json:"timehuman,omitempty" yaml:"timehuman,omitempty"`
+}
+
+// Stream is a stream of the media: video, audio, subtitle or data
+type Stream struct {
+	Index         int               `json:"index" yaml:"index"`
+	Type          string            `json:"type" yaml:"type"`
+	Codec         string            `json:"codec" yaml:"codec"`
+	Profile       string            `json:"profile,omitempty" yaml:"profile,omitempty"`
+	Width         int               `json:"width,omitempty" yaml:"width,omitempty"`
+	Height        int               `json:"height,omitempty" yaml:"height,omitempty"`
+	PixelFormat   string            `json:"pixelformat,omitempty" yaml:"pixelformat,omitempty"`
+	FrameRate     float64           `json:"framerate,omitempty" yaml:"framerate,omitempty"`
+	FrameCount    int64             `json:"framecount,omitempty" yaml:"framecount,omitempty"`
+	SampleRate    int               `json:"samplerate,omitempty" yaml:"samplerate,omitempty"`
+	Channels      int               `json:"channels,omitempty" yaml:"channels,omitempty"`
+	ChannelLayout string            `json:"channellayout,omitempty" yaml:"channellayout,omitempty"`
+	Duration      float64           `json:"duration,omitempty" yaml:"duration,omitempty"`
+	BitRate       int64             `json:"bitrate,omitempty" yaml:"bitrate,omitempty"`
+	Tags          map[string]string `json:"tags,omitempty" yaml:"tags,omitempty"`
+}
+
+// probe is the json output of ffprobe -show_format -show_streams
+type probe struct {
+	Format struct {
+		FormatName string            `json:"format_name"`
+		NbStreams  int               `json:"nb_streams"`
+		Duration   string            `json:"duration"`
+		Size       string            `json:"size"`
+		BitRate    string            `json:"bit_rate"`
+		Tags       map[string]string `json:"tags"`
+	} `json:"format"`
+	Streams []struct {
+		Index         int               `json:"index"`
+		CodecType     string            `json:"codec_type"`
+		CodecName     string            `json:"codec_name"`
+		Profile       string            `json:"profile"`
+		Width         int               `json:"width"`
+		Height        int               `json:"height"`
+		PixFmt        string            `json:"pix_fmt"`
+		AvgFrameRate  string            `json:"avg_frame_rate"`
+		RFrameRate    string            `json:"r_frame_rate"`
+		NbFrames      string            `json:"nb_frames"`
+		SampleRate    string            `json:"sample_rate"`
+		Channels      int               `json:"channels"`
+		ChannelLayout string            `json:"channel_layout"`
+		Duration      string            `json:"duration"`
+		BitRate       string            `json:"bit_rate"`
+		Tags          map[string]string `json:"tags"`
+	} `json:"streams"`
+}
+
+// ZeroValueResult return an empty implemtation of this executor result
+func (Executor) ZeroValueResult() venom.ExecutorResult {
+	r, _ := executors.Dump(Result{})
+	return r
+}
+
+// GetDefaultAssertions return default assertions for this executor
+func (Executor) GetDefaultAssertions() *venom.StepAssertions {
+	return &venom.StepAssertions{Assertions: []string{"result.code ShouldEqual 0"}}
+}
+
+// Run execute TestStep
+func (Executor) Run(testCaseContext venom.TestCaseContext, l venom.Logger, step venom.TestStep, workdir string) (venom.ExecutorResult, error) {
+	var e Executor
+	if err := mapstructure.Decode(step, &e); err != nil {
+		return nil, err
+	}
+	if e.Input == "" {
+		return nil, fmt.Errorf("input is mandatory")
+	}
+	input := e.Input
+	if !strings.Contains(input, "://") && !filepath.IsAbs(input) {
+		input = filepath.Join(workdir, input)
+	}
+
+	start := time.Now()
+	args := append([]string{"-v", "error", "-print_format", "json", "-show_format", "-show_streams"}, e.Args...)
+	args = append(args, input)
+	l.Debugf("%s %s", ffprobeCommand, strings.Join(args, " "))
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd := exec.CommandContext(testCaseContext.Context(), ffprobeCommand, args...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	result := Result{Executor: e}
+	if err := cmd.Run(); err != nil {
+		exitErr, ok := err.(*exec.ExitError)
+		if !ok {
+			return nil, fmt.Errorf("unable to run %s: %v", ffprobeCommand, err)
+		}
+		result.Code = exitErr.ExitCode()
+	}
+	result.Systemerr = stderr.String()
+	if result.Code == 0 {
+		var p probe
+		if err := json.Unmarshal(stdout.Bytes(), &p); err != nil {
+			return nil, fmt.Errorf("unable to read the output of %s: %v", ffprobeCommand, err)
+		}
+		result.apply(p)
+	}
+
+	elapsed := time.Since(start)
+	result.TimeSeconds = elapsed.Seconds()
+	result.TimeHuman = elapsed.String()
+	return executors.Dump(result)
+}
+
+// apply sets the result from the output of ffprobe, the numbers written as strings are parsed
+func (r *Result) apply(p probe) {
+	r.Format = p.Format.FormatName
+	r.StreamCount = p.Format.NbStreams
+	r.Duration = parseFloat(p.Format.Duration)
+	r.Size = parseInt(p.Format.Size)
+	r.BitRate = parseInt(p.Format.BitRate)
+	r.Tags = p.Format.Tags
+	for _, s := range p.Streams {
+		stream := Stream{
+			Index:         s.Index,
+			Type:          s.CodecType,
+			Codec:         s.CodecName,
+			Profile:       s.Profile,
+			Width:         s.Width,
+			Height:        s.Height,
+			PixelFormat:   s.PixFmt,
+			FrameCount:    parseInt(s.NbFrames),
+			SampleRate:    int(parseInt(s.SampleRate)),
+			Channels:      s.Channels,
+			ChannelLayout: s.ChannelLayout,
+			Duration:      parseFloat(s.Duration),
+			BitRate:       parseInt(s.BitRate),
+			Tags:          s.Tags,
+		}
+		if s.CodecType == "video" {
+			stream.FrameRate = parseRate(s.AvgFrameRate)
+			if stream.FrameRate == 0 {
+				stream.FrameRate = parseRate(s.RFrameRate)
+			}
+		}
+		r.Streams = append(r.Streams, stream)
+		// the first video and audio streams are the streams played by default
+		switch {
+		case s.CodecType == "video" && r.Video.Type == "":
+			r.Video = stream
+		case s.CodecType == "audio" && r.Audio.Type == "":
+			r.Audio = stream
+		}
+	}
+}
+
+func parseFloat(s string) float64 {
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
+
+func parseInt(s string) int64 {
+	i, _ := strconv.ParseInt(s, 10, 64)
+	return i
+}
+
+// parseRate parses a frame rate written as a fraction, 30000/1001, rounded to the hundredth
+func parseRate(s string) float64 {
+	parts := strings.SplitN(s, "/", 2)
+	num := parseFloat(parts[0])
+	if len(parts) == 2 {
+		den := parseFloat(parts[1])
+		if den == 0 {
+			return 0
+		}
+		num /= den
+	}
+	return float64(int64(num*100+0.5)) / 100
+}
@@ -0,0 +1,148 @@
+package executors
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GoogleCredentials is the content of a Google service account JSON key file
+type GoogleCredentials struct {
+	ProjectID   string `json:"project_id"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// ReadGoogleCredentials reads a service account JSON key file. If file is empty,
+// the GOOGLE_APPLICATION_CREDENTIALS environment variable is used.
+func ReadGoogleCredentials(file string) (*GoogleCredentials, error) {
+	if file == "" {
+		file = os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	}
+	if file == "" {
+		return nil, fmt.Errorf("no credentials file, and GOOGLE_APPLICATION_CREDENTIALS is not set")
+	}
+	btes, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var c GoogleCredentials
+	if err := json.Unmarshal(btes, &c); err != nil {
+		return nil, fmt.Errorf("invalid credentials file %s: %v", file, err)
+	}
+	if c.TokenURI == "" {
+		c.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+	return &c, nil
+}
+
+// googleToken is an access token and its expiry
+type googleToken struct {
+	value  string
+	expiry time.Time
+}
+
+// googleTokens are the access tokens by service account, token uri and scopes: they are reused by the steps until
+// they are about to expire
+var (
+	googleTokens      = map[string]googleToken{}
+	googleTokensMutex sync.Mutex
+)
+
+// AccessToken exchanges a JWT signed with the service account key for an OAuth2 access token with the given
+// scopes. The token is reused by the next calls until a minute before its expiry. The exchange is cancelled
+// when ctx is done.
+func (c *GoogleCredentials) AccessToken(ctx context.Context, scopes ...string) (string, error) {
+	key := c.ClientEmail + " " + c.TokenURI + " " + strings.Join(scopes, " ")
+	googleTokensMutex.Lock()
+	token, ok := googleTokens[key]
+	googleTokensMutex.Unlock()
+	if ok && time.Until(token.expiry) > time.Minute {
+		return token.value, nil
+	}
+
+	token, err := c.exchangeToken(ctx, scopes)
+	if err != nil {
+		return "", err
+	}
+	googleTokensMutex.Lock()
+	googleTokens[key] = token
+	googleTokensMutex.Unlock()
+	return token.value, nil
+}
+
+func (c *GoogleCredentials) exchangeToken(ctx context.Context, scopes []string) (googleToken, error) {
+	block, _ := pem.Decode([]byte(c.PrivateKey))
+	if block == nil {
+		return googleToken{}, fmt.Errorf("invalid private key in credentials")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return googleToken{}, fmt.Errorf("invalid private key in credentials: %v", err)
+	}
+	pk, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return googleToken{}, fmt.Errorf("private key in credentials is not a RSA key")
+	}
+
+	now := time.Now().Unix()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iss":   c.ClientEmail,
+		"scope": strings.Join(scopes, " "),
+		"aud":   c.TokenURI,
+		"iat":   now,
+		"exp":   now + 3600,
+	})
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	hash := sha256.Sum256([]byte(signingInput))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, pk, crypto.SHA256, hash[:])
+	if err != nil {
+		return googleToken{}, err
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {signingInput + "." + base64.RawURLEncoding.EncodeToString(sig)},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return googleToken{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return googleToken{}, err
+	}
+	defer resp.Body.Close()
+	btes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return googleToken{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return googleToken{}, fmt.Errorf("unable to get an access token: %s: %s", resp.Status, string(btes))
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal(btes, &token); err != nil {
+		return googleToken{}, fmt.Errorf("unable to read access token: %v", err)
+	}
+	return googleToken{value: token.AccessToken, expiry: time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)}, nil
+}
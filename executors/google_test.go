@@ -0,0 +1,142 @@
+package executors
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newGoogleTokenServer starts a fake OAuth2 token endpoint of the service accounts. It checks the signature of the
+// JWT with the public key, and returns the tokens token-1, token-2... valid for expiresIn seconds.
+func newGoogleTokenServer(key *rsa.PrivateKey, expiresIn int) (*httptest.Server, *int) {
+	var exchanges int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		assertion := strings.Split(r.Form.Get("assertion"), ".")
+		if r.Form.Get("grant_type") != "urn:ietf:params:oauth:grant-type:jwt-bearer" || len(assertion) != 3 {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error": "invalid_grant"}`)
+			return
+		}
+		hash := sha256.Sum256([]byte(assertion[0] + "." + assertion[1]))
+		sig, _ := base64.RawURLEncoding.DecodeString(assertion[2])
+		claims, _ := base64.RawURLEncoding.DecodeString(assertion[1])
+		var c map[string]interface{}
+		json.Unmarshal(claims, &c)
+		if rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, hash[:], sig) != nil || c["iss"] != "venom@project.iam.gserviceaccount.com" || c["aud"] != "http://"+r.Host+"/token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error": "invalid_client"}`)
+			return
+		}
+		exchanges++
+		fmt.Fprintf(w, `{"access_token": "token-%d", "expires_in": %d, "token_type": "Bearer", "scope": %q}`, exchanges, expiresIn, c["scope"])
+	}))
+	return srv, &exchanges
+}
+
+// googleKeyFile returns the content of a service account key file
+func googleKeyFile(t *testing.T, key *rsa.PrivateKey, tokenURI string) []byte {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	btes, err := json.Marshal(map[string]string{
+		"type":         "service_account",
+		"project_id":   "project",
+		"client_email": "venom@project.iam.gserviceaccount.com",
+		"private_key":  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"token_uri":    tokenURI,
+	})
+	require.NoError(t, err)
+	return btes
+}
+
+func TestGoogleAccessToken(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	dir, err := ioutil.TempDir("", "google")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, tt := range []struct {
+		name      string
+		expiresIn int
+		tokens    []string
+		exchanges int
+	}{
+		{name: "reused", expiresIn: 3600, tokens: []string{"token-1", "token-1"}, exchanges: 1},
+		{name: "about to expire", expiresIn: 30, tokens: []string{"token-1", "token-2"}, exchanges: 2},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, exchanges := newGoogleTokenServer(key, tt.expiresIn)
+			defer srv.Close()
+			file := filepath.Join(dir, tt.name+".json")
+			require.NoError(t, ioutil.WriteFile(file, googleKeyFile(t, key, srv.URL+"/token"), 0600))
+
+			creds, err := ReadGoogleCredentials(file)
+			require.NoError(t, err)
+			assert.Equal(t, "project", creds.ProjectID)
+			var tokens []string
+			for range tt.tokens {
+				token, err := creds.AccessToken(context.Background(), "https://www.googleapis.com/auth/bigquery")
+				require.NoError(t, err)
+				tokens = append(tokens, token)
+			}
+			assert.Equal(t, tt.tokens, tokens)
+			assert.Equal(t, tt.exchanges, *exchanges)
+		})
+	}
+
+	srv, _ := newGoogleTokenServer(key, 3600)
+	defer srv.Close()
+	var wrong *GoogleCredentials
+	require.NoError(t, json.Unmarshal(googleKeyFile(t, key, srv.URL+"/token"), &wrong))
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, _ := x509.MarshalPKCS8PrivateKey(other)
+	wrong.PrivateKey = string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	_, err = wrong.AccessToken(context.Background(), "scope")
+	assert.EqualError(t, err, `unable to get an access token: 401 Unauthorized: {"error": "invalid_client"}`)
+
+	wrong.PrivateKey = "not a key"
+	_, err = wrong.AccessToken(context.Background(), "scope")
+	assert.EqualError(t, err, "invalid private key in credentials")
+
+	_, err = ReadGoogleCredentials(filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestGoogleAccessToken_cancelled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the context of the request is done when the client closes the connection, once the body is read
+		r.ParseForm()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	var creds *GoogleCredentials
+	require.NoError(t, json.Unmarshal(googleKeyFile(t, key, srv.URL+"/token"), &creds))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = creds.AccessToken(ctx, "scope")
+	assert.Error(t, err)
+	assert.Equal(t, context.DeadlineExceeded, ctx.Err())
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
 	"strconv"
 	"time"
 
@@ -14,7 +15,9 @@ import (
 	"github.com/jhump/protoreflect/grpcreflect"
 	"github.com/mitchellh/mapstructure"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/metadata"
 	reflectpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
 	"google.golang.org/grpc/status"
@@ -53,6 +56,9 @@ type Result struct {
 	SystemerrJSON interface{} `json:"systemerrjson,omitempty" yaml:"systemerrjson,omitempty"`
 	Err           string      `json:"err,omitempty" yaml:"err,omitempty"`
 	Code          string      `json:"code,omitempty" yaml:"code,omitempty"`
+	Status        string      `json:"status,omitempty" yaml:"status,omitempty"`
+	Services      []string    `json:"services,omitempty" yaml:"services,omitempty"`
+	Methods       []string    `json:"methods,omitempty" yaml:"methods,omitempty"`
 	TimeSeconds   float64     `json:"timeseconds,omitempty" yaml:"timeseconds,omitempty"`
 	TimeHuman     string      `json:"timehuman,omitempty" yaml:"timehuman,omitempty"`
 }
@@ -124,7 +130,7 @@ func (Executor) Run(testCaseContext venom.TestCaseContext, l venom.Logger, step
 	result := Result{Executor: e}
 	start := time.Now()
 
-	ctx := context.Background()
+	ctx := testCaseContext.Context()
 
 	// prepare dial function
 	dial := func() *grpc.ClientConn {
@@ -135,7 +141,15 @@ func (Executor) Run(testCaseContext venom.TestCaseContext, l venom.Logger, step
 		ctx, cancel := context.WithTimeout(ctx, dialTime)
 		defer cancel()
 		var creds credentials.TransportCredentials
-		cc, err := grpcurl.BlockingDial(ctx, "tcp", e.Url, creds)
+		var cc *grpc.ClientConn
+		var err error
+		if executors.SOCKS5Proxy != nil {
+			cc, err = grpc.DialContext(ctx, e.Url, grpc.WithBlock(), grpc.WithInsecure(), grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+				return executors.DialContext(ctx, "tcp", addr)
+			}))
+		} else {
+			cc, err = grpcurl.BlockingDial(ctx, "tcp", e.Url, creds)
+		}
 		if err != nil {
 			return nil
 		}
@@ -168,6 +182,20 @@ func (Executor) Run(testCaseContext venom.TestCaseContext, l venom.Logger, step
 		cc = dial()
 	}
 
+	switch e.Method {
+	case "list":
+		if err := e.list(descSource, &result); err != nil {
+			return nil, err
+		}
+		return e.dumpResult(result, start)
+	case "health":
+		if cc == nil {
+			return nil, fmt.Errorf("unable to connect to %s", e.Url)
+		}
+		e.health(refCtx, cc, &result)
+		return e.dumpResult(result, start)
+	}
+
 	// prepare request and send
 	in := bytes.NewReader(data)
 	rf, formatter, err := grpcurl.RequestParserAndFormatterFor(
@@ -194,10 +222,6 @@ func (Executor) Run(testCaseContext venom.TestCaseContext, l venom.Logger, step
 		return nil, fmt.Errorf("error invoking method %s", err)
 	}
 
-	elapsed := time.Since(start)
-	result.TimeSeconds = elapsed.Seconds()
-	result.TimeHuman = elapsed.String()
-
 	if handle.err != nil {
 		result.Err = handle.err.Error()
 	}
@@ -224,5 +248,44 @@ func (Executor) Run(testCaseContext venom.TestCaseContext, l venom.Logger, step
 		result.SystemoutJSON = errJSONArray
 	}
 
+	return e.dumpResult(result, start)
+}
+
+func (e Executor) dumpResult(result Result, start time.Time) (venom.ExecutorResult, error) {
+	elapsed := time.Since(start)
+	result.TimeSeconds = elapsed.Seconds()
+	result.TimeHuman = elapsed.String()
 	return executors.Dump(result)
 }
+
+// list lists the services exposed by the server, or the methods of the service, using reflection
+func (e Executor) list(descSource grpcurl.DescriptorSource, result *Result) error {
+	if e.Service == "" {
+		services, err := grpcurl.ListServices(descSource)
+		if err != nil {
+			return fmt.Errorf("failed to list services: %v", err)
+		}
+		result.Services = services
+	} else {
+		methods, err := grpcurl.ListMethods(descSource, e.Service)
+		if err != nil {
+			return fmt.Errorf("failed to list methods of service %s: %v", e.Service, err)
+		}
+		result.Methods = methods
+	}
+	result.Code = strconv.Itoa(int(codes.OK))
+	return nil
+}
+
+// health calls the standard health service, https://github.com/grpc/grpc/blob/master/doc/health-checking.md
+func (e Executor) health(ctx context.Context, cc *grpc.ClientConn, result *Result) {
+	resp, err := healthpb.NewHealthClient(cc).Check(ctx, &healthpb.HealthCheckRequest{Service: e.Service})
+	if err != nil {
+		stat, _ := status.FromError(err)
+		result.Systemerr = err.Error()
+		result.Code = strconv.Itoa(int(uint32(stat.Code())))
+		return
+	}
+	result.Status = resp.Status.String()
+	result.Code = strconv.Itoa(int(codes.OK))
+}
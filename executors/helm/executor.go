@@ -0,0 +1,175 @@
+package helm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/ovh/venom"
+	"github.com/ovh/venom/executors"
+)
+
+// Name of executor
+const Name = "helm"
+
+const helmCommand = "helm"
+
+// New returns a new Executor
+func New() venom.Executor {
+	return &Executor{}
+}
+
+// Executor verifies a helm release
+type Executor struct {
+	Release     string `json:"release" yaml:"release"`
+	Namespace   string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	KubeContext string `json:"kube_context,omitempty" yaml:"kube_context,omitempty" mapstructure:"kube_context"`
+	Kubeconfig  string `json:"kubeconfig,omitempty" yaml:"kubeconfig,omitempty"`
+	Test        bool   `json:"test,omitempty" yaml:"test,omitempty"`
+	AllValues   bool   `json:"all_values,omitempty" yaml:"all_values,omitempty" mapstructure:"all_values"`
+}
+
+// Result represents a step result
+type Result struct {
+	Executor     Executor               `json:"executor,omitempty" yaml:"executor,omitempty"`
+	Exists       bool                   `json:"exists" yaml:"exists"`
+	Status       string                 `json:"status,omitempty" yaml:"status,omitempty"`
+	Revision     int                    `json:"revision,omitempty" yaml:"revision,omitempty"`
+	Namespace    string                 `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	Chart        string                 `json:"chart,omitempty" yaml:"chart,omitempty"`
+	ChartVersion string                 `json:"chartversion,omitempty" yaml:"chartversion,omitempty"`
+	AppVersion   string                 `json:"appversion,omitempty" yaml:"appversion,omitempty"`
+	LastDeployed string                 `json:"lastdeployed,omitempty" yaml:"lastdeployed,omitempty"`
+	Values       map[string]interface{} `json:"values,omitempty" yaml:"values,omitempty"`
+	TestPassed   bool                   `json:"testpassed,omitempty" yaml:"testpassed,omitempty"`
+	TestOutput   string                 `json:"testoutput,omitempty" yaml:"testoutput,omitempty"`
+	Err          string                 `json:"err,omitempty" yaml:"err,omitempty"`
+	TimeSeconds  float64                `json:"timeseconds,omitempty" yaml:"timeseconds,omitempty"`
+	TimeHuman    string                 `json:"timehuman,omitempty" yaml:"timehuman,omitempty"`
+}
+
+// helmStatus is the subset of `helm status -o json` output used by the executor
+type helmStatus struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Version   int    `json:"version"`
+	Info      struct {
+		Status       string `json:"status"`
+		LastDeployed string `json:"last_deployed"`
+	} `json:"info"`
+	Chart struct {
+		Metadata struct {
+			Name       string `json:"name"`
+			Version    string `json:"version"`
+			AppVersion string `json:"appVersion"`
+		} `json:"metadata"`
+	} `json:"chart"`
+}
+
+// ZeroValueResult return an empty implemtation of this executor result
+func (Executor) ZeroValueResult() venom.ExecutorResult {
+	r, _ := executors.Dump(Result{Values: map[string]interface{}{}})
+	return r
+}
+
+// GetDefaultAssertions return default assertions for this executor
+func (Executor) GetDefaultAssertions() *venom.StepAssertions {
+	return &venom.StepAssertions{Assertions: []string{"result.status ShouldEqual deployed"}}
+}
+
+// Run execute TestStep
+func (Executor) Run(testCaseContext venom.TestCaseContext, l venom.Logger, step venom.TestStep, workdir string) (venom.ExecutorResult, error) {
+	var e Executor
+	if err := mapstructure.Decode(step, &e); err != nil {
+		return nil, err
+	}
+	if e.Release == "" {
+		return nil, fmt.Errorf("release is mandatory")
+	}
+
+	start := time.Now()
+	result := Result{Executor: e}
+	if err := e.status(testCaseContext.Context(), l, &result); err != nil {
+		result.Err = err.Error()
+	}
+
+	elapsed := time.Since(start)
+	result.TimeSeconds = elapsed.Seconds()
+	result.TimeHuman = elapsed.String()
+
+	return executors.Dump(result)
+}
+
+func (e Executor) status(ctx context.Context, l venom.Logger, result *Result) error {
+	out, err := e.helm(ctx, l, "status", e.Release, "-o", "json")
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil
+		}
+		return err
+	}
+
+	var s helmStatus
+	if err := json.Unmarshal(out, &s); err != nil {
+		return fmt.Errorf("unable to read helm status output: %v", err)
+	}
+	result.Exists = true
+	result.Status = s.Info.Status
+	result.Revision = s.Version
+	result.Namespace = s.Namespace
+	result.LastDeployed = s.Info.LastDeployed
+	result.Chart = s.Chart.Metadata.Name
+	result.ChartVersion = s.Chart.Metadata.Version
+	result.AppVersion = s.Chart.Metadata.AppVersion
+
+	args := []string{"get", "values", e.Release, "-o", "json"}
+	if e.AllValues {
+		args = append(args, "--all")
+	}
+	out, err = e.helm(ctx, l, args...)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(out, &result.Values); err != nil {
+		return fmt.Errorf("unable to read helm values output: %v", err)
+	}
+
+	if e.Test {
+		out, err := e.helm(ctx, l, "test", e.Release)
+		result.TestOutput = string(out)
+		if err != nil {
+			result.TestOutput += err.Error()
+			return nil
+		}
+		result.TestPassed = true
+	}
+	return nil
+}
+
+func (e Executor) helm(ctx context.Context, l venom.Logger, args ...string) ([]byte, error) {
+	if e.Namespace != "" {
+		args = append(args, "--namespace", e.Namespace)
+	}
+	if e.KubeContext != "" {
+		args = append(args, "--kube-context", e.KubeContext)
+	}
+	if e.Kubeconfig != "" {
+		args = append(args, "--kubeconfig", e.Kubeconfig)
+	}
+	l.Debugf("%s %s", helmCommand, strings.Join(args, " "))
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd := exec.CommandContext(ctx, helmCommand, args...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return stdout.Bytes(), fmt.Errorf("%s %s failed: %v: %s", helmCommand, args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
@@ -1,6 +1,7 @@
 package http
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"crypto/tls"
@@ -11,15 +12,18 @@ import (
 	"mime/multipart"
 	"net"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
 	"github.com/mitchellh/mapstructure"
 	"github.com/ovh/venom"
 	"github.com/ovh/venom/executors"
+	venomtls "github.com/ovh/venom/executors/tls"
 )
 
 // Name of executor
@@ -50,18 +54,39 @@ type Executor struct {
 	Proxy             string      `json:"proxy" yaml:"proxy" mapstructure:"proxy"`
 	NoFollowRedirect  bool        `json:"no_follow_redirect" yaml:"no_follow_redirect" mapstructure:"no_follow_redirect"`
 	UnixSock          string      `json:"unix_sock" yaml:"unix_sock" mapstructure:"unix_sock"`
+	ReadLimitBytes    int         `json:"read_limit_bytes" yaml:"read_limit_bytes" mapstructure:"read_limit_bytes"`
+	ReadTimeout       int         `json:"read_timeout" yaml:"read_timeout" mapstructure:"read_timeout"`
+	ReadUntil         string      `json:"read_until" yaml:"read_until" mapstructure:"read_until"`
+	RawRequest        string      `json:"raw_request" yaml:"raw_request" mapstructure:"raw_request"`
+	Golden            string      `json:"golden,omitempty" yaml:"golden,omitempty" mapstructure:"golden"`
+	ForceIP           string      `json:"force_ip,omitempty" yaml:"force_ip,omitempty" mapstructure:"force_ip"`
+	HTTP3             bool        `json:"http3,omitempty" yaml:"http3,omitempty" mapstructure:"http3"`
+	Signature         *Signature  `json:"signature,omitempty" yaml:"signature,omitempty"`
 }
 
 // Result represents a step result. Json and yaml descriptor are used for json output
 type Result struct {
-	Executor    Executor    `json:"executor,omitempty" yaml:"executor,omitempty"`
-	TimeSeconds float64     `json:"timeseconds,omitempty" yaml:"timeseconds,omitempty"`
-	TimeHuman   string      `json:"timehuman,omitempty" yaml:"timehuman,omitempty"`
-	StatusCode  int         `json:"statuscode,omitempty" yaml:"statuscode,omitempty"`
-	Body        string      `json:"body,omitempty" yaml:"body,omitempty"`
-	BodyJSON    interface{} `json:"bodyjson,omitempty" yaml:"bodyjson,omitempty"`
-	Headers     Headers     `json:"headers,omitempty" yaml:"headers,omitempty"`
-	Err         string      `json:"err,omitempty" yaml:"err,omitempty"`
+	Executor    Executor             `json:"executor,omitempty" yaml:"executor,omitempty"`
+	TimeSeconds float64              `json:"timeseconds,omitempty" yaml:"timeseconds,omitempty"`
+	TimeHuman   string               `json:"timehuman,omitempty" yaml:"timehuman,omitempty"`
+	StatusCode  int                  `json:"statuscode,omitempty" yaml:"statuscode,omitempty"`
+	Body        string               `json:"body,omitempty" yaml:"body,omitempty"`
+	BodyJSON    interface{}          `json:"bodyjson,omitempty" yaml:"bodyjson,omitempty"`
+	Headers     Headers              `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Truncated   bool                 `json:"truncated,omitempty" yaml:"truncated,omitempty"`
+	Err         string               `json:"err,omitempty" yaml:"err,omitempty"`
+	RemoteAddr  string               `json:"remoteaddr,omitempty" yaml:"remoteaddr,omitempty"`
+	IPVersion   string               `json:"ipversion,omitempty" yaml:"ipversion,omitempty"`
+	TLS         *venomtls.Connection `json:"tls,omitempty" yaml:"tls,omitempty"`
+	Proto       string               `json:"proto,omitempty" yaml:"proto,omitempty"`
+	// HTTP3Advertised is true when the server advertises HTTP/3 in its Alt-Svc header
+	HTTP3Advertised bool `json:"http3advertised" yaml:"http3advertised"`
+	// HTTP3Err is the error of the QUIC connection of a step with http3, the request is then sent with HTTP/1.1 or HTTP/2
+	HTTP3Err string `json:"http3err,omitempty" yaml:"http3err,omitempty"`
+	// TLSHandshakeSeconds is the time of the TLS handshake, or of the QUIC handshake with HTTP/3
+	TLSHandshakeSeconds float64 `json:"tlshandshakeseconds,omitempty" yaml:"tlshandshakeseconds,omitempty"`
+	// WireLog is the file of the step in the wire log directory of the run
+	WireLog string `json:"wirelog,omitempty" yaml:"wirelog,omitempty"`
 }
 
 // ZeroValueResult return an empty implemtation of this executor result
@@ -93,16 +118,55 @@ func (Executor) Run(testCaseContext venom.TestCaseContext, l venom.Logger, step
 	e.MultipartForm = step["multipart_form"]
 
 	r := Result{Executor: e}
+	if e.Signature != nil {
+		s := *e.Signature
+		s.Secret = "****hidden****" // do not output secret
+		r.Executor.Signature = &s
+	}
 
-	req, err := e.getRequest(workdir)
+	var readUntil *regexp.Regexp
+	if e.ReadUntil != "" {
+		var err error
+		readUntil, err = regexp.Compile(e.ReadUntil)
+		if err != nil {
+			return nil, fmt.Errorf("invalid read_until: %v", err)
+		}
+	}
+	network, err := tcpNetwork(e.ForceIP)
 	if err != nil {
 		return nil, err
 	}
+	// the request is cancelled when the step is stopped
+	ctx, cancel := context.WithCancel(testCaseContext.Context())
+	defer cancel()
+	// the address of the last connection, the connection to the proxy when there is one
+	var tlsStart time.Time
+	ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			r.RemoteAddr = info.Conn.RemoteAddr().String()
+		},
+		TLSHandshakeStart: func() {
+			tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			r.TLSHandshakeSeconds = time.Since(tlsStart).Seconds()
+		},
+	})
+
+	var req *http.Request
+	if e.RawRequest == "" {
+		var err error
+		req, err = e.getRequest(workdir)
+		if err != nil {
+			return nil, err
+		}
+		req = req.WithContext(ctx)
 
-	for k, v := range e.Headers {
-		req.Header.Set(k, v)
-		if strings.ToLower(k) == "host" {
-			req.Host = v
+		for k, v := range e.Headers {
+			req.Header.Set(k, v)
+			if strings.ToLower(k) == "host" {
+				req.Host = v
+			}
 		}
 	}
 
@@ -118,6 +182,17 @@ func (Executor) Run(testCaseContext venom.TestCaseContext, l venom.Logger, step
 				Net:  "unix",
 			})
 		}
+	} else if executors.SOCKS5Proxy != nil {
+		// the proxies of the environment are not used, all the connections go through the SOCKS5 proxy of the run
+		tr.Proxy = nil
+		tr.DialContext = func(ctx context.Context, _, addr string) (net.Conn, error) {
+			return executors.DialContext(ctx, network, addr)
+		}
+	} else if network != "tcp" {
+		dialer := &net.Dialer{}
+		tr.DialContext = func(ctx context.Context, _, addr string) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, addr)
+		}
 	}
 
 	if len(e.Proxy) > 0 {
@@ -128,6 +203,30 @@ func (Executor) Run(testCaseContext venom.TestCaseContext, l venom.Logger, step
 		tr.Proxy = http.ProxyURL(proxyURL)
 	}
 	client := &http.Client{Transport: tr}
+	if e.HTTP3 {
+		if err := e.checkHTTP3(); err != nil {
+			return nil, err
+		}
+		h3, err := e.newHTTP3Transport(network, &r, tr)
+		if err != nil {
+			return nil, err
+		}
+		defer h3.Close()
+		client.Transport = h3
+	}
+	var wl *wireLog
+	if WireLog != "" {
+		if wl, err = e.newWireLog(); err != nil {
+			return nil, fmt.Errorf("unable to create the wire log: %v", err)
+		}
+		defer wl.Close()
+		r.WireLog = wl.Name()
+		client.Transport = wl.RoundTripper(client.Transport)
+	}
+	if VCR != nil {
+		// the replayed interactions are not sent, they are not in the wire log
+		client.Transport = VCR.RoundTripper(client.Transport)
+	}
 	if e.NoFollowRedirect {
 		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
 			return http.ErrUseLastResponse
@@ -136,22 +235,40 @@ func (Executor) Run(testCaseContext venom.TestCaseContext, l venom.Logger, step
 
 	start := time.Now()
 	l.Debugf("http.Run.doRequest> Begin")
-	resp, err := client.Do(req)
+	var resp *http.Response
+	if e.RawRequest != "" && VCR != nil && VCR.Replay {
+		return nil, fmt.Errorf("raw_request can not be replayed from cassettes")
+	} else if e.RawRequest != "" {
+		resp, err = e.doRawRequest(ctx, network, wl)
+	} else {
+		resp, err = client.Do(req)
+	}
 	l.Debugf("http.Run.doRequest> End (%.3f seconds)", time.Since(t0).Seconds())
 	if err != nil {
 		return nil, err
 	}
 	elapsed := time.Since(start)
+	r.IPVersion = ipVersion(r.RemoteAddr)
 	r.TimeSeconds = elapsed.Seconds()
 	r.TimeHuman = fmt.Sprintf("%s", elapsed)
 
+	if e.ReadTimeout > 0 {
+		timer := time.AfterFunc(time.Duration(e.ReadTimeout)*time.Second, cancel)
+		defer timer.Stop()
+	}
+
 	var bb []byte
 	if resp.Body != nil {
-		defer resp.Body.Close()
+		defer func() {
+			// the interaction is recorded in its cassette when the body is closed
+			if err := resp.Body.Close(); err != nil {
+				l.Errorf("http> unable to close response body: %v", err)
+			}
+		}()
 
 		if !e.SkipBody {
 			var errr error
-			bb, errr = ioutil.ReadAll(resp.Body)
+			bb, r.Truncated, errr = e.readBody(ctx, resp.Body, readUntil)
 			if errr != nil {
 				return nil, errr
 			}
@@ -185,14 +302,57 @@ func (Executor) Run(testCaseContext venom.TestCaseContext, l venom.Logger, step
 
 	r.StatusCode = resp.StatusCode
 	l.Debugf("http.Response.Status.Code (%d)", r.StatusCode)
+	r.TLS = venomtls.NewConnection(resp.TLS)
+	r.Proto = resp.Proto
+	r.HTTP3Advertised = advertisesHTTP3(resp.Header.Get("Alt-Svc"))
+
+	if e.Golden != "" {
+		if err := checkGolden(e.Golden, workdir, bb); err != nil {
+			return nil, err
+		}
+	}
 
 	return executors.Dump(r)
 }
 
+// readBody reads the response body until its end, or until read_limit_bytes are read,
+// the read_until pattern is found or read_timeout is reached. The returned
+// boolean is true if the body was not read until its end.
+func (e Executor) readBody(ctx context.Context, body io.Reader, readUntil *regexp.Regexp) ([]byte, bool, error) {
+	if e.ReadLimitBytes <= 0 && e.ReadTimeout <= 0 && readUntil == nil {
+		bb, err := ioutil.ReadAll(body)
+		return bb, false, err
+	}
+
+	var bb []byte
+	buf := make([]byte, 4096)
+	for {
+		n, err := body.Read(buf)
+		bb = append(bb, buf[:n]...)
+		if e.ReadLimitBytes > 0 && len(bb) >= e.ReadLimitBytes {
+			return bb[:e.ReadLimitBytes], true, nil
+		}
+		if readUntil != nil && readUntil.Match(bb) {
+			return bb, true, nil
+		}
+		if err == io.EOF {
+			return bb, false, nil
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				// read_timeout reached
+				return bb, true, nil
+			}
+			return nil, false, err
+		}
+	}
+}
+
 // getRequest returns the request correctly set for the current executor
 func (e Executor) getRequest(workdir string) (*http.Request, error) {
 	path := fmt.Sprintf("%s%s", e.URL, e.Path)
-	method := e.Method
+	// any method is allowed (PROPFIND, MKCOL, PURGE...)
+	method := strings.ToUpper(e.Method)
 	if method == "" {
 		method = "GET"
 	}
@@ -261,9 +421,122 @@ func (e Executor) getRequest(workdir string) (*http.Request, error) {
 	if writer != nil {
 		req.Header.Set("Content-Type", writer.FormDataContentType())
 	}
+
+	if e.Signature != nil {
+		if err := e.Signature.sign(req, body.Bytes()); err != nil {
+			return nil, err
+		}
+	}
 	return req, err
 }
 
+// doRawRequest sends raw_request as is on a connection to the host of url (or to unix_sock),
+// and reads the response. Line endings of the request line and headers are converted to CRLF.
+func (e Executor) doRawRequest(ctx context.Context, network string, wl *wireLog) (*http.Response, error) {
+	u, err := url.Parse(e.URL)
+	if err != nil {
+		return nil, err
+	}
+	var conn net.Conn
+	switch {
+	case e.UnixSock != "":
+		conn, err = (&net.Dialer{}).DialContext(ctx, "unix", e.UnixSock)
+	case u.Scheme == "https":
+		address := u.Host
+		if u.Port() == "" {
+			address += ":443"
+		}
+		conn, err = executors.DialContext(ctx, network, address)
+	default:
+		address := u.Host
+		if u.Port() == "" {
+			address += ":80"
+		}
+		conn, err = executors.DialContext(ctx, network, address)
+	}
+	if err != nil {
+		return nil, err
+	}
+	go func(conn net.Conn) {
+		// close the connection when the step ends, or when read_timeout is reached
+		<-ctx.Done()
+		conn.Close()
+	}(conn)
+	if u.Scheme == "https" && e.UnixSock == "" {
+		tlsConn := tls.Client(conn, &tls.Config{InsecureSkipVerify: e.IgnoreVerifySSL, ServerName: u.Hostname()})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn = tlsConn
+	}
+	if trace := httptrace.ContextClientTrace(ctx); trace != nil && trace.GotConn != nil {
+		trace.GotConn(httptrace.GotConnInfo{Conn: conn})
+	}
+	if wl != nil {
+		conn = wl.Conn(conn)
+	}
+
+	raw := e.RawRequest
+	head, body := raw, ""
+	if i := strings.Index(raw, "\n\n"); i >= 0 {
+		head, body = raw[:i], raw[i+2:]
+	} else if i := strings.Index(raw, "\r\n\r\n"); i >= 0 {
+		head, body = raw[:i], raw[i+4:]
+	}
+	head = strings.Replace(strings.Replace(head, "\r\n", "\n", -1), "\n", "\r\n", -1)
+	if _, err := io.WriteString(conn, head+"\r\n\r\n"+body); err != nil {
+		return nil, err
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if tlsConn, ok := conn.(*tls.Conn); ok && err == nil {
+		state := tlsConn.ConnectionState()
+		resp.TLS = &state
+	}
+	return resp, err
+}
+
+// advertisesHTTP3 returns true if the Alt-Svc header advertises HTTP/3, h3 or one of its drafts: h3-29...
+func advertisesHTTP3(altSvc string) bool {
+	for _, service := range strings.Split(altSvc, ",") {
+		protocol := strings.TrimSpace(strings.SplitN(service, "=", 2)[0])
+		if protocol == "h3" || strings.HasPrefix(protocol, "h3-") {
+			return true
+		}
+	}
+	return false
+}
+
+// tcpNetwork returns the network of the connections for force_ip: v4, v6, or both when empty. Both are tried
+// with the happy eyeballs of the dialer.
+func tcpNetwork(forceIP string) (string, error) {
+	switch forceIP {
+	case "":
+		return "tcp", nil
+	case "v4":
+		return "tcp4", nil
+	case "v6":
+		return "tcp6", nil
+	}
+	return "", fmt.Errorf("invalid force_ip %q, it must be v4 or v6", forceIP)
+}
+
+// ipVersion returns v4 or v6, the version of the IP of a remote address, or an empty string for the unix sockets
+func ipVersion(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return ""
+	}
+	ip := net.ParseIP(host)
+	switch {
+	case ip == nil:
+		return ""
+	case ip.To4() != nil:
+		return "v4"
+	}
+	return "v6"
+}
+
 // writeFile writes the content of the file to an io.Writer
 func writeFile(part io.Writer, filename string) error {
 	file, err := os.Open(filename)
@@ -0,0 +1,72 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/venom"
+)
+
+func TestRunRawRequest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "http")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	sock := filepath.Join(dir, "raw.sock")
+	listener, err := net.Listen("unix", sock)
+	require.NoError(t, err)
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"path": %q, "host": %q}`, r.URL.Path, r.Host)
+	})}
+	go srv.Serve(listener)
+	defer srv.Close()
+
+	step := venom.TestStep{"url": "http://localhost/", "unix_sock": sock, "raw_request": "GET /ping HTTP/1.1\nHost: api.local\n\n"}
+	res, err := Executor{}.Run(&venom.CommonTestCaseContext{}, logrus.New(), step, "")
+	require.NoError(t, err)
+	assert.Equal(t, 200, res["result.statuscode"])
+	assert.Equal(t, "/ping", res["result.bodyjson.path"])
+	assert.Equal(t, "api.local", res["result.bodyjson.host"])
+}
+
+func TestRunRawRequest_cancelled(t *testing.T) {
+	// the server accepts the connections, and never answers to the TLS handshake
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		var conns []net.Conn
+		defer func() {
+			for _, c := range conns {
+				c.Close()
+			}
+		}()
+		for {
+			c, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conns = append(conns, c)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	tcc := &venom.CommonTestCaseContext{}
+	tcc.SetContext(ctx)
+	start := time.Now()
+	step := venom.TestStep{"url": "https://" + listener.Addr().String() + "/", "raw_request": "GET / HTTP/1.1\nHost: api.local\n\n"}
+	_, err = Executor{}.Run(tcc, logrus.New(), step, "")
+	assert.Error(t, err)
+	assert.True(t, time.Since(start) < 5*time.Second, "the handshake should stop with its step")
+}
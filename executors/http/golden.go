@@ -0,0 +1,58 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ovh/venom/assertions"
+)
+
+// UpdateGolden rewrites the golden files of the http steps from the actual responses, when set
+var UpdateGolden bool
+
+// checkGolden compares the body of the response with the golden file, relative to the working directory.
+// JSON bodies are compared once indented, with their keys sorted.
+func checkGolden(golden, workdir string, body []byte) error {
+	if !filepath.IsAbs(golden) {
+		golden = filepath.Join(workdir, golden)
+	}
+	actual := normalizeGolden(body)
+
+	if UpdateGolden {
+		if err := os.MkdirAll(filepath.Dir(golden), 0755); err != nil {
+			return fmt.Errorf("unable to write the golden file: %v", err)
+		}
+		return ioutil.WriteFile(golden, []byte(actual), 0644)
+	}
+
+	expected, err := ioutil.ReadFile(golden)
+	if err != nil {
+		return fmt.Errorf("unable to read the golden file, it's written by venom run --update-golden: %v", err)
+	}
+	if err := assertions.ShouldEqual(actual, normalizeGolden(expected)); err != nil {
+		return fmt.Errorf("the body does not match the golden file %s: %v", golden, err)
+	}
+	return nil
+}
+
+// normalizeGolden indents the JSON bodies with their keys sorted, the other bodies are kept as is
+func normalizeGolden(body []byte) string {
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err == nil && !dec.More() {
+		var out bytes.Buffer
+		enc := json.NewEncoder(&out)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(v); err == nil {
+			return out.String()
+		}
+	}
+	return strings.TrimRight(string(body), "\n") + "\n"
+}
@@ -0,0 +1,117 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+
+	"github.com/ovh/venom/executors"
+)
+
+// http3HandshakeTimeout is the time to establish the QUIC connection, the request is then sent with HTTP/1.1 or HTTP/2
+var http3HandshakeTimeout = 5 * time.Second
+
+// http3Transport sends the requests with HTTP/3 over QUIC. A request is sent with next, HTTP/1.1 or HTTP/2, when
+// the QUIC connection can't be established: UDP blocked, or a server not supporting HTTP/3.
+type http3Transport struct {
+	h3      *http3.RoundTripper
+	quic    *quic.Transport
+	udp     *net.UDPConn
+	next    http.RoundTripper
+	r       *Result
+	errDial error
+}
+
+// newHTTP3Transport returns the HTTP/3 transport of the step, network is the network of the TCP connections
+// of force_ip: tcp, tcp4 or tcp6. The address and the handshake time of the QUIC connection are set in the result.
+func (e Executor) newHTTP3Transport(network string, r *Result, next http.RoundTripper) (*http3Transport, error) {
+	udpNetwork := strings.Replace(network, "tcp", "udp", 1)
+	udp, err := net.ListenUDP(udpNetwork, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open the UDP socket of HTTP/3: %v", err)
+	}
+	t := &http3Transport{quic: &quic.Transport{Conn: udp}, udp: udp, next: next, r: r}
+	t.h3 = &http3.RoundTripper{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: e.IgnoreVerifySSL},
+		QuicConfig:      &quic.Config{HandshakeIdleTimeout: http3HandshakeTimeout},
+		Dial: func(ctx context.Context, addr string, tlsCfg *tls.Config, cfg *quic.Config) (quic.EarlyConnection, error) {
+			conn, err := t.dial(ctx, udpNetwork, addr, tlsCfg, cfg)
+			if err != nil {
+				t.errDial = err
+			}
+			return conn, err
+		},
+	}
+	return t, nil
+}
+
+// dial establishes the QUIC connection, waiting for the end of its handshake to measure it
+func (t *http3Transport) dial(ctx context.Context, network, addr string, tlsCfg *tls.Config, cfg *quic.Config) (quic.EarlyConnection, error) {
+	raddr, err := net.ResolveUDPAddr(network, addr)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	conn, err := t.quic.DialEarly(ctx, raddr, tlsCfg, cfg)
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case <-conn.HandshakeComplete():
+	case <-conn.Context().Done():
+		return nil, conn.Context().Err()
+	case <-ctx.Done():
+		conn.CloseWithError(0, "")
+		return nil, ctx.Err()
+	}
+	t.r.TLSHandshakeSeconds = time.Since(start).Seconds()
+	t.r.RemoteAddr = conn.RemoteAddr().String()
+	return conn, nil
+}
+
+// checkHTTP3 returns an error if the step can't be sent with HTTP/3: QUIC needs an https url and a direct UDP
+// connection to the host
+func (e Executor) checkHTTP3() error {
+	switch {
+	case !strings.HasPrefix(strings.ToLower(e.URL), "https://"):
+		return fmt.Errorf("http3 needs an https url")
+	case e.UnixSock != "" || e.RawRequest != "":
+		return fmt.Errorf("http3 can't be used with unix_sock or raw_request")
+	case e.Proxy != "" || executors.SOCKS5Proxy != nil:
+		return fmt.Errorf("http3 can't be used with a proxy")
+	}
+	return nil
+}
+
+// RoundTrip sends the request with HTTP/3, or with next if the QUIC connection can't be established
+func (t *http3Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.errDial = nil
+	resp, err := t.h3.RoundTrip(req)
+	if err == nil || t.errDial == nil || t.next == nil {
+		return resp, err
+	}
+	t.r.HTTP3Err = t.errDial.Error()
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		retry.Body = body
+	}
+	return t.next.RoundTrip(retry)
+}
+
+// Close closes the QUIC connections and the UDP socket
+func (t *http3Transport) Close() error {
+	t.h3.Close()
+	t.quic.Close()
+	return t.udp.Close()
+}
@@ -0,0 +1,86 @@
+package http
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go/http3"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/venom"
+)
+
+// newHTTP3TestServer starts a TLS server, and an HTTP/3 server on the same port if quic is true. It returns
+// the url of the servers and the function closing them.
+func newHTTP3TestServer(t *testing.T, quic bool) (string, func()) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"proto": %q}`, r.Proto)
+	})
+	srv := httptest.NewTLSServer(handler)
+	if !quic {
+		return srv.URL, srv.Close
+	}
+	addr := srv.Listener.Addr().(*net.TCPAddr)
+	udp, err := net.ListenUDP("udp", &net.UDPAddr{IP: addr.IP, Port: addr.Port})
+	require.NoError(t, err)
+	h3 := &http3.Server{Handler: handler, TLSConfig: srv.TLS}
+	go h3.Serve(udp)
+	return srv.URL, func() {
+		h3.Close()
+		udp.Close()
+		srv.Close()
+	}
+}
+
+func TestRunHTTP3(t *testing.T) {
+	http3HandshakeTimeout = 500 * time.Millisecond
+	tests := []struct {
+		name     string
+		quic     bool
+		proto    string
+		http3Err bool
+	}{
+		{name: "QUIC", quic: true, proto: "HTTP/3.0"},
+		{name: "fallback without QUIC", proto: "HTTP/1.1", http3Err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url, stop := newHTTP3TestServer(t, tt.quic)
+			defer stop()
+			step := venom.TestStep{"url": url, "http3": true, "ignore_verify_ssl": true}
+			res, err := Executor{}.Run(&venom.CommonTestCaseContext{}, logrus.New(), step, "")
+			require.NoError(t, err)
+			assert.Equal(t, 200, res["result.statuscode"])
+			assert.Equal(t, tt.proto, res["result.proto"])
+			assert.Equal(t, tt.proto, res["result.bodyjson.proto"])
+			assert.NotEmpty(t, res["result.remoteaddr"])
+			assert.Equal(t, tt.http3Err, res["result.http3err"] != "")
+			if tt.quic {
+				assert.Greater(t, res["result.tlshandshakeseconds"], 0.0)
+			}
+		})
+	}
+}
+
+func TestCheckHTTP3(t *testing.T) {
+	tests := []struct {
+		executor Executor
+		valid    bool
+	}{
+		{executor: Executor{URL: "https://cdn.example.com"}, valid: true},
+		{executor: Executor{URL: "http://cdn.example.com"}},
+		{executor: Executor{URL: "https://cdn.example.com", Proxy: "http://proxy:3128"}},
+		{executor: Executor{URL: "https://cdn.example.com", RawRequest: "GET / HTTP/1.1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.executor.URL, func(t *testing.T) {
+			assert.Equal(t, tt.valid, tt.executor.checkHTTP3() == nil)
+		})
+	}
+}
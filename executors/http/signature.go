@@ -0,0 +1,92 @@
+package http
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Signature computes a webhook style HMAC signature of the body and adds it to the request headers
+type Signature struct {
+	Provider  string `json:"provider,omitempty" yaml:"provider,omitempty"`
+	Secret    string `json:"secret,omitempty" yaml:"secret,omitempty"`
+	Header    string `json:"header,omitempty" yaml:"header,omitempty"`
+	Algorithm string `json:"algorithm,omitempty" yaml:"algorithm,omitempty"`
+	Encoding  string `json:"encoding,omitempty" yaml:"encoding,omitempty"`
+	Prefix    string `json:"prefix,omitempty" yaml:"prefix,omitempty"`
+	Timestamp int64  `json:"timestamp,omitempty" yaml:"timestamp,omitempty"`
+}
+
+// sign adds the signature headers of body to req
+func (s Signature) sign(req *http.Request, body []byte) error {
+	if s.Secret == "" {
+		return fmt.Errorf("signature: secret is mandatory")
+	}
+	ts := s.Timestamp
+	if ts == 0 {
+		ts = time.Now().Unix()
+	}
+	timestamp := strconv.FormatInt(ts, 10)
+
+	switch strings.ToLower(s.Provider) {
+	case "github":
+		// X-Hub-Signature-256: sha256=<hex>
+		req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(hmacSum(sha256.New, s.Secret, body)))
+	case "stripe":
+		// Stripe-Signature: t=<timestamp>,v1=<hex of timestamp.body>
+		payload := append([]byte(timestamp+"."), body...)
+		req.Header.Set("Stripe-Signature", "t="+timestamp+",v1="+hex.EncodeToString(hmacSum(sha256.New, s.Secret, payload)))
+	case "slack":
+		// X-Slack-Signature: v0=<hex of v0:timestamp:body>
+		payload := append([]byte("v0:"+timestamp+":"), body...)
+		req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+		req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(hmacSum(sha256.New, s.Secret, payload)))
+	case "", "custom":
+		if s.Header == "" {
+			return fmt.Errorf("signature: header is mandatory without provider")
+		}
+		var h func() hash.Hash
+		switch strings.ToLower(s.Algorithm) {
+		case "md5":
+			h = md5.New
+		case "sha1":
+			h = sha1.New
+		case "", "sha256":
+			h = sha256.New
+		case "sha512":
+			h = sha512.New
+		default:
+			return fmt.Errorf("signature: unsupported algorithm %s", s.Algorithm)
+		}
+		sum := hmacSum(h, s.Secret, body)
+		var sig string
+		switch strings.ToLower(s.Encoding) {
+		case "", "hex":
+			sig = hex.EncodeToString(sum)
+		case "base64":
+			sig = base64.StdEncoding.EncodeToString(sum)
+		default:
+			return fmt.Errorf("signature: unsupported encoding %s", s.Encoding)
+		}
+		req.Header.Set(s.Header, s.Prefix+sig)
+	default:
+		return fmt.Errorf("signature: unsupported provider %s, must be github, stripe, slack or custom", s.Provider)
+	}
+	return nil
+}
+
+func hmacSum(h func() hash.Hash, secret string, data []byte) []byte {
+	mac := hmac.New(h, []byte(secret))
+	mac.Write(data)
+	return mac.Sum(nil)
+}
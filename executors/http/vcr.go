@@ -0,0 +1,228 @@
+package http
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// VCR records the HTTP interactions of all the http steps in cassettes, or replays them, when set
+var VCR *Cassettes
+
+var regexpCassetteName = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// Cassettes records HTTP interactions in cassette files, or replays them without hitting the network.
+// A cassette holds the interactions of a request: identical requests (same method, URL and body)
+// are replayed in the order they were recorded, the last one is replayed again when all have been replayed.
+type Cassettes struct {
+	Dir    string
+	Replay bool
+
+	mutex     sync.Mutex
+	cassettes map[string]*cassette
+}
+
+type cassette struct {
+	Interactions []interaction `json:"interactions"`
+	played       int
+}
+
+type interaction struct {
+	Request  cassetteRequest  `json:"request"`
+	Response cassetteResponse `json:"response"`
+}
+
+type cassetteRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   string `json:"body,omitempty"`
+}
+
+type cassetteResponse struct {
+	StatusCode int         `json:"status_code"`
+	Headers    http.Header `json:"headers,omitempty"`
+	Body       string      `json:"body,omitempty"`
+	BodyBase64 string      `json:"body_base64,omitempty"`
+}
+
+// NewCassettes returns Cassettes recording in dir, or replaying from dir
+func NewCassettes(dir string, replay bool) *Cassettes {
+	return &Cassettes{Dir: dir, Replay: replay, cassettes: map[string]*cassette{}}
+}
+
+// RoundTripper returns a RoundTripper recording the interactions made with next, or replaying them
+func (c *Cassettes) RoundTripper(next http.RoundTripper) http.RoundTripper {
+	return &vcrTransport{cassettes: c, next: next}
+}
+
+type vcrTransport struct {
+	cassettes *Cassettes
+	next      http.RoundTripper
+}
+
+func (t *vcrTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	r := cassetteRequest{Method: req.Method, URL: req.URL.String(), Body: string(body)}
+	if t.cassettes.Replay {
+		return t.cassettes.replay(req, r)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	// the body is recorded when the executor closes it, it may be read partially (read_until, read_timeout...)
+	resp.Body = &recordingBody{ReadCloser: resp.Body, onClose: func(b []byte) error {
+		return t.cassettes.record(r, resp, b)
+	}}
+	return resp, nil
+}
+
+func (c *Cassettes) replay(req *http.Request, r cassetteRequest) (*http.Response, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	name := c.filename(r)
+	cas, ok := c.cassettes[name]
+	if !ok {
+		btes, err := ioutil.ReadFile(name)
+		if err != nil {
+			return nil, fmt.Errorf("no cassette for %s %s: %v", r.Method, r.URL, err)
+		}
+		cas = &cassette{}
+		if err := json.Unmarshal(btes, cas); err != nil {
+			return nil, fmt.Errorf("invalid cassette %s: %v", name, err)
+		}
+		if len(cas.Interactions) == 0 {
+			return nil, fmt.Errorf("empty cassette %s", name)
+		}
+		c.cassettes[name] = cas
+	}
+
+	i := cas.Interactions[len(cas.Interactions)-1]
+	if cas.played < len(cas.Interactions) {
+		i = cas.Interactions[cas.played]
+	}
+	cas.played++
+
+	body := []byte(i.Response.Body)
+	if i.Response.BodyBase64 != "" {
+		var err error
+		if body, err = base64.StdEncoding.DecodeString(i.Response.BodyBase64); err != nil {
+			return nil, fmt.Errorf("invalid cassette %s: %v", name, err)
+		}
+	}
+	header := i.Response.Headers
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", i.Response.StatusCode, http.StatusText(i.Response.StatusCode)),
+		StatusCode:    i.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+func (c *Cassettes) record(r cassetteRequest, resp *http.Response, body []byte) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	i := interaction{Request: r, Response: cassetteResponse{StatusCode: resp.StatusCode, Headers: resp.Header}}
+	if utf8.Valid(body) {
+		i.Response.Body = string(body)
+	} else {
+		i.Response.BodyBase64 = base64.StdEncoding.EncodeToString(body)
+	}
+
+	// cassettes recorded by a previous run are replaced
+	name := c.filename(r)
+	cas, ok := c.cassettes[name]
+	if !ok {
+		cas = &cassette{}
+		c.cassettes[name] = cas
+	}
+	cas.Interactions = append(cas.Interactions, i)
+
+	btes, err := json.MarshalIndent(cas, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(name, btes, 0644)
+}
+
+// filename returns the cassette file of a request: <dir>/<host>/<method>-<path>-<hash>.json
+func (c *Cassettes) filename(r cassetteRequest) string {
+	h := sha256.Sum256([]byte(r.Method + " " + r.URL + "\n" + r.Body))
+	host, path := r.URL, ""
+	if i := strings.Index(host, "://"); i >= 0 {
+		host = host[i+3:]
+	}
+	if i := strings.IndexAny(host, "/?"); i >= 0 {
+		host, path = host[:i], host[i:]
+	}
+	if i := strings.Index(path, "?"); i >= 0 {
+		path = path[:i]
+	}
+	path = strings.Trim(regexpCassetteName.ReplaceAllString(path, "_"), "_")
+	if len(path) > 64 {
+		path = path[:64]
+	}
+	name := strings.ToLower(r.Method) + "-" + path + "-" + hex.EncodeToString(h[:])[:12] + ".json"
+	return filepath.Join(c.Dir, regexpCassetteName.ReplaceAllString(host, "_"), name)
+}
+
+// recordingBody calls onClose with the bytes read from the body when it is closed
+type recordingBody struct {
+	io.ReadCloser
+	buf     bytes.Buffer
+	onClose func([]byte) error
+	closed  bool
+}
+
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.buf.Write(p[:n])
+	return n, err
+}
+
+func (b *recordingBody) Close() error {
+	err := b.ReadCloser.Close()
+	if b.closed {
+		return err
+	}
+	b.closed = true
+	if errRecord := b.onClose(b.buf.Bytes()); errRecord != nil {
+		return errRecord
+	}
+	return err
+}
@@ -0,0 +1,174 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WireLog is the directory where the requests and the responses of the http steps are written as they are sent and
+// received, a file by step, when set
+var WireLog string
+
+var wireLogSequence uint64
+
+// wireLog is the wire log file of a step
+type wireLog struct {
+	mutex sync.Mutex
+	file  *os.File
+	// sent is the direction of the last bytes written by a raw request
+	sent *bool
+}
+
+// newWireLog creates the wire log file of a step, named after its method and its host
+func (e Executor) newWireLog() (*wireLog, error) {
+	if err := os.MkdirAll(WireLog, os.FileMode(0755)); err != nil {
+		return nil, err
+	}
+	method := strings.ToUpper(e.Method)
+	switch {
+	case e.RawRequest != "":
+		method = "RAW"
+	case method == "":
+		method = "GET"
+	}
+	host := "unix"
+	if u, err := url.Parse(e.URL); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	n := atomic.AddUint64(&wireLogSequence, 1)
+	name := fmt.Sprintf("%04d-%s-%s.log", n, method, host)
+	f, err := os.Create(filepath.Join(WireLog, regexpCassetteName.ReplaceAllString(name, "_")))
+	if err != nil {
+		return nil, err
+	}
+	return &wireLog{file: f}, nil
+}
+
+func (w *wireLog) Name() string {
+	return w.file.Name()
+}
+
+func (w *wireLog) Close() error {
+	return w.file.Close()
+}
+
+func (w *wireLog) section(title string, btes []byte) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	fmt.Fprintf(w.file, "%s %s\n", title, time.Now().Format(time.RFC3339Nano))
+	_, _ = w.file.Write(btes)
+}
+
+// RoundTripper returns a RoundTripper writing the requests and the headers of the responses in the wire log, the
+// bodies of the responses are written as they are read
+func (w *wireLog) RoundTripper(next http.RoundTripper) http.RoundTripper {
+	return &wireLogTransport{wireLog: w, next: next}
+}
+
+type wireLogTransport struct {
+	wireLog *wireLog
+	next    http.RoundTripper
+}
+
+func (t *wireLogTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// the request is dumped without the trace of the step, the dump is sent on a fake connection
+	out := req.WithContext(context.Background())
+	dump, err := httputil.DumpRequestOut(out, true)
+	if err != nil {
+		return nil, err
+	}
+	req.Body = out.Body
+	t.wireLog.section(">>> request", append(dump, '\n'))
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		t.wireLog.section("!!! error", []byte(err.Error()+"\n"))
+		return nil, err
+	}
+	head, err := httputil.DumpResponse(resp, false)
+	if err != nil {
+		return nil, err
+	}
+	t.wireLog.section("<<< response", head)
+	if resp.Body != nil {
+		resp.Body = &wireLogBody{ReadCloser: resp.Body, wireLog: t.wireLog}
+	}
+	return resp, nil
+}
+
+type wireLogBody struct {
+	io.ReadCloser
+	wireLog *wireLog
+}
+
+func (b *wireLogBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.wireLog.mutex.Lock()
+		_, _ = b.wireLog.file.Write(p[:n])
+		b.wireLog.mutex.Unlock()
+	}
+	return n, err
+}
+
+func (b *wireLogBody) Close() error {
+	b.wireLog.mutex.Lock()
+	_, _ = b.wireLog.file.WriteString("\n")
+	b.wireLog.mutex.Unlock()
+	return b.ReadCloser.Close()
+}
+
+// Conn returns a connection writing the bytes sent and received on conn in the wire log, for the raw requests
+func (w *wireLog) Conn(conn net.Conn) net.Conn {
+	return &wireLogConn{Conn: conn, wireLog: w}
+}
+
+type wireLogConn struct {
+	net.Conn
+	wireLog *wireLog
+}
+
+func (c *wireLogConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	if n > 0 {
+		c.wireLog.raw(false, p[:n])
+	}
+	return n, err
+}
+
+func (c *wireLogConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	if n > 0 {
+		c.wireLog.raw(true, p[:n])
+	}
+	return n, err
+}
+
+// raw writes the bytes of a raw request, with a title when the direction changes
+func (w *wireLog) raw(sent bool, btes []byte) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.sent == nil || *w.sent != sent {
+		title := "<<< received"
+		if sent {
+			title = ">>> sent"
+		}
+		if w.sent != nil {
+			_, _ = w.file.WriteString("\n")
+		}
+		fmt.Fprintf(w.file, "%s %s\n", title, time.Now().Format(time.RFC3339Nano))
+		w.sent = &sent
+	}
+	_, _ = w.file.Write(btes)
+}
@@ -0,0 +1,250 @@
+package imagediff
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"  // decode the gif images
+	_ "image/jpeg" // decode the jpeg images
+	"image/png"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/ovh/venom"
+	"github.com/ovh/venom/executors"
+)
+
+// Name of executor
+const Name = "imagediff"
+
+// UpdateBaseline rewrites the baselines of the imagediff steps from the actual images, when set
+var UpdateBaseline bool
+
+// New returns a new Executor
+func New() venom.Executor {
+	return &Executor{}
+}
+
+// Executor compares an image, a screenshot of the web executor for instance, with a baseline image
+type Executor struct {
+	Image    string `json:"image,omitempty" yaml:"image,omitempty"`
+	Baseline string `json:"baseline,omitempty" yaml:"baseline,omitempty"`
+	Diff     string `json:"diff,omitempty" yaml:"diff,omitempty"`
+	// Tolerance is the difference ignored on each color channel of a pixel, from 0 to 255, for the antialiasing
+	Tolerance      int     `json:"tolerance,omitempty" yaml:"tolerance,omitempty"`
+	MaxDiffPixels  int     `json:"max_diff_pixels,omitempty" yaml:"max_diff_pixels,omitempty" mapstructure:"max_diff_pixels"`
+	MaxDiffPercent float64 `json:"max_diff_percent,omitempty" yaml:"max_diff_percent,omitempty" mapstructure:"max_diff_percent"`
+}
+
+// Result represents a step result
+type Result struct {
+	Executor       Executor `json:"executor,omitempty" yaml:"executor,omitempty"`
+	Match          bool     `json:"match" yaml:"match"`
+	Width          int      `json:"width" yaml:"width"`
+	Height         int      `json:"height" yaml:"height"`
+	BaselineWidth  int      `json:"baselinewidth" yaml:"baselinewidth"`
+	BaselineHeight int      `json:"baselineheight" yaml:"baselineheight"`
+	DiffPixels     int      `json:"diffpixels" yaml:"diffpixels"`
+	DiffPercent    float64  `json:"diffpercent" yaml:"diffpercent"`
+	Diff           string   `json:"diff,omitempty" yaml:"diff,omitempty"`
+	TimeSeconds    float64  `json:"timeseconds,omitempty" yaml:"timeseconds,omitempty"`
+	TimeHuman      string   `json:"timehuman,omitempty" yaml:"timehuman,omitempty"`
+}
+
+// ZeroValueResult return an empty implemtation of this executor result
+func (Executor) ZeroValueResult() venom.ExecutorResult {
+	r, _ := executors.Dump(Result{})
+	return r
+}
+
+// GetDefaultAssertions return default assertions for this executor
+func (Executor) GetDefaultAssertions() *venom.StepAssertions {
+	return &venom.StepAssertions{Assertions: []string{"result.match ShouldBeTrue"}}
+}
+
+// Run execute TestStep
+func (Executor) Run(testCaseContext venom.TestCaseContext, l venom.Logger, step venom.TestStep, workdir string) (venom.ExecutorResult, error) {
+	var e Executor
+	if err := mapstructure.Decode(step, &e); err != nil {
+		return nil, err
+	}
+	if e.Image == "" || e.Baseline == "" {
+		return nil, fmt.Errorf("image and baseline are mandatory")
+	}
+	if e.Tolerance < 0 || e.Tolerance > 255 {
+		return nil, fmt.Errorf("invalid tolerance %d, it must be between 0 and 255", e.Tolerance)
+	}
+
+	start := time.Now()
+	btes, err := readImage(e.Image, workdir)
+	if err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(bytes.NewReader(btes))
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode the image %s: %v", e.Image, err)
+	}
+
+	baseline := rebase(e.Baseline, workdir)
+	if UpdateBaseline {
+		if err := os.MkdirAll(filepath.Dir(baseline), 0755); err != nil {
+			return nil, fmt.Errorf("unable to write the baseline: %v", err)
+		}
+		if err := ioutil.WriteFile(baseline, btes, 0644); err != nil {
+			return nil, fmt.Errorf("unable to write the baseline: %v", err)
+		}
+		l.Infof("Baseline %s is written", baseline)
+	}
+	f, err := os.Open(baseline)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the baseline, it's written by venom run --update-golden: %v", err)
+	}
+	defer f.Close()
+	base, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode the baseline %s: %v", baseline, err)
+	}
+
+	result := Result{
+		Executor:       e,
+		Width:          img.Bounds().Dx(),
+		Height:         img.Bounds().Dy(),
+		BaselineWidth:  base.Bounds().Dx(),
+		BaselineHeight: base.Bounds().Dy(),
+	}
+	diff, pixels, total := compare(img, base, e.Tolerance)
+	result.DiffPixels = pixels
+	if total > 0 {
+		result.DiffPercent = float64(pixels) * 100 / float64(total)
+	}
+	// the images match when they have the same size and the thresholds set are respected, without threshold all
+	// the pixels must be the same
+	result.Match = result.Width == result.BaselineWidth && result.Height == result.BaselineHeight
+	if e.MaxDiffPixels > 0 || e.MaxDiffPercent == 0 {
+		result.Match = result.Match && result.DiffPixels <= e.MaxDiffPixels
+	}
+	if e.MaxDiffPercent > 0 || e.MaxDiffPixels == 0 {
+		result.Match = result.Match && result.DiffPercent <= e.MaxDiffPercent
+	}
+
+	if e.Diff != "" {
+		file := rebase(e.Diff, workdir)
+		if err := writePNG(file, diff); err != nil {
+			return nil, fmt.Errorf("unable to write the diff image: %v", err)
+		}
+		result.Diff = file
+	}
+	l.Debugf("imagediff %s: %d pixels differ from the baseline (%.2f%%)", e.Image, result.DiffPixels, result.DiffPercent)
+
+	elapsed := time.Since(start)
+	result.TimeSeconds = elapsed.Seconds()
+	result.TimeHuman = elapsed.String()
+	return executors.Dump(result)
+}
+
+// compare compares the images pixel by pixel. It returns the diff image, the baseline faded with the different
+// pixels in red, the number of different pixels and the number of pixels compared. The pixels out of one of the
+// images are different.
+func compare(img, base image.Image, tolerance int) (*image.RGBA, int, int) {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	if w := base.Bounds().Dx(); w > width {
+		width = w
+	}
+	if h := base.Bounds().Dy(); h > height {
+		height = h
+	}
+	diff := image.NewRGBA(image.Rect(0, 0, width, height))
+	var pixels int
+	red := color.RGBA{R: 255, A: 255}
+	b1, b2 := img.Bounds(), base.Bounds()
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if x >= b1.Dx() || y >= b1.Dy() || x >= b2.Dx() || y >= b2.Dy() {
+				pixels++
+				diff.Set(x, y, red)
+				continue
+			}
+			c1 := img.At(b1.Min.X+x, b1.Min.Y+y)
+			c2 := base.At(b2.Min.X+x, b2.Min.Y+y)
+			if !similar(c1, c2, tolerance) {
+				pixels++
+				diff.Set(x, y, red)
+				continue
+			}
+			g := color.GrayModel.Convert(c2).(color.Gray)
+			faded := uint8(192 + int(g.Y)/4)
+			diff.Set(x, y, color.RGBA{R: faded, G: faded, B: faded, A: 255})
+		}
+	}
+	return diff, pixels, width * height
+}
+
+// similar returns true if no channel of the colors differs by more than the tolerance
+func similar(c1, c2 color.Color, tolerance int) bool {
+	r1, g1, b1, a1 := c1.RGBA()
+	r2, g2, b2, a2 := c2.RGBA()
+	for _, d := range [][2]uint32{{r1, r2}, {g1, g2}, {b1, b2}, {a1, a2}} {
+		delta := int(d[0]>>8) - int(d[1]>>8)
+		if delta < 0 {
+			delta = -delta
+		}
+		if delta > tolerance {
+			return false
+		}
+	}
+	return true
+}
+
+// readImage reads the image from an url, or from a file relative to the workdir
+func readImage(src, workdir string) ([]byte, error) {
+	if !strings.HasPrefix(src, "http://") && !strings.HasPrefix(src, "https://") {
+		btes, err := ioutil.ReadFile(rebase(src, workdir))
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the image: %v", err)
+		}
+		return btes, nil
+	}
+	tr := &http.Transport{Proxy: http.ProxyFromEnvironment}
+	if executors.SOCKS5Proxy != nil {
+		tr.Proxy = nil
+		tr.DialContext = executors.DialContext
+	}
+	resp, err := (&http.Client{Transport: tr}).Get(src)
+	if err != nil {
+		return nil, fmt.Errorf("unable to download the image: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to download the image %s: %s", src, resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+func rebase(file, workdir string) string {
+	if filepath.IsAbs(file) {
+		return file
+	}
+	return filepath.Join(workdir, file)
+}
+
+func writePNG(file string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
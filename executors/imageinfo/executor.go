@@ -0,0 +1,202 @@
+package imageinfo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"  // decode the gif images
+	_ "image/jpeg" // decode the jpeg images
+	_ "image/png"  // decode the png images
+	"io/ioutil"
+	"math/bits"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/ovh/venom"
+	"github.com/ovh/venom/executors"
+)
+
+// Name of executor
+const Name = "imageinfo"
+
+// New returns a new Executor
+func New() venom.Executor {
+	return &Executor{}
+}
+
+// Executor reads the dimensions, the format, the EXIF fields and the perceptual hash of an image
+type Executor struct {
+	Image string `json:"image,omitempty" yaml:"image,omitempty"`
+	// Hash is an expected perceptual hash, the distance of the image to it is computed when set
+	Hash string `json:"hash,omitempty" yaml:"hash,omitempty"`
+}
+
+// Result represents a step result
+type Result struct {
+	Executor    Executor          `json:"executor,omitempty" yaml:"executor,omitempty"`
+	Format      string            `json:"format" yaml:"format"`
+	Width       int               `json:"width" yaml:"width"`
+	Height      int               `json:"height" yaml:"height"`
+	ColorModel  string            `json:"colormodel" yaml:"colormodel"`
+	Size        int               `json:"size" yaml:"size"`
+	Exif        map[string]string `json:"exif,omitempty" yaml:"exif,omitempty"`
+	Hash        string            `json:"hash" yaml:"hash"`
+	Distance    int               `json:"distance" yaml:"distance"`
+	TimeSeconds float64           `json:"timeseconds,omitempty" yaml:"timeseconds,omitempty"`
+	TimeHuman   string            `json:"timehuman,omitempty" yaml:"timehuman,omitempty"`
+}
+
+// ZeroValueResult return an empty implemtation of this executor result
+func (Executor) ZeroValueResult() venom.ExecutorResult {
+	r, _ := executors.Dump(Result{})
+	return r
+}
+
+// GetDefaultAssertions return default assertions for this executor
+func (Executor) GetDefaultAssertions() *venom.StepAssertions {
+	return &venom.StepAssertions{Assertions: []string{"result.width ShouldBeGreaterThan 0"}}
+}
+
+// Run execute TestStep
+func (Executor) Run(testCaseContext venom.TestCaseContext, l venom.Logger, step venom.TestStep, workdir string) (venom.ExecutorResult, error) {
+	var e Executor
+	if err := mapstructure.Decode(step, &e); err != nil {
+		return nil, err
+	}
+	if e.Image == "" {
+		return nil, fmt.Errorf("image is mandatory")
+	}
+	var expected uint64
+	if e.Hash != "" {
+		var err error
+		if expected, err = strconv.ParseUint(e.Hash, 16, 64); err != nil {
+			return nil, fmt.Errorf("invalid hash %q, it must be the 16 hexadecimal digits of a perceptual hash", e.Hash)
+		}
+	}
+
+	start := time.Now()
+	btes, err := readImage(e.Image, workdir)
+	if err != nil {
+		return nil, err
+	}
+	img, format, err := image.Decode(bytes.NewReader(btes))
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode the image %s: %v", e.Image, err)
+	}
+
+	hash := perceptualHash(img)
+	result := Result{
+		Executor:   e,
+		Format:     format,
+		Width:      img.Bounds().Dx(),
+		Height:     img.Bounds().Dy(),
+		ColorModel: colorModel(img.ColorModel()),
+		Size:       len(btes),
+		Hash:       fmt.Sprintf("%016x", hash),
+	}
+	if e.Hash != "" {
+		result.Distance = bits.OnesCount64(hash ^ expected)
+	}
+	if tiff := exifData(format, btes); tiff != nil {
+		if result.Exif, err = parseExif(tiff); err != nil {
+			l.Warnf("unable to read the EXIF fields of the image %s: %v", e.Image, err)
+		}
+	}
+	l.Debugf("imageinfo %s: %s %dx%d, hash %s", e.Image, format, result.Width, result.Height, result.Hash)
+
+	elapsed := time.Since(start)
+	result.TimeSeconds = elapsed.Seconds()
+	result.TimeHuman = elapsed.String()
+	return executors.Dump(result)
+}
+
+func colorModel(m color.Model) string {
+	switch m {
+	case color.RGBAModel, color.NRGBAModel:
+		return "rgba"
+	case color.RGBA64Model, color.NRGBA64Model:
+		return "rgba64"
+	case color.GrayModel:
+		return "gray"
+	case color.Gray16Model:
+		return "gray16"
+	case color.YCbCrModel:
+		return "ycbcr"
+	case color.CMYKModel:
+		return "cmyk"
+	}
+	if _, ok := m.(color.Palette); ok {
+		return "paletted"
+	}
+	return "unknown"
+}
+
+// exifData returns the TIFF structure of the EXIF fields of a jpeg, from its APP1 segment, or of a png, from its
+// eXIf chunk
+func exifData(format string, btes []byte) []byte {
+	switch format {
+	case "jpeg":
+		for i := 2; i+4 <= len(btes) && btes[i] == 0xff; {
+			marker := btes[i+1]
+			if marker == 0xda || marker == 0xd9 { // start of the image data
+				return nil
+			}
+			length := int(binary.BigEndian.Uint16(btes[i+2:]))
+			if length < 2 || i+2+length > len(btes) {
+				return nil
+			}
+			segment := btes[i+4 : i+2+length]
+			if marker == 0xe1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+				return segment[6:]
+			}
+			i += 2 + length
+		}
+	case "png":
+		for i := 8; i+8 <= len(btes); {
+			length := int(binary.BigEndian.Uint32(btes[i:]))
+			if length < 0 || i+12+length > len(btes) {
+				return nil
+			}
+			if string(btes[i+4:i+8]) == "eXIf" {
+				return btes[i+8 : i+8+length]
+			}
+			i += 12 + length
+		}
+	}
+	return nil
+}
+
+// readImage reads the image from an url, or from a file relative to the workdir
+func readImage(src, workdir string) ([]byte, error) {
+	if !strings.HasPrefix(src, "http://") && !strings.HasPrefix(src, "https://") {
+		if !filepath.IsAbs(src) {
+			src = filepath.Join(workdir, src)
+		}
+		btes, err := ioutil.ReadFile(src)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the image: %v", err)
+		}
+		return btes, nil
+	}
+	tr := &http.Transport{Proxy: http.ProxyFromEnvironment}
+	if executors.SOCKS5Proxy != nil {
+		tr.Proxy = nil
+		tr.DialContext = executors.DialContext
+	}
+	resp, err := (&http.Client{Transport: tr}).Get(src)
+	if err != nil {
+		return nil, fmt.Errorf("unable to download the image: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to download the image %s: %s", src, resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
@@ -0,0 +1,215 @@
+package imageinfo
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// exifTags are the names of the fields read in the IFD0 and the Exif IFD
+var exifTags = map[uint16]string{
+	0x0100: "imagewidth",
+	0x0101: "imagelength",
+	0x010e: "imagedescription",
+	0x010f: "make",
+	0x0110: "model",
+	0x0112: "orientation",
+	0x011a: "xresolution",
+	0x011b: "yresolution",
+	0x0128: "resolutionunit",
+	0x0131: "software",
+	0x0132: "datetime",
+	0x013b: "artist",
+	0x8298: "copyright",
+	0x829a: "exposuretime",
+	0x829d: "fnumber",
+	0x8827: "isospeedratings",
+	0x9000: "exifversion",
+	0x9003: "datetimeoriginal",
+	0x9004: "datetimedigitized",
+	0x9209: "flash",
+	0x920a: "focallength",
+	0xa001: "colorspace",
+	0xa002: "pixelxdimension",
+	0xa003: "pixelydimension",
+	0xa433: "lensmake",
+	0xa434: "lensmodel",
+}
+
+// gpsTags are the names of the fields read in the GPS IFD
+var gpsTags = map[uint16]string{
+	0x0001: "gpslatituderef",
+	0x0002: "gpslatitude",
+	0x0003: "gpslongituderef",
+	0x0004: "gpslongitude",
+	0x0005: "gpsaltituderef",
+	0x0006: "gpsaltitude",
+	0x001d: "gpsdatestamp",
+}
+
+const (
+	exifIFDPointer = 0x8769
+	gpsIFDPointer  = 0x8825
+)
+
+// parseExif returns the known EXIF fields of a TIFF structure, by name. The rationals are written as decimals and the
+// GPS coordinates in signed decimal degrees.
+func parseExif(tiff []byte) (map[string]string, error) {
+	if len(tiff) < 8 {
+		return nil, fmt.Errorf("invalid TIFF header")
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return nil, fmt.Errorf("invalid TIFF byte order")
+	}
+	if order.Uint16(tiff[2:]) != 42 {
+		return nil, fmt.Errorf("invalid TIFF header")
+	}
+
+	fields := map[string]string{}
+	visited := map[uint32]bool{}
+	var readIFD func(offset uint32, tags map[uint16]string) error
+	readIFD = func(offset uint32, tags map[uint16]string) error {
+		if visited[offset] {
+			return nil
+		}
+		visited[offset] = true
+		if int(offset)+2 > len(tiff) {
+			return fmt.Errorf("invalid IFD offset %d", offset)
+		}
+		n := int(order.Uint16(tiff[offset:]))
+		for i := 0; i < n; i++ {
+			entry := int(offset) + 2 + 12*i
+			if entry+12 > len(tiff) {
+				return fmt.Errorf("truncated IFD at %d", offset)
+			}
+			tag := order.Uint16(tiff[entry:])
+			typ := order.Uint16(tiff[entry+2:])
+			count := order.Uint32(tiff[entry+4:])
+			value, ok := exifValue(tiff, order, typ, count, tiff[entry+8:entry+12])
+			if !ok {
+				continue
+			}
+			switch {
+			case tag == exifIFDPointer && tags == nil:
+				if p, err := strconv.ParseUint(value, 10, 32); err == nil {
+					if err := readIFD(uint32(p), exifTags); err != nil {
+						return err
+					}
+				}
+			case tag == gpsIFDPointer && tags == nil:
+				if p, err := strconv.ParseUint(value, 10, 32); err == nil {
+					if err := readIFD(uint32(p), gpsTags); err != nil {
+						return err
+					}
+				}
+			default:
+				t := tags
+				if t == nil {
+					t = exifTags
+				}
+				if name, ok := t[tag]; ok {
+					fields[name] = value
+				}
+			}
+		}
+		return nil
+	}
+	if err := readIFD(order.Uint32(tiff[4:]), nil); err != nil {
+		return fields, err
+	}
+
+	for _, c := range []struct{ coordinate, ref, negative string }{{"gpslatitude", "gpslatituderef", "S"}, {"gpslongitude", "gpslongituderef", "W"}} {
+		if v, ok := fields[c.coordinate]; ok {
+			if degrees, ok := decimalDegrees(v); ok {
+				if strings.EqualFold(fields[c.ref], c.negative) {
+					degrees = -degrees
+				}
+				fields[c.coordinate] = strconv.FormatFloat(degrees, 'f', -1, 64)
+			}
+		}
+	}
+	return fields, nil
+}
+
+// exifValue returns the value of an IFD entry as a string, the values of an array are separated by a space
+func exifValue(tiff []byte, order binary.ByteOrder, typ uint16, count uint32, inline []byte) (string, bool) {
+	sizes := map[uint16]int{1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 7: 1, 9: 4, 10: 8}
+	size, ok := sizes[typ]
+	if !ok || count == 0 || count > 1<<20 {
+		return "", false
+	}
+	total := size * int(count)
+	data := inline
+	if total > 4 {
+		offset := int(order.Uint32(inline))
+		if offset < 0 || offset+total > len(tiff) {
+			return "", false
+		}
+		data = tiff[offset : offset+total]
+	}
+	data = data[:total]
+
+	switch typ {
+	case 2:
+		return strings.TrimSpace(strings.TrimRight(string(data), "\x00")), true
+	case 7:
+		// the undefined values are kept when they are text, the version of exif for instance
+		for _, b := range data {
+			if b < 0x20 || b > 0x7e {
+				return "", false
+			}
+		}
+		return string(data), true
+	}
+	values := make([]string, count)
+	for i := range values {
+		d := data[i*size:]
+		switch typ {
+		case 1:
+			values[i] = strconv.Itoa(int(d[0]))
+		case 3:
+			values[i] = strconv.Itoa(int(order.Uint16(d)))
+		case 4:
+			values[i] = strconv.FormatUint(uint64(order.Uint32(d)), 10)
+		case 9:
+			values[i] = strconv.Itoa(int(int32(order.Uint32(d))))
+		case 5, 10:
+			num, den := float64(order.Uint32(d)), float64(order.Uint32(d[4:]))
+			if typ == 10 {
+				num, den = float64(int32(order.Uint32(d))), float64(int32(order.Uint32(d[4:])))
+			}
+			if den == 0 {
+				values[i] = "0"
+				continue
+			}
+			values[i] = strconv.FormatFloat(num/den, 'f', -1, 64)
+		}
+	}
+	return strings.Join(values, " "), true
+}
+
+// decimalDegrees converts the degrees, minutes and seconds of a GPS coordinate in decimal degrees
+func decimalDegrees(v string) (float64, bool) {
+	parts := strings.Fields(v)
+	if len(parts) != 3 {
+		return 0, false
+	}
+	var dms [3]float64
+	for i, p := range parts {
+		f, err := strconv.ParseFloat(p, 64)
+		if err != nil {
+			return 0, false
+		}
+		dms[i] = f
+	}
+	degrees := dms[0] + dms[1]/60 + dms[2]/3600
+	return math.Round(degrees*1e6) / 1e6, true
+}
@@ -0,0 +1,77 @@
+package imageinfo
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sort"
+)
+
+// perceptualHash returns the DCT perceptual hash of the image: the image is reduced to 32x32 gray pixels, the bits
+// of the hash are the 8x8 lowest frequencies of its discrete cosine transform above their median. The hashes of
+// similar images, resized or recompressed, differ by a few bits.
+func perceptualHash(img image.Image) uint64 {
+	const size, low = 32, 8
+	pixels := grayscale(img, size)
+
+	// the 2D DCT is computed in rows then columns, for the low frequencies only
+	var rows [size][low]float64
+	for y := 0; y < size; y++ {
+		for u := 0; u < low; u++ {
+			var sum float64
+			for x := 0; x < size; x++ {
+				sum += pixels[y][x] * math.Cos(float64((2*x+1)*u)*math.Pi/(2*size))
+			}
+			rows[y][u] = sum
+		}
+	}
+	coefficients := make([]float64, 0, low*low)
+	for v := 0; v < low; v++ {
+		for u := 0; u < low; u++ {
+			var sum float64
+			for y := 0; y < size; y++ {
+				sum += rows[y][u] * math.Cos(float64((2*y+1)*v)*math.Pi/(2*size))
+			}
+			coefficients = append(coefficients, sum)
+		}
+	}
+
+	// the median excludes the first coefficient, the mean of the image
+	sorted := append([]float64{}, coefficients[1:]...)
+	sort.Float64s(sorted)
+	median := (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2
+	var hash uint64
+	for i, c := range coefficients {
+		if c > median {
+			hash |= 1 << uint(len(coefficients)-1-i)
+		}
+	}
+	return hash
+}
+
+// grayscale reduces the image to size x size gray pixels, each one is the mean of the pixels of its area
+func grayscale(img image.Image, size int) [][]float64 {
+	b := img.Bounds()
+	pixels := make([][]float64, size)
+	for y := 0; y < size; y++ {
+		pixels[y] = make([]float64, size)
+		y0, y1 := b.Min.Y+y*b.Dy()/size, b.Min.Y+(y+1)*b.Dy()/size
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < size; x++ {
+			x0, x1 := b.Min.X+x*b.Dx()/size, b.Min.X+(x+1)*b.Dx()/size
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+			var sum float64
+			for py := y0; py < y1 && py < b.Max.Y; py++ {
+				for px := x0; px < x1 && px < b.Max.X; px++ {
+					sum += float64(color.Gray16Model.Convert(img.At(px, py)).(color.Gray16).Y)
+				}
+			}
+			pixels[y][x] = sum / float64((y1-y0)*(x1-x0))
+		}
+	}
+	return pixels
+}
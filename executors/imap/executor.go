@@ -31,6 +31,8 @@ type Executor struct {
 	IMAPPort        string `json:"imapport,omitempty" yaml:"imapport,omitempty"`
 	IMAPUser        string `json:"imapuser,omitempty" yaml:"imapuser,omitempty"`
 	IMAPPassword    string `json:"imappassword,omitempty" yaml:"imappassword,omitempty"`
+	IMAPToken       string `json:"imaptoken,omitempty" yaml:"imaptoken,omitempty"`
+	IMAPStartTLS    bool   `json:"imapstarttls,omitempty" yaml:"imapstarttls,omitempty"`
 	MBox            string `json:"mbox,omitempty" yaml:"mbox,omitempty"`
 	MBoxOnSuccess   string `json:"mboxonsuccess,omitempty" yaml:"mboxonsuccess,omitempty"`
 	DeleteOnSuccess bool   `json:"deleteonsuccess,omitempty" yaml:"deleteonsuccess,omitempty"`
@@ -95,6 +97,9 @@ func (Executor) Run(ctx venom.TestCaseContext, l venom.Logger, step venom.TestSt
 	result.TimeSeconds = elapsed.Seconds()
 	result.TimeHuman = fmt.Sprintf("%s", elapsed)
 	result.Executor.IMAPPassword = "****hidden****" // do not output password
+	if result.Executor.IMAPToken != "" {
+		result.Executor.IMAPToken = "****hidden****"
+	}
 
 	return executors.Dump(result)
 }
@@ -104,7 +109,7 @@ func (e *Executor) getMail(l venom.Logger) (*Mail, error) {
 		return nil, fmt.Errorf("You have to use one of searchfrom, searchto, searchsubject or subjectbody parameters.")
 	}
 
-	c, errc := connect(e.IMAPHost, e.IMAPPort, e.IMAPUser, e.IMAPPassword)
+	c, errc := e.connect()
 	if errc != nil {
 		return nil, fmt.Errorf("Error while connecting:%s", errc.Error())
 	}
@@ -217,20 +222,32 @@ func (m *Mail) delete(c *imap.Client) error {
 	return nil
 }
 
-func connect(host, port, imapUsername, imapPassword string) (*imap.Client, error) {
+func (e *Executor) connect() (*imap.Client, error) {
+	host, port := e.IMAPHost, e.IMAPPort
 	if !strings.Contains(host, ":") {
-		if port == "" {
+		if port == "" && e.IMAPStartTLS {
+			port = ":143"
+		} else if port == "" {
 			port = ":993"
 		} else if port != "" && !strings.HasPrefix(port, ":") {
 			port = ":" + port
 		}
 	}
 
-	c, errd := imap.DialTLS(host+port, nil)
+	var c *imap.Client
+	var errd error
+	if e.IMAPStartTLS {
+		c, errd = imap.Dial(host + port)
+	} else {
+		c, errd = imap.DialTLS(host+port, nil)
+	}
 	if errd != nil {
 		return nil, fmt.Errorf("Unable to dial: %s", errd)
 	}
 
+	if e.IMAPStartTLS && !c.Caps["STARTTLS"] {
+		return nil, fmt.Errorf("Server does not support STARTTLS")
+	}
 	if c.Caps["STARTTLS"] {
 		if _, err := check(c.StartTLS(nil)); err != nil {
 			return nil, fmt.Errorf("Unable to start TLS: %s\n", err)
@@ -238,7 +255,11 @@ func connect(host, port, imapUsername, imapPassword string) (*imap.Client, error
 	}
 
 	c.SetLogMask(imapSafeLogMask)
-	if _, err := check(c.Login(imapUsername, imapPassword)); err != nil {
+	if e.IMAPToken != "" {
+		if _, err := check(c.Auth(xoauth2(e.IMAPUser, e.IMAPToken))); err != nil {
+			return nil, fmt.Errorf("Unable to authenticate with XOAUTH2: %s", err)
+		}
+	} else if _, err := check(c.Login(e.IMAPUser, e.IMAPPassword)); err != nil {
 		return nil, fmt.Errorf("Unable to login: %s", err)
 	}
 	c.SetLogMask(imapLogMask)
@@ -246,6 +267,23 @@ func connect(host, port, imapUsername, imapPassword string) (*imap.Client, error
 	return c, nil
 }
 
+type xoauth2Auth []byte
+
+// xoauth2 returns an implementation of the XOAUTH2 authentication mechanism
+// https://developers.google.com/gmail/imap/xoauth2-protocol
+func xoauth2(username, token string) imap.SASL {
+	return xoauth2Auth("user=" + username + "\x01auth=Bearer " + token + "\x01\x01")
+}
+
+func (a xoauth2Auth) Start(s *imap.ServerInfo) (string, []byte, error) {
+	return "XOAUTH2", a, nil
+}
+
+func (a xoauth2Auth) Next(challenge []byte) ([]byte, error) {
+	// the server sends a JSON error as challenge, an empty response ends the exchange
+	return []byte{}, nil
+}
+
 func fetch(c *imap.Client, box string, nb uint32, l venom.Logger) ([]imap.Response, error) {
 	l.Debugf("call Select")
 	if _, err := c.Select(box, false); err != nil {
@@ -0,0 +1,228 @@
+package junit
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-zglob"
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/ovh/venom"
+	"github.com/ovh/venom/executors"
+)
+
+// Name of executor
+const Name = "junit"
+
+// New returns a new Executor
+func New() venom.Executor {
+	return &Executor{}
+}
+
+// Executor reads the JUnit XML reports written by other tools, to assert on their results
+type Executor struct {
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+}
+
+// Result represents a step result
+type Result struct {
+	Executor    Executor   `json:"executor,omitempty" yaml:"executor,omitempty"`
+	Files       []string   `json:"files,omitempty" yaml:"files,omitempty"`
+	Total       int        `json:"total" yaml:"total"`
+	Passed      int        `json:"passed" yaml:"passed"`
+	Failures    int        `json:"failures" yaml:"failures"`
+	Errors      int        `json:"errors" yaml:"errors"`
+	Skipped     int        `json:"skipped" yaml:"skipped"`
+	Time        float64    `json:"time" yaml:"time"`
+	TestSuites  []Suite    `json:"testsuites,omitempty" yaml:"testsuites,omitempty"`
+	Failed      []TestCase `json:"failed,omitempty" yaml:"failed,omitempty"`
+	TimeSeconds float64    `json:"timeseconds,omitempty" yaml:"timeseconds,omitempty"`
+	TimeHuman   string     `json:"timehuman,omitempty" yaml:"timehuman,omitempty"`
+}
+
+// Suite is the summary of a testsuite of the reports
+type Suite struct {
+	Name     string  `json:"name" yaml:"name"`
+	File     string  `json:"file" yaml:"file"`
+	Total    int     `json:"total" yaml:"total"`
+	Failures int     `json:"failures" yaml:"failures"`
+	Errors   int     `json:"errors" yaml:"errors"`
+	Skipped  int     `json:"skipped" yaml:"skipped"`
+	Time     float64 `json:"time" yaml:"time"`
+}
+
+// TestCase is a testcase of the reports in failure or in error
+type TestCase struct {
+	Suite     string `json:"suite" yaml:"suite"`
+	Classname string `json:"classname,omitempty" yaml:"classname,omitempty"`
+	Name      string `json:"name" yaml:"name"`
+	// Status is failure or error
+	Status  string `json:"status" yaml:"status"`
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+// ZeroValueResult return an empty implemtation of this executor result
+func (Executor) ZeroValueResult() venom.ExecutorResult {
+	r, _ := executors.Dump(Result{})
+	return r
+}
+
+// GetDefaultAssertions return default assertions for this executor
+func (Executor) GetDefaultAssertions() *venom.StepAssertions {
+	return &venom.StepAssertions{Assertions: []string{"result.failures ShouldEqual 0", "result.errors ShouldEqual 0"}}
+}
+
+// Run execute TestStep
+func (Executor) Run(testCaseContext venom.TestCaseContext, l venom.Logger, step venom.TestStep, workdir string) (venom.ExecutorResult, error) {
+	var e Executor
+	if err := mapstructure.Decode(step, &e); err != nil {
+		return nil, err
+	}
+	if e.Path == "" {
+		return nil, fmt.Errorf("Invalid path")
+	}
+
+	start := time.Now()
+	path := e.Path
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(workdir, path)
+	}
+	files, err := zglob.Glob(path)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading files on path:%s :%s", path, err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no JUnit report found on path %s", path)
+	}
+	sort.Strings(files)
+
+	result := Result{Executor: e}
+	for _, f := range files {
+		suites, err := readReport(f)
+		if err != nil {
+			return nil, err
+		}
+		rel, err := filepath.Rel(workdir, f)
+		if err != nil {
+			rel = f
+		}
+		result.Files = append(result.Files, rel)
+		for _, s := range suites {
+			result.add(s, rel)
+		}
+	}
+	result.Passed = result.Total - result.Failures - result.Errors - result.Skipped
+	l.Debugf("junit %s: %d testcases, %d failures, %d errors, %d skipped", e.Path, result.Total, result.Failures, result.Errors, result.Skipped)
+
+	elapsed := time.Since(start)
+	result.TimeSeconds = elapsed.Seconds()
+	result.TimeHuman = elapsed.String()
+	return executors.Dump(result)
+}
+
+type xmlTestSuites struct {
+	XMLName    xml.Name
+	TestSuites []xmlTestSuite `xml:"testsuite"`
+}
+
+type xmlTestSuite struct {
+	Name       string         `xml:"name,attr"`
+	Time       string         `xml:"time,attr"`
+	TestCases  []xmlTestCase  `xml:"testcase"`
+	TestSuites []xmlTestSuite `xml:"testsuite"`
+}
+
+type xmlTestCase struct {
+	Classname string      `xml:"classname,attr"`
+	Name      string      `xml:"name,attr"`
+	Failures  []xmlResult `xml:"failure"`
+	Errors    []xmlResult `xml:"error"`
+	Skipped   *xmlResult  `xml:"skipped"`
+}
+
+type xmlResult struct {
+	Message string `xml:"message,attr"`
+	Value   string `xml:",chardata"`
+}
+
+// readReport returns the testsuites of a report, its root is <testsuites> or <testsuite>. The nested testsuites
+// are flattened.
+func readReport(file string) ([]xmlTestSuite, error) {
+	btes, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var root xmlTestSuites
+	if err := xml.Unmarshal(btes, &root); err != nil {
+		return nil, fmt.Errorf("invalid JUnit report %s: %v", file, err)
+	}
+	var suites []xmlTestSuite
+	switch root.XMLName.Local {
+	case "testsuites":
+		suites = root.TestSuites
+	case "testsuite":
+		var s xmlTestSuite
+		if err := xml.Unmarshal(btes, &s); err != nil {
+			return nil, fmt.Errorf("invalid JUnit report %s: %v", file, err)
+		}
+		suites = []xmlTestSuite{s}
+	default:
+		return nil, fmt.Errorf("invalid JUnit report %s: the root element is <%s>, not <testsuites> or <testsuite>", file, root.XMLName.Local)
+	}
+	return flatten(suites), nil
+}
+
+func flatten(suites []xmlTestSuite) []xmlTestSuite {
+	var res []xmlTestSuite
+	for _, s := range suites {
+		if len(s.TestCases) > 0 || len(s.TestSuites) == 0 {
+			res = append(res, s)
+		}
+		res = append(res, flatten(s.TestSuites)...)
+	}
+	return res
+}
+
+// add counts the testcases of a testsuite, the counters of the report are not used: they are missing or wrong in the
+// reports of some tools
+func (r *Result) add(s xmlTestSuite, file string) {
+	suite := Suite{Name: s.Name, File: file, Total: len(s.TestCases)}
+	suite.Time, _ = strconv.ParseFloat(s.Time, 64)
+	for _, tc := range s.TestCases {
+		failed := TestCase{Suite: s.Name, Classname: tc.Classname, Name: tc.Name}
+		switch {
+		case len(tc.Errors) > 0:
+			suite.Errors++
+			failed.Status, failed.Message = "error", message(tc.Errors[0])
+		case len(tc.Failures) > 0:
+			suite.Failures++
+			failed.Status, failed.Message = "failure", message(tc.Failures[0])
+		case tc.Skipped != nil:
+			suite.Skipped++
+			continue
+		default:
+			continue
+		}
+		r.Failed = append(r.Failed, failed)
+	}
+	r.TestSuites = append(r.TestSuites, suite)
+	r.Total += suite.Total
+	r.Failures += suite.Failures
+	r.Errors += suite.Errors
+	r.Skipped += suite.Skipped
+	r.Time += suite.Time
+}
+
+// message returns the message of a failure, or the first line of its content without message
+func message(res xmlResult) string {
+	if res.Message != "" {
+		return res.Message
+	}
+	return strings.SplitN(strings.TrimSpace(res.Value), "\n", 2)[0]
+}
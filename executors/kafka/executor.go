@@ -112,7 +112,7 @@ func (Executor) Run(testCaseContext venom.TestCaseContext, l venom.Logger, step
 		}
 	} else if e.ClientType == "consumer" {
 		var err error
-		result.Messages, result.MessagesJSON, err = e.consumeMessages(l)
+		result.Messages, result.MessagesJSON, err = e.consumeMessages(testCaseContext.Context(), l)
 		if err != nil {
 			result.Err = err.Error()
 		}
@@ -178,7 +178,7 @@ func (e Executor) produceMessages(workdir string) error {
 	return sp.SendMessages(messages)
 }
 
-func (e Executor) consumeMessages(l venom.Logger) ([]Message, []interface{}, error) {
+func (e Executor) consumeMessages(ctx context.Context, l venom.Logger) ([]Message, []interface{}, error) {
 	if len(e.Topics) == 0 {
 		return nil, nil, fmt.Errorf("You must provide topics")
 	}
@@ -196,8 +196,8 @@ func (e Executor) consumeMessages(l venom.Logger) ([]Message, []interface{}, err
 		return nil, nil, fmt.Errorf("error instanciate consumer err:%s", err)
 	}
 
-	ctx := context.Background()
-	ctx, _ = context.WithTimeout(ctx, time.Duration(e.Timeout)*time.Millisecond)
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(e.Timeout)*time.Millisecond)
+	defer cancel()
 
 	// Track errors
 	go func() {
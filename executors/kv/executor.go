@@ -0,0 +1,252 @@
+package kv
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/ovh/venom"
+	"github.com/ovh/venom/executors"
+)
+
+// Name of executor
+const Name = "kv"
+
+// Backends supported by the executor
+const (
+	Consul = "consul"
+	Etcd   = "etcd"
+)
+
+// New returns a new Executor
+func New() venom.Executor {
+	return &Executor{}
+}
+
+// Executor gets, puts, deletes or watches a key on a Consul or etcd key/value store
+type Executor struct {
+	Backend     string `json:"backend" yaml:"backend"`
+	Address     string `json:"address" yaml:"address"`
+	Token       string `json:"token,omitempty" yaml:"token,omitempty"`
+	Operation   string `json:"operation" yaml:"operation"`
+	Key         string `json:"key" yaml:"key"`
+	Value       string `json:"value,omitempty" yaml:"value,omitempty"`
+	WaitTimeout int    `json:"wait_timeout,omitempty" yaml:"wait_timeout,omitempty" mapstructure:"wait_timeout"`
+	Interval    int    `json:"interval,omitempty" yaml:"interval,omitempty"`
+}
+
+// Result represents a step result
+type Result struct {
+	Executor    Executor    `json:"executor,omitempty" yaml:"executor,omitempty"`
+	Found       bool        `json:"found" yaml:"found"`
+	Value       string      `json:"value,omitempty" yaml:"value,omitempty"`
+	ValueJSON   interface{} `json:"valuejson,omitempty" yaml:"valuejson,omitempty"`
+	Reads       int         `json:"reads,omitempty" yaml:"reads,omitempty"`
+	Err         string      `json:"err,omitempty" yaml:"err,omitempty"`
+	TimeSeconds float64     `json:"timeseconds,omitempty" yaml:"timeseconds,omitempty"`
+	TimeHuman   string      `json:"timehuman,omitempty" yaml:"timehuman,omitempty"`
+}
+
+// ZeroValueResult return an empty implemtation of this executor result
+func (Executor) ZeroValueResult() venom.ExecutorResult {
+	r, _ := executors.Dump(Result{})
+	return r
+}
+
+// GetDefaultAssertions return default assertions for this executor
+func (Executor) GetDefaultAssertions() *venom.StepAssertions {
+	return &venom.StepAssertions{Assertions: []string{"result.err ShouldBeEmpty"}}
+}
+
+// Run execute TestStep
+func (Executor) Run(testCaseContext venom.TestCaseContext, l venom.Logger, step venom.TestStep, workdir string) (venom.ExecutorResult, error) {
+	var e Executor
+	if err := mapstructure.Decode(step, &e); err != nil {
+		return nil, err
+	}
+	if e.Backend != Consul && e.Backend != Etcd {
+		return nil, fmt.Errorf("backend must be %s or %s", Consul, Etcd)
+	}
+	if e.Key == "" {
+		return nil, fmt.Errorf("key is mandatory")
+	}
+	if e.Address == "" {
+		if e.Backend == Consul {
+			e.Address = "http://localhost:8500"
+		} else {
+			e.Address = "http://localhost:2379"
+		}
+	}
+	e.Address = strings.TrimSuffix(e.Address, "/")
+
+	ctx := testCaseContext.Context()
+	start := time.Now()
+	result := Result{Executor: e}
+	var err error
+	switch e.Operation {
+	case "get", "":
+		result.Found, result.Value, err = e.get(ctx)
+	case "put":
+		err = e.put(ctx)
+	case "delete":
+		err = e.delete(ctx)
+	case "watch":
+		err = e.watch(ctx, l, &result)
+	default:
+		return nil, fmt.Errorf("operation must be get, put, delete or watch")
+	}
+	if err != nil {
+		result.Err = err.Error()
+	}
+	if result.Value != "" {
+		var v interface{}
+		if err := json.Unmarshal([]byte(result.Value), &v); err == nil {
+			result.ValueJSON = v
+		}
+	}
+	result.Executor.Token = "****hidden****" // do not output token
+
+	elapsed := time.Since(start)
+	result.TimeSeconds = elapsed.Seconds()
+	result.TimeHuman = elapsed.String()
+
+	return executors.Dump(result)
+}
+
+// watch polls the key until its value is equal to e.Value, or ctx is done
+func (e Executor) watch(ctx context.Context, l venom.Logger, result *Result) error {
+	if e.WaitTimeout <= 0 {
+		e.WaitTimeout = 30
+	}
+	if e.Interval <= 0 {
+		e.Interval = 1
+	}
+	deadline := time.Now().Add(time.Duration(e.WaitTimeout) * time.Second)
+	for {
+		result.Reads++
+		found, value, err := e.get(ctx)
+		if err != nil {
+			return err
+		}
+		result.Found, result.Value = found, value
+		if found && value == e.Value {
+			return nil
+		}
+		l.Debugf("kv.watch> key %s value is %q, waiting for %q", e.Key, value, e.Value)
+		if time.Now().After(deadline) {
+			return fmt.Errorf("key %s has not reached expected value after %d second(s)", e.Key, e.WaitTimeout)
+		}
+		select {
+		case <-time.After(time.Duration(e.Interval) * time.Second):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (e Executor) get(ctx context.Context) (bool, string, error) {
+	if e.Backend == Consul {
+		code, body, err := e.do(ctx, http.MethodGet, "/v1/kv/"+strings.TrimPrefix(e.Key, "/")+"?raw", nil)
+		if err != nil {
+			return false, "", err
+		}
+		if code == http.StatusNotFound {
+			return false, "", nil
+		}
+		return true, string(body), nil
+	}
+
+	_, body, err := e.do(ctx, http.MethodPost, "/v3/kv/range", map[string]string{"key": b64(e.Key)})
+	if err != nil {
+		return false, "", err
+	}
+	var res struct {
+		Kvs []struct {
+			Value string `json:"value"`
+		} `json:"kvs"`
+	}
+	if err := json.Unmarshal(body, &res); err != nil {
+		return false, "", fmt.Errorf("unable to read etcd response: %v", err)
+	}
+	if len(res.Kvs) == 0 {
+		return false, "", nil
+	}
+	value, err := base64.StdEncoding.DecodeString(res.Kvs[0].Value)
+	if err != nil {
+		return false, "", err
+	}
+	return true, string(value), nil
+}
+
+func (e Executor) put(ctx context.Context) error {
+	if e.Backend == Consul {
+		_, _, err := e.do(ctx, http.MethodPut, "/v1/kv/"+strings.TrimPrefix(e.Key, "/"), []byte(e.Value))
+		return err
+	}
+	_, _, err := e.do(ctx, http.MethodPost, "/v3/kv/put", map[string]string{"key": b64(e.Key), "value": b64(e.Value)})
+	return err
+}
+
+func (e Executor) delete(ctx context.Context) error {
+	if e.Backend == Consul {
+		_, _, err := e.do(ctx, http.MethodDelete, "/v1/kv/"+strings.TrimPrefix(e.Key, "/"), nil)
+		return err
+	}
+	_, _, err := e.do(ctx, http.MethodPost, "/v3/kv/deleterange", map[string]string{"key": b64(e.Key)})
+	return err
+}
+
+// do calls the backend HTTP API. A 404 is not considered as an error.
+func (e Executor) do(ctx context.Context, method, path string, body interface{}) (int, []byte, error) {
+	var reader *bytes.Reader
+	switch b := body.(type) {
+	case nil:
+		reader = bytes.NewReader(nil)
+	case []byte:
+		reader = bytes.NewReader(b)
+	default:
+		btes, err := json.Marshal(b)
+		if err != nil {
+			return 0, nil, err
+		}
+		reader = bytes.NewReader(btes)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, e.Address+path, reader)
+	if err != nil {
+		return 0, nil, err
+	}
+	if e.Token != "" {
+		if e.Backend == Consul {
+			req.Header.Set("X-Consul-Token", e.Token)
+		} else {
+			req.Header.Set("Authorization", e.Token)
+		}
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	btes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, nil, err
+	}
+	if resp.StatusCode >= 400 && resp.StatusCode != http.StatusNotFound {
+		return resp.StatusCode, btes, fmt.Errorf("%s %s returns %d: %s", method, path, resp.StatusCode, btes)
+	}
+	return resp.StatusCode, btes, nil
+}
+
+func b64(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
@@ -0,0 +1,84 @@
+package kv
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/venom"
+)
+
+// fakeConsul is a Consul key/value store
+type fakeConsul struct {
+	mutex  sync.Mutex
+	values map[string]string
+}
+
+func (f *fakeConsul) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	switch r.Method {
+	case http.MethodGet:
+		v, ok := f.values[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(v))
+	case http.MethodPut:
+		f.values[r.URL.Path] = string(body)
+		w.Write([]byte("true"))
+	case http.MethodDelete:
+		delete(f.values, r.URL.Path)
+		w.Write([]byte("true"))
+	}
+}
+
+func TestRun(t *testing.T) {
+	consul := &fakeConsul{values: map[string]string{}}
+	srv := httptest.NewServer(consul)
+	defer srv.Close()
+	run := func(ctx context.Context, step venom.TestStep) venom.ExecutorResult {
+		step["backend"] = "consul"
+		step["address"] = srv.URL + "/"
+		step["key"] = "app/config"
+		tcc := &venom.CommonTestCaseContext{}
+		tcc.SetContext(ctx)
+		res, err := Executor{}.Run(tcc, logrus.New(), step, "")
+		require.NoError(t, err)
+		return res
+	}
+
+	res := run(context.Background(), venom.TestStep{"operation": "put", "value": `{"replicas": 3}`})
+	assert.Empty(t, res["result.err"])
+	assert.Equal(t, `{"replicas": 3}`, consul.values["/v1/kv/app/config"])
+
+	res = run(context.Background(), venom.TestStep{"operation": "get"})
+	assert.Equal(t, true, res["result.found"])
+	assert.Equal(t, 3.0, res["result.valuejson.replicas"])
+
+	res = run(context.Background(), venom.TestStep{"operation": "watch", "value": `{"replicas": 3}`})
+	assert.Empty(t, res["result.err"])
+	assert.Equal(t, 1, res["result.reads"])
+
+	run(context.Background(), venom.TestStep{"operation": "delete"})
+	res = run(context.Background(), venom.TestStep{"operation": "get"})
+	assert.Equal(t, false, res["result.found"])
+
+	// the watch stops with the context of the step
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	res = run(ctx, venom.TestStep{"operation": "watch", "value": "ready", "interval": 10})
+	assert.Equal(t, "context deadline exceeded", res["result.err"])
+	assert.True(t, time.Since(start) < 5*time.Second, "the watch should stop with its step")
+}
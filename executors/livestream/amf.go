@@ -0,0 +1,182 @@
+package livestream
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// AMF0 markers of the values of the RTMP commands and data messages
+const (
+	amfNumber      = 0x00
+	amfBoolean     = 0x01
+	amfString      = 0x02
+	amfObject      = 0x03
+	amfNull        = 0x05
+	amfUndefined   = 0x06
+	amfECMAArray   = 0x08
+	amfObjectEnd   = 0x09
+	amfStrictArray = 0x0a
+	amfDate        = 0x0b
+	amfLongString  = 0x0c
+)
+
+// amfProperty is a property of an AMF0 object, the properties of an object are encoded in their order
+type amfProperty struct {
+	key   string
+	value interface{}
+}
+
+// amfEncode encodes numbers, strings, booleans, nil and objects
+func amfEncode(values ...interface{}) []byte {
+	var b bytes.Buffer
+	for _, v := range values {
+		switch v := v.(type) {
+		case float64:
+			b.WriteByte(amfNumber)
+			binary.Write(&b, binary.BigEndian, math.Float64bits(v))
+		case int:
+			b.WriteByte(amfNumber)
+			binary.Write(&b, binary.BigEndian, math.Float64bits(float64(v)))
+		case bool:
+			b.WriteByte(amfBoolean)
+			if v {
+				b.WriteByte(1)
+			} else {
+				b.WriteByte(0)
+			}
+		case string:
+			b.WriteByte(amfString)
+			amfWriteString(&b, v)
+		case []amfProperty:
+			b.WriteByte(amfObject)
+			for _, p := range v {
+				amfWriteString(&b, p.key)
+				b.Write(amfEncode(p.value))
+			}
+			b.Write([]byte{0, 0, amfObjectEnd})
+		default:
+			b.WriteByte(amfNull)
+		}
+	}
+	return b.Bytes()
+}
+
+func amfWriteString(b *bytes.Buffer, s string) {
+	binary.Write(b, binary.BigEndian, uint16(len(s)))
+	b.WriteString(s)
+}
+
+// amfDecode decodes the values of a message: the numbers are float64, the objects and the ECMA arrays are
+// map[string]interface{} and the strict arrays []interface{}
+func amfDecode(data []byte) ([]interface{}, error) {
+	r := bytes.NewReader(data)
+	var values []interface{}
+	for r.Len() > 0 {
+		v, err := amfRead(r)
+		if err != nil {
+			return values, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+func amfRead(r *bytes.Reader) (interface{}, error) {
+	marker, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	switch marker {
+	case amfNumber:
+		var n uint64
+		if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(n), nil
+	case amfBoolean:
+		b, err := r.ReadByte()
+		return b != 0, err
+	case amfString:
+		return amfReadString(r, 2)
+	case amfLongString:
+		return amfReadString(r, 4)
+	case amfObject:
+		return amfReadProperties(r)
+	case amfECMAArray:
+		if _, err := r.Seek(4, io.SeekCurrent); err != nil {
+			return nil, err
+		}
+		return amfReadProperties(r)
+	case amfStrictArray:
+		var count uint32
+		if err := binary.Read(r, binary.BigEndian, &count); err != nil {
+			return nil, err
+		}
+		var values []interface{}
+		for i := uint32(0); i < count; i++ {
+			v, err := amfRead(r)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+		return values, nil
+	case amfDate:
+		var date struct {
+			Time uint64
+			Zone int16
+		}
+		if err := binary.Read(r, binary.BigEndian, &date); err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(date.Time), nil
+	case amfNull, amfUndefined:
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unsupported amf0 marker 0x%02x", marker)
+}
+
+func amfReadString(r *bytes.Reader, size int) (string, error) {
+	var length uint32
+	if size == 2 {
+		var l uint16
+		if err := binary.Read(r, binary.BigEndian, &l); err != nil {
+			return "", err
+		}
+		length = uint32(l)
+	} else if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+		return "", err
+	}
+	if int64(length) > int64(r.Len()) {
+		return "", io.ErrUnexpectedEOF
+	}
+	s := make([]byte, length)
+	_, err := io.ReadFull(r, s)
+	return string(s), err
+}
+
+func amfReadProperties(r *bytes.Reader) (map[string]interface{}, error) {
+	properties := map[string]interface{}{}
+	for {
+		key, err := amfReadString(r, 2)
+		if err != nil {
+			return nil, err
+		}
+		if key == "" {
+			marker, err := r.ReadByte()
+			if err != nil {
+				return nil, err
+			}
+			if marker == amfObjectEnd {
+				return properties, nil
+			}
+			r.UnreadByte()
+		}
+		if properties[key], err = amfRead(r); err != nil {
+			return nil, err
+		}
+	}
+}
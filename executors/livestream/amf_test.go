@@ -0,0 +1,89 @@
+package livestream
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAMF(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []interface{}
+		expected []interface{}
+	}{
+		{name: "number", values: []interface{}{1.5, 3}, expected: []interface{}{1.5, 3.0}},
+		{name: "boolean", values: []interface{}{true, false}, expected: []interface{}{true, false}},
+		{name: "string", values: []interface{}{"", "NetStream.Play.Start"}, expected: []interface{}{"", "NetStream.Play.Start"}},
+		{name: "null", values: []interface{}{nil}, expected: []interface{}{nil}},
+		{
+			name: "command with an object",
+			values: []interface{}{"connect", 1, []amfProperty{
+				{"app", "live"},
+				{"fpad", false},
+				{"capabilities", 15},
+				{"options", []amfProperty{{"audio", true}}},
+			}},
+			expected: []interface{}{"connect", 1.0, map[string]interface{}{
+				"app":          "live",
+				"fpad":         false,
+				"capabilities": 15.0,
+				"options":      map[string]interface{}{"audio": true},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := amfDecode(amfEncode(tt.values...))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, values)
+		})
+	}
+}
+
+func TestAMFDecode(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected []interface{}
+	}{
+		{
+			name:     "ecma array",
+			data:     "\x08\x00\x00\x00\x02\x00\x05width\x00\x40\x9e\x00\x00\x00\x00\x00\x00\x00\x06height\x00\x40\x90\xe0\x00\x00\x00\x00\x00\x00\x00\x09",
+			expected: []interface{}{map[string]interface{}{"width": 1920.0, "height": 1080.0}},
+		},
+		{
+			name:     "strict array",
+			data:     "\x0a\x00\x00\x00\x02\x02\x00\x01a\x05",
+			expected: []interface{}{[]interface{}{"a", nil}},
+		},
+		{
+			name:     "long string and undefined",
+			data:     "\x0c\x00\x00\x00\x03abc\x06",
+			expected: []interface{}{"abc", nil},
+		},
+		{
+			name:     "date",
+			data:     "\x0b\x42\x70\x00\x00\x00\x00\x00\x00\x00\x00",
+			expected: []interface{}{float64(1 << 40)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := amfDecode([]byte(tt.data))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, values)
+		})
+	}
+
+	for data, err := range map[string]string{
+		"\x11":              "unsupported amf0 marker 0x11",
+		"\x02\x00\x05ab":    "unexpected EOF",
+		"\x00\x40\x9e":      "unexpected EOF",
+		"\x03\x00\x01a\x01": "EOF",
+	} {
+		_, e := amfDecode([]byte(data))
+		assert.EqualError(t, e, err, "%q", data)
+	}
+}
@@ -0,0 +1,218 @@
+package livestream
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/ovh/venom"
+	"github.com/ovh/venom/executors"
+)
+
+// Name of executor
+const Name = "livestream"
+
+// defaultFrameTimeout is the time to wait for the first frame, in seconds
+const defaultFrameTimeout = 10
+
+// New returns a new Executor
+func New() venom.Executor {
+	return &Executor{}
+}
+
+// Executor connects to a RTSP or RTMP endpoint, plays its stream and waits for its first frames
+type Executor struct {
+	URL string `json:"url" yaml:"url"`
+	// FrameTimeout is the time to connect and receive the first frame, in seconds
+	FrameTimeout int `json:"frame_timeout,omitempty" yaml:"frame_timeout,omitempty" mapstructure:"frame_timeout"`
+	// Duration is the time the stream is received after its first frame, in seconds, to count its frames and measure
+	// its bitrate
+	Duration        int  `json:"duration,omitempty" yaml:"duration,omitempty"`
+	IgnoreVerifySSL bool `json:"ignore_verify_ssl,omitempty" yaml:"ignore_verify_ssl,omitempty" mapstructure:"ignore_verify_ssl"`
+}
+
+// Result represents a step result
+type Result struct {
+	Executor       Executor `json:"executor,omitempty" yaml:"executor,omitempty"`
+	Protocol       string   `json:"protocol" yaml:"protocol"`
+	Connected      bool     `json:"connected" yaml:"connected"`
+	ConnectTime    float64  `json:"connecttime" yaml:"connecttime"`
+	Status         string   `json:"status" yaml:"status"`
+	Server         string   `json:"server,omitempty" yaml:"server,omitempty"`
+	VideoCodec     string   `json:"videocodec" yaml:"videocodec"`
+	AudioCodec     string   `json:"audiocodec" yaml:"audiocodec"`
+	Width          int      `json:"width,omitempty" yaml:"width,omitempty"`
+	Height         int      `json:"height,omitempty" yaml:"height,omitempty"`
+	Receiving      bool     `json:"receiving" yaml:"receiving"`
+	FirstFrameTime float64  `json:"firstframetime" yaml:"firstframetime"`
+	VideoFrames    int      `json:"videoframes" yaml:"videoframes"`
+	AudioFrames    int      `json:"audioframes" yaml:"audioframes"`
+	Bytes          int64    `json:"bytes" yaml:"bytes"`
+	BitRate        int64    `json:"bitrate" yaml:"bitrate"`
+	Err            string   `json:"err,omitempty" yaml:"err,omitempty"`
+	TimeSeconds    float64  `json:"timeseconds,omitempty" yaml:"timeseconds,omitempty"`
+	TimeHuman      string   `json:"timehuman,omitempty" yaml:"timehuman,omitempty"`
+}
+
+// ZeroValueResult return an empty implemtation of this executor result
+func (Executor) ZeroValueResult() venom.ExecutorResult {
+	r, _ := executors.Dump(Result{})
+	return r
+}
+
+// GetDefaultAssertions return default assertions for this executor
+func (Executor) GetDefaultAssertions() *venom.StepAssertions {
+	return &venom.StepAssertions{Assertions: []string{"result.receiving ShouldBeTrue"}}
+}
+
+// Run execute TestStep
+func (Executor) Run(testCaseContext venom.TestCaseContext, l venom.Logger, step venom.TestStep, workdir string) (venom.ExecutorResult, error) {
+	var e Executor
+	if err := mapstructure.Decode(step, &e); err != nil {
+		return nil, err
+	}
+	if e.URL == "" {
+		return nil, fmt.Errorf("url is mandatory")
+	}
+	u, err := url.Parse(e.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url %q: %v", e.URL, err)
+	}
+	var play func(*session) error
+	var port string
+	switch u.Scheme {
+	case "rtsp":
+		play, port = playRTSP, "554"
+	case "rtsps":
+		play, port = playRTSP, "322"
+	case "rtmp":
+		play, port = playRTMP, "1935"
+	case "rtmps":
+		play, port = playRTMP, "443"
+	default:
+		return nil, fmt.Errorf("invalid url %q, it must be a rtsp, rtsps, rtmp or rtmps url", e.URL)
+	}
+	if e.FrameTimeout <= 0 {
+		e.FrameTimeout = defaultFrameTimeout
+	}
+	if e.Duration < 0 {
+		e.Duration = 0
+	}
+	address := u.Host
+	if u.Port() == "" {
+		address = net.JoinHostPort(u.Hostname(), port)
+	}
+
+	start := time.Now()
+	result := Result{Executor: e, Protocol: u.Scheme}
+	s := &session{
+		url:        u,
+		result:     &result,
+		l:          l,
+		frameLimit: start.Add(time.Duration(e.FrameTimeout) * time.Second),
+		duration:   time.Duration(e.Duration) * time.Second,
+	}
+	ctx, cancel := context.WithDeadline(testCaseContext.Context(), s.frameLimit)
+	defer cancel()
+	conn, err := executors.DialContext(ctx, "tcp", address)
+	if err == nil && (u.Scheme == "rtsps" || u.Scheme == "rtmps") {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: u.Hostname(), InsecureSkipVerify: e.IgnoreVerifySSL})
+		tlsConn.SetDeadline(s.frameLimit)
+		if err = tlsConn.Handshake(); err != nil {
+			conn.Close()
+		}
+		conn = tlsConn
+	}
+	if err != nil {
+		result.Err = fmt.Sprintf("unable to connect to %s: %v", address, err)
+	} else {
+		result.Connected = true
+		result.ConnectTime = time.Since(start).Seconds()
+		s.conn = conn
+		conn.SetDeadline(s.frameLimit)
+		// the connection is closed when the step is stopped
+		stop := make(chan struct{})
+		go func() {
+			select {
+			case <-testCaseContext.Context().Done():
+				conn.Close()
+			case <-stop:
+			}
+		}()
+		err = play(s)
+		close(stop)
+		conn.Close()
+		if err != nil {
+			result.Err = err.Error()
+		}
+		if result.Receiving && e.Duration > 0 {
+			if elapsed := time.Since(s.firstFrame).Seconds(); elapsed > 0 {
+				result.BitRate = int64(float64(result.Bytes*8) / elapsed)
+			}
+		}
+	}
+	// the credentials of the url aren't logged
+	target := *u
+	target.User = nil
+	if result.Err != "" {
+		l.Warnf("%s: %s", target.String(), result.Err)
+	}
+	l.Debugf("%s: receiving:%t, %d video and %d audio frames", target.String(), result.Receiving, result.VideoFrames, result.AudioFrames)
+
+	elapsed := time.Since(start)
+	result.TimeSeconds = elapsed.Seconds()
+	result.TimeHuman = elapsed.String()
+	return executors.Dump(result)
+}
+
+// session is the connection to the endpoint playing its stream
+type session struct {
+	url    *url.URL
+	conn   net.Conn
+	result *Result
+	l      venom.Logger
+	// frameLimit is the time limit to receive the first frame, the stream is then received for duration
+	frameLimit time.Time
+	duration   time.Duration
+	playStart  time.Time
+	firstFrame time.Time
+}
+
+// playing starts the wait of the first frame, the play request is sent
+func (s *session) playing() {
+	s.playStart = time.Now()
+}
+
+// frame counts a frame received, and tells if the stream has been received long enough
+func (s *session) frame(video bool, size int) bool {
+	if !s.result.Receiving {
+		s.result.Receiving = true
+		s.firstFrame = time.Now()
+		s.result.FirstFrameTime = s.firstFrame.Sub(s.playStart).Seconds()
+		s.conn.SetDeadline(s.firstFrame.Add(s.duration))
+	}
+	if video {
+		s.result.VideoFrames++
+	} else {
+		s.result.AudioFrames++
+	}
+	s.result.Bytes += int64(size)
+	return time.Since(s.firstFrame) >= s.duration
+}
+
+// receiveError returns the error of a read while waiting for the frames: the end of the duration after the first
+// frame is not an error, a timeout before it is reported as such
+func (s *session) receiveError(err error) error {
+	if ne, ok := err.(net.Error); ok && ne.Timeout() {
+		if s.result.Receiving {
+			return nil
+		}
+		return fmt.Errorf("no frame received in %ds", s.result.Executor.FrameTimeout)
+	}
+	return err
+}
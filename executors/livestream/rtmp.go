@@ -0,0 +1,509 @@
+package livestream
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// RTMP message types
+const (
+	rtmpSetChunkSize     = 1
+	rtmpAbort            = 2
+	rtmpAcknowledgement  = 3
+	rtmpUserControl      = 4
+	rtmpWindowAckSize    = 5
+	rtmpSetPeerBandwidth = 6
+	rtmpAudio            = 8
+	rtmpVideo            = 9
+	rtmpData             = 18
+	rtmpCommand          = 20
+	rtmpAggregate        = 22
+)
+
+// RTMP user control events
+const (
+	rtmpSetBufferLength = 3
+	rtmpPingRequest     = 6
+	rtmpPingResponse    = 7
+)
+
+const (
+	rtmpHandshakeSize = 1536
+	// rtmpWindow is the window acknowledgement size sent to the server
+	rtmpWindow = 2500000
+)
+
+// rtmpVideoCodecs are the codecs of the video codec ids of the FLV tags
+var rtmpVideoCodecs = map[byte]string{
+	2:  "h263",
+	3:  "screen",
+	4:  "vp6",
+	5:  "vp6",
+	6:  "screen",
+	7:  "h264",
+	12: "h265",
+}
+
+// rtmpAudioCodecs are the codecs of the sound formats of the FLV tags
+var rtmpAudioCodecs = map[byte]string{
+	0:  "pcm",
+	1:  "adpcm",
+	2:  "mp3",
+	3:  "pcm",
+	4:  "nellymoser",
+	5:  "nellymoser",
+	6:  "nellymoser",
+	7:  "pcma",
+	8:  "pcmu",
+	10: "aac",
+	11: "speex",
+	13: "opus",
+	14: "mp3",
+}
+
+// rtmpFourCCs are the codecs of the fourcc of the enhanced RTMP tags
+var rtmpFourCCs = map[string]string{
+	"avc1": "h264",
+	"hvc1": "h265",
+	"vp08": "vp8",
+	"vp09": "vp9",
+	"av01": "av1",
+	"mp4a": "aac",
+	"Opus": "opus",
+	"fLaC": "flac",
+	".mp3": "mp3",
+	"ac-3": "ac3",
+	"ec-3": "eac3",
+}
+
+type rtmpMessage struct {
+	typ      byte
+	stream   uint32
+	time     uint32
+	delta    uint32
+	length   uint32
+	extended bool
+	payload  []byte
+}
+
+type rtmpConn struct {
+	*session
+	r        *bufio.Reader
+	inChunk  uint32
+	outChunk uint32
+	chunks   map[uint32]*rtmpMessage
+	window   uint32
+	received uint32
+	acked    uint32
+	tx       float64
+}
+
+// countedReader counts the bytes read, acknowledged to the server
+type countedReader struct {
+	r io.Reader
+	c *rtmpConn
+}
+
+func (r countedReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.c.received += uint32(n)
+	return n, err
+}
+
+// playRTMP connects to the application of the stream, creates a stream and plays it. The stream name is the last
+// segment of the path of the url with its query, the application the segments before it.
+func playRTMP(s *session) error {
+	path := strings.Trim(s.url.Path, "/")
+	i := strings.LastIndex(path, "/")
+	if i < 0 {
+		return fmt.Errorf("the url has no application or no stream name")
+	}
+	app, name := path[:i], path[i+1:]
+	if s.url.RawQuery != "" {
+		name += "?" + s.url.RawQuery
+	}
+	tcURL := fmt.Sprintf("%s://%s/%s", s.url.Scheme, s.url.Host, app)
+
+	c := &rtmpConn{session: s, inChunk: 128, outChunk: 128, chunks: map[uint32]*rtmpMessage{}}
+	c.r = bufio.NewReader(countedReader{r: s.conn, c: c})
+	if err := c.handshake(); err != nil {
+		return fmt.Errorf("handshake: %v", err)
+	}
+	if err := c.write(2, rtmpSetChunkSize, 0, uint32be(4096)); err != nil {
+		return err
+	}
+	c.outChunk = 4096
+
+	connect := []amfProperty{
+		{"app", app},
+		{"flashVer", "LNX 9,0,124,2"},
+		{"tcUrl", tcURL},
+		{"fpad", false},
+		{"capabilities", 15},
+		{"audioCodecs", 3575},
+		{"videoCodecs", 252},
+		{"videoFunction", 1},
+	}
+	if _, err := c.call("connect", connect); err != nil {
+		return err
+	}
+	values, err := c.call("createStream", nil)
+	if err != nil {
+		return err
+	}
+	var stream uint32
+	if len(values) > 3 {
+		if id, ok := values[3].(float64); ok {
+			stream = uint32(id)
+		}
+	}
+
+	control := make([]byte, 10)
+	binary.BigEndian.PutUint16(control, rtmpSetBufferLength)
+	binary.BigEndian.PutUint32(control[2:], stream)
+	binary.BigEndian.PutUint32(control[6:], 3000)
+	if err := c.write(2, rtmpUserControl, 0, control); err != nil {
+		return err
+	}
+	s.playing()
+	if err := c.write(8, rtmpCommand, stream, amfEncode("play", 0, nil, name, -2)); err != nil {
+		return err
+	}
+	for {
+		m, err := c.read()
+		if err != nil {
+			return s.receiveError(err)
+		}
+		done, err := c.handle(m)
+		if err != nil || done {
+			return err
+		}
+	}
+}
+
+// handshake is the simple handshake: C0 and C1 are sent, S0, S1 and S2 are read and S1 is echoed in C2
+func (c *rtmpConn) handshake() error {
+	c1 := make([]byte, 1+rtmpHandshakeSize)
+	c1[0] = 3
+	rand.Read(c1[9:])
+	if _, err := c.conn.Write(c1); err != nil {
+		return err
+	}
+	s := make([]byte, 1+2*rtmpHandshakeSize)
+	if _, err := io.ReadFull(c.r, s); err != nil {
+		return err
+	}
+	if s[0] != 3 {
+		return fmt.Errorf("unsupported rtmp version %d", s[0])
+	}
+	_, err := c.conn.Write(s[1 : 1+rtmpHandshakeSize])
+	return err
+}
+
+// call sends a command on the stream 0 and waits for its result, the other messages received meanwhile are handled
+func (c *rtmpConn) call(command string, object []amfProperty) ([]interface{}, error) {
+	c.tx++
+	tx := c.tx
+	args := amfEncode(command, tx, nil)
+	if object != nil {
+		args = amfEncode(command, tx, object)
+	}
+	if err := c.write(3, rtmpCommand, 0, args); err != nil {
+		return nil, err
+	}
+	for {
+		m, err := c.read()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", command, err)
+		}
+		if m.typ != rtmpCommand {
+			if _, err := c.handle(m); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		values, err := amfDecode(m.payload)
+		if err != nil || len(values) < 2 || values[1] != tx {
+			continue
+		}
+		// the properties of the server and the information of the result
+		for _, v := range values[2:] {
+			c.status(v)
+		}
+		switch values[0] {
+		case "_result":
+			return values, nil
+		case "_error":
+			return nil, fmt.Errorf("%s: %s", command, rtmpDescription(values))
+		}
+	}
+}
+
+// handle handles a message, and tells if the stream has been received long enough
+func (c *rtmpConn) handle(m *rtmpMessage) (bool, error) {
+	switch m.typ {
+	case rtmpSetChunkSize:
+		if len(m.payload) >= 4 {
+			c.inChunk = binary.BigEndian.Uint32(m.payload) & 0x7fffffff
+		}
+	case rtmpAbort:
+		if len(m.payload) >= 4 {
+			delete(c.chunks, binary.BigEndian.Uint32(m.payload))
+		}
+	case rtmpWindowAckSize:
+		if len(m.payload) >= 4 {
+			c.window = binary.BigEndian.Uint32(m.payload)
+		}
+	case rtmpSetPeerBandwidth:
+		return false, c.write(2, rtmpWindowAckSize, 0, uint32be(rtmpWindow))
+	case rtmpUserControl:
+		if len(m.payload) >= 6 && binary.BigEndian.Uint16(m.payload) == rtmpPingRequest {
+			response := append([]byte{0, rtmpPingResponse}, m.payload[2:6]...)
+			return false, c.write(2, rtmpUserControl, 0, response)
+		}
+	case rtmpCommand:
+		values, err := amfDecode(m.payload)
+		if err != nil || len(values) < 4 || values[0] != "onStatus" {
+			return false, nil
+		}
+		if level := c.status(values[3]); level == "error" {
+			return false, fmt.Errorf("play: %s", rtmpDescription(values))
+		}
+	case rtmpData:
+		values, _ := amfDecode(m.payload)
+		if len(values) > 0 && values[0] == "@setDataFrame" {
+			values = values[1:]
+		}
+		if len(values) > 1 && values[0] == "onMetaData" {
+			c.metadata(values[1])
+		}
+	case rtmpAudio, rtmpVideo:
+		return c.media(m.typ == rtmpVideo, m.payload), nil
+	case rtmpAggregate:
+		// the aggregated messages are FLV tags: a header of 11 bytes, the payload and the size of the tag
+		for p := m.payload; len(p) >= 11; {
+			size := int(p[1])<<16 | int(p[2])<<8 | int(p[3])
+			if len(p) < 11+size {
+				break
+			}
+			if (p[0] == rtmpAudio || p[0] == rtmpVideo) && c.media(p[0] == rtmpVideo, p[11:11+size]) {
+				return true, nil
+			}
+			if len(p) < 15+size {
+				break
+			}
+			p = p[15+size:]
+		}
+	}
+	return false, nil
+}
+
+// media counts an audio or video tag, the sequence headers and the command frames aren't frames
+func (c *rtmpConn) media(video bool, tag []byte) bool {
+	if len(tag) < 2 {
+		return false
+	}
+	var codec string
+	frame := true
+	switch {
+	case video && tag[0]&0x80 != 0 && len(tag) >= 5:
+		// enhanced RTMP: the packet type and the fourcc of the codec
+		codec = rtmpFourCCs[string(tag[1:5])]
+		packet := tag[0] & 0x0f
+		frame = packet == 1 || packet == 3
+	case video:
+		codec = rtmpVideoCodecs[tag[0]&0x0f]
+		frame = tag[0]>>4 != 5 && !((codec == "h264" || codec == "h265") && tag[1] != 1)
+	case tag[0]>>4 == 9 && len(tag) >= 5:
+		codec = rtmpFourCCs[string(tag[1:5])]
+		frame = tag[0]&0x0f == 1
+	default:
+		codec = rtmpAudioCodecs[tag[0]>>4]
+		frame = codec != "aac" || tag[1] == 1
+	}
+	if video && codec != "" {
+		c.result.VideoCodec = codec
+	} else if !video && codec != "" {
+		c.result.AudioCodec = codec
+	}
+	if !frame {
+		return false
+	}
+	return c.frame(video, len(tag))
+}
+
+// status sets the status of the result with the code of an info object, and returns its level
+func (c *rtmpConn) status(info interface{}) string {
+	properties, ok := info.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	if code, ok := properties["code"].(string); ok {
+		c.result.Status = code
+	}
+	if server, ok := properties["fmsVer"].(string); ok && server != "" {
+		c.result.Server = server
+	}
+	level, _ := properties["level"].(string)
+	return level
+}
+
+func (c *rtmpConn) metadata(data interface{}) {
+	properties, ok := data.(map[string]interface{})
+	if !ok {
+		return
+	}
+	if width, ok := properties["width"].(float64); ok {
+		c.result.Width = int(width)
+	}
+	if height, ok := properties["height"].(float64); ok {
+		c.result.Height = int(height)
+	}
+}
+
+// rtmpDescription returns the code and the description of the info object of a command
+func rtmpDescription(values []interface{}) string {
+	if len(values) > 3 {
+		if properties, ok := values[3].(map[string]interface{}); ok {
+			code, _ := properties["code"].(string)
+			description, _ := properties["description"].(string)
+			return strings.TrimSpace(code + " " + description)
+		}
+	}
+	return fmt.Sprintf("%v", values)
+}
+
+// write sends a message in chunks, with a header of type 0 on its first chunk and of type 3 on the next ones
+func (c *rtmpConn) write(csid byte, typ byte, stream uint32, payload []byte) error {
+	var b bytes.Buffer
+	b.WriteByte(csid)
+	b.Write([]byte{0, 0, 0})
+	b.Write(uint32be(uint32(len(payload)))[1:])
+	b.WriteByte(typ)
+	binary.Write(&b, binary.LittleEndian, stream)
+	for i := 0; i < len(payload); i += int(c.outChunk) {
+		if i > 0 {
+			b.WriteByte(0xc0 | csid)
+		}
+		end := i + int(c.outChunk)
+		if end > len(payload) {
+			end = len(payload)
+		}
+		b.Write(payload[i:end])
+	}
+	_, err := c.conn.Write(b.Bytes())
+	return err
+}
+
+// read reads the chunks until a message is complete, the received bytes are acknowledged
+func (c *rtmpConn) read() (*rtmpMessage, error) {
+	for {
+		m, err := c.readChunk()
+		if err != nil {
+			return nil, err
+		}
+		if c.window > 0 && c.received-c.acked >= c.window/2 {
+			c.acked = c.received
+			if err := c.write(2, rtmpAcknowledgement, 0, uint32be(c.received)); err != nil {
+				return nil, err
+			}
+		}
+		if m != nil {
+			return m, nil
+		}
+	}
+}
+
+// readChunk reads a chunk, and returns its message if it is the last chunk of it
+func (c *rtmpConn) readChunk() (*rtmpMessage, error) {
+	b, err := c.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	format := b >> 6
+	csid := uint32(b & 0x3f)
+	switch csid {
+	case 0:
+		b, err := c.r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		csid = 64 + uint32(b)
+	case 1:
+		var id [2]byte
+		if _, err := io.ReadFull(c.r, id[:]); err != nil {
+			return nil, err
+		}
+		csid = 64 + uint32(id[0]) + uint32(id[1])<<8
+	}
+
+	m, ok := c.chunks[csid]
+	if !ok {
+		if format != 0 {
+			return nil, fmt.Errorf("invalid chunk of type %d on a new chunk stream %d", format, csid)
+		}
+		m = &rtmpMessage{}
+		c.chunks[csid] = m
+	}
+	header := make([]byte, []int{11, 7, 3, 0}[format])
+	if _, err := io.ReadFull(c.r, header); err != nil {
+		return nil, err
+	}
+	var timestamp uint32
+	if format < 3 {
+		timestamp = uint32(header[0])<<16 | uint32(header[1])<<8 | uint32(header[2])
+		m.extended = timestamp == 0xffffff
+	}
+	if format < 2 {
+		m.length = uint32(header[3])<<16 | uint32(header[4])<<8 | uint32(header[5])
+		m.typ = header[6]
+	}
+	if format == 0 {
+		m.stream = binary.LittleEndian.Uint32(header[7:])
+	}
+	if m.extended {
+		var ext [4]byte
+		if _, err := io.ReadFull(c.r, ext[:]); err != nil {
+			return nil, err
+		}
+		if format < 3 {
+			timestamp = binary.BigEndian.Uint32(ext[:])
+		}
+	}
+	switch {
+	case format == 0:
+		m.time = timestamp
+	case format < 3:
+		m.delta = timestamp
+		m.time += timestamp
+	case len(m.payload) == 0:
+		m.time += m.delta
+	}
+
+	size := m.length - uint32(len(m.payload))
+	if size > c.inChunk {
+		size = c.inChunk
+	}
+	chunk := make([]byte, size)
+	if _, err := io.ReadFull(c.r, chunk); err != nil {
+		return nil, err
+	}
+	m.payload = append(m.payload, chunk...)
+	if uint32(len(m.payload)) < m.length {
+		return nil, nil
+	}
+	message := *m
+	m.payload = nil
+	return &message, nil
+}
+
+func uint32be(n uint32) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, n)
+	return b
+}
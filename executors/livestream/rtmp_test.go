@@ -0,0 +1,165 @@
+package livestream
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rtmpServer answers the handshake of a client with the version, then its connect, createStream and play commands
+// with the status, and sends the metadata, the sequence header and a frame of a h264 stream
+func rtmpServer(version byte, playStatus string, commands *[]string) func(conn net.Conn) error {
+	return func(conn net.Conn) error {
+		c := &rtmpConn{session: &session{conn: conn, result: &Result{}}, inChunk: 128, outChunk: 128, chunks: map[uint32]*rtmpMessage{}}
+		c.r = bufio.NewReader(countedReader{r: conn, c: c})
+
+		c0c1 := make([]byte, 1+rtmpHandshakeSize)
+		if _, err := io.ReadFull(c.r, c0c1); err != nil {
+			return err
+		}
+		if c0c1[0] != 3 {
+			return fmt.Errorf("unexpected version %d", c0c1[0])
+		}
+		s1 := bytes.Repeat([]byte{0x5a}, rtmpHandshakeSize)
+		handshake := append(append([]byte{version}, s1...), c0c1[1:]...)
+		if _, err := conn.Write(handshake); err != nil || version != 3 {
+			return err
+		}
+		c2 := make([]byte, rtmpHandshakeSize)
+		if _, err := io.ReadFull(c.r, c2); err != nil {
+			return err
+		}
+		if !bytes.Equal(c2, s1) {
+			return fmt.Errorf("C2 is not S1")
+		}
+
+		for {
+			m, err := c.read()
+			if err == io.EOF || err == io.ErrClosedPipe {
+				return nil
+			} else if err != nil {
+				return err
+			}
+			if m.typ != rtmpCommand {
+				c.handle(m)
+				continue
+			}
+			values, err := amfDecode(m.payload)
+			if err != nil {
+				return err
+			}
+			command, _ := values[0].(string)
+			*commands = append(*commands, command)
+			switch command {
+			case "connect":
+				properties := values[2].(map[string]interface{})
+				if properties["app"] != "live" || properties["tcUrl"] != "rtmp://media.example.com/live" {
+					return fmt.Errorf("unexpected connect %v", properties)
+				}
+				c.write(2, rtmpWindowAckSize, 0, uint32be(rtmpWindow))
+				c.write(3, rtmpCommand, 0, amfEncode("_result", values[1], []amfProperty{{"fmsVer", "FMS/3,5,7,7009"}},
+					[]amfProperty{{"level", "status"}, {"code", "NetConnection.Connect.Success"}}))
+			case "createStream":
+				c.write(3, rtmpCommand, 0, amfEncode("_result", values[1], nil, 1))
+			case "play":
+				if m.stream != 1 || values[3] != "camera?token=abc" {
+					return fmt.Errorf("unexpected play of %v on the stream %d", values[3], m.stream)
+				}
+				level := "status"
+				if !strings.HasPrefix(playStatus, "NetStream.Play.Start") {
+					level = "error"
+				}
+				c.write(5, rtmpCommand, 1, amfEncode("onStatus", 0, nil, []amfProperty{{"level", level}, {"code", playStatus}}))
+				if level == "error" {
+					continue
+				}
+				c.write(5, rtmpData, 1, amfEncode("onMetaData", []amfProperty{{"width", 1280}, {"height", 720}}))
+				c.write(6, rtmpVideo, 1, []byte{0x17, 0, 0, 0, 0, 1, 0x64, 0, 0x1f})
+				c.write(6, rtmpVideo, 1, append([]byte{0x17, 1, 0, 0, 0}, make([]byte, 200)...))
+			}
+		}
+	}
+}
+
+func TestPlayRTMP(t *testing.T) {
+	tests := []struct {
+		name     string
+		version  byte
+		status   string
+		err      string
+		commands []string
+	}{
+		{name: "play", version: 3, status: "NetStream.Play.Start", commands: []string{"connect", "createStream", "play"}},
+		{
+			name:     "stream not found",
+			version:  3,
+			status:   "NetStream.Play.StreamNotFound",
+			err:      "play: NetStream.Play.StreamNotFound",
+			commands: []string{"connect", "createStream", "play"},
+		},
+		{name: "unsupported version", version: 6, err: "handshake: unsupported rtmp version 6"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var commands []string
+			s, stop := pipeSession(t, "rtmp://media.example.com/live/camera?token=abc", rtmpServer(tt.version, tt.status, &commands))
+			err := playRTMP(s)
+			require.NoError(t, stop())
+			assert.Equal(t, tt.commands, commands)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				assert.False(t, s.result.Receiving)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, s.result.Receiving)
+			assert.Equal(t, "NetStream.Play.Start", s.result.Status)
+			assert.Equal(t, "FMS/3,5,7,7009", s.result.Server)
+			assert.Equal(t, "h264", s.result.VideoCodec)
+			assert.Equal(t, 1280, s.result.Width)
+			assert.Equal(t, 720, s.result.Height)
+			// the sequence header is not a frame
+			assert.Equal(t, 1, s.result.VideoFrames)
+			assert.Equal(t, int64(205), s.result.Bytes)
+		})
+	}
+}
+
+func TestRTMPMedia(t *testing.T) {
+	tests := []struct {
+		name  string
+		video bool
+		tag   []byte
+		codec string
+		frame bool
+	}{
+		{name: "aac sequence header", tag: []byte{0xaf, 0}, codec: "aac"},
+		{name: "aac frame", tag: []byte{0xaf, 1, 0x21}, codec: "aac", frame: true},
+		{name: "mp3 frame", tag: []byte{0x2f, 0xff}, codec: "mp3", frame: true},
+		{name: "video command frame", video: true, tag: []byte{0x57, 0}, codec: "h264"},
+		{name: "enhanced hevc frame", video: true, tag: []byte{0x91, 'h', 'v', 'c', '1', 0}, codec: "h265", frame: true},
+		{name: "enhanced av1 sequence start", video: true, tag: []byte{0x90, 'a', 'v', '0', '1'}, codec: "av1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _ := net.Pipe()
+			defer client.Close()
+			c := &rtmpConn{session: &session{conn: client, result: &Result{}}}
+			c.media(tt.video, tt.tag)
+			codec := c.result.AudioCodec
+			frames := c.result.AudioFrames
+			if tt.video {
+				codec, frames = c.result.VideoCodec, c.result.VideoFrames
+			}
+			assert.Equal(t, tt.codec, codec)
+			assert.Equal(t, tt.frame, frames == 1)
+		})
+	}
+}
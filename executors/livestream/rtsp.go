@@ -0,0 +1,374 @@
+package livestream
+
+import (
+	"bufio"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/textproto"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rtspStaticPayloads are the codecs of the static RTP payload types, the SDP has no rtpmap for them
+var rtspStaticPayloads = map[string]string{
+	"0":  "pcmu",
+	"8":  "pcma",
+	"14": "mp3",
+	"26": "jpeg",
+	"32": "mpeg2",
+}
+
+// rtspCodecs are the codecs of the RTP encoding names, the other ones are the name in lower case
+var rtspCodecs = map[string]string{
+	"mpeg4-generic": "aac",
+	"mp4a-latm":     "aac",
+	"mpa":           "mp3",
+	"mp4v-es":       "mpeg4",
+}
+
+var (
+	rtspAuthParam   = regexp.MustCompile(`(\w+)=(?:"([^"]*)"|([^,\s]*))`)
+	rtspInterleaved = regexp.MustCompile(`interleaved=(\d+)`)
+)
+
+type rtspResponse struct {
+	code   int
+	status string
+	header textproto.MIMEHeader
+	body   []byte
+}
+
+// rtspMedia is a media of the SDP of the stream
+type rtspMedia struct {
+	video   bool
+	codec   string
+	control string
+}
+
+type rtspConn struct {
+	*session
+	r       *bufio.Reader
+	url     string
+	cseq    int
+	auth    string
+	params  map[string]string
+	sess    string
+	channel map[int]bool
+}
+
+// playRTSP describes the stream, sets up its first video and audio medias interleaved on the connection and plays it
+func playRTSP(s *session) error {
+	u := *s.url
+	u.User = nil
+	c := &rtspConn{session: s, r: bufio.NewReader(s.conn), url: u.String(), channel: map[int]bool{}}
+
+	res, err := c.request("DESCRIBE", c.url, map[string]string{"Accept": "application/sdp"})
+	if err != nil {
+		return err
+	}
+	base := res.header.Get("Content-Base")
+	if base == "" {
+		base = res.header.Get("Content-Location")
+	}
+	if base == "" {
+		base = c.url
+	}
+	medias, control := parseSDP(string(res.body))
+	if control != "" && control != "*" {
+		base = rtspControlURL(base, control)
+	}
+
+	var video, audio *rtspMedia
+	for i := range medias {
+		if medias[i].video && video == nil {
+			video = &medias[i]
+			s.result.VideoCodec = video.codec
+		} else if !medias[i].video && audio == nil {
+			audio = &medias[i]
+			s.result.AudioCodec = audio.codec
+		}
+	}
+	if video == nil && audio == nil {
+		return fmt.Errorf("no video nor audio in the description of the stream")
+	}
+	for i, m := range []*rtspMedia{video, audio} {
+		if m == nil {
+			continue
+		}
+		header := map[string]string{"Transport": fmt.Sprintf("RTP/AVP/TCP;unicast;interleaved=%d-%d", 2*i, 2*i+1)}
+		if c.sess != "" {
+			header["Session"] = c.sess
+		}
+		res, err := c.request("SETUP", rtspControlURL(base, m.control), header)
+		if err != nil {
+			return err
+		}
+		if c.sess == "" {
+			c.sess = strings.TrimSpace(strings.Split(res.header.Get("Session"), ";")[0])
+		}
+		channel := 2 * i
+		if match := rtspInterleaved.FindStringSubmatch(res.header.Get("Transport")); match != nil {
+			channel, _ = strconv.Atoi(match[1])
+		}
+		c.channel[channel] = m.video
+	}
+
+	header := map[string]string{"Range": "npt=0.000-"}
+	if c.sess != "" {
+		header["Session"] = c.sess
+	}
+	s.playing()
+	if _, err := c.request("PLAY", base, header); err != nil {
+		return err
+	}
+	defer c.teardown(base)
+	for {
+		channel, payload, _, err := c.read()
+		if err != nil {
+			return s.receiveError(err)
+		}
+		if c.receive(channel, payload) {
+			return nil
+		}
+	}
+}
+
+// receive counts the RTP packets received, and tells if the stream has been received long enough. The last packet
+// of a video frame has the marker bit, an audio packet is counted as a frame.
+func (c *rtspConn) receive(channel int, payload []byte) bool {
+	video, ok := c.channel[channel]
+	if !ok || len(payload) < 12 {
+		return false
+	}
+	if video && payload[1]&0x80 == 0 {
+		c.result.Bytes += int64(len(payload))
+		return false
+	}
+	return c.frame(video, len(payload))
+}
+
+func (c *rtspConn) teardown(uri string) {
+	c.conn.SetDeadline(time.Now().Add(time.Second))
+	header := map[string]string{}
+	if c.sess != "" {
+		header["Session"] = c.sess
+	}
+	c.write("TEARDOWN", uri, header)
+}
+
+// request sends a request and reads its response, the request is sent again authenticated when the server requires
+// it and the url has credentials
+func (c *rtspConn) request(method, uri string, header map[string]string) (*rtspResponse, error) {
+	res, err := c.roundTrip(method, uri, header)
+	if err != nil {
+		return nil, err
+	}
+	if res.code == 401 && c.auth == "" && c.session.url.User != nil {
+		if err := c.authenticate(res.header["Www-Authenticate"]); err != nil {
+			return nil, err
+		}
+		if res, err = c.roundTrip(method, uri, header); err != nil {
+			return nil, err
+		}
+	}
+	c.result.Status = res.status
+	if server := res.header.Get("Server"); server != "" {
+		c.result.Server = server
+	}
+	if res.code != 200 {
+		return nil, fmt.Errorf("%s %s: %s", method, uri, res.status)
+	}
+	return res, nil
+}
+
+// roundTrip sends a request and reads its response, the interleaved packets received before it are counted
+func (c *rtspConn) roundTrip(method, uri string, header map[string]string) (*rtspResponse, error) {
+	if err := c.write(method, uri, header); err != nil {
+		return nil, err
+	}
+	for {
+		channel, payload, res, err := c.read()
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %v", method, uri, err)
+		}
+		if res != nil {
+			return res, nil
+		}
+		c.receive(channel, payload)
+	}
+}
+
+func (c *rtspConn) write(method, uri string, header map[string]string) error {
+	c.cseq++
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s RTSP/1.0\r\nCSeq: %d\r\nUser-Agent: venom\r\n", method, uri, c.cseq)
+	for k, v := range header {
+		fmt.Fprintf(&b, "%s: %s\r\n", k, v)
+	}
+	if c.auth != "" {
+		fmt.Fprintf(&b, "Authorization: %s\r\n", c.authorization(method, uri))
+	}
+	b.WriteString("\r\n")
+	c.l.Debugf("rtsp request: %s %s", method, uri)
+	_, err := io.WriteString(c.conn, b.String())
+	return err
+}
+
+// read reads an interleaved packet, returned with its channel, or a response
+func (c *rtspConn) read() (int, []byte, *rtspResponse, error) {
+	first, err := c.r.Peek(1)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	if first[0] == '$' {
+		var header [4]byte
+		if _, err := io.ReadFull(c.r, header[:]); err != nil {
+			return 0, nil, nil, err
+		}
+		payload := make([]byte, binary.BigEndian.Uint16(header[2:]))
+		if _, err := io.ReadFull(c.r, payload); err != nil {
+			return 0, nil, nil, err
+		}
+		return int(header[1]), payload, nil, nil
+	}
+
+	tp := textproto.NewReader(c.r)
+	line, err := tp.ReadLine()
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	parts := strings.SplitN(line, " ", 3)
+	if len(parts) < 2 || !strings.HasPrefix(parts[0], "RTSP/") {
+		return 0, nil, nil, fmt.Errorf("invalid rtsp response %q", line)
+	}
+	res := &rtspResponse{status: strings.Join(parts[1:], " ")}
+	if res.code, err = strconv.Atoi(parts[1]); err != nil {
+		return 0, nil, nil, fmt.Errorf("invalid rtsp response %q", line)
+	}
+	if res.header, err = tp.ReadMIMEHeader(); err != nil {
+		return 0, nil, nil, err
+	}
+	if length, _ := strconv.Atoi(res.header.Get("Content-Length")); length > 0 {
+		res.body = make([]byte, length)
+		if _, err := io.ReadFull(c.r, res.body); err != nil {
+			return 0, nil, nil, err
+		}
+	}
+	c.l.Debugf("rtsp response: %s", line)
+	return 0, nil, res, nil
+}
+
+// authenticate reads the challenges of the server, a MD5 or SHA-256 digest challenge is preferred to a basic one
+func (c *rtspConn) authenticate(challenges []string) error {
+	for _, scheme := range []string{"Digest", "Basic"} {
+		for _, challenge := range challenges {
+			if !strings.HasPrefix(strings.ToLower(challenge), strings.ToLower(scheme)+" ") {
+				continue
+			}
+			params := map[string]string{}
+			for _, match := range rtspAuthParam.FindAllStringSubmatch(challenge[len(scheme):], -1) {
+				params[strings.ToLower(match[1])] = match[2] + match[3]
+			}
+			if algorithm := strings.ToUpper(params["algorithm"]); algorithm != "" && algorithm != "MD5" && algorithm != "SHA-256" {
+				continue
+			}
+			c.auth, c.params = scheme, params
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported authentication %q", strings.Join(challenges, ", "))
+}
+
+func (c *rtspConn) authorization(method, uri string) string {
+	user := c.session.url.User.Username()
+	password, _ := c.session.url.User.Password()
+	if c.auth == "Basic" {
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+password))
+	}
+	algorithm := c.params["algorithm"]
+	hash := func(s string) string {
+		if strings.ToUpper(algorithm) == "SHA-256" {
+			sum := sha256.Sum256([]byte(s))
+			return hex.EncodeToString(sum[:])
+		}
+		sum := md5.Sum([]byte(s))
+		return hex.EncodeToString(sum[:])
+	}
+	realm, nonce := c.params["realm"], c.params["nonce"]
+	ha1 := hash(user + ":" + realm + ":" + password)
+	ha2 := hash(method + ":" + uri)
+	auth := fmt.Sprintf(`Digest username="%s", realm="%s", nonce="%s", uri="%s"`, user, realm, nonce, uri)
+	if algorithm != "" {
+		auth += ", algorithm=" + algorithm
+	}
+	if qop := c.params["qop"]; qop != "" {
+		cnonce := make([]byte, 8)
+		rand.Read(cnonce)
+		nc := fmt.Sprintf("%08x", c.cseq)
+		response := hash(ha1 + ":" + nonce + ":" + nc + ":" + hex.EncodeToString(cnonce) + ":auth:" + ha2)
+		auth += fmt.Sprintf(`, qop=auth, nc=%s, cnonce="%s", response="%s"`, nc, hex.EncodeToString(cnonce), response)
+	} else {
+		auth += fmt.Sprintf(`, response="%s"`, hash(ha1+":"+nonce+":"+ha2))
+	}
+	if opaque, ok := c.params["opaque"]; ok {
+		auth += fmt.Sprintf(`, opaque="%s"`, opaque)
+	}
+	return auth
+}
+
+// parseSDP returns the video and audio medias of a SDP, and its session level control
+func parseSDP(sdp string) ([]rtspMedia, string) {
+	var medias []rtspMedia
+	var control string
+	var media *rtspMedia
+	var payload string
+	sessionLevel := true
+	for _, line := range strings.Split(sdp, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "m="):
+			sessionLevel, media = false, nil
+			fields := strings.Fields(line[2:])
+			if len(fields) < 4 || (fields[0] != "video" && fields[0] != "audio") {
+				continue
+			}
+			payload = fields[3]
+			medias = append(medias, rtspMedia{video: fields[0] == "video", codec: rtspStaticPayloads[payload]})
+			media = &medias[len(medias)-1]
+		case strings.HasPrefix(line, "a=control:"):
+			if sessionLevel {
+				control = line[len("a=control:"):]
+			} else if media != nil {
+				media.control = line[len("a=control:"):]
+			}
+		case strings.HasPrefix(line, "a=rtpmap:"+payload+" ") && media != nil:
+			name := strings.ToLower(strings.Split(strings.TrimPrefix(line, "a=rtpmap:"+payload+" "), "/")[0])
+			if codec, ok := rtspCodecs[name]; ok {
+				name = codec
+			}
+			media.codec = name
+		}
+	}
+	return medias, control
+}
+
+// rtspControlURL returns the url of a control, relative to the base url of the stream
+func rtspControlURL(base, control string) string {
+	switch {
+	case control == "" || control == "*":
+		return base
+	case strings.HasPrefix(control, "rtsp://") || strings.HasPrefix(control, "rtsps://"):
+		return control
+	case strings.HasSuffix(base, "/"):
+		return base + control
+	}
+	return base + "/" + control
+}
@@ -0,0 +1,165 @@
+package promtool
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"gopkg.in/yaml.v2"
+
+	"github.com/ovh/venom"
+	"github.com/ovh/venom/executors"
+)
+
+// Name of executor
+const Name = "promtool"
+
+const promtoolCommand = "promtool"
+
+// New returns a new Executor
+func New() venom.Executor {
+	return &Executor{}
+}
+
+// Executor runs the unit tests of Prometheus alerting and recording rules with promtool test rules
+type Executor struct {
+	RuleFiles          []string      `json:"rule_files,omitempty" yaml:"rule_files,omitempty" mapstructure:"rule_files"`
+	EvaluationInterval string        `json:"evaluation_interval,omitempty" yaml:"evaluation_interval,omitempty" mapstructure:"evaluation_interval"`
+	Tests              []interface{} `json:"tests,omitempty" yaml:"tests,omitempty"`
+	// TestFile is an existing promtool test file, used instead of rule_files and tests
+	TestFile string `json:"test_file,omitempty" yaml:"test_file,omitempty" mapstructure:"test_file"`
+}
+
+// Result represents a step result
+type Result struct {
+	Executor    Executor `json:"executor,omitempty" yaml:"executor,omitempty"`
+	Passed      bool     `json:"passed" yaml:"passed"`
+	Failures    []string `json:"failures,omitempty" yaml:"failures,omitempty"`
+	Systemout   string   `json:"systemout,omitempty" yaml:"systemout,omitempty"`
+	Systemerr   string   `json:"systemerr,omitempty" yaml:"systemerr,omitempty"`
+	Code        int      `json:"code" yaml:"code"`
+	TimeSeconds float64  `json:"timeseconds,omitempty" yaml:"timeseconds,omitempty"`
+	TimeHuman   string   `json:"timehuman,omitempty" yaml:"timehuman,omitempty"`
+}
+
+// testFile is the promtool unit tests file written for the step
+type testFile struct {
+	RuleFiles          []string      `yaml:"rule_files"`
+	EvaluationInterval string        `yaml:"evaluation_interval,omitempty"`
+	Tests              []interface{} `yaml:"tests"`
+}
+
+// ZeroValueResult return an empty implemtation of this executor result
+func (Executor) ZeroValueResult() venom.ExecutorResult {
+	r, _ := executors.Dump(Result{})
+	return r
+}
+
+// GetDefaultAssertions return default assertions for this executor
+func (Executor) GetDefaultAssertions() *venom.StepAssertions {
+	return &venom.StepAssertions{Assertions: []string{"result.passed ShouldBeTrue"}}
+}
+
+// Run execute TestStep
+func (Executor) Run(testCaseContext venom.TestCaseContext, l venom.Logger, step venom.TestStep, workdir string) (venom.ExecutorResult, error) {
+	var e Executor
+	if err := mapstructure.Decode(step, &e); err != nil {
+		return nil, err
+	}
+
+	file := e.TestFile
+	switch {
+	case file != "" && (len(e.RuleFiles) > 0 || len(e.Tests) > 0):
+		return nil, fmt.Errorf("test_file can not be used with rule_files and tests")
+	case file != "":
+		file = rebase(workdir, file)
+	case len(e.RuleFiles) == 0 || len(e.Tests) == 0:
+		return nil, fmt.Errorf("rule_files and tests are mandatory, or test_file")
+	default:
+		f, err := e.writeTestFile(workdir)
+		if err != nil {
+			return nil, err
+		}
+		defer os.Remove(f)
+		file = f
+	}
+
+	start := time.Now()
+	result := Result{Executor: e}
+	l.Debugf("%s test rules %s", promtoolCommand, file)
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd := exec.Command(promtoolCommand, "test", "rules", file)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		exitErr, ok := err.(*exec.ExitError)
+		if !ok {
+			return nil, fmt.Errorf("unable to run %s: %v", promtoolCommand, err)
+		}
+		result.Code = exitErr.ExitCode()
+	}
+	result.Passed = result.Code == 0
+	result.Systemout = stdout.String()
+	result.Systemerr = stderr.String()
+	// promtool writes the failed tests on stderr (stdout for the oldest versions)
+	result.Failures = failures(result.Systemerr + result.Systemout)
+
+	elapsed := time.Since(start)
+	result.TimeSeconds = elapsed.Seconds()
+	result.TimeHuman = elapsed.String()
+	return executors.Dump(result)
+}
+
+// writeTestFile writes the promtool unit tests file of the step in a temporary file, the rule files are rebased on
+// the directory of the testsuite
+func (e Executor) writeTestFile(workdir string) (string, error) {
+	tf := testFile{EvaluationInterval: e.EvaluationInterval, Tests: e.Tests}
+	for _, f := range e.RuleFiles {
+		tf.RuleFiles = append(tf.RuleFiles, rebase(workdir, f))
+	}
+	btes, err := yaml.Marshal(tf)
+	if err != nil {
+		return "", fmt.Errorf("invalid tests: %v", err)
+	}
+	f, err := ioutil.TempFile("", "venom-promtool-*.yml")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := f.Write(btes); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+func rebase(workdir, file string) string {
+	if filepath.IsAbs(file) {
+		return file
+	}
+	return filepath.Join(workdir, file)
+}
+
+// failures returns the lines of the failed tests of the promtool output, between FAILED: and the next file
+func failures(out string) []string {
+	var res []string
+	failed := false
+	for _, line := range strings.Split(out, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "FAILED"):
+			failed = true
+		case strings.HasPrefix(line, "Unit Testing:") || trimmed == "SUCCESS":
+			failed = false
+		case failed && trimmed != "":
+			res = append(res, trimmed)
+		}
+	}
+	return res
+}
@@ -0,0 +1,43 @@
+package executors
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+
+	"golang.org/x/net/proxy"
+)
+
+// SOCKS5Proxy is the SOCKS5 proxy of the run, socks5://[user:password@]host:port. When it is set, the connections of
+// the network executors go through it
+var SOCKS5Proxy *url.URL
+
+// ParseSOCKS5Proxy parses the url of a SOCKS5 proxy, the socks5h scheme is accepted too
+func ParseSOCKS5Proxy(s string) (*url.URL, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SOCKS5 proxy %q: %v", s, err)
+	}
+	if (u.Scheme != "socks5" && u.Scheme != "socks5h") || u.Host == "" {
+		return nil, fmt.Errorf("invalid SOCKS5 proxy %q, it must be socks5://[user:password@]host:port", s)
+	}
+	return u, nil
+}
+
+// DialContext connects to the address on the network, through the SOCKS5 proxy of the run when it is set. The
+// proxy resolves the host names.
+func DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	if SOCKS5Proxy == nil {
+		return (&net.Dialer{}).DialContext(ctx, network, address)
+	}
+	d, err := proxy.FromURL(SOCKS5Proxy, proxy.Direct)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := d.(proxy.ContextDialer).DialContext(ctx, network, address)
+	if err != nil {
+		return nil, fmt.Errorf("SOCKS5 proxy %s: %v", SOCKS5Proxy.Host, err)
+	}
+	return conn, nil
+}
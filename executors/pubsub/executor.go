@@ -0,0 +1,271 @@
+package pubsub
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/ovh/venom"
+	"github.com/ovh/venom/executors"
+)
+
+// Name of executor
+const Name = "pubsub"
+
+const scope = "https://www.googleapis.com/auth/pubsub"
+
+// New returns a new Executor
+func New() venom.Executor {
+	return &Executor{}
+}
+
+// Executor publishes or pulls messages on Google Cloud Pub/Sub
+type Executor struct {
+	Operation       string            `json:"operation,omitempty" yaml:"operation,omitempty"`
+	Project         string            `json:"project,omitempty" yaml:"project,omitempty"`
+	Topic           string            `json:"topic,omitempty" yaml:"topic,omitempty"`
+	Subscription    string            `json:"subscription,omitempty" yaml:"subscription,omitempty"`
+	Messages        []Message         `json:"messages,omitempty" yaml:"messages,omitempty"`
+	Data            string            `json:"data,omitempty" yaml:"data,omitempty"`
+	Attributes      map[string]string `json:"attributes,omitempty" yaml:"attributes,omitempty"`
+	OrderingKey     string            `json:"ordering_key,omitempty" yaml:"ordering_key,omitempty" mapstructure:"ordering_key"`
+	MaxMessages     int               `json:"max_messages,omitempty" yaml:"max_messages,omitempty" mapstructure:"max_messages"`
+	NoAck           bool              `json:"no_ack,omitempty" yaml:"no_ack,omitempty" mapstructure:"no_ack"`
+	WaitTimeout     int               `json:"wait_timeout,omitempty" yaml:"wait_timeout,omitempty" mapstructure:"wait_timeout"`
+	CredentialsFile string            `json:"credentials_file,omitempty" yaml:"credentials_file,omitempty" mapstructure:"credentials_file"`
+	Token           string            `json:"token,omitempty" yaml:"token,omitempty"`
+	Endpoint        string            `json:"endpoint,omitempty" yaml:"endpoint,omitempty"`
+}
+
+// Message is a message to publish, or a pulled message
+type Message struct {
+	ID          string            `json:"id,omitempty" yaml:"id,omitempty"`
+	Data        string            `json:"data,omitempty" yaml:"data,omitempty"`
+	DataJSON    interface{}       `json:"datajson,omitempty" yaml:"datajson,omitempty"`
+	Attributes  map[string]string `json:"attributes,omitempty" yaml:"attributes,omitempty"`
+	OrderingKey string            `json:"orderingkey,omitempty" yaml:"orderingkey,omitempty" mapstructure:"ordering_key"`
+	PublishTime string            `json:"publishtime,omitempty" yaml:"publishtime,omitempty"`
+}
+
+// Result represents a step result
+type Result struct {
+	Executor    Executor  `json:"executor,omitempty" yaml:"executor,omitempty"`
+	MessageIDs  []string  `json:"messageids,omitempty" yaml:"messageids,omitempty"`
+	Messages    []Message `json:"messages,omitempty" yaml:"messages,omitempty"`
+	Count       int       `json:"count" yaml:"count"`
+	Err         string    `json:"err,omitempty" yaml:"err,omitempty"`
+	TimeSeconds float64   `json:"timeseconds,omitempty" yaml:"timeseconds,omitempty"`
+	TimeHuman   string    `json:"timehuman,omitempty" yaml:"timehuman,omitempty"`
+}
+
+type pubsubMessage struct {
+	Data        string            `json:"data,omitempty"`
+	Attributes  map[string]string `json:"attributes,omitempty"`
+	OrderingKey string            `json:"orderingKey,omitempty"`
+	MessageID   string            `json:"messageId,omitempty"`
+	PublishTime string            `json:"publishTime,omitempty"`
+}
+
+// ZeroValueResult return an empty implemtation of this executor result
+func (Executor) ZeroValueResult() venom.ExecutorResult {
+	r, _ := executors.Dump(Result{})
+	return r
+}
+
+// GetDefaultAssertions return default assertions for this executor
+func (Executor) GetDefaultAssertions() *venom.StepAssertions {
+	return &venom.StepAssertions{Assertions: []string{"result.err ShouldBeEmpty"}}
+}
+
+// Run execute TestStep
+func (Executor) Run(testCaseContext venom.TestCaseContext, l venom.Logger, step venom.TestStep, workdir string) (venom.ExecutorResult, error) {
+	var e Executor
+	if err := mapstructure.Decode(step, &e); err != nil {
+		return nil, err
+	}
+	if e.WaitTimeout <= 0 {
+		e.WaitTimeout = 10
+	}
+	if e.MaxMessages <= 0 {
+		e.MaxMessages = 1
+	}
+
+	emulator := os.Getenv("PUBSUB_EMULATOR_HOST")
+	if e.Endpoint == "" && emulator != "" {
+		e.Endpoint = "http://" + emulator
+	}
+	if e.Endpoint == "" {
+		e.Endpoint = "https://pubsub.googleapis.com"
+	}
+	if emulator == "" && (e.Token == "" || e.Project == "") {
+		creds, err := executors.ReadGoogleCredentials(e.CredentialsFile)
+		if err != nil {
+			return nil, err
+		}
+		if e.Project == "" {
+			e.Project = creds.ProjectID
+		}
+		if e.Token == "" {
+			if e.Token, err = creds.AccessToken(testCaseContext.Context(), scope); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if e.Project == "" {
+		return nil, fmt.Errorf("project is mandatory")
+	}
+
+	start := time.Now()
+	result := Result{Executor: e}
+	var err error
+	switch e.Operation {
+	case "publish":
+		err = e.publish(testCaseContext.Context(), l, &result)
+	case "pull":
+		err = e.pull(testCaseContext.Context(), l, &result)
+	default:
+		return nil, fmt.Errorf("operation must be publish or pull")
+	}
+	if err != nil {
+		result.Err = err.Error()
+	}
+	result.Executor.Token = "****hidden****" // do not output token
+
+	elapsed := time.Since(start)
+	result.TimeSeconds = elapsed.Seconds()
+	result.TimeHuman = elapsed.String()
+
+	return executors.Dump(result)
+}
+
+func (e Executor) publish(ctx context.Context, l venom.Logger, result *Result) error {
+	if e.Topic == "" {
+		return fmt.Errorf("topic is mandatory")
+	}
+	messages := e.Messages
+	if len(messages) == 0 {
+		messages = []Message{{Data: e.Data, Attributes: e.Attributes, OrderingKey: e.OrderingKey}}
+	}
+	body := struct {
+		Messages []pubsubMessage `json:"messages"`
+	}{}
+	for _, m := range messages {
+		body.Messages = append(body.Messages, pubsubMessage{
+			Data:        base64.StdEncoding.EncodeToString([]byte(m.Data)),
+			Attributes:  m.Attributes,
+			OrderingKey: m.OrderingKey,
+		})
+	}
+	var resp struct {
+		MessageIDs []string `json:"messageIds"`
+	}
+	if err := e.do(ctx, l, "topics/"+e.Topic+":publish", body, &resp); err != nil {
+		return err
+	}
+	result.MessageIDs = resp.MessageIDs
+	result.Count = len(resp.MessageIDs)
+	return nil
+}
+
+// pull pulls messages until max_messages are received or wait_timeout is reached
+func (e Executor) pull(ctx context.Context, l venom.Logger, result *Result) error {
+	if e.Subscription == "" {
+		return fmt.Errorf("subscription is mandatory")
+	}
+	deadline := time.Now().Add(time.Duration(e.WaitTimeout) * time.Second)
+	for {
+		var resp struct {
+			ReceivedMessages []struct {
+				AckID   string        `json:"ackId"`
+				Message pubsubMessage `json:"message"`
+			} `json:"receivedMessages"`
+		}
+		body := map[string]int{"maxMessages": e.MaxMessages - len(result.Messages)}
+		if err := e.do(ctx, l, "subscriptions/"+e.Subscription+":pull", body, &resp); err != nil {
+			return err
+		}
+
+		var ackIDs []string
+		for _, r := range resp.ReceivedMessages {
+			ackIDs = append(ackIDs, r.AckID)
+			data, err := base64.StdEncoding.DecodeString(r.Message.Data)
+			if err != nil {
+				return fmt.Errorf("invalid data for message %s: %v", r.Message.MessageID, err)
+			}
+			m := Message{
+				ID:          r.Message.MessageID,
+				Data:        string(data),
+				Attributes:  r.Message.Attributes,
+				OrderingKey: r.Message.OrderingKey,
+				PublishTime: r.Message.PublishTime,
+			}
+			var dataJSON interface{}
+			if err := json.Unmarshal(data, &dataJSON); err == nil {
+				m.DataJSON = dataJSON
+			}
+			result.Messages = append(result.Messages, m)
+		}
+		result.Count = len(result.Messages)
+
+		if len(ackIDs) > 0 && !e.NoAck {
+			var ack struct{}
+			if err := e.do(ctx, l, "subscriptions/"+e.Subscription+":acknowledge", map[string][]string{"ackIds": ackIDs}, &ack); err != nil {
+				return err
+			}
+		}
+		if len(result.Messages) >= e.MaxMessages {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%d message(s) received after %d second(s), expected %d", len(result.Messages), e.WaitTimeout, e.MaxMessages)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+func (e Executor) do(ctx context.Context, l venom.Logger, path string, body interface{}, target interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	u := strings.TrimSuffix(e.Endpoint, "/") + "/v1/projects/" + e.Project + "/" + path
+	l.Debugf("pubsub> POST %s", u)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+e.Token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	btes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(btes)))
+	}
+	if err := json.Unmarshal(btes, target); err != nil {
+		return fmt.Errorf("unable to read response: %v", err)
+	}
+	return nil
+}
@@ -4,7 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"os"
-	"path"
+	"path/filepath"
 
 	"github.com/garyburd/redigo/redis"
 	shellwords "github.com/mattn/go-shellwords"
@@ -68,7 +68,7 @@ func (Executor) Run(testCaseContext venom.TestCaseContext, l venom.Logger, step
 	}
 	commands := []string{}
 	if e.FilePath != "" {
-		commands, err = file2lines(path.Join(workdir, e.FilePath))
+		commands, err = file2lines(filepath.Join(workdir, e.FilePath))
 		if err != nil {
 			return nil, fmt.Errorf("Failed to load file %v", err)
 		}
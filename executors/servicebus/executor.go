@@ -0,0 +1,376 @@
+package servicebus
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/ovh/venom"
+	"github.com/ovh/venom/executors"
+)
+
+// Name of executor
+const Name = "servicebus"
+
+// New returns a new Executor
+func New() venom.Executor {
+	return &Executor{}
+}
+
+// Executor sends or receives messages on Azure Service Bus queues and topics
+type Executor struct {
+	Operation        string            `json:"operation,omitempty" yaml:"operation,omitempty"`
+	ConnectionString string            `json:"connection_string,omitempty" yaml:"connection_string,omitempty" mapstructure:"connection_string"`
+	Namespace        string            `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	Queue            string            `json:"queue,omitempty" yaml:"queue,omitempty"`
+	Topic            string            `json:"topic,omitempty" yaml:"topic,omitempty"`
+	Subscription     string            `json:"subscription,omitempty" yaml:"subscription,omitempty"`
+	Body             string            `json:"body,omitempty" yaml:"body,omitempty"`
+	Properties       map[string]string `json:"properties,omitempty" yaml:"properties,omitempty"`
+	MessageID        string            `json:"message_id,omitempty" yaml:"message_id,omitempty" mapstructure:"message_id"`
+	CorrelationID    string            `json:"correlation_id,omitempty" yaml:"correlation_id,omitempty" mapstructure:"correlation_id"`
+	SessionID        string            `json:"session_id,omitempty" yaml:"session_id,omitempty" mapstructure:"session_id"`
+	Label            string            `json:"label,omitempty" yaml:"label,omitempty"`
+	ContentType      string            `json:"content_type,omitempty" yaml:"content_type,omitempty" mapstructure:"content_type"`
+	MaxMessages      int               `json:"max_messages,omitempty" yaml:"max_messages,omitempty" mapstructure:"max_messages"`
+	WaitTimeout      int               `json:"wait_timeout,omitempty" yaml:"wait_timeout,omitempty" mapstructure:"wait_timeout"`
+	NoDelete         bool              `json:"no_delete,omitempty" yaml:"no_delete,omitempty" mapstructure:"no_delete"`
+	Token            string            `json:"token,omitempty" yaml:"token,omitempty"`
+	TenantID         string            `json:"tenant_id,omitempty" yaml:"tenant_id,omitempty" mapstructure:"tenant_id"`
+	ClientID         string            `json:"client_id,omitempty" yaml:"client_id,omitempty" mapstructure:"client_id"`
+	ClientSecret     string            `json:"client_secret,omitempty" yaml:"client_secret,omitempty" mapstructure:"client_secret"`
+}
+
+// Message is a received message
+type Message struct {
+	ID               string                 `json:"id,omitempty" yaml:"id,omitempty"`
+	Body             string                 `json:"body,omitempty" yaml:"body,omitempty"`
+	BodyJSON         interface{}            `json:"bodyjson,omitempty" yaml:"bodyjson,omitempty"`
+	Properties       map[string]string      `json:"properties,omitempty" yaml:"properties,omitempty"`
+	BrokerProperties map[string]interface{} `json:"brokerproperties,omitempty" yaml:"brokerproperties,omitempty"`
+}
+
+// Result represents a step result
+type Result struct {
+	Executor    Executor  `json:"executor,omitempty" yaml:"executor,omitempty"`
+	Messages    []Message `json:"messages,omitempty" yaml:"messages,omitempty"`
+	Count       int       `json:"count" yaml:"count"`
+	Err         string    `json:"err,omitempty" yaml:"err,omitempty"`
+	TimeSeconds float64   `json:"timeseconds,omitempty" yaml:"timeseconds,omitempty"`
+	TimeHuman   string    `json:"timehuman,omitempty" yaml:"timehuman,omitempty"`
+}
+
+// ZeroValueResult return an empty implemtation of this executor result
+func (Executor) ZeroValueResult() venom.ExecutorResult {
+	r, _ := executors.Dump(Result{})
+	return r
+}
+
+// GetDefaultAssertions return default assertions for this executor
+func (Executor) GetDefaultAssertions() *venom.StepAssertions {
+	return &venom.StepAssertions{Assertions: []string{"result.err ShouldBeEmpty"}}
+}
+
+// Run execute TestStep
+func (Executor) Run(testCaseContext venom.TestCaseContext, l venom.Logger, step venom.TestStep, workdir string) (venom.ExecutorResult, error) {
+	var e Executor
+	if err := mapstructure.Decode(step, &e); err != nil {
+		return nil, err
+	}
+	if e.MaxMessages <= 0 {
+		e.MaxMessages = 1
+	}
+	if e.WaitTimeout <= 0 {
+		e.WaitTimeout = 10
+	}
+
+	ctx := testCaseContext.Context()
+	c, err := e.newClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	result := Result{Executor: e}
+	switch e.Operation {
+	case "send":
+		if e.Queue == "" && e.Topic == "" {
+			return nil, fmt.Errorf("queue or topic is mandatory")
+		}
+		err = e.send(ctx, l, c)
+		if err == nil {
+			result.Count = 1
+		}
+	case "receive":
+		if e.Queue == "" && (e.Topic == "" || e.Subscription == "") {
+			return nil, fmt.Errorf("queue, or topic and subscription are mandatory")
+		}
+		err = e.receive(ctx, l, c, &result)
+	default:
+		return nil, fmt.Errorf("operation must be send or receive")
+	}
+	if err != nil {
+		result.Err = err.Error()
+	}
+	result.Executor.ConnectionString = "****hidden****" // do not output secrets
+	result.Executor.Token = ""
+	result.Executor.ClientSecret = ""
+
+	elapsed := time.Since(start)
+	result.TimeSeconds = elapsed.Seconds()
+	result.TimeHuman = elapsed.String()
+
+	return executors.Dump(result)
+}
+
+// client calls the Service Bus REST API
+type client struct {
+	endpoint string
+	keyName  string
+	key      string
+	token    string
+}
+
+// newClient reads the connection string, or gets an Azure AD token
+func (e Executor) newClient(ctx context.Context) (*client, error) {
+	if e.ConnectionString != "" {
+		c := &client{}
+		for _, part := range strings.Split(e.ConnectionString, ";") {
+			kv := strings.SplitN(part, "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+			switch kv[0] {
+			case "Endpoint":
+				c.endpoint = "https://" + strings.TrimSuffix(strings.TrimPrefix(kv[1], "sb://"), "/")
+			case "SharedAccessKeyName":
+				c.keyName = kv[1]
+			case "SharedAccessKey":
+				c.key = kv[1]
+			}
+		}
+		if c.endpoint == "" || c.keyName == "" || c.key == "" {
+			return nil, fmt.Errorf("invalid connection string, Endpoint, SharedAccessKeyName and SharedAccessKey are mandatory")
+		}
+		return c, nil
+	}
+
+	if e.Namespace == "" {
+		return nil, fmt.Errorf("connection_string or namespace is mandatory")
+	}
+	c := &client{endpoint: "https://" + e.Namespace, token: e.Token}
+	if !strings.Contains(e.Namespace, ".") {
+		c.endpoint += ".servicebus.windows.net"
+	}
+	if c.token == "" {
+		var err error
+		if c.token, err = e.aadToken(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// aadToken gets an Azure AD token with the client credentials flow
+func (e Executor) aadToken(ctx context.Context) (string, error) {
+	tenant, id, secret := e.TenantID, e.ClientID, e.ClientSecret
+	if tenant == "" {
+		tenant = os.Getenv("AZURE_TENANT_ID")
+	}
+	if id == "" {
+		id = os.Getenv("AZURE_CLIENT_ID")
+	}
+	if secret == "" {
+		secret = os.Getenv("AZURE_CLIENT_SECRET")
+	}
+	if tenant == "" || id == "" || secret == "" {
+		return "", fmt.Errorf("token, or tenant_id, client_id and client_secret are mandatory with namespace")
+	}
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {id},
+		"client_secret": {secret},
+		"scope":         {"https://servicebus.azure.net/.default"},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://login.microsoftonline.com/"+url.PathEscape(tenant)+"/oauth2/v2.0/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	btes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unable to get an Azure AD token: %s: %s", resp.Status, string(btes))
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(btes, &token); err != nil {
+		return "", fmt.Errorf("unable to read Azure AD token: %v", err)
+	}
+	return token.AccessToken, nil
+}
+
+func (c *client) authorization(resource string) string {
+	if c.token != "" {
+		return "Bearer " + c.token
+	}
+	uri := strings.ToLower(url.QueryEscape(resource))
+	expiry := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(c.key))
+	mac.Write([]byte(uri + "\n" + expiry))
+	sig := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	return fmt.Sprintf("SharedAccessSignature sr=%s&sig=%s&se=%s&skn=%s", uri, url.QueryEscape(sig), expiry, url.QueryEscape(c.keyName))
+}
+
+func (c *client) do(ctx context.Context, l venom.Logger, method, u string, body string, headers map[string]string) (*http.Response, []byte, error) {
+	l.Debugf("servicebus> %s %s", method, u)
+	req, err := http.NewRequestWithContext(ctx, method, u, strings.NewReader(body))
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Authorization", c.authorization(c.endpoint))
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	btes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode >= 300 {
+		return nil, nil, fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(btes)))
+	}
+	return resp, btes, nil
+}
+
+func (e Executor) send(ctx context.Context, l venom.Logger, c *client) error {
+	entity := e.Queue
+	if entity == "" {
+		entity = e.Topic
+	}
+	broker := map[string]string{}
+	for k, v := range map[string]string{
+		"MessageId":     e.MessageID,
+		"CorrelationId": e.CorrelationID,
+		"SessionId":     e.SessionID,
+		"Label":         e.Label,
+	} {
+		if v != "" {
+			broker[k] = v
+		}
+	}
+	headers := map[string]string{}
+	if len(broker) > 0 {
+		btes, err := json.Marshal(broker)
+		if err != nil {
+			return err
+		}
+		headers["BrokerProperties"] = string(btes)
+	}
+	if e.ContentType != "" {
+		headers["Content-Type"] = e.ContentType
+	}
+	for k, v := range e.Properties {
+		// custom properties are sent as headers, quoted to be read as strings
+		headers[k] = strconv.Quote(v)
+	}
+	_, _, err := c.do(ctx, l, http.MethodPost, c.endpoint+"/"+url.PathEscape(entity)+"/messages", e.Body, headers)
+	return err
+}
+
+// receive receives messages until max_messages are received, wait_timeout is reached or ctx is done.
+// Messages are removed from the entity, unless no_delete is set: they are then locked,
+// read and unlocked.
+func (e Executor) receive(ctx context.Context, l venom.Logger, c *client, result *Result) error {
+	path := url.PathEscape(e.Queue)
+	if path == "" {
+		path = url.PathEscape(e.Topic) + "/subscriptions/" + url.PathEscape(e.Subscription)
+	}
+	path += "/messages/head"
+
+	method := http.MethodDelete
+	if e.NoDelete {
+		method = http.MethodPost
+	}
+	deadline := time.Now().Add(time.Duration(e.WaitTimeout) * time.Second)
+	for len(result.Messages) < e.MaxMessages {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return fmt.Errorf("%d message(s) received after %d second(s), expected %d", len(result.Messages), e.WaitTimeout, e.MaxMessages)
+		}
+		resp, btes, err := c.do(ctx, l, method, c.endpoint+"/"+path+"?timeout="+strconv.Itoa(int(math.Ceil(remaining.Seconds()))), "", nil)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode == http.StatusNoContent {
+			continue
+		}
+		m := readMessage(resp, btes)
+		result.Messages = append(result.Messages, m)
+		result.Count = len(result.Messages)
+
+		if location := resp.Header.Get("Location"); e.NoDelete && location != "" {
+			// unlock the message, so it can be received again
+			if _, _, err := c.do(ctx, l, http.MethodPut, location, "", nil); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func readMessage(resp *http.Response, body []byte) Message {
+	m := Message{
+		Body:       string(body),
+		Properties: map[string]string{},
+	}
+	if err := json.Unmarshal([]byte(resp.Header.Get("BrokerProperties")), &m.BrokerProperties); err == nil {
+		if id, ok := m.BrokerProperties["MessageId"].(string); ok {
+			m.ID = id
+		}
+	}
+	for k, v := range resp.Header {
+		if len(v) == 0 || k == "BrokerProperties" || !strings.HasPrefix(v[0], `"`) {
+			continue
+		}
+		// custom properties are returned as quoted headers
+		if s, err := strconv.Unquote(v[0]); err == nil {
+			m.Properties[k] = s
+		}
+	}
+	var bodyJSON interface{}
+	if err := json.Unmarshal(body, &bodyJSON); err == nil {
+		m.BodyJSON = bodyJSON
+	}
+	return m
+}
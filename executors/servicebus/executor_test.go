@@ -0,0 +1,145 @@
+package servicebus
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/venom"
+)
+
+// fakeServiceBus is a queue served with the Service Bus REST API
+type fakeServiceBus struct {
+	mutex    sync.Mutex
+	messages []fakeMessage
+	unlocked int
+}
+
+type fakeMessage struct {
+	body   string
+	broker string
+	header http.Header
+}
+
+func (f *fakeServiceBus) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "SharedAccessSignature sr=") || !strings.Contains(r.Header.Get("Authorization"), "&skn=RootManageSharedAccessKey") {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, "<Error><Code>401</Code><Detail>InvalidSignature</Detail></Error>")
+		return
+	}
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/orders/messages":
+		f.messages = append(f.messages, fakeMessage{body: string(body), broker: r.Header.Get("BrokerProperties"), header: r.Header})
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodPut && r.URL.Path == "/orders/messages/1/lock-1":
+		f.unlocked++
+	case r.URL.Path == "/orders/messages/head" && r.URL.Query().Get("timeout") != "":
+		if len(f.messages) == 0 {
+			// the long polling waits for a message
+			f.mutex.Unlock()
+			select {
+			case <-r.Context().Done():
+			case <-time.After(50 * time.Millisecond):
+			}
+			f.mutex.Lock()
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		m := f.messages[0]
+		w.Header().Set("BrokerProperties", m.broker)
+		w.Header().Set("Origin", m.header.Get("Origin"))
+		if r.Method == http.MethodDelete {
+			f.messages = f.messages[1:]
+		} else {
+			w.Header().Set("Location", "https://"+r.Host+"/orders/messages/1/lock-1")
+		}
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, m.body)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestRun(t *testing.T) {
+	queue := &fakeServiceBus{}
+	srv := httptest.NewTLSServer(queue)
+	defer srv.Close()
+	client := http.DefaultClient
+	http.DefaultClient = srv.Client()
+	defer func() { http.DefaultClient = client }()
+
+	run := func(ctx context.Context, step venom.TestStep) venom.ExecutorResult {
+		if step["connection_string"] == nil {
+			step["connection_string"] = "Endpoint=sb://" + srv.Listener.Addr().String() + "/;SharedAccessKeyName=RootManageSharedAccessKey;SharedAccessKey=secret"
+		}
+		step["queue"] = "orders"
+		tcc := &venom.CommonTestCaseContext{}
+		tcc.SetContext(ctx)
+		res, err := Executor{}.Run(tcc, logrus.New(), step, "")
+		require.NoError(t, err)
+		return res
+	}
+
+	res := run(context.Background(), venom.TestStep{
+		"operation":    "send",
+		"body":         `{"id": 1}`,
+		"message_id":   "order-1",
+		"content_type": "application/json",
+		"properties":   map[string]string{"origin": "venom"},
+	})
+	assert.Empty(t, res["result.err"])
+	assert.Equal(t, 1, res["result.count"])
+	assert.Equal(t, "****hidden****", res["result.executor.connectionstring"])
+	require.Len(t, queue.messages, 1)
+	assert.Equal(t, `{"MessageId":"order-1"}`, queue.messages[0].broker)
+	assert.Equal(t, "application/json", queue.messages[0].header.Get("Content-Type"))
+	// the custom properties are quoted
+	assert.Equal(t, `"venom"`, queue.messages[0].header.Get("Origin"))
+
+	// the message is locked then unlocked
+	res = run(context.Background(), venom.TestStep{"operation": "receive", "no_delete": true})
+	assert.Empty(t, res["result.err"])
+	assert.Equal(t, "order-1", res["result.messages.messages0.id"])
+	assert.Equal(t, 1, queue.unlocked)
+	assert.Len(t, queue.messages, 1)
+
+	res = run(context.Background(), venom.TestStep{"operation": "receive"})
+	assert.Empty(t, res["result.err"])
+	assert.Equal(t, 1, res["result.count"])
+	assert.Equal(t, "order-1", res["result.messages.messages0.id"])
+	assert.Equal(t, "order-1", res["result.messages.messages0.brokerproperties.messageid"])
+	assert.Equal(t, 1.0, res["result.messages.messages0.bodyjson.id"])
+	assert.Equal(t, "venom", res["result.messages.messages0.properties.origin"])
+	assert.Empty(t, queue.messages)
+
+	res = run(context.Background(), venom.TestStep{"operation": "receive", "wait_timeout": 1})
+	assert.Equal(t, "0 message(s) received after 1 second(s), expected 1", res["result.err"])
+
+	// the receive stops with the context of the step
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	res = run(ctx, venom.TestStep{"operation": "receive", "wait_timeout": 30})
+	assert.Contains(t, res["result.err"], "context deadline exceeded")
+	assert.True(t, time.Since(start) < 5*time.Second, "the receive should stop with its step")
+
+	res = run(context.Background(), venom.TestStep{
+		"operation":         "send",
+		"connection_string": "Endpoint=sb://" + srv.Listener.Addr().String() + "/;SharedAccessKeyName=Listen;SharedAccessKey=secret",
+		"body":              "denied",
+	})
+	assert.Equal(t, "401 Unauthorized: <Error><Code>401</Code><Detail>InvalidSignature</Detail></Error>", res["result.err"])
+}
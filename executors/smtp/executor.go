@@ -25,10 +25,12 @@ func New() venom.Executor {
 // Executor represents a Test Exec
 type Executor struct {
 	WithTLS  bool   `json:"withtls,omitempty" yaml:"withtls,omitempty"`
+	StartTLS bool   `json:"starttls,omitempty" yaml:"starttls,omitempty"`
 	Host     string `json:"host,omitempty" yaml:"host,omitempty"`
 	Port     int    `json:"port,omitempty" yaml:"port,omitempty"`
 	User     string `json:"user,omitempty" yaml:"user,omitempty"`
 	Password string `json:"password,omitempty" yaml:"password,omitempty"`
+	Token    string `json:"token,omitempty" yaml:"token,omitempty"`
 	To       string `json:"to,omitempty" yaml:"to,omitempty"`
 	From     string `json:"from,omitempty" yaml:"from,omitempty"`
 	Subject  string `json:"subject,omitempty" yaml:"subject,omitempty"`
@@ -73,6 +75,9 @@ func (Executor) Run(testCaseContext venom.TestCaseContext, l venom.Logger, step
 	result.TimeSeconds = elapsed.Seconds()
 	result.TimeHuman = fmt.Sprintf("%s", elapsed)
 	result.Executor.Password = "****hidden****" // do not output password
+	if result.Executor.Token != "" {
+		result.Executor.Token = "****hidden****"
+	}
 
 	return executors.Dump(result)
 }
@@ -84,6 +89,9 @@ func (e *Executor) sendEmail(l venom.Logger) error {
 	if e.From == "" {
 		return fmt.Errorf("Invalid From")
 	}
+	if e.WithTLS && e.StartTLS {
+		return fmt.Errorf("Can only use one of withtls and starttls")
+	}
 
 	mailFrom := mail.Address{
 		Name:    "",
@@ -135,10 +143,20 @@ func (e *Executor) sendEmail(l venom.Logger) error {
 			return fmt.Errorf("Error while smtp.Dial:%s", errd)
 		}
 		defer c.Close()
+
+		if e.StartTLS {
+			if err := c.StartTLS(tlsconfig); err != nil {
+				return fmt.Errorf("Error with c.StartTLS:%s", err.Error())
+			}
+		}
 	}
 
 	// Auth
-	if e.User != "" && e.Password != "" {
+	if e.User != "" && e.Token != "" {
+		if err := c.Auth(xoauth2(e.User, e.Token)); err != nil {
+			return fmt.Errorf("Error with c.Auth:%s", err.Error())
+		}
+	} else if e.User != "" && e.Password != "" {
 		auth := smtp.PlainAuth("", e.User, e.Password, e.Host)
 		if err := c.Auth(auth); err != nil {
 			return fmt.Errorf("Error with c.Auth:%s", err.Error())
@@ -177,3 +195,25 @@ func (e *Executor) sendEmail(l venom.Logger) error {
 
 	return nil
 }
+
+type xoauth2Auth struct {
+	username, token string
+}
+
+// xoauth2 returns an smtp.Auth implementing the XOAUTH2 authentication mechanism
+// https://developers.google.com/gmail/imap/xoauth2-protocol
+func xoauth2(username, token string) smtp.Auth {
+	return &xoauth2Auth{username: username, token: token}
+}
+
+func (a *xoauth2Auth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	return "XOAUTH2", []byte("user=" + a.username + "\x01auth=Bearer " + a.token + "\x01\x01"), nil
+}
+
+func (a *xoauth2Auth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if more {
+		// the server sends a JSON error, an empty response ends the exchange
+		return []byte{}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,139 @@
+package sns
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/ovh/venom"
+	"github.com/ovh/venom/executors"
+)
+
+// Name of executor
+const Name = "sns"
+
+// New returns a new Executor
+func New() venom.Executor {
+	return &Executor{}
+}
+
+// Executor publishes messages on an AWS SNS topic
+type Executor struct {
+	TopicARN        string            `json:"topic_arn,omitempty" yaml:"topic_arn,omitempty" mapstructure:"topic_arn"`
+	Message         string            `json:"message,omitempty" yaml:"message,omitempty"`
+	Subject         string            `json:"subject,omitempty" yaml:"subject,omitempty"`
+	Attributes      map[string]string `json:"attributes,omitempty" yaml:"attributes,omitempty"`
+	GroupID         string            `json:"group_id,omitempty" yaml:"group_id,omitempty" mapstructure:"group_id"`
+	DeduplicationID string            `json:"deduplication_id,omitempty" yaml:"deduplication_id,omitempty" mapstructure:"deduplication_id"`
+	Endpoint        string            `json:"endpoint,omitempty" yaml:"endpoint,omitempty"`
+	Region          string            `json:"region,omitempty" yaml:"region,omitempty"`
+	AccessKeyID     string            `json:"access_key_id,omitempty" yaml:"access_key_id,omitempty" mapstructure:"access_key_id"`
+	SecretAccessKey string            `json:"secret_access_key,omitempty" yaml:"secret_access_key,omitempty" mapstructure:"secret_access_key"`
+	SessionToken    string            `json:"session_token,omitempty" yaml:"session_token,omitempty" mapstructure:"session_token"`
+}
+
+// Result represents a step result
+type Result struct {
+	Executor    Executor `json:"executor,omitempty" yaml:"executor,omitempty"`
+	MessageID   string   `json:"messageid,omitempty" yaml:"messageid,omitempty"`
+	Err         string   `json:"err,omitempty" yaml:"err,omitempty"`
+	TimeSeconds float64  `json:"timeseconds,omitempty" yaml:"timeseconds,omitempty"`
+	TimeHuman   string   `json:"timehuman,omitempty" yaml:"timehuman,omitempty"`
+}
+
+type publishResponse struct {
+	MessageID string `xml:"PublishResult>MessageId"`
+}
+
+// ZeroValueResult return an empty implemtation of this executor result
+func (Executor) ZeroValueResult() venom.ExecutorResult {
+	r, _ := executors.Dump(Result{})
+	return r
+}
+
+// GetDefaultAssertions return default assertions for this executor
+func (Executor) GetDefaultAssertions() *venom.StepAssertions {
+	return &venom.StepAssertions{Assertions: []string{"result.err ShouldBeEmpty"}}
+}
+
+// Run execute TestStep
+func (Executor) Run(testCaseContext venom.TestCaseContext, l venom.Logger, step venom.TestStep, workdir string) (venom.ExecutorResult, error) {
+	var e Executor
+	if err := mapstructure.Decode(step, &e); err != nil {
+		return nil, err
+	}
+	if e.TopicARN == "" {
+		return nil, fmt.Errorf("topic_arn is mandatory")
+	}
+
+	creds := executors.AWSCredentialsFromEnv(executors.AWSCredentials{
+		AccessKeyID:     e.AccessKeyID,
+		SecretAccessKey: e.SecretAccessKey,
+		SessionToken:    e.SessionToken,
+		Region:          e.Region,
+	})
+	if creds.Region == "" {
+		// arn:aws:sns:<region>:<account>:<topic>
+		if parts := strings.Split(e.TopicARN, ":"); len(parts) > 3 {
+			creds.Region = parts[3]
+		}
+	}
+	if e.Endpoint == "" {
+		e.Endpoint = "https://sns." + creds.Region + ".amazonaws.com/"
+	}
+
+	params := url.Values{
+		"Action":   {"Publish"},
+		"Version":  {"2010-03-31"},
+		"TopicArn": {e.TopicARN},
+		"Message":  {e.Message},
+	}
+	if e.Subject != "" {
+		params.Set("Subject", e.Subject)
+	}
+	if e.GroupID != "" {
+		params.Set("MessageGroupId", e.GroupID)
+	}
+	if e.DeduplicationID != "" {
+		params.Set("MessageDeduplicationId", e.DeduplicationID)
+	}
+	names := make([]string, 0, len(e.Attributes))
+	for k := range e.Attributes {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for i, k := range names {
+		prefix := "MessageAttributes.entry." + strconv.Itoa(i+1) + "."
+		params.Set(prefix+"Name", k)
+		params.Set(prefix+"Value.DataType", "String")
+		params.Set(prefix+"Value.StringValue", e.Attributes[k])
+	}
+
+	start := time.Now()
+	result := Result{Executor: e}
+	l.Debugf("sns> Publish %s", e.TopicARN)
+	btes, err := executors.AWSQuery(testCaseContext.Context(), e.Endpoint, "sns", creds, params)
+	if err == nil {
+		var resp publishResponse
+		if err = xml.Unmarshal(btes, &resp); err == nil {
+			result.MessageID = resp.MessageID
+		}
+	}
+	if err != nil {
+		result.Err = err.Error()
+	}
+	result.Executor.SecretAccessKey = "****hidden****" // do not output secrets
+	result.Executor.SessionToken = ""
+
+	elapsed := time.Since(start)
+	result.TimeSeconds = elapsed.Seconds()
+	result.TimeHuman = elapsed.String()
+
+	return executors.Dump(result)
+}
@@ -0,0 +1,83 @@
+package sns
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/venom"
+)
+
+func TestRun(t *testing.T) {
+	var published url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("Message") == "slow" {
+			<-r.Context().Done()
+			return
+		}
+		// the region of the signature is the region of the topic
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") || !strings.Contains(r.Header.Get("Authorization"), "/eu-west-3/sns/aws4_request") {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, "<ErrorResponse><Error><Code>InvalidClientTokenId</Code></Error></ErrorResponse>")
+			return
+		}
+		published = r.Form
+		fmt.Fprint(w, "<PublishResponse><PublishResult><MessageId>b4cc5ae2</MessageId></PublishResult></PublishResponse>")
+	}))
+	defer srv.Close()
+	run := func(ctx context.Context, step venom.TestStep) venom.ExecutorResult {
+		step["topic_arn"] = "arn:aws:sns:eu-west-3:123456789012:orders.fifo"
+		step["endpoint"] = srv.URL
+		step["access_key_id"] = "AKID"
+		step["secret_access_key"] = "secret"
+		tcc := &venom.CommonTestCaseContext{}
+		tcc.SetContext(ctx)
+		res, err := Executor{}.Run(tcc, logrus.New(), step, "")
+		require.NoError(t, err)
+		return res
+	}
+
+	res := run(context.Background(), venom.TestStep{
+		"message":    `{"id": 1}`,
+		"subject":    "order",
+		"group_id":   "orders",
+		"attributes": map[string]string{"origin": "venom", "env": "staging"},
+	})
+	assert.Empty(t, res["result.err"])
+	assert.Equal(t, "b4cc5ae2", res["result.messageid"])
+	assert.Equal(t, "****hidden****", res["result.executor.secretaccesskey"])
+	assert.Equal(t, url.Values{
+		"Action":         {"Publish"},
+		"Version":        {"2010-03-31"},
+		"TopicArn":       {"arn:aws:sns:eu-west-3:123456789012:orders.fifo"},
+		"Message":        {`{"id": 1}`},
+		"Subject":        {"order"},
+		"MessageGroupId": {"orders"},
+		// the attributes are sorted by name
+		"MessageAttributes.entry.1.Name":              {"env"},
+		"MessageAttributes.entry.1.Value.DataType":    {"String"},
+		"MessageAttributes.entry.1.Value.StringValue": {"staging"},
+		"MessageAttributes.entry.2.Name":              {"origin"},
+		"MessageAttributes.entry.2.Value.DataType":    {"String"},
+		"MessageAttributes.entry.2.Value.StringValue": {"venom"},
+	}, published)
+
+	res = run(context.Background(), venom.TestStep{"message": "denied", "region": "us-east-1"})
+	assert.Contains(t, res["result.err"], "sns 403 Forbidden: <ErrorResponse>")
+
+	// the publish stops with the context of the step
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	res = run(ctx, venom.TestStep{"message": "slow"})
+	assert.Contains(t, res["result.err"], "context deadline exceeded")
+}
@@ -3,7 +3,7 @@ package sql
 import (
 	"fmt"
 	"io/ioutil"
-	"path"
+	"path/filepath"
 
 	"github.com/mitchellh/mapstructure"
 
@@ -17,6 +17,9 @@ import (
 	// Oracle
 	_ "github.com/sijms/go-ora"
 
+	// SQL Server
+	_ "github.com/denisenkom/go-mssqldb"
+
 	// ODBC
 	_ "github.com/alexbrainman/odbc"
 
@@ -89,7 +92,7 @@ func (e Executor) Run(testCaseContext venom.TestCaseContext, l venom.Logger, ste
 		}
 	} else if e.File != "" {
 		l.Debugf("loading SQL file from folder %s\n", e.File)
-		file := path.Join(workdir, e.File)
+		file := filepath.Join(workdir, e.File)
 		sbytes, errs := ioutil.ReadFile(file)
 		if errs != nil {
 			return nil, errs
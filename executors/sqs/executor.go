@@ -0,0 +1,275 @@
+package sqs
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/ovh/venom"
+	"github.com/ovh/venom/executors"
+)
+
+// Name of executor
+const Name = "sqs"
+
+const apiVersion = "2012-11-05"
+
+// New returns a new Executor
+func New() venom.Executor {
+	return &Executor{}
+}
+
+// Executor sends or receives messages on AWS SQS
+type Executor struct {
+	Operation       string            `json:"operation,omitempty" yaml:"operation,omitempty"`
+	QueueURL        string            `json:"queue_url,omitempty" yaml:"queue_url,omitempty" mapstructure:"queue_url"`
+	Body            string            `json:"body,omitempty" yaml:"body,omitempty"`
+	Attributes      map[string]string `json:"attributes,omitempty" yaml:"attributes,omitempty"`
+	GroupID         string            `json:"group_id,omitempty" yaml:"group_id,omitempty" mapstructure:"group_id"`
+	DeduplicationID string            `json:"deduplication_id,omitempty" yaml:"deduplication_id,omitempty" mapstructure:"deduplication_id"`
+	DelaySeconds    int               `json:"delay_seconds,omitempty" yaml:"delay_seconds,omitempty" mapstructure:"delay_seconds"`
+	MaxMessages     int               `json:"max_messages,omitempty" yaml:"max_messages,omitempty" mapstructure:"max_messages"`
+	WaitTimeout     int               `json:"wait_timeout,omitempty" yaml:"wait_timeout,omitempty" mapstructure:"wait_timeout"`
+	NoDelete        bool              `json:"no_delete,omitempty" yaml:"no_delete,omitempty" mapstructure:"no_delete"`
+	Region          string            `json:"region,omitempty" yaml:"region,omitempty"`
+	AccessKeyID     string            `json:"access_key_id,omitempty" yaml:"access_key_id,omitempty" mapstructure:"access_key_id"`
+	SecretAccessKey string            `json:"secret_access_key,omitempty" yaml:"secret_access_key,omitempty" mapstructure:"secret_access_key"`
+	SessionToken    string            `json:"session_token,omitempty" yaml:"session_token,omitempty" mapstructure:"session_token"`
+}
+
+// Message is a received message
+type Message struct {
+	ID               string            `json:"id,omitempty" yaml:"id,omitempty"`
+	Body             string            `json:"body,omitempty" yaml:"body,omitempty"`
+	BodyJSON         interface{}       `json:"bodyjson,omitempty" yaml:"bodyjson,omitempty"`
+	Attributes       map[string]string `json:"attributes,omitempty" yaml:"attributes,omitempty"`
+	SystemAttributes map[string]string `json:"systemattributes,omitempty" yaml:"systemattributes,omitempty"`
+}
+
+// Result represents a step result
+type Result struct {
+	Executor    Executor  `json:"executor,omitempty" yaml:"executor,omitempty"`
+	MessageID   string    `json:"messageid,omitempty" yaml:"messageid,omitempty"`
+	Messages    []Message `json:"messages,omitempty" yaml:"messages,omitempty"`
+	Count       int       `json:"count" yaml:"count"`
+	Err         string    `json:"err,omitempty" yaml:"err,omitempty"`
+	TimeSeconds float64   `json:"timeseconds,omitempty" yaml:"timeseconds,omitempty"`
+	TimeHuman   string    `json:"timehuman,omitempty" yaml:"timehuman,omitempty"`
+}
+
+type sendMessageResponse struct {
+	MessageID string `xml:"SendMessageResult>MessageId"`
+}
+
+type receiveMessageResponse struct {
+	Messages []struct {
+		MessageID     string `xml:"MessageId"`
+		ReceiptHandle string `xml:"ReceiptHandle"`
+		Body          string `xml:"Body"`
+		Attributes    []struct {
+			Name  string `xml:"Name"`
+			Value string `xml:"Value"`
+		} `xml:"Attribute"`
+		MessageAttributes []struct {
+			Name  string `xml:"Name"`
+			Value string `xml:"Value>StringValue"`
+		} `xml:"MessageAttribute"`
+	} `xml:"ReceiveMessageResult>Message"`
+}
+
+// ZeroValueResult return an empty implemtation of this executor result
+func (Executor) ZeroValueResult() venom.ExecutorResult {
+	r, _ := executors.Dump(Result{})
+	return r
+}
+
+// GetDefaultAssertions return default assertions for this executor
+func (Executor) GetDefaultAssertions() *venom.StepAssertions {
+	return &venom.StepAssertions{Assertions: []string{"result.err ShouldBeEmpty"}}
+}
+
+// Run execute TestStep
+func (Executor) Run(testCaseContext venom.TestCaseContext, l venom.Logger, step venom.TestStep, workdir string) (venom.ExecutorResult, error) {
+	var e Executor
+	if err := mapstructure.Decode(step, &e); err != nil {
+		return nil, err
+	}
+	if e.QueueURL == "" {
+		return nil, fmt.Errorf("queue_url is mandatory")
+	}
+	if e.MaxMessages <= 0 {
+		e.MaxMessages = 1
+	}
+	if e.WaitTimeout <= 0 {
+		e.WaitTimeout = 10
+	}
+
+	creds := executors.AWSCredentialsFromEnv(executors.AWSCredentials{
+		AccessKeyID:     e.AccessKeyID,
+		SecretAccessKey: e.SecretAccessKey,
+		SessionToken:    e.SessionToken,
+		Region:          e.Region,
+	})
+	if creds.Region == "" {
+		creds.Region = regionFromURL(e.QueueURL)
+	}
+
+	start := time.Now()
+	result := Result{Executor: e}
+	var err error
+	switch e.Operation {
+	case "send":
+		err = e.send(testCaseContext.Context(), l, creds, &result)
+	case "receive":
+		err = e.receive(testCaseContext.Context(), l, creds, &result)
+	default:
+		return nil, fmt.Errorf("operation must be send or receive")
+	}
+	if err != nil {
+		result.Err = err.Error()
+	}
+	result.Executor.SecretAccessKey = "****hidden****" // do not output secrets
+	result.Executor.SessionToken = ""
+
+	elapsed := time.Since(start)
+	result.TimeSeconds = elapsed.Seconds()
+	result.TimeHuman = elapsed.String()
+
+	return executors.Dump(result)
+}
+
+func (e Executor) send(ctx context.Context, l venom.Logger, creds executors.AWSCredentials, result *Result) error {
+	params := url.Values{
+		"Action":      {"SendMessage"},
+		"Version":     {apiVersion},
+		"MessageBody": {e.Body},
+	}
+	if e.GroupID != "" {
+		params.Set("MessageGroupId", e.GroupID)
+	}
+	if e.DeduplicationID != "" {
+		params.Set("MessageDeduplicationId", e.DeduplicationID)
+	}
+	if e.DelaySeconds > 0 {
+		params.Set("DelaySeconds", strconv.Itoa(e.DelaySeconds))
+	}
+	names := make([]string, 0, len(e.Attributes))
+	for k := range e.Attributes {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for i, k := range names {
+		prefix := "MessageAttribute." + strconv.Itoa(i+1) + "."
+		params.Set(prefix+"Name", k)
+		params.Set(prefix+"Value.DataType", "String")
+		params.Set(prefix+"Value.StringValue", e.Attributes[k])
+	}
+
+	l.Debugf("sqs> SendMessage %s", e.QueueURL)
+	btes, err := executors.AWSQuery(ctx, e.QueueURL, "sqs", creds, params)
+	if err != nil {
+		return err
+	}
+	var resp sendMessageResponse
+	if err := xml.Unmarshal(btes, &resp); err != nil {
+		return fmt.Errorf("unable to read response: %v", err)
+	}
+	result.MessageID = resp.MessageID
+	result.Count = 1
+	return nil
+}
+
+// receive receives messages until max_messages are received or wait_timeout is reached.
+// Received messages are deleted from the queue, unless no_delete is set.
+func (e Executor) receive(ctx context.Context, l venom.Logger, creds executors.AWSCredentials, result *Result) error {
+	deadline := time.Now().Add(time.Duration(e.WaitTimeout) * time.Second)
+	for {
+		remaining := int(time.Until(deadline).Seconds())
+		if remaining > 20 {
+			remaining = 20
+		}
+		if remaining < 0 {
+			remaining = 0
+		}
+		max := e.MaxMessages - len(result.Messages)
+		if max > 10 {
+			max = 10
+		}
+		params := url.Values{
+			"Action":                 {"ReceiveMessage"},
+			"Version":                {apiVersion},
+			"MaxNumberOfMessages":    {strconv.Itoa(max)},
+			"WaitTimeSeconds":        {strconv.Itoa(remaining)},
+			"AttributeName.1":        {"All"},
+			"MessageAttributeName.1": {"All"},
+		}
+		l.Debugf("sqs> ReceiveMessage %s", e.QueueURL)
+		btes, err := executors.AWSQuery(ctx, e.QueueURL, "sqs", creds, params)
+		if err != nil {
+			return err
+		}
+		var resp receiveMessageResponse
+		if err := xml.Unmarshal(btes, &resp); err != nil {
+			return fmt.Errorf("unable to read response: %v", err)
+		}
+
+		for _, m := range resp.Messages {
+			msg := Message{
+				ID:               m.MessageID,
+				Body:             m.Body,
+				Attributes:       map[string]string{},
+				SystemAttributes: map[string]string{},
+			}
+			for _, a := range m.MessageAttributes {
+				msg.Attributes[a.Name] = a.Value
+			}
+			for _, a := range m.Attributes {
+				msg.SystemAttributes[a.Name] = a.Value
+			}
+			var bodyJSON interface{}
+			if err := json.Unmarshal([]byte(m.Body), &bodyJSON); err == nil {
+				msg.BodyJSON = bodyJSON
+			}
+			result.Messages = append(result.Messages, msg)
+
+			if !e.NoDelete {
+				if _, err := executors.AWSQuery(ctx, e.QueueURL, "sqs", creds, url.Values{
+					"Action":        {"DeleteMessage"},
+					"Version":       {apiVersion},
+					"ReceiptHandle": {m.ReceiptHandle},
+				}); err != nil {
+					return err
+				}
+			}
+		}
+		result.Count = len(result.Messages)
+
+		if len(result.Messages) >= e.MaxMessages {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%d message(s) received after %d second(s), expected %d", len(result.Messages), e.WaitTimeout, e.MaxMessages)
+		}
+	}
+}
+
+// regionFromURL returns the region of a queue URL like https://sqs.eu-west-1.amazonaws.com/123456789012/myqueue
+func regionFromURL(queueURL string) string {
+	u, err := url.Parse(queueURL)
+	if err != nil {
+		return ""
+	}
+	parts := strings.Split(u.Hostname(), ".")
+	if len(parts) >= 4 && parts[0] == "sqs" {
+		return parts[1]
+	}
+	return ""
+}
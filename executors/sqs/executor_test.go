@@ -0,0 +1,131 @@
+package sqs
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/venom"
+)
+
+// fakeSQS is a queue served with the SQS query API
+type fakeSQS struct {
+	mutex    sync.Mutex
+	messages []fakeMessage
+	deleted  []string
+}
+
+type fakeMessage struct {
+	id, body, origin string
+}
+
+func (f *fakeSQS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") || r.URL.Path != "/123456789012/orders" {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, "<ErrorResponse><Error><Code>AccessDenied</Code></Error></ErrorResponse>")
+		return
+	}
+	r.ParseForm()
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	switch r.Form.Get("Action") {
+	case "SendMessage":
+		id := fmt.Sprintf("m%d", len(f.messages)+len(f.deleted)+1)
+		f.messages = append(f.messages, fakeMessage{id: id, body: r.Form.Get("MessageBody"), origin: r.Form.Get("MessageAttribute.1.Value.StringValue")})
+		fmt.Fprintf(w, "<SendMessageResponse><SendMessageResult><MessageId>%s</MessageId></SendMessageResult></SendMessageResponse>", id)
+	case "ReceiveMessage":
+		max, _ := strconv.Atoi(r.Form.Get("MaxNumberOfMessages"))
+		if len(f.messages) == 0 {
+			// the long polling waits for a message
+			f.mutex.Unlock()
+			time.Sleep(20 * time.Millisecond)
+			f.mutex.Lock()
+		}
+		fmt.Fprint(w, "<ReceiveMessageResponse><ReceiveMessageResult>")
+		for i, m := range f.messages {
+			if i == max {
+				break
+			}
+			fmt.Fprintf(w, "<Message><MessageId>%s</MessageId><ReceiptHandle>rh-%s</ReceiptHandle><Body>%s</Body>", m.id, m.id, m.body)
+			fmt.Fprint(w, "<Attribute><Name>ApproximateReceiveCount</Name><Value>1</Value></Attribute>")
+			if m.origin != "" {
+				fmt.Fprintf(w, "<MessageAttribute><Name>origin</Name><Value><StringValue>%s</StringValue></Value></MessageAttribute>", m.origin)
+			}
+			fmt.Fprint(w, "</Message>")
+		}
+		fmt.Fprint(w, "</ReceiveMessageResult></ReceiveMessageResponse>")
+	case "DeleteMessage":
+		handle := r.Form.Get("ReceiptHandle")
+		for i, m := range f.messages {
+			if "rh-"+m.id == handle {
+				f.messages = append(f.messages[:i], f.messages[i+1:]...)
+				f.deleted = append(f.deleted, m.id)
+				break
+			}
+		}
+		fmt.Fprint(w, "<DeleteMessageResponse/>")
+	}
+}
+
+func TestRun(t *testing.T) {
+	queue := &fakeSQS{}
+	srv := httptest.NewServer(queue)
+	defer srv.Close()
+	run := func(ctx context.Context, step venom.TestStep) venom.ExecutorResult {
+		step["queue_url"] = srv.URL + "/123456789012/orders"
+		step["region"] = "eu-west-1"
+		step["secret_access_key"] = "secret"
+		if step["access_key_id"] == nil {
+			step["access_key_id"] = "AKID"
+		}
+		tcc := &venom.CommonTestCaseContext{}
+		tcc.SetContext(ctx)
+		res, err := Executor{}.Run(tcc, logrus.New(), step, "")
+		require.NoError(t, err)
+		return res
+	}
+
+	res := run(context.Background(), venom.TestStep{"operation": "send", "body": `{"id": 1}`, "attributes": map[string]string{"origin": "venom"}})
+	assert.Empty(t, res["result.err"])
+	assert.Equal(t, "m1", res["result.messageid"])
+	assert.Equal(t, "****hidden****", res["result.executor.secretaccesskey"])
+	run(context.Background(), venom.TestStep{"operation": "send", "body": "second"})
+
+	res = run(context.Background(), venom.TestStep{"operation": "receive", "max_messages": 2})
+	assert.Empty(t, res["result.err"])
+	assert.Equal(t, 2, res["result.count"])
+	assert.Equal(t, 1.0, res["result.messages.messages0.bodyjson.id"])
+	assert.Equal(t, "venom", res["result.messages.messages0.attributes.origin"])
+	assert.Equal(t, "1", res["result.messages.messages0.systemattributes.approximatereceivecount"])
+	assert.Equal(t, "second", res["result.messages.messages1.body"])
+	assert.Equal(t, []string{"m1", "m2"}, queue.deleted)
+
+	res = run(context.Background(), venom.TestStep{"operation": "receive", "wait_timeout": 1})
+	assert.Equal(t, "0 message(s) received after 1 second(s), expected 1", res["result.err"])
+
+	// the receive stops with the context of the step
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	res = run(ctx, venom.TestStep{"operation": "receive", "wait_timeout": 30})
+	assert.Contains(t, res["result.err"], "context deadline exceeded")
+	assert.True(t, time.Since(start) < 5*time.Second, "the receive should stop with its step")
+
+	res = run(context.Background(), venom.TestStep{"operation": "send", "access_key_id": "other", "body": "denied"})
+	assert.Contains(t, res["result.err"], "sqs 403 Forbidden")
+}
+
+func TestRegionFromURL(t *testing.T) {
+	assert.Equal(t, "eu-west-1", regionFromURL("https://sqs.eu-west-1.amazonaws.com/123456789012/orders"))
+	assert.Equal(t, "", regionFromURL("http://localhost:9324/queue/orders"))
+}
@@ -0,0 +1,395 @@
+package stream
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxLiveSegments is the number of segments listed for a live without time shift buffer depth
+const maxLiveSegments = 1000
+
+type mpd struct {
+	Type                  string      `xml:"type,attr"`
+	Duration              string      `xml:"mediaPresentationDuration,attr"`
+	AvailabilityStartTime string      `xml:"availabilityStartTime,attr"`
+	TimeShiftBufferDepth  string      `xml:"timeShiftBufferDepth,attr"`
+	BaseURL               string      `xml:"BaseURL"`
+	Periods               []mpdPeriod `xml:"Period"`
+}
+
+type mpdPeriod struct {
+	Start          string             `xml:"start,attr"`
+	Duration       string             `xml:"duration,attr"`
+	BaseURL        string             `xml:"BaseURL"`
+	AdaptationSets []mpdAdaptationSet `xml:"AdaptationSet"`
+}
+
+type mpdAdaptationSet struct {
+	ContentType     string              `xml:"contentType,attr"`
+	MimeType        string              `xml:"mimeType,attr"`
+	Codecs          string              `xml:"codecs,attr"`
+	BaseURL         string              `xml:"BaseURL"`
+	SegmentTemplate *mpdSegmentTemplate `xml:"SegmentTemplate"`
+	SegmentList     *mpdSegmentList     `xml:"SegmentList"`
+	Representations []mpdRepresentation `xml:"Representation"`
+}
+
+type mpdRepresentation struct {
+	ID              string              `xml:"id,attr"`
+	Bandwidth       int64               `xml:"bandwidth,attr"`
+	Width           int                 `xml:"width,attr"`
+	Height          int                 `xml:"height,attr"`
+	MimeType        string              `xml:"mimeType,attr"`
+	Codecs          string              `xml:"codecs,attr"`
+	BaseURL         string              `xml:"BaseURL"`
+	SegmentTemplate *mpdSegmentTemplate `xml:"SegmentTemplate"`
+	SegmentList     *mpdSegmentList     `xml:"SegmentList"`
+	SegmentBase     *struct {
+		Initialization *struct {
+			Range string `xml:"range,attr"`
+		} `xml:"Initialization"`
+	} `xml:"SegmentBase"`
+}
+
+type mpdSegmentTemplate struct {
+	Media          string `xml:"media,attr"`
+	Initialization string `xml:"initialization,attr"`
+	StartNumber    *int64 `xml:"startNumber,attr"`
+	Timescale      int64  `xml:"timescale,attr"`
+	Duration       int64  `xml:"duration,attr"`
+	Timeline       *struct {
+		S []struct {
+			T *int64 `xml:"t,attr"`
+			D int64  `xml:"d,attr"`
+			R int64  `xml:"r,attr"`
+		} `xml:"S"`
+	} `xml:"SegmentTimeline"`
+}
+
+type mpdSegmentList struct {
+	Timescale      int64 `xml:"timescale,attr"`
+	Duration       int64 `xml:"duration,attr"`
+	Initialization *struct {
+		SourceURL string `xml:"sourceURL,attr"`
+		Range     string `xml:"range,attr"`
+	} `xml:"Initialization"`
+	SegmentURLs []struct {
+		Media      string `xml:"media,attr"`
+		MediaRange string `xml:"mediaRange,attr"`
+	} `xml:"SegmentURL"`
+}
+
+// readDASH returns the representations of a DASH stream, with their segments. The segments of a live, a dynamic
+// manifest, are the segments available at now, the representations of its last period only are read.
+func readDASH(u *url.URL, manifest []byte, now time.Time) ([]Variant, bool, error) {
+	var m mpd
+	if err := xml.Unmarshal(manifest, &m); err != nil {
+		return nil, false, err
+	}
+	if len(m.Periods) == 0 {
+		return nil, false, fmt.Errorf("the manifest has no period")
+	}
+	live := m.Type == "dynamic"
+	total, err := isoDuration(m.Duration)
+	if err != nil {
+		return nil, false, err
+	}
+	periods := m.Periods
+	if live {
+		periods = periods[len(periods)-1:]
+	}
+
+	base := resolveURL(u, m.BaseURL)
+	var variants []Variant
+	var periodStart float64
+	for i, p := range periods {
+		if p.Start != "" {
+			if periodStart, err = isoDuration(p.Start); err != nil {
+				return nil, false, err
+			}
+		}
+		periodDuration, err := isoDuration(p.Duration)
+		if err != nil {
+			return nil, false, err
+		}
+		if periodDuration == 0 {
+			// the last period lasts until the end of the presentation
+			if i == len(periods)-1 && total > periodStart {
+				periodDuration = total - periodStart
+			} else if i < len(periods)-1 && periods[i+1].Start != "" {
+				next, _ := isoDuration(periods[i+1].Start)
+				periodDuration = next - periodStart
+			}
+		}
+		tl := timeline{duration: periodDuration}
+		if live {
+			ast, err := time.Parse(time.RFC3339, m.AvailabilityStartTime)
+			if err != nil {
+				return nil, false, fmt.Errorf("invalid availabilityStartTime %q of a dynamic manifest", m.AvailabilityStartTime)
+			}
+			tl.live = true
+			tl.elapsed = now.Sub(ast).Seconds() - periodStart
+			if tl.buffer, err = isoDuration(m.TimeShiftBufferDepth); err != nil {
+				return nil, false, err
+			}
+		}
+
+		periodBase := resolveURL(base, p.BaseURL)
+		for _, as := range p.AdaptationSets {
+			asBase := resolveURL(periodBase, as.BaseURL)
+			for _, r := range as.Representations {
+				repBase := resolveURL(asBase, r.BaseURL)
+				v := Variant{
+					ID:        r.ID,
+					Type:      contentType(as, r),
+					Bandwidth: r.Bandwidth,
+					Codecs:    r.Codecs,
+					URL:       repBase.String(),
+				}
+				if v.Codecs == "" {
+					v.Codecs = as.Codecs
+				}
+				if r.Width > 0 && r.Height > 0 {
+					v.Resolution = fmt.Sprintf("%dx%d", r.Width, r.Height)
+				}
+				switch {
+				case r.SegmentTemplate != nil || as.SegmentTemplate != nil:
+					v.init, v.segments = tl.templateSegments(repBase, mergeTemplates(as.SegmentTemplate, r.SegmentTemplate), r)
+				case r.SegmentList != nil || as.SegmentList != nil:
+					list := r.SegmentList
+					if list == nil {
+						list = as.SegmentList
+					}
+					v.init, v.segments = listSegments(repBase, list)
+				default:
+					// the representation is a single file, its initialization range is enough to check it
+					s := segment{url: repBase.String(), duration: periodDuration}
+					if r.SegmentBase != nil && r.SegmentBase.Initialization != nil && r.SegmentBase.Initialization.Range != "" {
+						s.rng = "bytes=" + r.SegmentBase.Initialization.Range
+					}
+					v.segments = []segment{s}
+				}
+				variants = append(variants, v)
+			}
+		}
+		periodStart += periodDuration
+	}
+	return variants, live, nil
+}
+
+// timeline is the time of a period: its duration, and for a live the time elapsed since its start and the depth of
+// its time shift buffer, in seconds
+type timeline struct {
+	duration float64
+	live     bool
+	elapsed  float64
+	buffer   float64
+}
+
+// templateSegments returns the segments of a representation using a segment template, with a segment timeline or
+// with segments of the same duration
+func (tl timeline) templateSegments(base *url.URL, t mpdSegmentTemplate, r mpdRepresentation) (*segment, []segment) {
+	timescale := float64(t.Timescale)
+	if timescale <= 0 {
+		timescale = 1
+	}
+	number := int64(1)
+	if t.StartNumber != nil {
+		number = *t.StartNumber
+	}
+	var init *segment
+	if t.Initialization != "" {
+		init = &segment{url: resolve(base, expandTemplate(t.Initialization, r, 0, 0))}
+	}
+	newSegment := func(n, time int64, duration float64) segment {
+		return segment{url: resolve(base, expandTemplate(t.Media, r, n, time)), duration: duration}
+	}
+
+	var segments []segment
+	switch {
+	case t.Timeline != nil:
+		var time int64
+		for i, s := range t.Timeline.S {
+			if s.T != nil {
+				time = *s.T
+			}
+			repeat := s.R
+			if repeat < 0 && s.D > 0 {
+				// a negative repeat lasts until the next segment or the end of the period, for a live until now
+				end := tl.duration * timescale
+				if i+1 < len(t.Timeline.S) && t.Timeline.S[i+1].T != nil {
+					end = float64(*t.Timeline.S[i+1].T)
+				} else if tl.live {
+					end = tl.elapsed * timescale
+				}
+				repeat = int64(math.Ceil((end-float64(time))/float64(s.D))) - 1
+			}
+			for j := int64(0); j <= repeat; j++ {
+				segments = append(segments, newSegment(number, time, float64(s.D)/timescale))
+				number++
+				time += s.D
+			}
+		}
+	case t.Duration > 0:
+		duration := float64(t.Duration) / timescale
+		first, count := int64(0), int64(math.Ceil(tl.duration/duration))
+		if tl.live {
+			// the segments available are the segments complete at now, in the time shift buffer
+			count = int64(math.Floor(tl.elapsed / duration))
+			window := int64(maxLiveSegments)
+			if tl.buffer > 0 {
+				window = int64(math.Ceil(tl.buffer / duration))
+			}
+			if count > window {
+				first = count - window
+			}
+		}
+		for i := first; i < count; i++ {
+			d := duration
+			if !tl.live && tl.duration > 0 && float64(i+1)*duration > tl.duration {
+				d = tl.duration - float64(i)*duration
+			}
+			segments = append(segments, newSegment(number+i, t.Duration*i, d))
+		}
+	}
+	return init, segments
+}
+
+// listSegments returns the segments of a representation using a segment list
+func listSegments(base *url.URL, list *mpdSegmentList) (*segment, []segment) {
+	timescale := float64(list.Timescale)
+	if timescale <= 0 {
+		timescale = 1
+	}
+	var init *segment
+	if list.Initialization != nil {
+		init = &segment{url: resolve(base, list.Initialization.SourceURL)}
+		if list.Initialization.Range != "" {
+			init.rng = "bytes=" + list.Initialization.Range
+		}
+	}
+	var segments []segment
+	for _, s := range list.SegmentURLs {
+		seg := segment{url: resolve(base, s.Media), duration: float64(list.Duration) / timescale}
+		if s.MediaRange != "" {
+			seg.rng = "bytes=" + s.MediaRange
+		}
+		segments = append(segments, seg)
+	}
+	return init, segments
+}
+
+// mergeTemplates returns the segment template of a representation, its attributes not set are the attributes of the
+// template of its adaptation set
+func mergeTemplates(set, rep *mpdSegmentTemplate) mpdSegmentTemplate {
+	if set == nil {
+		return *rep
+	}
+	if rep == nil {
+		return *set
+	}
+	t := *rep
+	if t.Media == "" {
+		t.Media = set.Media
+	}
+	if t.Initialization == "" {
+		t.Initialization = set.Initialization
+	}
+	if t.StartNumber == nil {
+		t.StartNumber = set.StartNumber
+	}
+	if t.Timescale == 0 {
+		t.Timescale = set.Timescale
+	}
+	if t.Duration == 0 {
+		t.Duration = set.Duration
+	}
+	if t.Timeline == nil {
+		t.Timeline = set.Timeline
+	}
+	return t
+}
+
+var templateIdentifier = regexp.MustCompile(`\$(RepresentationID|Number|Time|Bandwidth|)(%0(\d+)d)?\$`)
+
+// expandTemplate replaces the identifiers of a segment template, $Number%05d$ is the number on 5 digits
+func expandTemplate(s string, r mpdRepresentation, number, time int64) string {
+	return templateIdentifier.ReplaceAllStringFunc(s, func(id string) string {
+		m := templateIdentifier.FindStringSubmatch(id)
+		var value int64
+		switch m[1] {
+		case "":
+			return "$"
+		case "RepresentationID":
+			return r.ID
+		case "Number":
+			value = number
+		case "Time":
+			value = time
+		case "Bandwidth":
+			value = r.Bandwidth
+		}
+		if m[3] != "" {
+			width, _ := strconv.Atoi(m[3])
+			return fmt.Sprintf("%0*d", width, value)
+		}
+		return strconv.FormatInt(value, 10)
+	})
+}
+
+// contentType returns the type of a representation, video, audio or text, from its mime type
+func contentType(as mpdAdaptationSet, r mpdRepresentation) string {
+	if as.ContentType != "" {
+		return as.ContentType
+	}
+	mime := r.MimeType
+	if mime == "" {
+		mime = as.MimeType
+	}
+	if i := strings.Index(mime, "/"); i > 0 {
+		mime = mime[:i]
+	}
+	if mime == "application" {
+		return "text"
+	}
+	return mime
+}
+
+func resolveURL(base *url.URL, ref string) *url.URL {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return base
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return base
+	}
+	return base.ResolveReference(r)
+}
+
+var isoDurationFormat = regexp.MustCompile(`^P(?:(\d+(?:\.\d+)?)D)?(?:T(?:(\d+(?:\.\d+)?)H)?(?:(\d+(?:\.\d+)?)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+// isoDuration returns the seconds of an ISO 8601 duration, PT1H30M12.5S, the durations in days at most
+func isoDuration(s string) (float64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	m := isoDurationFormat.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	var seconds float64
+	for i, unit := range []float64{86400, 3600, 60, 1} {
+		if m[i+1] != "" {
+			f, _ := strconv.ParseFloat(m[i+1], 64)
+			seconds += f * unit
+		}
+	}
+	return seconds, nil
+}
@@ -0,0 +1,207 @@
+package stream
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadDASH(t *testing.T) {
+	u, _ := url.Parse("https://origin.example.com/manifests/vod.mpd")
+	ast := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		manifest string
+		now      time.Time
+		live     bool
+		expected []Variant
+	}{
+		{
+			name: "segment template with a duration",
+			manifest: `<?xml version="1.0"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" type="static" mediaPresentationDuration="PT10S">
+  <BaseURL>https://cdn.example.com/vod/</BaseURL>
+  <Period>
+    <AdaptationSet contentType="video">
+      <SegmentTemplate timescale="1000" duration="4000" startNumber="1" media="$RepresentationID$/seg-$Number%03d$.m4s" initialization="$RepresentationID$/init.mp4"/>
+      <Representation id="v720" bandwidth="3000000" width="1280" height="720" codecs="avc1.64001f"/>
+    </AdaptationSet>
+    <AdaptationSet mimeType="audio/mp4" codecs="mp4a.40.2">
+      <BaseURL>audio/</BaseURL>
+      <Representation id="a128" bandwidth="128000">
+        <SegmentTemplate timescale="1" duration="5" startNumber="0" media="$Bandwidth$-$Number$.m4s"/>
+      </Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>`,
+			expected: []Variant{
+				{
+					ID: "v720", Type: "video", Bandwidth: 3000000, Resolution: "1280x720", Codecs: "avc1.64001f", URL: "https://cdn.example.com/vod/",
+					init: &segment{url: "https://cdn.example.com/vod/v720/init.mp4"},
+					segments: []segment{
+						{url: "https://cdn.example.com/vod/v720/seg-001.m4s", duration: 4},
+						{url: "https://cdn.example.com/vod/v720/seg-002.m4s", duration: 4},
+						// the last segment ends with the presentation
+						{url: "https://cdn.example.com/vod/v720/seg-003.m4s", duration: 2},
+					},
+				},
+				{
+					ID: "a128", Type: "audio", Bandwidth: 128000, Codecs: "mp4a.40.2", URL: "https://cdn.example.com/vod/audio/",
+					segments: []segment{
+						{url: "https://cdn.example.com/vod/audio/128000-0.m4s", duration: 5},
+						{url: "https://cdn.example.com/vod/audio/128000-1.m4s", duration: 5},
+					},
+				},
+			},
+		},
+		{
+			name: "segment timeline",
+			manifest: `<MPD type="static" mediaPresentationDuration="PT6S">
+  <Period>
+    <AdaptationSet contentType="video">
+      <SegmentTemplate timescale="90000" startNumber="5" media="chunk-$Time$-$Number$.m4s">
+        <SegmentTimeline><S t="0" d="180000" r="1"/><S d="90000"/></SegmentTimeline>
+      </SegmentTemplate>
+      <Representation id="hd"/>
+      <Representation id="sd">
+        <SegmentTemplate timescale="1" media="$RepresentationID$/$Time$.m4s">
+          <SegmentTimeline><S t="0" d="2" r="-1"/></SegmentTimeline>
+        </SegmentTemplate>
+      </Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>`,
+			expected: []Variant{
+				{
+					ID: "hd", Type: "video", URL: u.String(),
+					segments: []segment{
+						{url: "https://origin.example.com/manifests/chunk-0-5.m4s", duration: 2},
+						{url: "https://origin.example.com/manifests/chunk-180000-6.m4s", duration: 2},
+						{url: "https://origin.example.com/manifests/chunk-360000-7.m4s", duration: 1},
+					},
+				},
+				{
+					// the negative repeat lasts until the end of the period
+					ID: "sd", Type: "video", URL: u.String(),
+					segments: []segment{
+						{url: "https://origin.example.com/manifests/sd/0.m4s", duration: 2},
+						{url: "https://origin.example.com/manifests/sd/2.m4s", duration: 2},
+						{url: "https://origin.example.com/manifests/sd/4.m4s", duration: 2},
+					},
+				},
+			},
+		},
+		{
+			name: "segment list and segment base in several periods",
+			manifest: `<MPD type="static" mediaPresentationDuration="PT10S">
+  <Period start="PT0S">
+    <AdaptationSet mimeType="video/mp4">
+      <Representation id="list">
+        <SegmentList timescale="1000" duration="3000">
+          <Initialization sourceURL="list.mp4" range="0-699"/>
+          <SegmentURL media="list.mp4" mediaRange="700-1699"/>
+          <SegmentURL media="list.mp4" mediaRange="1700-2699"/>
+        </SegmentList>
+      </Representation>
+    </AdaptationSet>
+  </Period>
+  <Period start="PT6S">
+    <AdaptationSet mimeType="application/ttml+xml">
+      <Representation id="subtitles"><BaseURL>subtitles.mp4</BaseURL><SegmentBase><Initialization range="0-999"/></SegmentBase></Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>`,
+			expected: []Variant{
+				{
+					ID: "list", Type: "video", URL: u.String(),
+					init: &segment{url: "https://origin.example.com/manifests/list.mp4", rng: "bytes=0-699"},
+					segments: []segment{
+						{url: "https://origin.example.com/manifests/list.mp4", rng: "bytes=700-1699", duration: 3},
+						{url: "https://origin.example.com/manifests/list.mp4", rng: "bytes=1700-2699", duration: 3},
+					},
+				},
+				{
+					// the single file lasts until the end of the presentation
+					ID: "subtitles", Type: "text", URL: "https://origin.example.com/manifests/subtitles.mp4",
+					segments: []segment{{url: "https://origin.example.com/manifests/subtitles.mp4", rng: "bytes=0-999", duration: 4}},
+				},
+			},
+		},
+		{
+			name: "live",
+			manifest: `<MPD type="dynamic" availabilityStartTime="2024-03-01T10:00:00Z" timeShiftBufferDepth="PT10S">
+  <Period start="PT0S"><AdaptationSet contentType="video"><Representation id="old"/></AdaptationSet></Period>
+  <Period start="PT20S">
+    <AdaptationSet contentType="video">
+      <SegmentTemplate timescale="1" duration="2" media="$Number$.m4s"/>
+      <Representation id="live"/>
+    </AdaptationSet>
+  </Period>
+</MPD>`,
+			now:  ast.Add(61 * time.Second),
+			live: true,
+			expected: []Variant{
+				{
+					// 41s of the last period are available, the 5 segments of the time shift buffer are listed
+					ID: "live", Type: "video", URL: u.String(),
+					segments: []segment{
+						{url: "https://origin.example.com/manifests/16.m4s", duration: 2},
+						{url: "https://origin.example.com/manifests/17.m4s", duration: 2},
+						{url: "https://origin.example.com/manifests/18.m4s", duration: 2},
+						{url: "https://origin.example.com/manifests/19.m4s", duration: 2},
+						{url: "https://origin.example.com/manifests/20.m4s", duration: 2},
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			variants, live, err := readDASH(u, []byte(tt.manifest), tt.now)
+			require.NoError(t, err)
+			assert.Equal(t, tt.live, live)
+			assert.Equal(t, tt.expected, variants)
+		})
+	}
+}
+
+func TestReadDASH_errors(t *testing.T) {
+	u, _ := url.Parse("https://origin.example.com/vod.mpd")
+	tests := []struct {
+		name     string
+		manifest string
+		err      string
+	}{
+		{name: "malformed", manifest: `<MPD type="static"><Period>`, err: "XML syntax error on line 1: unexpected EOF"},
+		{name: "no period", manifest: `<MPD type="static" mediaPresentationDuration="PT10S"/>`, err: "the manifest has no period"},
+		{name: "invalid duration", manifest: `<MPD mediaPresentationDuration="10 seconds"><Period/></MPD>`, err: `invalid duration "10 seconds"`},
+		{name: "invalid period start", manifest: `<MPD><Period start="PT1X"/></MPD>`, err: `invalid duration "PT1X"`},
+		{name: "live without start", manifest: `<MPD type="dynamic"><Period/></MPD>`, err: `invalid availabilityStartTime "" of a dynamic manifest`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := readDASH(u, []byte(tt.manifest), time.Now())
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestExpandTemplate(t *testing.T) {
+	r := mpdRepresentation{ID: "v1", Bandwidth: 500000}
+	assert.Equal(t, "v1/500000/00042-9000-$.m4s", expandTemplate("$RepresentationID$/$Bandwidth$/$Number%05d$-$Time$-$$.m4s", r, 42, 9000))
+}
+
+func TestISODuration(t *testing.T) {
+	for s, expected := range map[string]float64{"": 0, "PT0S": 0, "PT1H30M12.5S": 5412.5, "P1DT1S": 86401, "PT2M": 120} {
+		d, err := isoDuration(s)
+		require.NoError(t, err, s)
+		assert.Equal(t, expected, d, s)
+	}
+	for _, s := range []string{"1H", "PT-1S", "P1Y", "PT1S5M"} {
+		_, err := isoDuration(s)
+		assert.Error(t, err, s)
+	}
+}
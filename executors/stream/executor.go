@@ -0,0 +1,332 @@
+package stream
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/ovh/venom"
+	"github.com/ovh/venom/executors"
+)
+
+// Name of executor
+const Name = "stream"
+
+// defaultSegments is the number of segments downloaded by variant
+const defaultSegments = 3
+
+// New returns a new Executor
+func New() venom.Executor {
+	return &Executor{}
+}
+
+// Executor fetches the manifest of a HLS or DASH stream and downloads some segments of its variants
+type Executor struct {
+	URL string `json:"url" yaml:"url"`
+	// Format is hls or dash, it is detected from the manifest when it is not set
+	Format string `json:"format,omitempty" yaml:"format,omitempty"`
+	// Segments is the number of segments downloaded by variant: the first ones of a vod, the last ones of a live
+	Segments int `json:"segments,omitempty" yaml:"segments,omitempty"`
+	// Variants is the number of variants checked, all of them when it is not set
+	Variants        int               `json:"variants,omitempty" yaml:"variants,omitempty"`
+	Headers         map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	IgnoreVerifySSL bool              `json:"ignore_verify_ssl,omitempty" yaml:"ignore_verify_ssl,omitempty" mapstructure:"ignore_verify_ssl"`
+}
+
+// Result represents a step result
+type Result struct {
+	Executor        Executor  `json:"executor,omitempty" yaml:"executor,omitempty"`
+	Format          string    `json:"format" yaml:"format"`
+	StatusCode      int       `json:"statuscode" yaml:"statuscode"`
+	Live            bool      `json:"live" yaml:"live"`
+	Duration        float64   `json:"duration" yaml:"duration"`
+	SegmentDuration float64   `json:"segmentduration" yaml:"segmentduration"`
+	VariantCount    int       `json:"variantcount" yaml:"variantcount"`
+	VariantsFailed  int       `json:"variantsfailed" yaml:"variantsfailed"`
+	Variants        []Variant `json:"variants,omitempty" yaml:"variants,omitempty"`
+	SegmentCount    int       `json:"segmentcount" yaml:"segmentcount"`
+	SegmentsChecked int       `json:"segmentschecked" yaml:"segmentschecked"`
+	SegmentsFailed  int       `json:"segmentsfailed" yaml:"segmentsfailed"`
+	Segments        []Segment `json:"segments,omitempty" yaml:"segments,omitempty"`
+	LatencyMin      float64   `json:"latencymin" yaml:"latencymin"`
+	LatencyAvg      float64   `json:"latencyavg" yaml:"latencyavg"`
+	LatencyMax      float64   `json:"latencymax" yaml:"latencymax"`
+	TimeSeconds     float64   `json:"timeseconds,omitempty" yaml:"timeseconds,omitempty"`
+	TimeHuman       string    `json:"timehuman,omitempty" yaml:"timehuman,omitempty"`
+}
+
+// Variant is a variant of the stream: a variant playlist or an alternative rendition of a HLS stream, a
+// representation of a DASH stream
+type Variant struct {
+	ID              string  `json:"id,omitempty" yaml:"id,omitempty"`
+	Type            string  `json:"type" yaml:"type"`
+	Bandwidth       int64   `json:"bandwidth,omitempty" yaml:"bandwidth,omitempty"`
+	Resolution      string  `json:"resolution,omitempty" yaml:"resolution,omitempty"`
+	Codecs          string  `json:"codecs,omitempty" yaml:"codecs,omitempty"`
+	URL             string  `json:"url" yaml:"url"`
+	StatusCode      int     `json:"statuscode,omitempty" yaml:"statuscode,omitempty"`
+	Error           string  `json:"error,omitempty" yaml:"error,omitempty"`
+	Duration        float64 `json:"duration" yaml:"duration"`
+	SegmentCount    int     `json:"segmentcount" yaml:"segmentcount"`
+	SegmentsChecked int     `json:"segmentschecked" yaml:"segmentschecked"`
+	SegmentsFailed  int     `json:"segmentsfailed" yaml:"segmentsfailed"`
+
+	segments []segment
+	init     *segment
+}
+
+// Segment is a segment downloaded, its latency is the time of its download in seconds
+type Segment struct {
+	Variant    int     `json:"variant" yaml:"variant"`
+	URL        string  `json:"url" yaml:"url"`
+	StatusCode int     `json:"statuscode" yaml:"statuscode"`
+	Size       int64   `json:"size" yaml:"size"`
+	Latency    float64 `json:"latency" yaml:"latency"`
+	Error      string  `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// segment is a segment listed by a manifest, rng is the value of its Range header when it is a byte range
+type segment struct {
+	url      string
+	rng      string
+	duration float64
+}
+
+// ZeroValueResult return an empty implemtation of this executor result
+func (Executor) ZeroValueResult() venom.ExecutorResult {
+	r, _ := executors.Dump(Result{})
+	return r
+}
+
+// GetDefaultAssertions return default assertions for this executor
+func (Executor) GetDefaultAssertions() *venom.StepAssertions {
+	return &venom.StepAssertions{Assertions: []string{
+		"result.statuscode ShouldEqual 200",
+		"result.variantsfailed ShouldEqual 0",
+		"result.segmentsfailed ShouldEqual 0",
+	}}
+}
+
+// Run execute TestStep
+func (Executor) Run(testCaseContext venom.TestCaseContext, l venom.Logger, step venom.TestStep, workdir string) (venom.ExecutorResult, error) {
+	var e Executor
+	if err := mapstructure.Decode(step, &e); err != nil {
+		return nil, err
+	}
+	if e.URL == "" {
+		return nil, fmt.Errorf("url is mandatory")
+	}
+	manifestURL, err := url.Parse(e.URL)
+	if err != nil || (manifestURL.Scheme != "http" && manifestURL.Scheme != "https") {
+		return nil, fmt.Errorf("invalid url %q, it must be a http or https url", e.URL)
+	}
+	if e.Format != "" && e.Format != "hls" && e.Format != "dash" {
+		return nil, fmt.Errorf("invalid format %q, it must be hls or dash", e.Format)
+	}
+	if e.Segments <= 0 {
+		e.Segments = defaultSegments
+	}
+
+	start := time.Now()
+	c := newClient(testCaseContext.Context(), e)
+	result := Result{Executor: e}
+	status, manifest, err := c.get(e.URL)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch the manifest %s: %v", e.URL, err)
+	}
+	result.StatusCode = status
+	if status != http.StatusOK {
+		l.Warnf("unable to fetch the manifest %s: %d", e.URL, status)
+		return executors.Dump(result)
+	}
+
+	result.Format = e.Format
+	if result.Format == "" {
+		result.Format = detectFormat(manifestURL, manifest)
+	}
+	var variants []Variant
+	switch result.Format {
+	case "hls":
+		variants, result.Live, err = readHLS(c, manifestURL, manifest)
+	case "dash":
+		variants, result.Live, err = readDASH(manifestURL, manifest, time.Now())
+	default:
+		err = fmt.Errorf("the manifest is neither a HLS playlist nor a DASH manifest")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the manifest %s: %v", e.URL, err)
+	}
+	if e.Variants > 0 && len(variants) > e.Variants {
+		variants = variants[:e.Variants]
+	}
+
+	var latency float64
+	for i := range variants {
+		v := &variants[i]
+		v.SegmentCount = len(v.segments)
+		for _, s := range v.segments {
+			v.Duration += s.duration
+			if s.duration > result.SegmentDuration {
+				result.SegmentDuration = s.duration
+			}
+		}
+		if v.Error != "" {
+			result.VariantsFailed++
+			l.Warnf("variant %s: %s", v.URL, v.Error)
+		}
+		for _, s := range v.checkedSegments(e.Segments, result.Live) {
+			seg := c.download(s)
+			seg.Variant = i
+			l.Debugf("segment %s: %d, %d bytes in %.3fs", seg.URL, seg.StatusCode, seg.Size, seg.Latency)
+			v.SegmentsChecked++
+			if seg.Error != "" {
+				v.SegmentsFailed++
+				l.Warnf("segment %s: %s", seg.URL, seg.Error)
+			}
+			if result.SegmentsChecked == 0 || seg.Latency < result.LatencyMin {
+				result.LatencyMin = seg.Latency
+			}
+			if seg.Latency > result.LatencyMax {
+				result.LatencyMax = seg.Latency
+			}
+			latency += seg.Latency
+			result.SegmentsChecked++
+			result.Segments = append(result.Segments, seg)
+		}
+		result.SegmentCount += v.SegmentCount
+		result.SegmentsFailed += v.SegmentsFailed
+	}
+	if result.SegmentsChecked > 0 {
+		result.LatencyAvg = latency / float64(result.SegmentsChecked)
+	}
+	// the duration of the stream is the duration of its first variant read
+	for _, v := range variants {
+		if v.SegmentCount > 0 {
+			result.Duration = v.Duration
+			break
+		}
+	}
+	result.Variants = variants
+	result.VariantCount = len(variants)
+
+	elapsed := time.Since(start)
+	result.TimeSeconds = elapsed.Seconds()
+	result.TimeHuman = elapsed.String()
+	return executors.Dump(result)
+}
+
+// checkedSegments returns the segments of the variant to download: its initialization segment, then the first
+// segments of a vod or the last segments of a live, the closest to the live edge
+func (v *Variant) checkedSegments(n int, live bool) []segment {
+	segments := v.segments
+	if len(segments) > n {
+		if live {
+			segments = segments[len(segments)-n:]
+		} else {
+			segments = segments[:n]
+		}
+	}
+	if v.init != nil {
+		segments = append([]segment{*v.init}, segments...)
+	}
+	return segments
+}
+
+// detectFormat returns the format of a manifest from its content, or from the extension of its url
+func detectFormat(u *url.URL, manifest []byte) string {
+	content := bytes.TrimSpace(bytes.TrimPrefix(manifest, []byte("\xef\xbb\xbf")))
+	switch {
+	case bytes.HasPrefix(content, []byte("#EXTM3U")):
+		return "hls"
+	case bytes.Contains(content, []byte("<MPD")):
+		return "dash"
+	case strings.HasSuffix(u.Path, ".m3u8"):
+		return "hls"
+	case strings.HasSuffix(u.Path, ".mpd"):
+		return "dash"
+	}
+	return ""
+}
+
+// client downloads the manifests, the playlists and the segments of the stream, until ctx is done
+type client struct {
+	ctx     context.Context
+	http    *http.Client
+	headers map[string]string
+}
+
+func newClient(ctx context.Context, e Executor) *client {
+	tr := &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: e.IgnoreVerifySSL},
+	}
+	if executors.SOCKS5Proxy != nil {
+		tr.Proxy = nil
+		tr.DialContext = executors.DialContext
+	}
+	return &client{ctx: ctx, http: &http.Client{Transport: tr}, headers: e.Headers}
+}
+
+func (c *client) request(u, rng string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(c.ctx)
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
+	if rng != "" {
+		req.Header.Set("Range", rng)
+	}
+	return c.http.Do(req)
+}
+
+// get returns the status code and the body of a manifest or a playlist
+func (c *client) get(u string) (int, []byte, error) {
+	resp, err := c.request(u, "")
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, nil, err
+	}
+	return resp.StatusCode, body, nil
+}
+
+// download downloads a segment, without keeping its content. The segment fails when it can't be downloaded
+// entirely or when it is empty.
+func (c *client) download(s segment) Segment {
+	seg := Segment{URL: s.url}
+	start := time.Now()
+	resp, err := c.request(s.url, s.rng)
+	if err != nil {
+		seg.Error = err.Error()
+		seg.Latency = time.Since(start).Seconds()
+		return seg
+	}
+	defer resp.Body.Close()
+	seg.StatusCode = resp.StatusCode
+	seg.Size, err = io.Copy(ioutil.Discard, resp.Body)
+	seg.Latency = time.Since(start).Seconds()
+	switch {
+	case err != nil:
+		seg.Error = err.Error()
+	case resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent:
+		seg.Error = resp.Status
+	case seg.Size == 0:
+		seg.Error = "empty segment"
+	}
+	return seg
+}
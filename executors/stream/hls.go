@@ -0,0 +1,191 @@
+package stream
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// hlsPlaylist is a HLS playlist: a master playlist lists variants, a media playlist lists segments
+type hlsPlaylist struct {
+	variants []Variant
+	segments []segment
+	init     *segment
+	live     bool
+}
+
+// readHLS returns the variants of a HLS stream. The media playlists of the variants of a master playlist are
+// fetched, a media playlist is the only variant of its stream.
+func readHLS(c *client, u *url.URL, manifest []byte) ([]Variant, bool, error) {
+	p, err := parseHLS(u, manifest)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(p.variants) == 0 {
+		return []Variant{{Type: "media", URL: u.String(), StatusCode: http.StatusOK, segments: p.segments, init: p.init}}, p.live, nil
+	}
+
+	var live bool
+	for i := range p.variants {
+		v := &p.variants[i]
+		status, content, err := c.get(v.URL)
+		v.StatusCode = status
+		switch {
+		case err != nil:
+			v.Error = err.Error()
+			continue
+		case status != http.StatusOK:
+			v.Error = fmt.Sprintf("unable to fetch the playlist: %d", status)
+			continue
+		}
+		vu, _ := url.Parse(v.URL)
+		media, err := parseHLS(vu, content)
+		if err != nil {
+			v.Error = err.Error()
+			continue
+		}
+		if len(media.variants) > 0 {
+			v.Error = "the playlist of a variant is a master playlist"
+			continue
+		}
+		v.segments, v.init = media.segments, media.init
+		live = live || media.live
+	}
+	return p.variants, live, nil
+}
+
+// parseHLS parses a master or a media playlist, the uris are resolved from the url of the playlist
+func parseHLS(u *url.URL, content []byte) (*hlsPlaylist, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	if !scanner.Scan() || strings.TrimSpace(scanner.Text()) != "#EXTM3U" {
+		return nil, fmt.Errorf("invalid HLS playlist, it must start with #EXTM3U")
+	}
+
+	p := &hlsPlaylist{live: true}
+	var (
+		variant  *Variant
+		duration float64
+		rng      string
+		// the byte range of a segment starts at the end of the previous one when its offset is not set
+		rangeEnd int64
+	)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		tag, value := line, ""
+		if i := strings.Index(line, ":"); i > 0 && strings.HasPrefix(line, "#") {
+			tag, value = line[:i], line[i+1:]
+		}
+		switch {
+		case line == "":
+		case tag == "#EXT-X-STREAM-INF":
+			attrs := hlsAttributes(value)
+			bandwidth, _ := strconv.ParseInt(attrs["BANDWIDTH"], 10, 64)
+			variant = &Variant{Type: "variant", Bandwidth: bandwidth, Resolution: attrs["RESOLUTION"], Codecs: attrs["CODECS"]}
+		case tag == "#EXT-X-MEDIA":
+			// the alternative renditions without uri are in the variant playlists
+			attrs := hlsAttributes(value)
+			if attrs["URI"] != "" {
+				p.variants = append(p.variants, Variant{ID: attrs["NAME"], Type: strings.ToLower(attrs["TYPE"]), URL: resolve(u, attrs["URI"])})
+			}
+		case tag == "#EXTINF":
+			duration, _ = strconv.ParseFloat(strings.TrimSpace(strings.SplitN(value, ",", 2)[0]), 64)
+		case tag == "#EXT-X-BYTERANGE":
+			var err error
+			if rng, rangeEnd, err = hlsByteRange(value, rangeEnd); err != nil {
+				return nil, err
+			}
+		case tag == "#EXT-X-MAP":
+			attrs := hlsAttributes(value)
+			if attrs["URI"] == "" {
+				return nil, fmt.Errorf("invalid #EXT-X-MAP without uri")
+			}
+			p.init = &segment{url: resolve(u, attrs["URI"])}
+			if attrs["BYTERANGE"] != "" {
+				var err error
+				if p.init.rng, _, err = hlsByteRange(attrs["BYTERANGE"], 0); err != nil {
+					return nil, err
+				}
+			}
+		case tag == "#EXT-X-ENDLIST", tag == "#EXT-X-PLAYLIST-TYPE" && value == "VOD":
+			p.live = false
+		case strings.HasPrefix(line, "#"):
+		case variant != nil:
+			variant.URL = resolve(u, line)
+			p.variants = append(p.variants, *variant)
+			variant = nil
+		default:
+			p.segments = append(p.segments, segment{url: resolve(u, line), rng: rng, duration: duration})
+			duration, rng = 0, ""
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(p.variants) == 0 && len(p.segments) == 0 {
+		return nil, fmt.Errorf("the playlist has neither variant nor segment")
+	}
+	return p, nil
+}
+
+// hlsAttributes parses the attributes of a tag, NAME=VALUE separated by commas, the quoted values can contain commas
+func hlsAttributes(s string) map[string]string {
+	attrs := map[string]string{}
+	for s != "" {
+		i := strings.Index(s, "=")
+		if i < 0 {
+			break
+		}
+		name := strings.TrimSpace(s[:i])
+		s = s[i+1:]
+		var value string
+		if strings.HasPrefix(s, `"`) {
+			end := strings.Index(s[1:], `"`)
+			if end < 0 {
+				value, s = s[1:], ""
+			} else {
+				value, s = s[1:end+1], s[end+2:]
+			}
+			if j := strings.Index(s, ","); j >= 0 {
+				s = s[j+1:]
+			} else {
+				s = ""
+			}
+		} else if j := strings.Index(s, ","); j >= 0 {
+			value, s = s[:j], s[j+1:]
+		} else {
+			value, s = s, ""
+		}
+		attrs[name] = strings.TrimSpace(value)
+	}
+	return attrs
+}
+
+// hlsByteRange returns the Range header of a byte range, length[@offset], and its end
+func hlsByteRange(s string, previousEnd int64) (string, int64, error) {
+	parts := strings.SplitN(s, "@", 2)
+	length, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || length <= 0 {
+		return "", 0, fmt.Errorf("invalid byte range %q", s)
+	}
+	offset := previousEnd
+	if len(parts) == 2 {
+		if offset, err = strconv.ParseInt(parts[1], 10, 64); err != nil || offset < 0 {
+			return "", 0, fmt.Errorf("invalid byte range %q", s)
+		}
+	}
+	return fmt.Sprintf("bytes=%d-%d", offset, offset+length-1), offset + length, nil
+}
+
+// resolve returns the url of a reference relative to the url of its manifest
+func resolve(base *url.URL, ref string) string {
+	r, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return base.ResolveReference(r).String()
+}
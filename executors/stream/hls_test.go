@@ -0,0 +1,188 @@
+package stream
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const hlsMediaPlaylist = `#EXTM3U
+#EXT-X-VERSION:7
+#EXT-X-TARGETDURATION:6
+#EXT-X-MAP:URI="init.mp4",BYTERANGE="720@0"
+#EXTINF:6.0,
+#EXT-X-BYTERANGE:1000@720
+main.mp4
+#EXTINF:5.5,the second segment
+#EXT-X-BYTERANGE:800
+main.mp4
+
+#EXTINF:4,
+https://other.example.com/seg3.mp4
+#EXT-X-ENDLIST
+`
+
+const hlsMasterPlaylist = `#EXTM3U
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aud",NAME="English",LANGUAGE="en",URI="audio/en.m3u8"
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aud",NAME="Default",DEFAULT=YES
+#EXT-X-STREAM-INF:BANDWIDTH=1280000,RESOLUTION=1280x720,CODECS="avc1.4d401f,mp4a.40.2",AUDIO="aud"
+720p/index.m3u8
+#EXT-X-STREAM-INF:BANDWIDTH=2560000,RESOLUTION=1920x1080
+/1080p/index.m3u8
+`
+
+func TestParseHLS(t *testing.T) {
+	u, _ := url.Parse("https://cdn.example.com/live/index.m3u8")
+	tests := []struct {
+		name     string
+		playlist string
+		expected hlsPlaylist
+	}{
+		{
+			name:     "media playlist with byte ranges",
+			playlist: hlsMediaPlaylist,
+			expected: hlsPlaylist{
+				init: &segment{url: "https://cdn.example.com/live/init.mp4", rng: "bytes=0-719"},
+				segments: []segment{
+					{url: "https://cdn.example.com/live/main.mp4", rng: "bytes=720-1719", duration: 6},
+					// the range without offset follows the previous one
+					{url: "https://cdn.example.com/live/main.mp4", rng: "bytes=1720-2519", duration: 5.5},
+					{url: "https://other.example.com/seg3.mp4", duration: 4},
+				},
+			},
+		},
+		{
+			name:     "live media playlist",
+			playlist: "\xef\xbb\xbf#EXTM3U\n#EXT-X-MEDIA-SEQUENCE:120\n#EXTINF:2,\nseg120.ts\n#EXTINF:2,\nseg121.ts\n",
+			expected: hlsPlaylist{
+				live: true,
+				segments: []segment{
+					{url: "https://cdn.example.com/live/seg120.ts", duration: 2},
+					{url: "https://cdn.example.com/live/seg121.ts", duration: 2},
+				},
+			},
+		},
+		{
+			name:     "vod media playlist without end",
+			playlist: "#EXTM3U\n#EXT-X-PLAYLIST-TYPE:VOD\n#EXTINF:10,\nseg.ts\n",
+			expected: hlsPlaylist{segments: []segment{{url: "https://cdn.example.com/live/seg.ts", duration: 10}}},
+		},
+		{
+			name:     "master playlist",
+			playlist: hlsMasterPlaylist,
+			expected: hlsPlaylist{
+				live: true,
+				variants: []Variant{
+					{ID: "English", Type: "audio", URL: "https://cdn.example.com/live/audio/en.m3u8"},
+					{Type: "variant", Bandwidth: 1280000, Resolution: "1280x720", Codecs: "avc1.4d401f,mp4a.40.2", URL: "https://cdn.example.com/live/720p/index.m3u8"},
+					{Type: "variant", Bandwidth: 2560000, Resolution: "1920x1080", URL: "https://cdn.example.com/1080p/index.m3u8"},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := parseHLS(u, []byte(tt.playlist))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, *p)
+		})
+	}
+}
+
+func TestParseHLS_errors(t *testing.T) {
+	u, _ := url.Parse("https://cdn.example.com/live/index.m3u8")
+	tests := []struct {
+		name     string
+		playlist string
+		err      string
+	}{
+		{name: "not a playlist", playlist: "<MPD/>", err: "invalid HLS playlist, it must start with #EXTM3U"},
+		{name: "empty", playlist: "", err: "invalid HLS playlist, it must start with #EXTM3U"},
+		{name: "no segment", playlist: "#EXTM3U\n#EXT-X-TARGETDURATION:6\n#EXT-X-ENDLIST\n", err: "the playlist has neither variant nor segment"},
+		{name: "invalid byte range", playlist: "#EXTM3U\n#EXTINF:6,\n#EXT-X-BYTERANGE:abc@0\nmain.mp4\n", err: `invalid byte range "abc@0"`},
+		{name: "invalid byte range offset", playlist: "#EXTM3U\n#EXTINF:6,\n#EXT-X-BYTERANGE:100@-1\nmain.mp4\n", err: `invalid byte range "100@-1"`},
+		{name: "map without uri", playlist: "#EXTM3U\n#EXT-X-MAP:BYTERANGE=\"720@0\"\n#EXTINF:6,\nmain.mp4\n", err: "invalid #EXT-X-MAP without uri"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseHLS(u, []byte(tt.playlist))
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestReadHLS(t *testing.T) {
+	playlists := map[string]string{
+		"/720p/index.m3u8":  hlsMediaPlaylist,
+		"/audio/index.m3u8": "#EXTM3U\n#EXTINF:4,\naudio1.aac\n",
+		"/nested.m3u8":      hlsMasterPlaylist,
+		"/broken.m3u8":      "not a playlist",
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, ok := playlists[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(p))
+	}))
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL + "/master.m3u8")
+	master := `#EXTM3U
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aud",NAME="en",URI="audio/index.m3u8"
+#EXT-X-STREAM-INF:BANDWIDTH=1280000
+720p/index.m3u8
+#EXT-X-STREAM-INF:BANDWIDTH=2560000
+1080p/index.m3u8
+#EXT-X-STREAM-INF:BANDWIDTH=640000
+nested.m3u8
+#EXT-X-STREAM-INF:BANDWIDTH=320000
+broken.m3u8
+`
+	variants, live, err := readHLS(newClient(context.Background(), Executor{}), u, []byte(master))
+	require.NoError(t, err)
+	// the audio rendition is live, it has no end
+	assert.True(t, live)
+	require.Len(t, variants, 5)
+
+	assert.Equal(t, http.StatusOK, variants[0].StatusCode)
+	assert.Empty(t, variants[0].Error)
+	assert.Equal(t, []segment{{url: srv.URL + "/audio/audio1.aac", duration: 4}}, variants[0].segments)
+
+	assert.Empty(t, variants[1].Error)
+	assert.Len(t, variants[1].segments, 3)
+	assert.Equal(t, &segment{url: srv.URL + "/720p/init.mp4", rng: "bytes=0-719"}, variants[1].init)
+
+	for i, expected := range map[int]string{
+		2: "unable to fetch the playlist: 404",
+		3: "the playlist of a variant is a master playlist",
+		4: "invalid HLS playlist, it must start with #EXTM3U",
+	} {
+		assert.Equal(t, expected, variants[i].Error, variants[i].URL)
+		assert.Empty(t, variants[i].segments)
+	}
+
+	// a media playlist is the only variant of its stream
+	variants, live, err = readHLS(nil, u, []byte(hlsMediaPlaylist))
+	require.NoError(t, err)
+	assert.False(t, live)
+	require.Len(t, variants, 1)
+	assert.Equal(t, "media", variants[0].Type)
+	assert.Equal(t, u.String(), variants[0].URL)
+	assert.Len(t, variants[0].segments, 3)
+}
+
+func TestHLSAttributes(t *testing.T) {
+	assert.Equal(t, map[string]string{
+		"BANDWIDTH":  "1280000",
+		"CODECS":     "avc1.4d401f,mp4a.40.2",
+		"RESOLUTION": "1280x720",
+		"NAME":       "Main, HD",
+	}, hlsAttributes(`BANDWIDTH=1280000,CODECS="avc1.4d401f,mp4a.40.2", RESOLUTION=1280x720,NAME="Main, HD"`))
+}
@@ -0,0 +1,114 @@
+package tls
+
+import (
+	cryptotls "crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"time"
+)
+
+// Connection describes a TLS connection: its version, its cipher suite and the certificates of the server, the
+// certificate of the server first
+type Connection struct {
+	Version      string        `json:"version,omitempty" yaml:"version,omitempty"`
+	CipherSuite  string        `json:"ciphersuite,omitempty" yaml:"ciphersuite,omitempty"`
+	ServerName   string        `json:"servername,omitempty" yaml:"servername,omitempty"`
+	Certificate  *Certificate  `json:"certificate,omitempty" yaml:"certificate,omitempty"`
+	Certificates []Certificate `json:"certificates,omitempty" yaml:"certificates,omitempty"`
+	OCSPStapled  bool          `json:"ocspstapled" yaml:"ocspstapled"`
+	// Revocation is the status of the OCSP response stapled by the server, if any
+	Revocation *Revocation `json:"revocation,omitempty" yaml:"revocation,omitempty"`
+}
+
+// Certificate describes a certificate of the server
+type Certificate struct {
+	Subject       string   `json:"subject,omitempty" yaml:"subject,omitempty"`
+	Issuer        string   `json:"issuer,omitempty" yaml:"issuer,omitempty"`
+	SANs          []string `json:"sans,omitempty" yaml:"sans,omitempty"`
+	SerialNumber  string   `json:"serialnumber,omitempty" yaml:"serialnumber,omitempty"`
+	NotBefore     string   `json:"notbefore,omitempty" yaml:"notbefore,omitempty"`
+	NotAfter      string   `json:"notafter,omitempty" yaml:"notafter,omitempty"`
+	DaysRemaining int      `json:"daysremaining" yaml:"daysremaining"`
+}
+
+var tlsVersions = map[uint16]string{
+	cryptotls.VersionTLS10: "TLS 1.0",
+	cryptotls.VersionTLS11: "TLS 1.1",
+	cryptotls.VersionTLS12: "TLS 1.2",
+	cryptotls.VersionTLS13: "TLS 1.3",
+}
+
+var cipherSuites = map[uint16]string{
+	cryptotls.TLS_RSA_WITH_RC4_128_SHA:                "TLS_RSA_WITH_RC4_128_SHA",
+	cryptotls.TLS_RSA_WITH_3DES_EDE_CBC_SHA:           "TLS_RSA_WITH_3DES_EDE_CBC_SHA",
+	cryptotls.TLS_RSA_WITH_AES_128_CBC_SHA:            "TLS_RSA_WITH_AES_128_CBC_SHA",
+	cryptotls.TLS_RSA_WITH_AES_256_CBC_SHA:            "TLS_RSA_WITH_AES_256_CBC_SHA",
+	cryptotls.TLS_RSA_WITH_AES_128_CBC_SHA256:         "TLS_RSA_WITH_AES_128_CBC_SHA256",
+	cryptotls.TLS_RSA_WITH_AES_128_GCM_SHA256:         "TLS_RSA_WITH_AES_128_GCM_SHA256",
+	cryptotls.TLS_RSA_WITH_AES_256_GCM_SHA384:         "TLS_RSA_WITH_AES_256_GCM_SHA384",
+	cryptotls.TLS_ECDHE_ECDSA_WITH_RC4_128_SHA:        "TLS_ECDHE_ECDSA_WITH_RC4_128_SHA",
+	cryptotls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA:    "TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA",
+	cryptotls.TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA:    "TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA",
+	cryptotls.TLS_ECDHE_RSA_WITH_RC4_128_SHA:          "TLS_ECDHE_RSA_WITH_RC4_128_SHA",
+	cryptotls.TLS_ECDHE_RSA_WITH_3DES_EDE_CBC_SHA:     "TLS_ECDHE_RSA_WITH_3DES_EDE_CBC_SHA",
+	cryptotls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA:      "TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA",
+	cryptotls.TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA:      "TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA",
+	cryptotls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA256: "TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA256",
+	cryptotls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA256:   "TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA256",
+	cryptotls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256:   "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256",
+	cryptotls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256: "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256",
+	cryptotls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384:   "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384",
+	cryptotls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384: "TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384",
+	cryptotls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305:    "TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305",
+	cryptotls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305:  "TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305",
+	cryptotls.TLS_AES_128_GCM_SHA256:                  "TLS_AES_128_GCM_SHA256",
+	cryptotls.TLS_AES_256_GCM_SHA384:                  "TLS_AES_256_GCM_SHA384",
+	cryptotls.TLS_CHACHA20_POLY1305_SHA256:            "TLS_CHACHA20_POLY1305_SHA256",
+}
+
+// NewConnection returns the description of a TLS connection, nil without connection state
+func NewConnection(state *cryptotls.ConnectionState) *Connection {
+	if state == nil {
+		return nil
+	}
+	t := &Connection{
+		Version:     tlsVersions[state.Version],
+		CipherSuite: cipherSuites[state.CipherSuite],
+		ServerName:  state.ServerName,
+		OCSPStapled: len(state.OCSPResponse) > 0,
+		Revocation:  staplingRevocation(state.OCSPResponse, state.PeerCertificates),
+	}
+	if t.Version == "" {
+		t.Version = fmt.Sprintf("0x%04x", state.Version)
+	}
+	if t.CipherSuite == "" {
+		t.CipherSuite = fmt.Sprintf("0x%04x", state.CipherSuite)
+	}
+	for _, c := range state.PeerCertificates {
+		t.Certificates = append(t.Certificates, newCertificate(c))
+	}
+	if len(t.Certificates) > 0 {
+		t.Certificate = &t.Certificates[0]
+	}
+	return t
+}
+
+func newCertificate(c *x509.Certificate) Certificate {
+	sans := append([]string{}, c.DNSNames...)
+	for _, ip := range c.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	sans = append(sans, c.EmailAddresses...)
+	for _, u := range c.URIs {
+		sans = append(sans, u.String())
+	}
+	return Certificate{
+		Subject:       c.Subject.String(),
+		Issuer:        c.Issuer.String(),
+		SANs:          sans,
+		SerialNumber:  c.SerialNumber.String(),
+		NotBefore:     c.NotBefore.UTC().Format(time.RFC3339),
+		NotAfter:      c.NotAfter.UTC().Format(time.RFC3339),
+		DaysRemaining: int(time.Until(c.NotAfter).Hours() / 24),
+	}
+}
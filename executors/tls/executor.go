@@ -0,0 +1,313 @@
+package tls
+
+import (
+	"bufio"
+	"context"
+	cryptotls "crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/ovh/venom"
+	"github.com/ovh/venom/executors"
+)
+
+// Name of executor
+const Name = "tls"
+
+// New returns a new Executor
+func New() venom.Executor {
+	return &Executor{}
+}
+
+// Executor connects to a TLS server and describes the connection and the certificates of the server
+type Executor struct {
+	Addr       string `json:"addr,omitempty" yaml:"addr,omitempty"`
+	ServerName string `json:"servername,omitempty" yaml:"servername,omitempty"`
+	StartTLS   string `json:"starttls,omitempty" yaml:"starttls,omitempty"`
+	// CheckRevocation asks the OCSP responder or the CRL distribution point when the server staples no OCSP response
+	CheckRevocation bool `json:"check_revocation,omitempty" yaml:"check_revocation,omitempty" mapstructure:"check_revocation"`
+}
+
+// Result represents a step result
+type Result struct {
+	Executor     Executor      `json:"executor,omitempty" yaml:"executor,omitempty"`
+	Version      string        `json:"version,omitempty" yaml:"version,omitempty"`
+	CipherSuite  string        `json:"ciphersuite,omitempty" yaml:"ciphersuite,omitempty"`
+	ServerName   string        `json:"servername,omitempty" yaml:"servername,omitempty"`
+	Certificate  *Certificate  `json:"certificate,omitempty" yaml:"certificate,omitempty"`
+	Certificates []Certificate `json:"certificates,omitempty" yaml:"certificates,omitempty"`
+	Verified     bool          `json:"verified" yaml:"verified"`
+	VerifyError  string        `json:"verifyerror,omitempty" yaml:"verifyerror,omitempty"`
+	OCSPStapled  bool          `json:"ocspstapled" yaml:"ocspstapled"`
+	Revocation   *Revocation   `json:"revocation,omitempty" yaml:"revocation,omitempty"`
+	TimeSeconds  float64       `json:"timeseconds,omitempty" yaml:"timeseconds,omitempty"`
+	TimeHuman    string        `json:"timehuman,omitempty" yaml:"timehuman,omitempty"`
+}
+
+// ZeroValueResult return an empty implemtation of this executor result
+func (Executor) ZeroValueResult() venom.ExecutorResult {
+	r, _ := executors.Dump(Result{})
+	return r
+}
+
+// GetDefaultAssertions return default assertions for this executor
+func (Executor) GetDefaultAssertions() *venom.StepAssertions {
+	return &venom.StepAssertions{Assertions: []string{"result.verified ShouldBeTrue"}}
+}
+
+// Run execute TestStep
+func (Executor) Run(testCaseContext venom.TestCaseContext, l venom.Logger, step venom.TestStep, workdir string) (venom.ExecutorResult, error) {
+	var e Executor
+	if err := mapstructure.Decode(step, &e); err != nil {
+		return nil, err
+	}
+	host, _, err := net.SplitHostPort(e.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid addr %q, it must be host:port: %v", e.Addr, err)
+	}
+	if e.ServerName == "" {
+		e.ServerName = host
+	}
+
+	start := time.Now()
+	// the certificates are verified once the handshake is done, to describe the invalid certificates too
+	config := &cryptotls.Config{ServerName: e.ServerName, InsecureSkipVerify: true}
+	state, err := e.handshake(testCaseContext.Context(), config)
+	if err != nil {
+		return nil, err
+	}
+	elapsed := time.Since(start)
+
+	conn := NewConnection(&state)
+	result := Result{
+		Executor:     e,
+		Version:      conn.Version,
+		CipherSuite:  conn.CipherSuite,
+		ServerName:   conn.ServerName,
+		Certificate:  conn.Certificate,
+		Certificates: conn.Certificates,
+		OCSPStapled:  conn.OCSPStapled,
+		Revocation:   conn.Revocation,
+		TimeSeconds:  elapsed.Seconds(),
+		TimeHuman:    elapsed.String(),
+	}
+	if err := verify(state.PeerCertificates, e.ServerName); err != nil {
+		result.VerifyError = err.Error()
+	} else {
+		result.Verified = true
+	}
+	if e.CheckRevocation && result.Revocation == nil {
+		result.Revocation = checkRevocation(state.PeerCertificates)
+	}
+	l.Debugf("tls %s: %s %s, verified:%t", e.Addr, result.Version, result.CipherSuite, result.Verified)
+
+	return executors.Dump(result)
+}
+
+// handshake connects to the server, upgrades the connection with starttls if needed, and returns the state of the
+// TLS connection. The connection is closed when ctx is done.
+func (e Executor) handshake(ctx context.Context, config *cryptotls.Config) (cryptotls.ConnectionState, error) {
+	var state cryptotls.ConnectionState
+	switch e.StartTLS {
+	case "", "smtp", "imap", "ldap":
+	default:
+		return state, fmt.Errorf("invalid starttls %q, it must be smtp, imap or ldap", e.StartTLS)
+	}
+	conn, err := executors.DialContext(ctx, "tcp", e.Addr)
+	if err != nil {
+		return state, err
+	}
+	defer conn.Close()
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	switch e.StartTLS {
+	case "smtp":
+		host, _, _ := net.SplitHostPort(e.Addr)
+		c, err := smtp.NewClient(conn, host)
+		if err != nil {
+			return state, e.contextError(ctx, err)
+		}
+		defer c.Close()
+		if err := c.StartTLS(config); err != nil {
+			return state, fmt.Errorf("smtp STARTTLS: %v", e.contextError(ctx, err))
+		}
+		state, _ = c.TLSConnectionState()
+		return state, nil
+	case "imap":
+		err = startTLSIMAP(conn)
+	case "ldap":
+		err = startTLSLDAP(conn)
+	}
+	if err != nil {
+		return state, e.contextError(ctx, err)
+	}
+	tlsConn := cryptotls.Client(conn, config)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return state, e.contextError(ctx, err)
+	}
+	return tlsConn.ConnectionState(), nil
+}
+
+// contextError returns the error of ctx if the connection has been closed because ctx is done
+func (e Executor) contextError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return fmt.Errorf("%s: %v", e.Addr, ctx.Err())
+	}
+	return err
+}
+
+// verify verifies the certificates of the server with the system roots, for the server name
+func verify(certificates []*x509.Certificate, serverName string) error {
+	if len(certificates) == 0 {
+		return fmt.Errorf("no certificate")
+	}
+	intermediates := x509.NewCertPool()
+	for _, c := range certificates[1:] {
+		intermediates.AddCert(c)
+	}
+	_, err := certificates[0].Verify(x509.VerifyOptions{DNSName: serverName, Intermediates: intermediates})
+	return err
+}
+
+// startTLSIMAP reads the greeting of the server and sends the STARTTLS command
+func startTLSIMAP(conn net.Conn) error {
+	r := bufio.NewReader(conn)
+	greeting, err := r.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("imap greeting: %v", err)
+	}
+	if !strings.HasPrefix(greeting, "* OK") {
+		return fmt.Errorf("imap greeting: %s", strings.TrimSpace(greeting))
+	}
+	if _, err := io.WriteString(conn, "venom STARTTLS\r\n"); err != nil {
+		return err
+	}
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("imap STARTTLS: %v", err)
+		}
+		if strings.HasPrefix(line, "venom ") {
+			if !strings.HasPrefix(line, "venom OK") {
+				return fmt.Errorf("imap STARTTLS: %s", strings.TrimSpace(line))
+			}
+			return nil
+		}
+	}
+}
+
+// startTLSLDAP sends the StartTLS extended operation, RFC 4511, and checks its result code
+func startTLSLDAP(conn net.Conn) error {
+	const oid = "1.3.6.1.4.1.1466.20037"
+	request := berTLV(0x30, append(berTLV(0x02, []byte{1}), berTLV(0x77, berTLV(0x80, []byte(oid)))...))
+	if _, err := conn.Write(request); err != nil {
+		return err
+	}
+
+	// LDAPMessage ::= SEQUENCE { messageID, ExtendedResponse ::= [APPLICATION 24] SEQUENCE { resultCode, ... } }
+	tag, message, err := readBER(bufio.NewReader(conn))
+	if err != nil || tag != 0x30 {
+		return fmt.Errorf("ldap StartTLS: invalid response: %v", err)
+	}
+	_, _, rest, err := parseBER(message)
+	if err != nil {
+		return fmt.Errorf("ldap StartTLS: invalid response: %v", err)
+	}
+	tag, response, _, err := parseBER(rest)
+	if err != nil || tag != 0x78 {
+		return fmt.Errorf("ldap StartTLS: invalid response: %v", err)
+	}
+	tag, code, _, err := parseBER(response)
+	if err != nil || tag != 0x0a || len(code) != 1 {
+		return fmt.Errorf("ldap StartTLS: invalid result code: %v", err)
+	}
+	if code[0] != 0 {
+		return fmt.Errorf("ldap StartTLS: result code %d", code[0])
+	}
+	return nil
+}
+
+// berTLV encodes a BER tag, length, value
+func berTLV(tag byte, value []byte) []byte {
+	var length []byte
+	switch n := len(value); {
+	case n < 0x80:
+		length = []byte{byte(n)}
+	case n < 0x100:
+		length = []byte{0x81, byte(n)}
+	default:
+		length = []byte{0x82, byte(n >> 8), byte(n)}
+	}
+	return append(append([]byte{tag}, length...), value...)
+}
+
+// readBER reads a BER tag, length, value
+func readBER(r *bufio.Reader) (byte, []byte, error) {
+	header, err := r.Peek(2)
+	if err != nil {
+		return 0, nil, err
+	}
+	size := 2
+	if header[1]&0x80 != 0 {
+		size += int(header[1] & 0x7f)
+	}
+	header, err = r.Peek(size)
+	if err != nil {
+		return 0, nil, err
+	}
+	_, length, err := berLength(header[1:])
+	if err != nil {
+		return 0, nil, err
+	}
+	tlv := make([]byte, size+length)
+	if _, err := io.ReadFull(r, tlv); err != nil {
+		return 0, nil, err
+	}
+	return tlv[0], tlv[size:], nil
+}
+
+// parseBER parses the first BER tag, length, value of b, and returns the bytes after it
+func parseBER(b []byte) (byte, []byte, []byte, error) {
+	if len(b) < 2 {
+		return 0, nil, nil, fmt.Errorf("truncated")
+	}
+	n, length, err := berLength(b[1:])
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	if len(b) < 1+n+length {
+		return 0, nil, nil, fmt.Errorf("truncated")
+	}
+	return b[0], b[1+n : 1+n+length], b[1+n+length:], nil
+}
+
+// berLength returns the size of the BER length at the start of b and its value
+func berLength(b []byte) (int, int, error) {
+	if b[0]&0x80 == 0 {
+		return 1, int(b[0]), nil
+	}
+	n := int(b[0] & 0x7f)
+	if n == 0 || n > 4 || len(b) < 1+n {
+		return 0, 0, fmt.Errorf("invalid length")
+	}
+	var length int
+	for _, c := range b[1 : 1+n] {
+		length = length<<8 | int(c)
+	}
+	return 1 + n, length, nil
+}
@@ -0,0 +1,71 @@
+package tls
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/venom"
+)
+
+func run(ctx context.Context, step venom.TestStep) (venom.ExecutorResult, error) {
+	tcc := &venom.CommonTestCaseContext{}
+	tcc.SetContext(ctx)
+	return Executor{}.Run(tcc, logrus.New(), step, "")
+}
+
+func TestRun(t *testing.T) {
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	res, err := run(context.Background(), venom.TestStep{"addr": srv.Listener.Addr().String(), "servername": "example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "TLS 1.3", res["result.version"])
+	assert.Equal(t, false, res["result.verified"])
+	assert.NotEmpty(t, res["result.verifyerror"])
+
+	_, err = run(context.Background(), venom.TestStep{"addr": srv.Listener.Addr().String(), "starttls": "pop3"})
+	assert.EqualError(t, err, `invalid starttls "pop3", it must be smtp, imap or ldap`)
+}
+
+func TestRun_cancelled(t *testing.T) {
+	// the server accepts the connections, and never answers
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		var conns []net.Conn
+		defer func() {
+			for _, c := range conns {
+				c.Close()
+			}
+		}()
+		for {
+			c, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conns = append(conns, c)
+		}
+	}()
+
+	for _, starttls := range []string{"", "smtp", "imap", "ldap"} {
+		t.Run("starttls "+starttls, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			start := time.Now()
+			_, err := run(ctx, venom.TestStep{"addr": listener.Addr().String(), "starttls": starttls})
+			require.Error(t, err)
+			assert.True(t, strings.HasSuffix(err.Error(), "context deadline exceeded"), err.Error())
+			assert.True(t, time.Since(start) < 5*time.Second, "the handshake should stop with its step")
+		})
+	}
+}
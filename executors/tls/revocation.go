@@ -0,0 +1,130 @@
+package tls
+
+import (
+	"bytes"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+
+	"github.com/ovh/venom/executors"
+)
+
+// Revocation is the revocation status of the certificate of the server
+type Revocation struct {
+	// Method is ocsp-stapling for the OCSP response stapled in the handshake, ocsp or crl
+	Method    string `json:"method,omitempty" yaml:"method,omitempty"`
+	Status    string `json:"status,omitempty" yaml:"status,omitempty"`
+	RevokedAt string `json:"revokedat,omitempty" yaml:"revokedat,omitempty"`
+	Err       string `json:"err,omitempty" yaml:"err,omitempty"`
+}
+
+// revocationTimeout is the timeout of the requests to the OCSP responders and the CRL distribution points
+const revocationTimeout = 10 * time.Second
+
+// staplingRevocation returns the status of the OCSP response stapled in the handshake, nil without stapled response
+func staplingRevocation(response []byte, certificates []*x509.Certificate) *Revocation {
+	if len(response) == 0 {
+		return nil
+	}
+	r := &Revocation{Method: "ocsp-stapling"}
+	if len(certificates) < 2 {
+		r.Status, r.Err = "unknown", "the issuer of the certificate is not sent by the server"
+		return r
+	}
+	r.parseOCSP(response, certificates[0], certificates[1])
+	return r
+}
+
+// checkRevocation asks the OCSP responder of the certificate of the server, or its CRL distribution point if it
+// has no OCSP responder, if the certificate is revoked
+func checkRevocation(certificates []*x509.Certificate) *Revocation {
+	if len(certificates) < 2 {
+		return &Revocation{Status: "unknown", Err: "the issuer of the certificate is not sent by the server"}
+	}
+	cert, issuer := certificates[0], certificates[1]
+	client := &http.Client{Timeout: revocationTimeout}
+	if executors.SOCKS5Proxy != nil {
+		client.Transport = &http.Transport{DialContext: executors.DialContext}
+	}
+
+	switch {
+	case len(cert.OCSPServer) > 0:
+		r := &Revocation{Method: "ocsp"}
+		req, err := ocsp.CreateRequest(cert, issuer, nil)
+		if err != nil {
+			r.Status, r.Err = "unknown", err.Error()
+			return r
+		}
+		resp, err := client.Post(cert.OCSPServer[0], "application/ocsp-request", bytes.NewReader(req))
+		if err != nil {
+			r.Status, r.Err = "unknown", err.Error()
+			return r
+		}
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			r.Status, r.Err = "unknown", err.Error()
+			return r
+		}
+		r.parseOCSP(body, cert, issuer)
+		return r
+	case len(cert.CRLDistributionPoints) > 0:
+		r := &Revocation{Method: "crl"}
+		if err := r.checkCRL(client, cert.CRLDistributionPoints[0], cert, issuer); err != nil {
+			r.Status, r.Err = "unknown", err.Error()
+		}
+		return r
+	}
+	return &Revocation{Status: "unknown", Err: "the certificate has no OCSP responder and no CRL distribution point"}
+}
+
+func (r *Revocation) parseOCSP(response []byte, cert, issuer *x509.Certificate) {
+	resp, err := ocsp.ParseResponseForCert(response, cert, issuer)
+	if err != nil {
+		r.Status, r.Err = "unknown", err.Error()
+		return
+	}
+	switch resp.Status {
+	case ocsp.Good:
+		r.Status = "good"
+	case ocsp.Revoked:
+		r.Status = "revoked"
+		r.RevokedAt = resp.RevokedAt.UTC().Format(time.RFC3339)
+	default:
+		r.Status = "unknown"
+	}
+}
+
+func (r *Revocation) checkCRL(client *http.Client, url string, cert, issuer *x509.Certificate) error {
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unable to download the CRL %s: %s", url, resp.Status)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	crl, err := x509.ParseCRL(body)
+	if err != nil {
+		return fmt.Errorf("invalid CRL %s: %v", url, err)
+	}
+	if err := issuer.CheckCRLSignature(crl); err != nil {
+		return fmt.Errorf("invalid signature of the CRL %s: %v", url, err)
+	}
+	r.Status = "good"
+	for _, revoked := range crl.TBSCertList.RevokedCertificates {
+		if revoked.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+			r.Status = "revoked"
+			r.RevokedAt = revoked.RevocationTime.UTC().Format(time.RFC3339)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package vault
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/ovh/venom"
+	"github.com/ovh/venom/executors"
+)
+
+// Name of executor
+const Name = "vault"
+
+// New returns a new Executor
+func New() venom.Executor {
+	return &Executor{}
+}
+
+// Executor reads, writes, lists, deletes secrets on Vault, or revokes a lease
+type Executor struct {
+	Address         string                 `json:"address,omitempty" yaml:"address,omitempty"`
+	Token           string                 `json:"token,omitempty" yaml:"token,omitempty"`
+	Namespace       string                 `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	Operation       string                 `json:"operation,omitempty" yaml:"operation,omitempty"`
+	Path            string                 `json:"path,omitempty" yaml:"path,omitempty"`
+	Data            map[string]interface{} `json:"data,omitempty" yaml:"data,omitempty"`
+	LeaseID         string                 `json:"lease_id,omitempty" yaml:"lease_id,omitempty" mapstructure:"lease_id"`
+	IgnoreVerifySSL bool                   `json:"ignore_verify_ssl,omitempty" yaml:"ignore_verify_ssl,omitempty" mapstructure:"ignore_verify_ssl"`
+}
+
+// Result represents a step result
+type Result struct {
+	Executor      Executor               `json:"executor,omitempty" yaml:"executor,omitempty"`
+	StatusCode    int                    `json:"statuscode,omitempty" yaml:"statuscode,omitempty"`
+	Data          map[string]interface{} `json:"data,omitempty" yaml:"data,omitempty"`
+	LeaseID       string                 `json:"leaseid,omitempty" yaml:"leaseid,omitempty"`
+	LeaseDuration int                    `json:"leaseduration,omitempty" yaml:"leaseduration,omitempty"`
+	Renewable     bool                   `json:"renewable,omitempty" yaml:"renewable,omitempty"`
+	Auth          map[string]interface{} `json:"auth,omitempty" yaml:"auth,omitempty"`
+	Warnings      []string               `json:"warnings,omitempty" yaml:"warnings,omitempty"`
+	Err           string                 `json:"err,omitempty" yaml:"err,omitempty"`
+	TimeSeconds   float64                `json:"timeseconds,omitempty" yaml:"timeseconds,omitempty"`
+	TimeHuman     string                 `json:"timehuman,omitempty" yaml:"timehuman,omitempty"`
+}
+
+// vaultResponse is the generic response of the Vault HTTP API
+type vaultResponse struct {
+	LeaseID       string                 `json:"lease_id"`
+	LeaseDuration int                    `json:"lease_duration"`
+	Renewable     bool                   `json:"renewable"`
+	Data          map[string]interface{} `json:"data"`
+	Auth          map[string]interface{} `json:"auth"`
+	Warnings      []string               `json:"warnings"`
+	Errors        []string               `json:"errors"`
+}
+
+// ZeroValueResult return an empty implemtation of this executor result
+func (Executor) ZeroValueResult() venom.ExecutorResult {
+	r, _ := executors.Dump(Result{Data: map[string]interface{}{}, Auth: map[string]interface{}{}})
+	return r
+}
+
+// GetDefaultAssertions return default assertions for this executor
+func (Executor) GetDefaultAssertions() *venom.StepAssertions {
+	return &venom.StepAssertions{Assertions: []string{"result.err ShouldBeEmpty"}}
+}
+
+// Run execute TestStep
+func (Executor) Run(testCaseContext venom.TestCaseContext, l venom.Logger, step venom.TestStep, workdir string) (venom.ExecutorResult, error) {
+	var e Executor
+	if err := mapstructure.Decode(step, &e); err != nil {
+		return nil, err
+	}
+	if e.Address == "" {
+		e.Address = os.Getenv("VAULT_ADDR")
+	}
+	if e.Token == "" {
+		e.Token = os.Getenv("VAULT_TOKEN")
+	}
+	if e.Address == "" {
+		return nil, fmt.Errorf("address is mandatory if VAULT_ADDR is not set")
+	}
+
+	var method, path string
+	var body interface{}
+	switch e.Operation {
+	case "read", "":
+		method, path = http.MethodGet, e.Path
+	case "list":
+		method, path = "LIST", e.Path
+	case "write":
+		method, path, body = http.MethodPut, e.Path, e.Data
+	case "delete":
+		method, path = http.MethodDelete, e.Path
+	case "revoke":
+		method, path, body = http.MethodPut, "sys/leases/revoke", map[string]string{"lease_id": e.LeaseID}
+	default:
+		return nil, fmt.Errorf("operation must be read, list, write, delete or revoke")
+	}
+	if path == "" {
+		return nil, fmt.Errorf("path is mandatory")
+	}
+
+	start := time.Now()
+	result := Result{Executor: e}
+	if err := e.do(l, method, path, body, &result); err != nil {
+		result.Err = err.Error()
+	}
+	result.Executor.Token = "****hidden****" // do not output token
+
+	elapsed := time.Since(start)
+	result.TimeSeconds = elapsed.Seconds()
+	result.TimeHuman = elapsed.String()
+
+	return executors.Dump(result)
+}
+
+func (e Executor) do(l venom.Logger, method, path string, body interface{}, result *Result) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return err
+		}
+	}
+
+	url := strings.TrimSuffix(e.Address, "/") + "/v1/" + strings.TrimPrefix(path, "/")
+	l.Debugf("vault> %s %s", method, url)
+	req, err := http.NewRequest(method, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	if e.Token != "" {
+		req.Header.Set("X-Vault-Token", e.Token)
+	}
+	if e.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", e.Namespace)
+	}
+
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: e.IgnoreVerifySSL},
+		Proxy:           http.ProxyFromEnvironment,
+	}}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	result.StatusCode = resp.StatusCode
+
+	btes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if len(btes) == 0 {
+		return nil
+	}
+
+	var vr vaultResponse
+	if err := json.Unmarshal(btes, &vr); err != nil {
+		return fmt.Errorf("unable to read vault response: %v", err)
+	}
+	if len(vr.Errors) > 0 {
+		return fmt.Errorf("%s", strings.Join(vr.Errors, ", "))
+	}
+	result.Data = vr.Data
+	result.LeaseID = vr.LeaseID
+	result.LeaseDuration = vr.LeaseDuration
+	result.Renewable = vr.Renewable
+	result.Auth = vr.Auth
+	result.Warnings = vr.Warnings
+	return nil
+}
@@ -0,0 +1,171 @@
+package waitfor
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"runtime"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/ovh/venom"
+	"github.com/ovh/venom/executors"
+)
+
+// Name of executor
+const Name = "waitfor"
+
+// New returns a new Executor
+func New() venom.Executor {
+	return &Executor{}
+}
+
+// Executor waits for a dependency to be ready. Only one of tcp, http or command can be used
+type Executor struct {
+	TCP             string `json:"tcp,omitempty" yaml:"tcp,omitempty"`
+	HTTP            string `json:"http,omitempty" yaml:"http,omitempty"`
+	StatusCode      int    `json:"statuscode,omitempty" yaml:"statuscode,omitempty"`
+	IgnoreVerifySSL bool   `json:"ignore_verify_ssl,omitempty" yaml:"ignore_verify_ssl,omitempty" mapstructure:"ignore_verify_ssl"`
+	Command         string `json:"command,omitempty" yaml:"command,omitempty"`
+	Timeout         int    `json:"wait_timeout,omitempty" yaml:"wait_timeout,omitempty" mapstructure:"wait_timeout"`
+	Interval        int    `json:"interval,omitempty" yaml:"interval,omitempty"`
+}
+
+// Result represents a step result
+type Result struct {
+	Executor    Executor `json:"executor,omitempty" yaml:"executor,omitempty"`
+	Ready       bool     `json:"ready" yaml:"ready"`
+	Probes      int      `json:"probes,omitempty" yaml:"probes,omitempty"`
+	Err         string   `json:"err,omitempty" yaml:"err,omitempty"`
+	TimeSeconds float64  `json:"timeseconds,omitempty" yaml:"timeseconds,omitempty"`
+	TimeHuman   string   `json:"timehuman,omitempty" yaml:"timehuman,omitempty"`
+}
+
+// ZeroValueResult return an empty implemtation of this executor result
+func (Executor) ZeroValueResult() venom.ExecutorResult {
+	r, _ := executors.Dump(Result{})
+	return r
+}
+
+// GetDefaultAssertions return default assertions for this executor
+func (Executor) GetDefaultAssertions() *venom.StepAssertions {
+	return &venom.StepAssertions{Assertions: []string{"result.ready ShouldBeTrue"}}
+}
+
+// Run execute TestStep
+func (Executor) Run(testCaseContext venom.TestCaseContext, l venom.Logger, step venom.TestStep, workdir string) (venom.ExecutorResult, error) {
+	var e Executor
+	if err := mapstructure.Decode(step, &e); err != nil {
+		return nil, err
+	}
+
+	// the probes and the wait stop when the step is stopped
+	ctx := testCaseContext.Context()
+	var probe func() error
+	switch {
+	case e.TCP != "" && e.HTTP == "" && e.Command == "":
+		probe = func() error { return e.probeTCP(ctx) }
+	case e.HTTP != "" && e.TCP == "" && e.Command == "":
+		probe = func() error { return e.probeHTTP(ctx) }
+	case e.Command != "" && e.TCP == "" && e.HTTP == "":
+		probe = func() error { return e.probeCommand(ctx, workdir) }
+	default:
+		return nil, fmt.Errorf("you have to use one of tcp, http or command")
+	}
+
+	if e.Timeout <= 0 {
+		e.Timeout = 30
+	}
+	if e.Interval <= 0 {
+		e.Interval = 1
+	}
+
+	result := Result{Executor: e}
+	start := time.Now()
+	deadline := start.Add(time.Duration(e.Timeout) * time.Second)
+	for {
+		result.Probes++
+		err := probe()
+		if err == nil {
+			result.Ready = true
+			result.Err = ""
+			break
+		}
+		result.Err = err.Error()
+		l.Debugf("waitfor probe %d: %v", result.Probes, err)
+		if time.Now().Add(time.Duration(e.Interval) * time.Second).After(deadline) {
+			result.Err = fmt.Sprintf("not ready after %d second(s): %s", e.Timeout, result.Err)
+			break
+		}
+		select {
+		case <-time.After(time.Duration(e.Interval) * time.Second):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	elapsed := time.Since(start)
+	result.TimeSeconds = elapsed.Seconds()
+	result.TimeHuman = elapsed.String()
+	l.Debugf("waitfor ready:%t after %d probe(s) in %s", result.Ready, result.Probes, result.TimeHuman)
+
+	return executors.Dump(result)
+}
+
+func (e Executor) probeTCP(ctx context.Context) error {
+	if e.Interval > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(e.Interval)*time.Second)
+		defer cancel()
+	}
+	conn, err := executors.DialContext(ctx, "tcp", e.TCP)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+func (e Executor) probeHTTP(ctx context.Context) error {
+	tr := &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: e.IgnoreVerifySSL},
+		Proxy:           http.ProxyFromEnvironment,
+	}
+	if executors.SOCKS5Proxy != nil {
+		tr.Proxy = nil
+		tr.DialContext = executors.DialContext
+	}
+	client := &http.Client{Timeout: time.Duration(e.Interval) * time.Second, Transport: tr}
+	req, err := http.NewRequest(http.MethodGet, e.HTTP, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if e.StatusCode != 0 && resp.StatusCode != e.StatusCode {
+		return fmt.Errorf("status code is %d, expected %d", resp.StatusCode, e.StatusCode)
+	}
+	if e.StatusCode == 0 && resp.StatusCode >= 400 {
+		return fmt.Errorf("status code is %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (e Executor) probeCommand(ctx context.Context, workdir string) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "PowerShell", "-ExecutionPolicy", "Bypass", "-Command", e.Command)
+	} else {
+		cmd = exec.CommandContext(ctx, "/bin/sh", "-c", e.Command)
+	}
+	cmd.Dir = workdir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%v: %s", err, out)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package web
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sclevine/agouti"
+)
+
+// defaultAxeScript is the axe-core script injected in the page when the step does not set one
+const defaultAxeScript = "https://cdnjs.cloudflare.com/ajax/libs/axe-core/4.0.2/axe.min.js"
+
+// Accessibility represents the options of the accessibility audit of the page, by axe-core
+type Accessibility struct {
+	Script  string   `json:"script,omitempty" yaml:"script,omitempty"` // url or path of axe.min.js
+	Tags    []string `json:"tags,omitempty" yaml:"tags,omitempty"`     // rules to run, wcag2a, wcag2aa, best-practice...
+	Timeout int64    `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
+// AccessibilityResult describes the accessibility violations of the page
+type AccessibilityResult struct {
+	Violations int         `json:"violations" yaml:"violations"`
+	Critical   int         `json:"critical" yaml:"critical"`
+	Serious    int         `json:"serious" yaml:"serious"`
+	Moderate   int         `json:"moderate" yaml:"moderate"`
+	Minor      int         `json:"minor" yaml:"minor"`
+	Rules      []Violation `json:"rules,omitempty" yaml:"rules,omitempty"`
+}
+
+// Violation is a rule of axe-core violated by some elements of the page
+type Violation struct {
+	ID     string `json:"id" yaml:"id"`
+	Impact string `json:"impact" yaml:"impact"`
+	Help   string `json:"help" yaml:"help"`
+	Nodes  int    `json:"nodes" yaml:"nodes"`
+}
+
+// audit injects axe-core in the page, runs it and returns the violations
+func (a Accessibility) audit(page *agouti.Page, workdir string) (*AccessibilityResult, error) {
+	source, err := a.readScript(workdir)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot read axe-core script: %s", err)
+	}
+
+	var loaded bool
+	if err := page.RunScript("if (typeof axe === 'undefined') { (0, eval)(source); } return typeof axe !== 'undefined';", map[string]interface{}{"source": source}, &loaded); err != nil {
+		return nil, fmt.Errorf("Cannot inject axe-core: %s", err)
+	}
+	if !loaded {
+		return nil, fmt.Errorf("Cannot inject axe-core: axe is not defined")
+	}
+
+	options := map[string]interface{}{}
+	if len(a.Tags) > 0 {
+		options["runOnly"] = map[string]interface{}{"type": "tag", "values": a.Tags}
+	}
+	// axe.run is asynchronous, its result is stored in the page and polled
+	run := `window.__venomAxe = null;
+axe.run(document, options).then(function(r) {
+	window.__venomAxe = {violations: r.violations.map(function(v) {
+		return {id: v.id, impact: v.impact || "", help: v.help, nodes: v.nodes.length};
+	})};
+}, function(e) {
+	window.__venomAxe = {error: String(e)};
+});`
+	if err := page.RunScript(run, map[string]interface{}{"options": options}, nil); err != nil {
+		return nil, fmt.Errorf("Cannot run axe-core: %s", err)
+	}
+
+	timeout := a.Timeout
+	if timeout <= 0 {
+		timeout = 30
+	}
+	deadline := time.Now().Add(time.Duration(timeout) * time.Second)
+	for {
+		var res *struct {
+			Violations []Violation `json:"violations"`
+			Error      string      `json:"error"`
+		}
+		if err := page.RunScript("return window.__venomAxe;", nil, &res); err != nil {
+			return nil, fmt.Errorf("Cannot get axe-core result: %s", err)
+		}
+		if res != nil {
+			if res.Error != "" {
+				return nil, fmt.Errorf("axe-core: %s", res.Error)
+			}
+			r := &AccessibilityResult{Violations: len(res.Violations), Rules: res.Violations}
+			for _, v := range res.Violations {
+				switch v.Impact {
+				case "critical":
+					r.Critical++
+				case "serious":
+					r.Serious++
+				case "moderate":
+					r.Moderate++
+				case "minor":
+					r.Minor++
+				}
+			}
+			return r, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("axe-core did not finish after %d seconds", timeout)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// readScript returns the source of axe-core, downloaded or read from a file relative to the workdir
+func (a Accessibility) readScript(workdir string) (string, error) {
+	script := a.Script
+	if script == "" {
+		script = defaultAxeScript
+	}
+	if strings.HasPrefix(script, "http://") || strings.HasPrefix(script, "https://") {
+		resp, err := http.Get(script)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("%s: %s", script, resp.Status)
+		}
+		btes, err := ioutil.ReadAll(resp.Body)
+		return string(btes), err
+	}
+	if !filepath.IsAbs(script) {
+		script = filepath.Join(workdir, script)
+	}
+	btes, err := ioutil.ReadFile(script)
+	return string(btes), err
+}
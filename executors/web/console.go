@@ -0,0 +1,41 @@
+package web
+
+import (
+	"fmt"
+
+	"github.com/sclevine/agouti"
+)
+
+// ConsoleResult describes the messages of the JavaScript console of the page
+type ConsoleResult struct {
+	Errors   int              `json:"errors" yaml:"errors"`
+	Warnings int              `json:"warnings" yaml:"warnings"`
+	Messages []ConsoleMessage `json:"messages,omitempty" yaml:"messages,omitempty"`
+}
+
+// ConsoleMessage is a message of the JavaScript console, the errors and the warnings only
+type ConsoleMessage struct {
+	Level   string `json:"level" yaml:"level"`
+	Message string `json:"message" yaml:"message"`
+}
+
+// readConsole returns the messages of the console logged since the last read
+func readConsole(page *agouti.Page) (*ConsoleResult, error) {
+	logs, err := page.ReadNewLogs("browser")
+	if err != nil {
+		return nil, fmt.Errorf("Cannot read the browser log: %s", err)
+	}
+	r := &ConsoleResult{}
+	for _, l := range logs {
+		switch l.Level {
+		case "SEVERE":
+			r.Errors++
+		case "WARNING":
+			r.Warnings++
+		default:
+			continue
+		}
+		r.Messages = append(r.Messages, ConsoleMessage{Level: l.Level, Message: l.Message})
+	}
+	return r, nil
+}
@@ -0,0 +1,272 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sclevine/agouti"
+
+	"github.com/ovh/venom"
+)
+
+// HAR describes the network requests of the page done during a step
+type HAR struct {
+	Requests       int   `json:"requests" yaml:"requests"`
+	FailedRequests int   `json:"failedrequests" yaml:"failedrequests"`
+	Bytes          int64 `json:"bytes" yaml:"bytes"`
+}
+
+// harEntry is an entry of a HAR 1.2 file, a request and its response
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	Comment         string      `json:"comment,omitempty"`
+
+	started time.Time
+	failed  bool
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	HeadersSize int64          `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int64          `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harContent struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// devtoolsEvent is a network event of the performance log of chrome
+type devtoolsEvent struct {
+	Message struct {
+		Method string `json:"method"`
+		Params struct {
+			RequestID string  `json:"requestId"`
+			Timestamp float64 `json:"timestamp"`
+			WallTime  float64 `json:"wallTime"`
+			Request   struct {
+				URL     string            `json:"url"`
+				Method  string            `json:"method"`
+				Headers map[string]string `json:"headers"`
+			} `json:"request"`
+			Response          *devtoolsResponse `json:"response"`
+			RedirectResponse  *devtoolsResponse `json:"redirectResponse"`
+			EncodedDataLength float64           `json:"encodedDataLength"`
+			ErrorText         string            `json:"errorText"`
+		} `json:"params"`
+	} `json:"message"`
+}
+
+type devtoolsResponse struct {
+	Status            int                    `json:"status"`
+	StatusText        string                 `json:"statusText"`
+	Headers           map[string]interface{} `json:"headers"`
+	MimeType          string                 `json:"mimeType"`
+	Protocol          string                 `json:"protocol"`
+	EncodedDataLength float64                `json:"encodedDataLength"`
+}
+
+// readHAREntries returns the requests of the page finished since the start of the session, in the order they
+// finished, from the performance log of chrome
+func readHAREntries(page *agouti.Page) ([]*harEntry, error) {
+	logs, err := page.ReadAllLogs("performance")
+	if err != nil {
+		return nil, fmt.Errorf("Cannot read the performance log: %s", err)
+	}
+	return parseHAREntries(logs), nil
+}
+
+// parseHAREntries builds the entries from the network events of the performance log
+func parseHAREntries(logs []agouti.Log) []*harEntry {
+
+	type pending struct {
+		entry *harEntry
+		start float64 // monotonic timestamp of the request, in seconds
+		resp  float64 // monotonic timestamp of the response, in seconds
+	}
+	requests := map[string]*pending{}
+	var entries []*harEntry
+	finish := func(p *pending, timestamp float64) {
+		if p.resp == 0 {
+			p.resp = timestamp
+		}
+		p.entry.Timings = harTimings{Wait: (p.resp - p.start) * 1000, Receive: (timestamp - p.resp) * 1000}
+		p.entry.Time = (timestamp - p.start) * 1000
+		p.entry.failed = p.entry.failed || p.entry.Response.Status >= 400
+		entries = append(entries, p.entry)
+	}
+
+	for _, l := range logs {
+		var event devtoolsEvent
+		if err := json.Unmarshal([]byte(l.Message), &event); err != nil {
+			continue
+		}
+		params := event.Message.Params
+		switch event.Message.Method {
+		case "Network.requestWillBeSent":
+			// a redirect reuses the id of the request, the redirect response finishes the previous request
+			if p, ok := requests[params.RequestID]; ok && params.RedirectResponse != nil {
+				p.entry.Response = newHARResponse(params.RedirectResponse)
+				p.entry.Response.RedirectURL = params.Request.URL
+				finish(p, params.Timestamp)
+			}
+			started := time.Unix(0, int64(params.WallTime*float64(time.Second)))
+			requests[params.RequestID] = &pending{
+				entry: &harEntry{
+					StartedDateTime: started.Format(time.RFC3339Nano),
+					Request:         newHARRequest(params.Request.Method, params.Request.URL, params.Request.Headers),
+					started:         started,
+				},
+				start: params.Timestamp,
+			}
+		case "Network.responseReceived":
+			if p, ok := requests[params.RequestID]; ok && params.Response != nil {
+				p.entry.Response = newHARResponse(params.Response)
+				p.entry.Request.HTTPVersion = p.entry.Response.HTTPVersion
+				p.resp = params.Timestamp
+			}
+		case "Network.loadingFinished":
+			if p, ok := requests[params.RequestID]; ok {
+				p.entry.Response.BodySize = int64(params.EncodedDataLength) - p.entry.Response.HeadersSize
+				if p.entry.Response.BodySize < 0 {
+					p.entry.Response.BodySize = int64(params.EncodedDataLength)
+				}
+				p.entry.Response.Content.Size = p.entry.Response.BodySize
+				finish(p, params.Timestamp)
+				delete(requests, params.RequestID)
+			}
+		case "Network.loadingFailed":
+			if p, ok := requests[params.RequestID]; ok {
+				p.entry.failed = true
+				p.entry.Comment = params.ErrorText
+				finish(p, params.Timestamp)
+				delete(requests, params.RequestID)
+			}
+		}
+	}
+	return entries
+}
+
+func newHARRequest(method, u string, headers map[string]string) harRequest {
+	r := harRequest{Method: method, URL: u, HTTPVersion: "HTTP/1.1", Cookies: []harNameValue{}, HeadersSize: -1}
+	for k, v := range headers {
+		r.Headers = append(r.Headers, harNameValue{Name: k, Value: v})
+	}
+	sort.Slice(r.Headers, func(i, j int) bool { return r.Headers[i].Name < r.Headers[j].Name })
+	r.QueryString = []harNameValue{}
+	if parsed, err := url.Parse(u); err == nil {
+		for k, values := range parsed.Query() {
+			for _, v := range values {
+				r.QueryString = append(r.QueryString, harNameValue{Name: k, Value: v})
+			}
+		}
+	}
+	if r.Headers == nil {
+		r.Headers = []harNameValue{}
+	}
+	return r
+}
+
+func newHARResponse(resp *devtoolsResponse) harResponse {
+	r := harResponse{
+		Status:      resp.Status,
+		StatusText:  resp.StatusText,
+		HTTPVersion: strings.ToUpper(resp.Protocol),
+		Cookies:     []harNameValue{},
+		Headers:     []harNameValue{},
+		Content:     harContent{MimeType: resp.MimeType},
+		HeadersSize: int64(resp.EncodedDataLength),
+	}
+	for k, v := range resp.Headers {
+		r.Headers = append(r.Headers, harNameValue{Name: k, Value: fmt.Sprintf("%v", v)})
+	}
+	sort.Slice(r.Headers, func(i, j int) bool { return r.Headers[i].Name < r.Headers[j].Name })
+	return r
+}
+
+// harStats returns the statistics of the requests
+func harStats(entries []*harEntry) *HAR {
+	h := &HAR{Requests: len(entries)}
+	for _, e := range entries {
+		if e.failed {
+			h.FailedRequests++
+		}
+		if e.Response.HeadersSize > 0 {
+			h.Bytes += e.Response.HeadersSize
+		}
+		if e.Response.BodySize > 0 {
+			h.Bytes += e.Response.BodySize
+		}
+	}
+	return h
+}
+
+// writeHAR writes the requests in a HAR file, ordered by start time
+func writeHAR(file string, entries []*harEntry) error {
+	sorted := append([]*harEntry{}, entries...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].started.Before(sorted[j].started) })
+	var har struct {
+		Log struct {
+			Version string `json:"version"`
+			Creator struct {
+				Name    string `json:"name"`
+				Version string `json:"version"`
+			} `json:"creator"`
+			Pages   []struct{}  `json:"pages"`
+			Entries []*harEntry `json:"entries"`
+		} `json:"log"`
+	}
+	har.Log.Version = "1.2"
+	har.Log.Creator.Name = "venom"
+	har.Log.Creator.Version = venom.Version
+	har.Log.Pages = []struct{}{}
+	har.Log.Entries = sorted
+	btes, err := json.MarshalIndent(har, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), os.FileMode(0755)); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, btes, 0644)
+}
@@ -3,6 +3,7 @@ package web
 import (
 	"fmt"
 	"io/ioutil"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -26,6 +27,10 @@ func New() venom.Executor {
 type Executor struct {
 	Action     Action `json:"action,omitempty" yaml:"action"`
 	Screenshot string `json:"screenshot,omitempty" yaml:"screenshot"`
+	HAR        string `json:"har,omitempty" yaml:"har"`
+	// Console reads the errors and the warnings of the JavaScript console logged since the last step reading them
+	Console       bool           `json:"console,omitempty" yaml:"console"`
+	Accessibility *Accessibility `json:"accessibility,omitempty" yaml:"accessibility"`
 }
 
 // Result represents a step result
@@ -39,6 +44,10 @@ type Result struct {
 	URL         string   `json:"url,omitempty" yaml:"url,omitempty"`
 	Text        string   `json:"text,omitempty" yaml:"text,omitempty"`
 	Value       string   `json:"value,omitempty" yaml:"value,omitempty"`
+	HAR         *HAR     `json:"har,omitempty" yaml:"har,omitempty"`
+
+	Console       *ConsoleResult       `json:"console,omitempty" yaml:"console,omitempty"`
+	Accessibility *AccessibilityResult `json:"accessibility,omitempty" yaml:"accessibility,omitempty"`
 }
 
 // ZeroValueResult return an empty implemtation of this executor result
@@ -103,6 +112,43 @@ func (Executor) Run(testCaseContext venom.TestCaseContext, l venom.Logger, step
 		result.URL = url
 	}
 
+	// describe the network requests of the step, and write all the requests of the testcase in the HAR file
+	if ctx.HAR {
+		entries, err := readHAREntries(ctx.Page)
+		if err != nil {
+			return nil, err
+		}
+		result.HAR = harStats(entries[ctx.HARRequests:])
+		ctx.HARRequests = len(entries)
+		if e.HAR != "" {
+			file := e.HAR
+			if !filepath.IsAbs(file) {
+				file = filepath.Join(workdir, file)
+			}
+			if err := writeHAR(file, entries); err != nil {
+				return nil, fmt.Errorf("Cannot write HAR file: %s", err)
+			}
+		}
+	} else if e.HAR != "" {
+		return nil, fmt.Errorf("Cannot write HAR file %s: %s is not enabled in the context", e.HAR, webctx.HAR)
+	}
+
+	if e.Console {
+		console, err := readConsole(ctx.Page)
+		if err != nil {
+			return nil, err
+		}
+		result.Console = console
+	}
+
+	if e.Accessibility != nil {
+		accessibility, err := e.Accessibility.audit(ctx.Page, workdir)
+		if err != nil {
+			return nil, err
+		}
+		result.Accessibility = accessibility
+	}
+
 	elapsed := time.Since(start)
 	result.TimeSeconds = elapsed.Seconds()
 	result.TimeHuman = fmt.Sprintf("%s", elapsed)
@@ -0,0 +1,36 @@
+package venom
+
+import (
+	"fmt"
+	"net"
+)
+
+// freePort returns a free TCP port of the local host. A port is not given again until it is released, the testcases
+// of parallel testsuites never get the same port.
+func (v *Venom) freePort() (int, error) {
+	v.freePortsMutex.Lock()
+	defer v.freePortsMutex.Unlock()
+	if v.freePorts == nil {
+		v.freePorts = map[int]bool{}
+	}
+	for i := 0; i < 100; i++ {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			return 0, fmt.Errorf("unable to find a free port: %v", err)
+		}
+		port := l.Addr().(*net.TCPAddr).Port
+		l.Close()
+		if !v.freePorts[port] {
+			v.freePorts[port] = true
+			return port, nil
+		}
+	}
+	return 0, fmt.Errorf("no free port found")
+}
+
+// releasePort releases a port given by freePort, once its testcase or its port-forward has ended
+func (v *Venom) releasePort(port int) {
+	v.freePortsMutex.Lock()
+	defer v.freePortsMutex.Unlock()
+	delete(v.freePorts, port)
+}
@@ -0,0 +1,58 @@
+package venom
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ovh/venom/history"
+)
+
+// saveHistory saves the results of the run in the history store given with --history
+func (v *Venom) saveHistory(tests Tests, elapsed time.Duration) error {
+	if v.HistoryStore == "" {
+		return nil
+	}
+	store, err := history.Open(v.HistoryStore)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	run := history.Run{
+		Start:       time.Now().Add(-elapsed),
+		GitSHA:      v.HistoryGitSHA,
+		Environment: v.HistoryEnv,
+	}
+	if run.GitSHA == "" {
+		run.GitSHA = gitSHA()
+	}
+	for _, ts := range tests.TestSuites {
+		for _, tc := range ts.TestCases {
+			duration, _ := strconv.ParseFloat(tc.Time, 64)
+			run.Results = append(run.Results, history.Result{
+				TestSuite: ts.Name,
+				TestCase:  tc.Name,
+				Status:    testCaseStatus(tc),
+				Duration:  duration,
+			})
+		}
+	}
+	return store.Save(run)
+}
+
+// gitSHA returns the commit of the CI, or of the git repository of the current directory
+func gitSHA() string {
+	for _, env := range []string{"GIT_COMMIT", "GITHUB_SHA", "CI_COMMIT_SHA"} {
+		if sha := os.Getenv(env); sha != "" {
+			return sha
+		}
+	}
+	out, err := exec.Command("git", "rev-parse", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
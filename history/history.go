@@ -0,0 +1,141 @@
+// Package history stores the results of the runs in a SQL database, SQLite or Postgres,
+// to follow the trend of the testcases between runs.
+//
+// The SQL drivers are not imported by this package: the program must import the sqlite3
+// (github.com/mattn/go-sqlite3) and postgres (github.com/lib/pq) drivers.
+package history
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Store is a database of the results of the runs
+type Store struct {
+	db     *sql.DB
+	driver string
+}
+
+// Run is a run of the testsuites, with its metadata
+type Run struct {
+	Start       time.Time
+	GitSHA      string
+	Environment string
+	Results     []Result
+}
+
+// Result is the result of a testcase: SUCCESS, FAILURE or SKIPPED, and its duration in seconds
+type Result struct {
+	TestSuite string
+	TestCase  string
+	Status    string
+	Duration  float64
+}
+
+// Entry is the result of a testcase during a run
+type Entry struct {
+	Result
+	Start       time.Time
+	GitSHA      string
+	Environment string
+}
+
+// Open opens the store of a DSN: postgres://... for Postgres, otherwise the path of a SQLite file
+// (sqlite://path is accepted). The tables are created if needed.
+func Open(dsn string) (*Store, error) {
+	s := &Store{driver: "sqlite3"}
+	switch {
+	case strings.HasPrefix(dsn, "postgres://"), strings.HasPrefix(dsn, "postgresql://"):
+		s.driver = "postgres"
+	case strings.HasPrefix(dsn, "sqlite://"):
+		dsn = strings.TrimPrefix(dsn, "sqlite://")
+	}
+	db, err := sql.Open(s.driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open the history store: %v", err)
+	}
+	s.db = db
+
+	id := "INTEGER PRIMARY KEY AUTOINCREMENT"
+	if s.driver == "postgres" {
+		id = "SERIAL PRIMARY KEY"
+	}
+	for _, query := range []string{
+		"CREATE TABLE IF NOT EXISTS venom_runs (id " + id + ", started_at TIMESTAMP NOT NULL, git_sha TEXT NOT NULL, environment TEXT NOT NULL)",
+		"CREATE TABLE IF NOT EXISTS venom_results (run_id INTEGER NOT NULL REFERENCES venom_runs(id), testsuite TEXT NOT NULL, testcase TEXT NOT NULL, status TEXT NOT NULL, duration DOUBLE PRECISION NOT NULL)",
+		"CREATE INDEX IF NOT EXISTS venom_results_testcase ON venom_results (testcase)",
+	} {
+		if _, err := db.Exec(query); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("unable to create the tables of the history store: %v", err)
+		}
+	}
+	return s, nil
+}
+
+// Close closes the store
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Save saves a run and the results of its testcases
+func (s *Store) Save(run Run) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // nolint
+
+	var id int64
+	query := "INSERT INTO venom_runs (started_at, git_sha, environment) VALUES ($1, $2, $3)"
+	if s.driver == "postgres" {
+		err = tx.QueryRow(query+" RETURNING id", run.Start.UTC(), run.GitSHA, run.Environment).Scan(&id)
+	} else {
+		var res sql.Result
+		if res, err = tx.Exec(query, run.Start.UTC(), run.GitSHA, run.Environment); err == nil {
+			id, err = res.LastInsertId()
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("unable to save the run: %v", err)
+	}
+
+	for _, r := range run.Results {
+		if _, err := tx.Exec("INSERT INTO venom_results (run_id, testsuite, testcase, status, duration) VALUES ($1, $2, $3, $4, $5)",
+			id, r.TestSuite, r.TestCase, r.Status, r.Duration); err != nil {
+			return fmt.Errorf("unable to save the result of %s: %v", r.TestCase, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// History returns the last results of a testcase, the most recent first. The testcase is its name, or
+// "<testsuite> / <testcase>" to only return the results of a testsuite.
+func (s *Store) History(testcase string, limit int) ([]Entry, error) {
+	query := `SELECT r.started_at, r.git_sha, r.environment, t.testsuite, t.testcase, t.status, t.duration
+FROM venom_results t JOIN venom_runs r ON r.id = t.run_id WHERE t.testcase = $1`
+	args := []interface{}{testcase}
+	if i := strings.Index(testcase, " / "); i >= 0 {
+		query += " AND t.testsuite = $2"
+		args = []interface{}{testcase[i+3:], testcase[:i]}
+	}
+	query += fmt.Sprintf(" ORDER BY r.started_at DESC, r.id DESC LIMIT %d", limit)
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the history of %s: %v", testcase, err)
+	}
+	defer rows.Close()
+
+	var entries []Entry
+	for rows.Next() {
+		var e Entry
+		if err := rows.Scan(&e.Start, &e.GitSHA, &e.Environment, &e.TestSuite, &e.TestCase, &e.Status, &e.Duration); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,49 @@
+package history
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "history")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	s, err := Open(filepath.Join(dir, "history.db"))
+	require.NoError(t, err)
+	defer s.Close()
+
+	start := time.Date(2020, 11, 1, 10, 0, 0, 0, time.UTC)
+	for i, status := range []string{"SUCCESS", "FAILURE", "SUCCESS"} {
+		require.NoError(t, s.Save(Run{
+			Start:       start.Add(time.Duration(i) * time.Hour),
+			GitSHA:      "sha" + string(rune('1'+i)),
+			Environment: "staging",
+			Results: []Result{
+				{TestSuite: "suite", TestCase: "login", Status: status, Duration: float64(i + 1)},
+				{TestSuite: "other", TestCase: "login", Status: "SUCCESS", Duration: 0.5},
+			},
+		}))
+	}
+
+	entries, err := s.History("suite / login", 2)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "sha3", entries[0].GitSHA)
+	assert.Equal(t, "SUCCESS", entries[0].Status)
+	assert.Equal(t, 3.0, entries[0].Duration)
+	assert.True(t, entries[0].Start.Equal(start.Add(2*time.Hour)))
+	assert.Equal(t, "FAILURE", entries[1].Status)
+
+	entries, err = s.History("login", 10)
+	require.NoError(t, err)
+	assert.Len(t, entries, 6)
+}
@@ -0,0 +1,90 @@
+package venom
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/acarl005/stripansi"
+	log "github.com/sirupsen/logrus"
+)
+
+// hookTestCase returns the testcase running the setup or the teardown steps of a testsuite. Its results are
+// available as {{.setup.<key>}} and {{.teardown.<key>}}, it is not reported.
+func hookTestCase(ts *TestSuite, name string, steps []TestStep) TestCase {
+	return TestCase{Name: name, Classname: ts.Filename, TestSteps: steps, hook: true}
+}
+
+// testCaseSteps returns the steps run by a testcase: the steps of before_each, its steps and the steps of after_each
+func (ts *TestSuite) testCaseSteps(tc *TestCase) []TestStep {
+	if tc.hook {
+		return tc.TestSteps
+	}
+	steps := append([]TestStep{}, ts.BeforeEach...)
+	steps = append(steps, tc.TestSteps...)
+	return append(steps, ts.AfterEach...)
+}
+
+// withHooks returns the testcases of the testsuite and the testcases of its hooks: setup, teardown, before_each and
+// after_each. Their steps share the slices of the testsuite, the steps modified in place are modified in the testsuite.
+func (ts *TestSuite) withHooks() []TestCase {
+	return append(append([]TestCase{}, ts.TestCases...),
+		hookTestCase(ts, "setup", ts.Setup),
+		hookTestCase(ts, "teardown", ts.Teardown),
+		hookTestCase(ts, "before_each", ts.BeforeEach),
+		hookTestCase(ts, "after_each", ts.AfterEach),
+	)
+}
+
+// runSetup runs the setup steps of the testsuite, all its testcases are in error if they fail
+func (v *Venom) runSetup(ctx context.Context, ts *TestSuite, l Logger) {
+	if len(ts.Setup) == 0 {
+		return
+	}
+	tc := hookTestCase(ts, "setup", ts.Setup)
+	v.runTestCase(ctx, ts, &tc, l)
+	if err := hookError(tc); err != nil {
+		log.Errorf("unable to set up testsuite %s: %v", ts.Name, err)
+		setupFailure(ts, err)
+	}
+}
+
+// runTeardown runs the teardown steps of the testsuite. If they fail, the testcases run are in error.
+func (v *Venom) runTeardown(ctx context.Context, ts *TestSuite, l Logger) {
+	if len(ts.Teardown) == 0 {
+		return
+	}
+	tc := hookTestCase(ts, "teardown", ts.Teardown)
+	v.runTestCase(ctx, ts, &tc, l)
+	err := hookError(tc)
+	if err == nil {
+		return
+	}
+	log.Errorf("unable to tear down testsuite %s: %v", ts.Name, err)
+	for i := range ts.TestCases {
+		tc := &ts.TestCases[i]
+		if len(tc.Skipped) > 0 || tc.Status == "quarantined" {
+			continue
+		}
+		// the testcase is counted in error instead of in failure
+		if len(tc.Errors) == 0 {
+			ts.Errors++
+			if len(tc.Failures) > 0 {
+				ts.Failures--
+			}
+		}
+		tc.Errors = append(tc.Errors, Failure{Value: RemoveNotPrintableChar(err.Error())})
+	}
+}
+
+// hookError returns the errors and the failures of the setup or the teardown, nil if it succeeded
+func hookError(tc TestCase) error {
+	var msgs []string
+	for _, f := range append(append([]Failure{}, tc.Errors...), tc.Failures...) {
+		msgs = append(msgs, strings.TrimSpace(stripansi.Strip(f.Value)))
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s failed: %s", tc.Name, strings.Join(msgs, "\n"))
+}
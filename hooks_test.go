@@ -0,0 +1,122 @@
+package venom
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// values returns the values of the steps run by the executor
+func (r *recordingExecutor) values() []string {
+	var values []string
+	for _, step := range r.steps {
+		values = append(values, fmt.Sprintf("%v", step["value"]))
+	}
+	return values
+}
+
+func TestProcess_hooks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hooks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	suite := `name: hooks
+setup:
+- type: recording
+  value: setup
+  vars:
+    token:
+      from: result.value
+teardown:
+- type: recording
+  value: teardown
+before_each:
+- type: recording
+  value: "before {{.venom.testcase}} {{.setup.token}}"
+after_each:
+- type: recording
+  value: "after {{.venom.testcase}}"
+testcases:
+- name: ok
+  steps:
+  - type: recording
+    value: ok
+- name: ko
+  steps:
+  - type: recording
+    value: ko
+    assertions:
+    - result.value ShouldEqual ok
+  - type: recording
+    value: not run
+`
+	filename := filepath.Join(dir, "hooks.yml")
+	if err := ioutil.WriteFile(filename, []byte(suite), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	exec := &recordingExecutor{}
+	v := New()
+	v.LogLevel = "disable"
+	v.PrintFunc = func(string, ...interface{}) (int, error) { return 0, nil }
+	v.RegisterExecutor("recording", exec)
+	v.RegisterTestCaseContext("default", &testContext{CommonTestCaseContext{Name: "default"}})
+	tests, err := v.Process(context.Background(), []string{filename}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"setup", "before ok setup", "ok", "after ok", "before ko setup", "ko", "after ko", "teardown"}
+	if !reflect.DeepEqual(expected, exec.values()) {
+		t.Errorf("expected the steps %v, got %v", expected, exec.values())
+	}
+	if tests.Total != 2 || tests.TotalOK != 1 || tests.TotalKO != 1 {
+		t.Errorf("expected 1 testcase ok and 1 ko, got %d ok and %d ko of %d", tests.TotalOK, tests.TotalKO, tests.Total)
+	}
+	// the steps of the hooks are not counted
+	if expected := (StatusCounts{Total: 3, OK: 1, KO: 1, Skipped: 1}); tests.StepsCounts != expected {
+		t.Errorf("expected steps counts %+v, got %+v", expected, tests.StepsCounts)
+	}
+}
+
+func TestRunTestSuite_hooksFailures(t *testing.T) {
+	exec := &recordingExecutor{}
+	v := New()
+	v.PrintFunc = func(string, ...interface{}) (int, error) { return 0, nil }
+	v.RegisterExecutor("recording", exec)
+	v.RegisterTestCaseContext("default", &testContext{CommonTestCaseContext{Name: "default"}})
+	ko := TestStep{"type": "recording", "value": "ko", "assertions": []interface{}{"result.value ShouldEqual ok"}}
+
+	// the testcases are in error when the setup fails, the teardown is run
+	ts := &TestSuite{Name: "setup", Templater: newTemplater(nil),
+		Setup:     []TestStep{ko},
+		Teardown:  []TestStep{{"type": "recording", "value": "teardown"}},
+		TestCases: []TestCase{{Name: "tc", TestSteps: []TestStep{{"type": "recording", "value": "tc"}}}},
+	}
+	v.runTestSuite(context.Background(), ts)
+	if !reflect.DeepEqual([]string{"ko", "teardown"}, exec.values()) {
+		t.Errorf("expected the setup and the teardown to run, got %v", exec.values())
+	}
+	if ts.Errors != 1 || len(ts.TestCases[0].Errors) != 1 {
+		t.Errorf("expected the testcase in error, got %d errors: %v", ts.Errors, ts.TestCases[0].Errors)
+	}
+
+	// the testcases run are in error when the teardown fails
+	ts = &TestSuite{Name: "teardown", Templater: newTemplater(nil),
+		Teardown: []TestStep{ko},
+		TestCases: []TestCase{
+			{Name: "ok", TestSteps: []TestStep{{"type": "recording", "value": "ok"}}},
+			{Name: "ko", TestSteps: []TestStep{ko}},
+		},
+	}
+	v.runTestSuite(context.Background(), ts)
+	if ts.Errors != 2 || ts.Failures != 0 {
+		t.Errorf("expected 2 testcases in error, got %d errors and %d failures", ts.Errors, ts.Failures)
+	}
+}
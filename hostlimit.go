@@ -0,0 +1,119 @@
+package venom
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// hostLimiter caps the number of steps running at the same time on a host, for all the testsuites
+type hostLimiter struct {
+	mutex      sync.Mutex
+	limits     map[string]int
+	semaphores map[string]chan struct{}
+}
+
+// parseHostConcurrency parses limits like api.example.com=2. A host can be a hostname, a host:port,
+// a wildcard like *.example.com or * for all the hosts. Each host has its own limit.
+func parseHostConcurrency(limits []string) (*hostLimiter, error) {
+	h := &hostLimiter{limits: map[string]int{}, semaphores: map[string]chan struct{}{}}
+	for _, limit := range limits {
+		if limit == "" {
+			continue
+		}
+		i := strings.LastIndex(limit, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid host concurrency %q, expected something like api.example.com=2", limit)
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(limit[i+1:]))
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid host concurrency %q, expected something like api.example.com=2", limit)
+		}
+		h.limits[strings.ToLower(strings.TrimSpace(limit[:i]))] = n
+	}
+	if len(h.limits) == 0 {
+		return nil, nil
+	}
+	return h, nil
+}
+
+// limit returns the limit of the host, the most specific one is used
+func (h *hostLimiter) limit(host string) (string, int) {
+	hostname := host
+	if hn, _, err := net.SplitHostPort(host); err == nil {
+		hostname = hn
+	}
+	for _, k := range []string{host, hostname} {
+		if n, ok := h.limits[k]; ok {
+			return k, n
+		}
+	}
+	var pattern string
+	for k := range h.limits {
+		if strings.HasPrefix(k, "*.") && strings.HasSuffix(hostname, k[1:]) && len(k) > len(pattern) {
+			pattern = k
+		}
+	}
+	if pattern == "" {
+		pattern = "*"
+	}
+	n, ok := h.limits[pattern]
+	if !ok {
+		return "", 0
+	}
+	return hostname, n
+}
+
+// acquire waits for a slot on the host, and returns the function releasing it. It returns an error
+// if the context is done before
+func (h *hostLimiter) acquire(ctx context.Context, host string) (func(), error) {
+	key, n := h.limit(strings.ToLower(host))
+	if n == 0 {
+		return func() {}, nil
+	}
+	h.mutex.Lock()
+	sem, ok := h.semaphores[key]
+	if !ok {
+		sem = make(chan struct{}, n)
+		h.semaphores[key] = sem
+	}
+	h.mutex.Unlock()
+
+	select {
+	case sem <- struct{}{}:
+		return func() { <-sem }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// stepHost returns the host targeted by a step: the host of its url attribute (http, grpc...)
+// or its host attribute (ssh, smtp...)
+func stepHost(step TestStep) string {
+	if u, ok := step["url"].(string); ok && u != "" {
+		if !strings.Contains(u, "://") {
+			// host:port, for grpc
+			return strings.SplitN(u, "/", 2)[0]
+		}
+		if parsed, err := url.Parse(u); err == nil {
+			return parsed.Host
+		}
+	}
+	if h, ok := step["host"].(string); ok {
+		return h
+	}
+	return ""
+}
+
+// acquireHost waits until the step can run on its host, and returns the function to call once it is done
+func (v *Venom) acquireHost(ctx context.Context, step TestStep) (func(), error) {
+	host := stepHost(step)
+	if v.hostLimiter == nil || host == "" {
+		return func() {}, nil
+	}
+	return v.hostLimiter.acquire(ctx, host)
+}
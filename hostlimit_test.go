@@ -0,0 +1,98 @@
+package venom
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStepHost(t *testing.T) {
+	tests := map[string]TestStep{
+		"api.example.com:8443": {"type": "http", "url": "https://api.example.com:8443/v1"},
+		"localhost:50051":      {"type": "grpc", "url": "localhost:50051"},
+		"ssh.example.com":      {"type": "ssh", "host": "ssh.example.com"},
+		"":                     {"type": "exec", "script": "echo"},
+	}
+	for expected, step := range tests {
+		if got := stepHost(step); got != expected {
+			t.Errorf("expected %q, got %q", expected, got)
+		}
+	}
+}
+
+func TestHostLimiter_limit(t *testing.T) {
+	h, err := parseHostConcurrency([]string{"api.example.com=1", "db.example.com:5432=2", "*.example.com=3", "*=4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		host string
+		key  string
+		n    int
+	}{
+		{"api.example.com:443", "api.example.com", 1},
+		{"db.example.com:5432", "db.example.com:5432", 2},
+		{"web.example.com", "web.example.com", 3},
+		{"localhost:8080", "localhost", 4},
+	}
+	for _, tt := range tests {
+		if key, n := h.limit(tt.host); key != tt.key || n != tt.n {
+			t.Errorf("%s: expected %s=%d, got %s=%d", tt.host, tt.key, tt.n, key, n)
+		}
+	}
+
+	if _, err := parseHostConcurrency([]string{"api.example.com"}); err == nil {
+		t.Error("expected an error without limit")
+	}
+	if h, _ := parseHostConcurrency(nil); h != nil {
+		t.Error("expected no limiter without limits")
+	}
+}
+
+func TestHostLimiter_acquire(t *testing.T) {
+	h, err := parseHostConcurrency([]string{"api.example.com=2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mutex sync.Mutex
+	var running, max int
+	wg := sync.WaitGroup{}
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := h.acquire(context.Background(), "api.example.com")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer release()
+			mutex.Lock()
+			running++
+			if running > max {
+				max = running
+			}
+			mutex.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			mutex.Lock()
+			running--
+			mutex.Unlock()
+		}()
+	}
+	wg.Wait()
+	if max != 2 {
+		t.Errorf("expected 2 steps at the same time, got %d", max)
+	}
+
+	// a step waiting for a slot is stopped when the run is interrupted
+	release, _ := h.acquire(context.Background(), "api.example.com")
+	defer release()
+	release2, _ := h.acquire(context.Background(), "api.example.com")
+	defer release2()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := h.acquire(ctx, "api.example.com"); err == nil {
+		t.Error("expected an error once the context is done")
+	}
+}
@@ -0,0 +1,107 @@
+package venom
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const kubectlCommand = "kubectl"
+
+// PortForward describes a kubectl port-forward opened for the duration of a testsuite
+type PortForward struct {
+	Name       string `json:"name" yaml:"name" hcl:"name"`
+	Resource   string `json:"resource" yaml:"resource" hcl:"resource"` // svc/my-service, pod/my-pod, deployment/my-app
+	Port       int    `json:"port" yaml:"port" hcl:"port"`
+	Namespace  string `json:"namespace,omitempty" yaml:"namespace,omitempty" hcl:"namespace"`
+	Context    string `json:"context,omitempty" yaml:"context,omitempty" hcl:"context"`
+	Kubeconfig string `json:"kubeconfig,omitempty" yaml:"kubeconfig,omitempty" hcl:"kubeconfig"`
+	Timeout    int    `json:"timeout,omitempty" yaml:"timeout,omitempty" hcl:"timeout"` // seconds to wait for the local port
+}
+
+// startPortForwards opens all the port-forwards and returns the variables
+// venom.portforward.<name>.host, venom.portforward.<name>.port and venom.portforward.<name>.address.
+// The returned func closes the port-forwards, it must be called even if an error is returned.
+func (v *Venom) startPortForwards(pfs []PortForward) (map[string]string, func(), error) {
+	var cmds []*exec.Cmd
+	var ports []int
+	stop := func() {
+		for _, c := range cmds {
+			if c.Process != nil {
+				_ = c.Process.Kill()
+				_ = c.Wait()
+			}
+		}
+		for _, port := range ports {
+			v.releasePort(port)
+		}
+	}
+
+	vars := map[string]string{}
+	for _, pf := range pfs {
+		if pf.Name == "" || pf.Resource == "" || pf.Port == 0 {
+			return nil, stop, fmt.Errorf("port_forwards: name, resource and port are mandatory")
+		}
+		localPort, err := v.freePort()
+		if err != nil {
+			return nil, stop, err
+		}
+		ports = append(ports, localPort)
+
+		args := []string{}
+		if pf.Kubeconfig != "" {
+			args = append(args, "--kubeconfig", pf.Kubeconfig)
+		}
+		if pf.Context != "" {
+			args = append(args, "--context", pf.Context)
+		}
+		if pf.Namespace != "" {
+			args = append(args, "--namespace", pf.Namespace)
+		}
+		args = append(args, "port-forward", "--address", "127.0.0.1", pf.Resource, fmt.Sprintf("%d:%d", localPort, pf.Port))
+
+		log.Infof("Opening port-forward %s: %s %s", pf.Name, kubectlCommand, strings.Join(args, " "))
+		stderr := &bytes.Buffer{}
+		c := exec.Command(kubectlCommand, args...)
+		c.Stderr = stderr
+		if err := c.Start(); err != nil {
+			return nil, stop, fmt.Errorf("unable to start port-forward %s: %v", pf.Name, err)
+		}
+		cmds = append(cmds, c)
+
+		address := net.JoinHostPort("127.0.0.1", strconv.Itoa(localPort))
+		timeout := pf.Timeout
+		if timeout <= 0 {
+			timeout = 30
+		}
+		if err := waitForPort(address, time.Duration(timeout)*time.Second); err != nil {
+			return nil, stop, fmt.Errorf("port-forward %s is not ready: %v: %s", pf.Name, err, strings.TrimSpace(stderr.String()))
+		}
+
+		prefix := "venom.portforward." + pf.Name
+		vars[prefix+".host"] = "127.0.0.1"
+		vars[prefix+".port"] = strconv.Itoa(localPort)
+		vars[prefix+".address"] = address
+	}
+	return vars, stop, nil
+}
+
+func waitForPort(address string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		conn, err := net.DialTimeout("tcp", address, time.Second)
+		if err == nil {
+			return conn.Close()
+		}
+		if time.Now().After(deadline) {
+			return err
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+}
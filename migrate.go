@@ -0,0 +1,250 @@
+package venom
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// LatestVersion is the latest version of the testsuite format
+const LatestVersion = "2"
+
+// testSuiteVersion is a version of the testsuite format. The testsuites are run with the behavior of their
+// version, set up when they are read, and they are rewritten to the latest version by venom migrate.
+type testSuiteVersion struct {
+	version string
+	// setup sets up a testsuite of this version once it has been read
+	setup func(ts *TestSuite) error
+	// migrate rewrites a testsuite of the previous version to this one, it returns the changes to check by hand
+	migrate func(doc yaml.MapSlice, rebase func(string) (string, bool)) []string
+}
+
+// testSuiteVersions are the versions of the testsuite format, the oldest first
+var testSuiteVersions = []testSuiteVersion{
+	{version: "1", setup: func(ts *TestSuite) (err error) {
+		ts.WorkDir, err = os.Getwd()
+		if err != nil {
+			return fmt.Errorf("Unable to get current working directory err:%s", err)
+		}
+		return nil
+	}},
+	{version: "2", setup: func(ts *TestSuite) (err error) {
+		ts.WorkDir, err = filepath.Abs(filepath.Dir(ts.Filename))
+		if err != nil {
+			return fmt.Errorf("Unable to get testsuite's working directory err:%s", err)
+		}
+		return nil
+	}, migrate: migrateToVersion2},
+}
+
+// pathAttributes are the attributes of the steps, by executor, which are paths relative to the working directory
+var pathAttributes = map[string][]string{
+	"clickhouse": {"file"},
+	"dbfixtures": {"files", "folder", "migrations"},
+	"http":       {"bodyfile", "golden"},
+	"kafka":      {"messages_file"},
+	"ovhapi":     {"bodyfile"},
+	"readfile":   {"path"},
+	"redis":      {"path"},
+	"sql":        {"file"},
+}
+
+// testSuiteVersionIndex returns the index of the version of a testsuite in testSuiteVersions:
+// the version is 1 when it's not given, 2.1 is a version 2
+func testSuiteVersionIndex(version string) (int, error) {
+	major := strings.SplitN(strings.TrimSpace(version), ".", 2)[0]
+	if major == "" {
+		major = "1"
+	}
+	for i, v := range testSuiteVersions {
+		if v.version == major {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("testsuite version %q is not supported, the latest version is %s", version, LatestVersion)
+}
+
+// setupTestSuiteVersion sets up a testsuite read with the behavior of its version
+func setupTestSuiteVersion(ts *TestSuite) error {
+	i, err := testSuiteVersionIndex(ts.Version)
+	if err != nil {
+		return fmt.Errorf("%s: %v", ts.Filename, err)
+	}
+	return testSuiteVersions[i].setup(ts)
+}
+
+// MigrateTestSuite rewrites a yaml testsuite file to the latest version of the format. The relative paths of a
+// version 1 testsuite are relative to workdir, the directory venom was run from.
+// It returns the file rewritten, the oldest version of its testsuites and the changes to check by hand.
+func MigrateTestSuite(filename, workdir string) ([]byte, string, []string, error) {
+	if ext := filepath.Ext(filename); ext != ".yml" && ext != ".yaml" {
+		return nil, "", nil, fmt.Errorf("%s: only the yaml testsuites can be migrated", filename)
+	}
+	btes, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	// the testsuites of a multi-document file are migrated one by one
+	var docs []yaml.MapSlice
+	var versions []int
+	oldest := len(testSuiteVersions) - 1
+	dec := yaml.NewDecoder(bytes.NewReader(btes))
+	for {
+		var doc yaml.MapSlice
+		if err := dec.Decode(&doc); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, "", nil, fmt.Errorf("%s: %v", filename, err)
+		}
+		if len(doc) == 0 {
+			continue
+		}
+		var version string
+		if v := mapSliceValue(doc, "version"); v != nil {
+			version = fmt.Sprintf("%v", v)
+		}
+		from, err := testSuiteVersionIndex(version)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("%s: %v", filename, err)
+		}
+		if from < oldest {
+			oldest = from
+		}
+		docs = append(docs, doc)
+		versions = append(versions, from)
+	}
+	if oldest == len(testSuiteVersions)-1 {
+		return btes, testSuiteVersions[oldest].version, nil, nil
+	}
+
+	suiteDir, err := filepath.Abs(filepath.Dir(filename))
+	if err != nil {
+		return nil, "", nil, err
+	}
+	workdir, err = filepath.Abs(workdir)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	rebase := func(p string) (string, bool) {
+		if p == "" || filepath.IsAbs(p) || strings.Contains(p, "{{") {
+			return p, p == "" || filepath.IsAbs(p)
+		}
+		rel, err := filepath.Rel(suiteDir, filepath.Join(workdir, p))
+		if err != nil {
+			return p, false
+		}
+		return filepath.ToSlash(rel), true
+	}
+
+	var warnings []string
+	var out bytes.Buffer
+	for i, doc := range docs {
+		for _, v := range testSuiteVersions[versions[i]+1:] {
+			warnings = append(warnings, v.migrate(doc, rebase)...)
+		}
+		doc = setMapSliceValue(doc, "version", LatestVersion)
+		btes, err := yaml.Marshal(doc)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		if i > 0 {
+			out.WriteString("---\n")
+		}
+		out.Write(btes)
+	}
+	return out.Bytes(), testSuiteVersions[oldest].version, warnings, nil
+}
+
+// migrateToVersion2 rebases the relative paths on the directory of the testsuite
+func migrateToVersion2(doc yaml.MapSlice, rebase func(string) (string, bool)) []string {
+	var warnings []string
+	// nothing changes when venom is run from the directory of the testsuite
+	if dir, _ := rebase("."); dir == "." {
+		return nil
+	}
+	rebaseValue := func(where string, v interface{}) interface{} {
+		switch t := v.(type) {
+		case string:
+			p, ok := rebase(t)
+			if !ok {
+				warnings = append(warnings, fmt.Sprintf("%s: %q can not be rebased on the directory of the testsuite", where, t))
+			}
+			return p
+		case []interface{}:
+			for i := range t {
+				if s, ok := t[i].(string); ok {
+					p, ok := rebase(s)
+					if !ok {
+						warnings = append(warnings, fmt.Sprintf("%s: %q can not be rebased on the directory of the testsuite", where, s))
+					}
+					t[i] = p
+				}
+			}
+		}
+		return v
+	}
+
+	for _, item := range doc {
+		if item.Key == "services" {
+			if services, ok := item.Value.(yaml.MapSlice); ok {
+				for j := range services {
+					if services[j].Key == "compose_file" {
+						services[j].Value = rebaseValue("services.compose_file", services[j].Value)
+					}
+				}
+			}
+		}
+		if item.Key != "testcases" {
+			continue
+		}
+		testcases, _ := item.Value.([]interface{})
+		for _, itc := range testcases {
+			tc, _ := itc.(yaml.MapSlice)
+			name, _ := mapSliceValue(tc, "name").(string)
+			steps, _ := mapSliceValue(tc, "steps").([]interface{})
+			for stepNumber, istep := range steps {
+				step, _ := istep.(yaml.MapSlice)
+				where := fmt.Sprintf("testcase %q, step %d", name, stepNumber)
+				stepType, _ := mapSliceValue(step, "type").(string)
+				if stepType == "" || stepType == "exec" {
+					warnings = append(warnings, where+": the script is run in the directory of the testsuite, check its relative paths")
+				}
+				for _, attr := range pathAttributes[stepType] {
+					for k := range step {
+						if step[k].Key == attr {
+							step[k].Value = rebaseValue(where+"."+attr, step[k].Value)
+						}
+					}
+				}
+			}
+		}
+	}
+	return warnings
+}
+
+func mapSliceValue(m yaml.MapSlice, key string) interface{} {
+	for _, item := range m {
+		if item.Key == key {
+			return item.Value
+		}
+	}
+	return nil
+}
+
+// setMapSliceValue sets the value of a key, a new key is added first
+func setMapSliceValue(m yaml.MapSlice, key string, value interface{}) yaml.MapSlice {
+	for i := range m {
+		if m[i].Key == key {
+			m[i].Value = value
+			return m
+		}
+	}
+	return append(yaml.MapSlice{{Key: key, Value: value}}, m...)
+}
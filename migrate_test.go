@@ -0,0 +1,93 @@
+package venom
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMigrateTestSuite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "migrate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := os.Mkdir(filepath.Join(dir, "suites"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	suite := `name: migrate
+testcases:
+- name: read
+  steps:
+  - type: readfile
+    path: suites/a.json
+  - type: dbfixtures
+    files:
+    - fixtures/a.yml
+    - /abs/b.yml
+  - type: sql
+    file: "{{.dir}}/a.sql"
+  - script: cat a.json
+`
+	filename := filepath.Join(dir, "suites", "suite.yml")
+	if err := ioutil.WriteFile(filename, []byte(suite), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, from, warnings, err := MigrateTestSuite(filename, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if from != "1" {
+		t.Errorf("expected a version 1 testsuite, got %s", from)
+	}
+	for _, s := range []string{`version: "2"` + "\nname: migrate\n", "path: a.json", "- ../fixtures/a.yml", "- /abs/b.yml", "file: '{{.dir}}/a.sql'"} {
+		if !strings.Contains(string(out), s) {
+			t.Errorf("expected %q in the testsuite migrated:\n%s", s, out)
+		}
+	}
+	if len(warnings) != 2 || !strings.Contains(warnings[0], "{{.dir}}/a.sql") || !strings.Contains(warnings[1], "step 3: the script") {
+		t.Errorf("unexpected warnings %v", warnings)
+	}
+
+	if err := ioutil.WriteFile(filename, out, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, from, _, err := MigrateTestSuite(filename, dir); err != nil || from != LatestVersion {
+		t.Errorf("expected a testsuite at the latest version, got %s %v", from, err)
+	}
+
+	// the testsuites of a multi-document file are migrated one by one
+	multi := "version: \"2\"\nname: a\n---\nname: b\ntestcases:\n- name: read\n  steps:\n  - type: readfile\n    path: suites/b.json\n"
+	if err := ioutil.WriteFile(filename, []byte(multi), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out, from, _, err = MigrateTestSuite(filename, dir)
+	if err != nil || from != "1" {
+		t.Fatalf("expected a version 1 testsuite, got %s %v", from, err)
+	}
+	if s := "version: \"2\"\nname: a\n---\nversion: \"2\"\nname: b\n"; !strings.Contains(string(out), s) || !strings.Contains(string(out), "path: b.json") {
+		t.Errorf("unexpected testsuites migrated:\n%s", out)
+	}
+}
+
+func TestSetupTestSuiteVersion(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := &TestSuite{Filename: "/tmp/suites/a.yml"}
+	if err := setupTestSuiteVersion(ts); err != nil || ts.WorkDir != wd {
+		t.Errorf("expected the current directory as workdir of a version 1 testsuite, got %s %v", ts.WorkDir, err)
+	}
+	ts.Version = "2.1"
+	if err := setupTestSuiteVersion(ts); err != nil || ts.WorkDir != "/tmp/suites" {
+		t.Errorf("expected the testsuite directory as workdir of a version 2 testsuite, got %s %v", ts.WorkDir, err)
+	}
+	ts.Version = "3"
+	if err := setupTestSuiteVersion(ts); err == nil {
+		t.Error("expected an error for an unknown version")
+	}
+}
@@ -0,0 +1,111 @@
+package venom
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/ovh/venom/openapi"
+)
+
+// httpCall returns the call made by an http step. Raw requests are ignored.
+func httpCall(e *ExecutorWrap, result ExecutorResult) (openapi.Call, bool) {
+	if e.name != "http" || fmt.Sprintf("%v", result["result.executor.rawrequest"]) != "" {
+		return openapi.Call{}, false
+	}
+	status, ok := result["result.statuscode"].(int)
+	if !ok {
+		return openapi.Call{}, false
+	}
+	return openapi.Call{
+		Method:          fmt.Sprintf("%v", result["result.executor.method"]),
+		URL:             fmt.Sprintf("%v%v", result["result.executor.url"], result["result.executor.path"]),
+		RequestHeaders:  resultMap(result, "result.executor.headers."),
+		RequestBody:     fmt.Sprintf("%v", result["result.executor.body"]),
+		StatusCode:      status,
+		ResponseHeaders: resultMap(result, "result.headers."),
+		ResponseBody:    fmt.Sprintf("%v", result["result.body"]),
+	}, true
+}
+
+// resultMap returns the values of a map of the result
+func resultMap(result ExecutorResult, prefix string) map[string]string {
+	m := map[string]string{}
+	for k, v := range result {
+		if strings.HasPrefix(k, prefix) && !strings.HasPrefix(k, prefix+"__") {
+			m[strings.TrimPrefix(k, prefix)] = fmt.Sprintf("%v", v)
+		}
+	}
+	return m
+}
+
+// recordOpenAPICall records the call made by an http step in the OpenAPI coverage
+func (v *Venom) recordOpenAPICall(e *ExecutorWrap, result ExecutorResult) {
+	if v.openAPICoverage == nil {
+		return
+	}
+	if c, ok := httpCall(e, result); ok {
+		v.openAPICoverage.Record(c.Method, c.URL, c.StatusCode)
+	}
+}
+
+// validateOpenAPICall validates the call made by an http step against the OpenAPI spec,
+// and returns a failure for each violation of the contract
+func (v *Venom) validateOpenAPICall(e *ExecutorWrap, tc TestCase, stepNumber int, result ExecutorResult) []Failure {
+	if v.openAPISpec == nil || !v.OpenAPIValidate {
+		return nil
+	}
+	c, ok := httpCall(e, result)
+	if !ok {
+		return nil
+	}
+	var failures []Failure
+	for _, err := range v.openAPISpec.Validate(c) {
+		failures = append(failures, *newFailure(tc, stepNumber, "OpenAPI contract", err, result))
+	}
+	return failures
+}
+
+// outputOpenAPICoverage prints the OpenAPI coverage and the operations not tested
+func (v *Venom) outputOpenAPICoverage() {
+	if v.openAPICoverage == nil {
+		return
+	}
+	yellow := color.New(color.FgYellow).SprintFunc()
+	r := v.openAPICoverage.Report()
+	v.PrintFunc("OpenAPI coverage: %d/%d operations (%.1f%%)\n", r.Covered, r.Total, r.Percent)
+	for _, op := range r.Operations {
+		if op.Calls == 0 {
+			v.PrintFunc("%s %s %s\n", yellow("NOT TESTED"), op.Method, op.Path)
+			continue
+		}
+		for _, sc := range op.Responses {
+			if !sc.Documented {
+				v.PrintFunc("%s %s %s returned %s\n", yellow("UNDOCUMENTED"), op.Method, op.Path, sc.Code)
+			}
+		}
+	}
+	for _, op := range r.UnknownOperations {
+		v.PrintFunc("%s %s\n", yellow("UNKNOWN"), op)
+	}
+}
+
+// writeOpenAPICoverage writes the OpenAPI coverage report in the output directory
+func (v *Venom) writeOpenAPICoverage() error {
+	if v.openAPICoverage == nil {
+		return nil
+	}
+	data, err := json.MarshalIndent(v.openAPICoverage.Report(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("Error: cannot format OpenAPI coverage: %v", err)
+	}
+	filename := filepath.Join(v.OutputDir, "openapi_coverage.json")
+	if err := ioutil.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("Error while creating file %s: %v", filename, err)
+	}
+	v.PrintFunc("Writing file %s\n", filename)
+	return nil
+}
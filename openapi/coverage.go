@@ -0,0 +1,112 @@
+package openapi
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Coverage records the HTTP calls made on the operations of a specification
+type Coverage struct {
+	spec    *Spec
+	mutex   sync.Mutex
+	calls   map[*Operation]map[int]int
+	unknown map[string]int
+}
+
+// CoverageReport is the coverage of the operations of a specification
+type CoverageReport struct {
+	Operations        []OperationCoverage `json:"operations" yaml:"operations"`
+	Total             int                 `json:"total" yaml:"total"`
+	Covered           int                 `json:"covered" yaml:"covered"`
+	Percent           float64             `json:"percent" yaml:"percent"`
+	UnknownOperations []string            `json:"unknown_operations,omitempty" yaml:"unknown_operations,omitempty"`
+}
+
+// OperationCoverage is the coverage of an operation
+type OperationCoverage struct {
+	Method      string           `json:"method" yaml:"method"`
+	Path        string           `json:"path" yaml:"path"`
+	OperationID string           `json:"operation_id,omitempty" yaml:"operation_id,omitempty"`
+	Calls       int              `json:"calls" yaml:"calls"`
+	Responses   []StatusCoverage `json:"responses" yaml:"responses"`
+}
+
+// StatusCoverage is the number of calls which returned a status code.
+// Status codes returned but not documented in the specification have Documented set to false.
+type StatusCoverage struct {
+	Code       string `json:"code" yaml:"code"`
+	Documented bool   `json:"documented" yaml:"documented"`
+	Calls      int    `json:"calls" yaml:"calls"`
+}
+
+// NewCoverage returns a new Coverage of the spec
+func NewCoverage(spec *Spec) *Coverage {
+	return &Coverage{
+		spec:    spec,
+		calls:   map[*Operation]map[int]int{},
+		unknown: map[string]int{},
+	}
+}
+
+// Record records a call on the URL which returned the status code
+func (c *Coverage) Record(method, url string, status int) {
+	op, _ := c.spec.Find(method, url)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if op == nil {
+		c.unknown[fmt.Sprintf("%s %s", method, url)]++
+		return
+	}
+	if c.calls[op] == nil {
+		c.calls[op] = map[int]int{}
+	}
+	c.calls[op][status]++
+}
+
+// Report returns the coverage of the operations
+func (c *Coverage) Report() CoverageReport {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	r := CoverageReport{Total: len(c.spec.Operations)}
+	for _, op := range c.spec.Operations {
+		oc := OperationCoverage{Method: op.Method, Path: op.Path, OperationID: op.OperationID}
+		responses := map[string]*StatusCoverage{}
+		for _, code := range op.Responses {
+			sc := &StatusCoverage{Code: code, Documented: true}
+			responses[code] = sc
+		}
+		for status, n := range c.calls[op] {
+			oc.Calls += n
+			code := op.ResponseFor(status)
+			if code == "" {
+				code = fmt.Sprintf("%d", status)
+				responses[code] = &StatusCoverage{Code: code}
+			}
+			responses[code].Calls += n
+		}
+		for _, sc := range responses {
+			oc.Responses = append(oc.Responses, *sc)
+		}
+		sort.Slice(oc.Responses, func(i, j int) bool { return oc.Responses[i].Code < oc.Responses[j].Code })
+		if oc.Calls > 0 {
+			r.Covered++
+		}
+		r.Operations = append(r.Operations, oc)
+	}
+	sort.SliceStable(r.Operations, func(i, j int) bool {
+		if r.Operations[i].Path != r.Operations[j].Path {
+			return r.Operations[i].Path < r.Operations[j].Path
+		}
+		return indexOf(methods, r.Operations[i].Method) < indexOf(methods, r.Operations[j].Method)
+	})
+	if r.Total > 0 {
+		r.Percent = float64(r.Covered) * 100 / float64(r.Total)
+	}
+	for k := range c.unknown {
+		r.UnknownOperations = append(r.UnknownOperations, k)
+	}
+	sort.Strings(r.UnknownOperations)
+	return r
+}
@@ -0,0 +1,234 @@
+// Package openapi reads OpenAPI 3 and Swagger 2 specifications and matches HTTP requests with their operations.
+package openapi
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+var methods = []string{"GET", "PUT", "POST", "DELETE", "OPTIONS", "HEAD", "PATCH", "TRACE"}
+
+// Spec is an OpenAPI specification
+type Spec struct {
+	BasePaths  []string
+	Operations []*Operation
+	doc        map[string]interface{}
+}
+
+// Operation is an operation of the specification, a method on a path
+type Operation struct {
+	Method      string
+	Path        string
+	OperationID string
+	Responses   []string
+	segments    []string
+	params      int
+	def         map[string]interface{}
+	pathDef     map[string]interface{}
+}
+
+// Load reads a specification file, in YAML or JSON
+func Load(file string) (*Spec, error) {
+	btes, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read OpenAPI spec: %v", err)
+	}
+	return Parse(btes)
+}
+
+// Parse parses a specification, in YAML or JSON
+func Parse(btes []byte) (*Spec, error) {
+	var raw interface{}
+	if err := yaml.Unmarshal(btes, &raw); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI spec: %v", err)
+	}
+	doc, ok := normalize(raw).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid OpenAPI spec: not an object")
+	}
+	s := &Spec{doc: doc}
+
+	// Swagger 2 has a basePath, OpenAPI 3 has servers
+	if basePath, ok := doc["basePath"].(string); ok {
+		s.BasePaths = append(s.BasePaths, basePath)
+	}
+	servers, _ := doc["servers"].([]interface{})
+	for _, server := range servers {
+		m, _ := server.(map[string]interface{})
+		serverURL, _ := m["url"].(string)
+		if i := strings.Index(serverURL, "{"); i >= 0 {
+			serverURL = serverURL[:i]
+		}
+		if u, err := url.Parse(serverURL); err == nil && u.Path != "" {
+			s.BasePaths = append(s.BasePaths, u.Path)
+		}
+	}
+	for i := range s.BasePaths {
+		s.BasePaths[i] = strings.TrimSuffix(s.BasePaths[i], "/")
+	}
+
+	paths, _ := doc["paths"].(map[string]interface{})
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("invalid OpenAPI spec: no paths")
+	}
+	for path, p := range paths {
+		pathDef, _ := p.(map[string]interface{})
+		for _, method := range methods {
+			def, ok := pathDef[strings.ToLower(method)].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			op := &Operation{
+				Method:   method,
+				Path:     path,
+				segments: strings.Split(strings.Trim(path, "/"), "/"),
+				def:      def,
+				pathDef:  pathDef,
+			}
+			op.OperationID, _ = def["operationId"].(string)
+			responses, _ := def["responses"].(map[string]interface{})
+			for code := range responses {
+				op.Responses = append(op.Responses, code)
+			}
+			sort.Strings(op.Responses)
+			for _, seg := range op.segments {
+				if strings.Contains(seg, "{") {
+					op.params++
+				}
+			}
+			s.Operations = append(s.Operations, op)
+		}
+	}
+
+	// operations with less parameters are matched first: /pets/mine before /pets/{id}
+	sort.SliceStable(s.Operations, func(i, j int) bool {
+		if s.Operations[i].params != s.Operations[j].params {
+			return s.Operations[i].params < s.Operations[j].params
+		}
+		if s.Operations[i].Path != s.Operations[j].Path {
+			return s.Operations[i].Path < s.Operations[j].Path
+		}
+		return indexOf(methods, s.Operations[i].Method) < indexOf(methods, s.Operations[j].Method)
+	})
+	return s, nil
+}
+
+// Find returns the operation matching the method and the URL, and the values of the path parameters.
+// It returns nil if no operation matches.
+func (s *Spec) Find(method, rawURL string) (*Operation, map[string]string) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, nil
+	}
+	method = strings.ToUpper(method)
+	if method == "" {
+		method = "GET"
+	}
+
+	candidates := []string{u.EscapedPath()}
+	for _, base := range s.BasePaths {
+		if base != "" && strings.HasPrefix(u.EscapedPath(), base+"/") {
+			candidates = append([]string{strings.TrimPrefix(u.EscapedPath(), base)}, candidates...)
+		}
+	}
+	for _, path := range candidates {
+		segments := strings.Split(strings.Trim(path, "/"), "/")
+		for _, op := range s.Operations {
+			if op.Method != method {
+				continue
+			}
+			if params, ok := op.match(segments); ok {
+				return op, params
+			}
+		}
+	}
+	return nil, nil
+}
+
+// match matches the segments of a path with the path template of the operation
+func (op *Operation) match(segments []string) (map[string]string, bool) {
+	if len(segments) != len(op.segments) {
+		return nil, false
+	}
+	params := map[string]string{}
+	for i, seg := range op.segments {
+		start, end := strings.Index(seg, "{"), strings.LastIndex(seg, "}")
+		if start < 0 || end < start {
+			if seg != segments[i] {
+				return nil, false
+			}
+			continue
+		}
+		// a segment can be a template like {id} or {name}.json
+		prefix, suffix := seg[:start], seg[end+1:]
+		value := segments[i]
+		if !strings.HasPrefix(value, prefix) || !strings.HasSuffix(value, suffix) || len(value) <= len(prefix)+len(suffix) {
+			return nil, false
+		}
+		value = value[len(prefix) : len(value)-len(suffix)]
+		if v, err := url.PathUnescape(value); err == nil {
+			value = v
+		}
+		params[seg[start+1:end]] = value
+	}
+	return params, true
+}
+
+// String returns the method and the path of the operation
+func (op *Operation) String() string {
+	return op.Method + " " + op.Path
+}
+
+// ResponseFor returns the documented response code matching the status code: 404, 4XX or default.
+// It returns an empty string if the status code is not documented.
+func (op *Operation) ResponseFor(status int) string {
+	code := fmt.Sprintf("%d", status)
+	for _, r := range op.Responses {
+		if r == code {
+			return r
+		}
+	}
+	for _, r := range op.Responses {
+		if strings.EqualFold(r, code[:1]+"XX") {
+			return r
+		}
+	}
+	for _, r := range op.Responses {
+		if r == "default" {
+			return r
+		}
+	}
+	return ""
+}
+
+// normalize converts the maps decoded by the yaml package to map[string]interface{}
+func normalize(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, v := range t {
+			m[fmt.Sprintf("%v", k)] = normalize(v)
+		}
+		return m
+	case []interface{}:
+		for i := range t {
+			t[i] = normalize(t[i])
+		}
+		return t
+	}
+	return v
+}
+
+func indexOf(s []string, v string) int {
+	for i := range s {
+		if s[i] == v {
+			return i
+		}
+	}
+	return -1
+}
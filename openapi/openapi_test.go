@@ -0,0 +1,114 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const petstore = `
+openapi: 3.0.0
+servers:
+- url: https://petstore.example.com/v1
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      responses:
+        200:
+          description: the pets
+        default:
+          description: error
+    post:
+      operationId: createPet
+      responses:
+        "201":
+          description: created
+  /pets/mine:
+    get:
+      responses:
+        "200":
+          description: my pets
+  /pets/{petId}:
+    get:
+      operationId: showPetById
+      responses:
+        "200":
+          description: a pet
+        4XX:
+          description: not found
+`
+
+func TestSpec_Find(t *testing.T) {
+	spec, err := Parse([]byte(petstore))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/v1"}, spec.BasePaths)
+	assert.Len(t, spec.Operations, 4)
+
+	tests := []struct {
+		method string
+		url    string
+		want   string
+		params map[string]string
+	}{
+		{method: "", url: "http://localhost/v1/pets?limit=1", want: "GET /pets", params: map[string]string{}},
+		{method: "post", url: "http://localhost/v1/pets", want: "POST /pets", params: map[string]string{}},
+		{method: "GET", url: "http://localhost/v1/pets/mine", want: "GET /pets/mine", params: map[string]string{}},
+		{method: "GET", url: "http://localhost/v1/pets/a%20b", want: "GET /pets/{petId}", params: map[string]string{"petId": "a b"}},
+		{method: "GET", url: "http://localhost/pets/42", want: "GET /pets/{petId}", params: map[string]string{"petId": "42"}},
+		{method: "DELETE", url: "http://localhost/v1/pets/42"},
+		{method: "GET", url: "http://localhost/v1/pets/42/owner"},
+	}
+	for _, tt := range tests {
+		op, params := spec.Find(tt.method, tt.url)
+		if tt.want == "" {
+			assert.Nil(t, op, tt.url)
+			continue
+		}
+		require.NotNil(t, op, tt.url)
+		assert.Equal(t, tt.want, op.String())
+		assert.Equal(t, tt.params, params)
+	}
+}
+
+func TestOperation_ResponseFor(t *testing.T) {
+	spec, err := Parse([]byte(petstore))
+	require.NoError(t, err)
+
+	op, _ := spec.Find("GET", "/v1/pets/42")
+	assert.Equal(t, "200", op.ResponseFor(200))
+	assert.Equal(t, "4XX", op.ResponseFor(404))
+	assert.Equal(t, "", op.ResponseFor(500))
+
+	op, _ = spec.Find("GET", "/v1/pets")
+	assert.Equal(t, "default", op.ResponseFor(500))
+}
+
+func TestCoverage_Report(t *testing.T) {
+	spec, err := Parse([]byte(petstore))
+	require.NoError(t, err)
+
+	c := NewCoverage(spec)
+	c.Record("GET", "http://localhost/v1/pets", 200)
+	c.Record("GET", "http://localhost/v1/pets", 200)
+	c.Record("GET", "http://localhost/v1/pets/1", 404)
+	c.Record("GET", "http://localhost/v1/pets/1", 500)
+	c.Record("GET", "http://localhost/v1/owners", 200)
+
+	r := c.Report()
+	assert.Equal(t, 4, r.Total)
+	assert.Equal(t, 2, r.Covered)
+	assert.Equal(t, 50.0, r.Percent)
+	assert.Equal(t, []string{"GET http://localhost/v1/owners"}, r.UnknownOperations)
+
+	require.Len(t, r.Operations, 4)
+	assert.Equal(t, "GET", r.Operations[0].Method)
+	assert.Equal(t, "/pets", r.Operations[0].Path)
+	assert.Equal(t, 2, r.Operations[0].Calls)
+	assert.Equal(t, []StatusCoverage{{Code: "200", Documented: true, Calls: 2}, {Code: "default", Documented: true}}, r.Operations[0].Responses)
+	assert.Equal(t, "POST /pets", r.Operations[1].Method+" "+r.Operations[1].Path)
+	assert.Equal(t, 0, r.Operations[1].Calls)
+	assert.Equal(t, "/pets/{petId}", r.Operations[3].Path)
+	assert.Equal(t, []StatusCoverage{{Code: "200", Documented: true}, {Code: "4XX", Documented: true, Calls: 1}, {Code: "500", Calls: 1}}, r.Operations[3].Responses)
+}
@@ -0,0 +1,288 @@
+package openapi
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+var regexpUUID = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// direction of a value: readOnly properties are not required in requests, writeOnly properties are not required in responses
+type direction int
+
+const (
+	inRequest direction = iota
+	inResponse
+)
+
+// resolve follows the local references ($ref: '#/components/schemas/Pet')
+func (s *Spec) resolve(v interface{}) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	for i := 0; i < 32 && m != nil; i++ {
+		ref, ok := m["$ref"].(string)
+		if !ok {
+			return m
+		}
+		if !strings.HasPrefix(ref, "#/") {
+			// remote references are not supported
+			return nil
+		}
+		var cur interface{} = s.doc
+		for _, part := range strings.Split(ref[2:], "/") {
+			part = strings.Replace(strings.Replace(part, "~1", "/", -1), "~0", "~", -1)
+			obj, _ := cur.(map[string]interface{})
+			cur = obj[part]
+		}
+		m, _ = cur.(map[string]interface{})
+	}
+	return m
+}
+
+// validateSchema validates the value against the schema, as decoded from JSON
+func (s *Spec) validateSchema(schema interface{}, value interface{}, path string, dir direction) []error {
+	sc := s.resolve(schema)
+	if sc == nil {
+		return nil
+	}
+
+	var errs []error
+	for _, sub := range list(sc["allOf"]) {
+		errs = append(errs, s.validateSchema(sub, value, path, dir)...)
+	}
+	if anyOf := list(sc["anyOf"]); len(anyOf) > 0 {
+		var valid bool
+		for _, sub := range anyOf {
+			if len(s.validateSchema(sub, value, path, dir)) == 0 {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			errs = append(errs, fmt.Errorf("%s: does not match any schema of anyOf", path))
+		}
+	}
+	if oneOf := list(sc["oneOf"]); len(oneOf) > 0 {
+		var n int
+		for _, sub := range oneOf {
+			if len(s.validateSchema(sub, value, path, dir)) == 0 {
+				n++
+			}
+		}
+		if n != 1 {
+			errs = append(errs, fmt.Errorf("%s: matches %d schemas of oneOf, expected 1", path, n))
+		}
+	}
+
+	if value == nil {
+		if sc["nullable"] == true || sc["x-nullable"] == true || len(schemaTypes(sc)) == 0 || hasType(sc, "null") {
+			return errs
+		}
+		return append(errs, fmt.Errorf("%s: should not be null", path))
+	}
+
+	if enum := list(sc["enum"]); len(enum) > 0 {
+		var found bool
+		for _, e := range enum {
+			if equal(e, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			errs = append(errs, fmt.Errorf("%s: %v is not one of %v", path, value, enum))
+		}
+	}
+
+	types := schemaTypes(sc)
+	if len(types) > 0 {
+		actual := jsonType(value)
+		var ok bool
+		for _, t := range types {
+			if t == actual || (t == "number" && actual == "integer") {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return append(errs, fmt.Errorf("%s: expected %s, got %s", path, strings.Join(types, " or "), actual))
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		if min, ok := number(sc["minLength"]); ok && float64(len([]rune(v))) < min {
+			errs = append(errs, fmt.Errorf("%s: length should be at least %v", path, min))
+		}
+		if max, ok := number(sc["maxLength"]); ok && float64(len([]rune(v))) > max {
+			errs = append(errs, fmt.Errorf("%s: length should be at most %v", path, max))
+		}
+		if pattern, ok := sc["pattern"].(string); ok {
+			if r, err := regexp.Compile(pattern); err == nil && !r.MatchString(v) {
+				errs = append(errs, fmt.Errorf("%s: %q does not match %s", path, v, pattern))
+			}
+		}
+		if err := checkFormat(sc["format"], v); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", path, err))
+		}
+	case float64:
+		if min, ok := number(sc["minimum"]); ok {
+			if v < min || (sc["exclusiveMinimum"] == true && v == min) {
+				errs = append(errs, fmt.Errorf("%s: %v should be greater than %v", path, v, min))
+			}
+		}
+		if min, ok := number(sc["exclusiveMinimum"]); ok && v <= min {
+			errs = append(errs, fmt.Errorf("%s: %v should be greater than %v", path, v, min))
+		}
+		if max, ok := number(sc["maximum"]); ok {
+			if v > max || (sc["exclusiveMaximum"] == true && v == max) {
+				errs = append(errs, fmt.Errorf("%s: %v should be lower than %v", path, v, max))
+			}
+		}
+		if max, ok := number(sc["exclusiveMaximum"]); ok && v >= max {
+			errs = append(errs, fmt.Errorf("%s: %v should be lower than %v", path, v, max))
+		}
+	case []interface{}:
+		if min, ok := number(sc["minItems"]); ok && float64(len(v)) < min {
+			errs = append(errs, fmt.Errorf("%s: should have at least %v items", path, min))
+		}
+		if max, ok := number(sc["maxItems"]); ok && float64(len(v)) > max {
+			errs = append(errs, fmt.Errorf("%s: should have at most %v items", path, max))
+		}
+		if items, ok := sc["items"]; ok {
+			for i, item := range v {
+				errs = append(errs, s.validateSchema(items, item, fmt.Sprintf("%s[%d]", path, i), dir)...)
+			}
+		}
+	case map[string]interface{}:
+		properties, _ := sc["properties"].(map[string]interface{})
+		for _, r := range list(sc["required"]) {
+			name, _ := r.(string)
+			if _, ok := v[name]; ok {
+				continue
+			}
+			prop := s.resolve(properties[name])
+			if (dir == inRequest && prop["readOnly"] == true) || (dir == inResponse && prop["writeOnly"] == true) {
+				continue
+			}
+			errs = append(errs, fmt.Errorf("%s: property %s is required", path, name))
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if prop, ok := properties[k]; ok {
+				errs = append(errs, s.validateSchema(prop, v[k], path+"."+k, dir)...)
+				continue
+			}
+			switch additional := sc["additionalProperties"].(type) {
+			case bool:
+				if !additional {
+					errs = append(errs, fmt.Errorf("%s: property %s is not allowed", path, k))
+				}
+			case map[string]interface{}:
+				errs = append(errs, s.validateSchema(additional, v[k], path+"."+k, dir)...)
+			}
+		}
+	}
+	return errs
+}
+
+// checkFormat checks the most common string formats, other formats are ignored
+func checkFormat(format interface{}, v string) error {
+	switch format {
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, v); err != nil {
+			return fmt.Errorf("%q is not a date-time", v)
+		}
+	case "date":
+		if _, err := time.Parse("2006-01-02", v); err != nil {
+			return fmt.Errorf("%q is not a date", v)
+		}
+	case "uuid":
+		if !regexpUUID.MatchString(v) {
+			return fmt.Errorf("%q is not an uuid", v)
+		}
+	}
+	return nil
+}
+
+// schemaTypes returns the types of the schema: type is a string, or a list since OpenAPI 3.1
+func schemaTypes(sc map[string]interface{}) []string {
+	switch t := sc["type"].(type) {
+	case string:
+		return []string{t}
+	case []interface{}:
+		var types []string
+		for _, v := range t {
+			types = append(types, fmt.Sprintf("%v", v))
+		}
+		return types
+	}
+	return nil
+}
+
+func hasType(sc map[string]interface{}, t string) bool {
+	for _, v := range schemaTypes(sc) {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonType returns the JSON schema type of a value decoded from JSON
+func jsonType(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if t == math.Trunc(t) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// number returns a number of the spec, decoded as int or float64
+func number(v interface{}) (float64, bool) {
+	switch t := v.(type) {
+	case int:
+		return float64(t), true
+	case int64:
+		return float64(t), true
+	case uint64:
+		return float64(t), true
+	case float64:
+		return t, true
+	}
+	return 0, false
+}
+
+func equal(expected, actual interface{}) bool {
+	if e, ok := number(expected); ok {
+		a, ok := number(actual)
+		return ok && a == e
+	}
+	return reflect.DeepEqual(expected, actual)
+}
+
+func list(v interface{}) []interface{} {
+	l, _ := v.([]interface{})
+	return l
+}
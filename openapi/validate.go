@@ -0,0 +1,257 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Call is an HTTP call: a request and its response
+type Call struct {
+	Method          string
+	URL             string
+	RequestHeaders  map[string]string
+	RequestBody     string
+	StatusCode      int
+	ResponseHeaders map[string]string
+	ResponseBody    string
+}
+
+// Validate validates the request and the response of the call against the specification:
+// parameters, bodies and status code. It returns the contract violations.
+func (s *Spec) Validate(c Call) []error {
+	op, pathParams := s.Find(c.Method, c.URL)
+	if op == nil {
+		return []error{fmt.Errorf("no operation of the OpenAPI spec matches %s %s", strings.ToUpper(c.Method), c.URL)}
+	}
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return []error{err}
+	}
+
+	var errs []error
+	for _, p := range s.parameters(op) {
+		errs = append(errs, s.validateParameter(p, u.Query(), pathParams, c.RequestHeaders)...)
+	}
+	errs = append(errs, s.validateRequestBody(op, c)...)
+	errs = append(errs, s.validateResponse(op, c)...)
+	for i := range errs {
+		errs[i] = fmt.Errorf("%s: %v", op, errs[i])
+	}
+	return errs
+}
+
+// parameters returns the parameters of the path and of the operation, the latter overriding the former
+func (s *Spec) parameters(op *Operation) []map[string]interface{} {
+	var params []map[string]interface{}
+	index := map[string]int{}
+	for _, l := range [][]interface{}{list(op.pathDef["parameters"]), list(op.def["parameters"])} {
+		for _, v := range l {
+			p := s.resolve(v)
+			if p == nil {
+				continue
+			}
+			key := fmt.Sprintf("%v/%v", p["in"], p["name"])
+			if i, ok := index[key]; ok {
+				params[i] = p
+				continue
+			}
+			index[key] = len(params)
+			params = append(params, p)
+		}
+	}
+	return params
+}
+
+func (s *Spec) validateParameter(p map[string]interface{}, query url.Values, pathParams, headers map[string]string) []error {
+	name, _ := p["name"].(string)
+	in, _ := p["in"].(string)
+	required := p["required"] == true || in == "path"
+
+	// OpenAPI 3 has a schema, Swagger 2 describes the type in the parameter, except for the body
+	schema := p["schema"]
+	if schema == nil {
+		schema = p
+	}
+
+	var values []string
+	switch in {
+	case "path":
+		if v, ok := pathParams[name]; ok {
+			values = []string{v}
+		}
+	case "query":
+		values = query[name]
+	case "header":
+		if v, ok := header(headers, name); ok {
+			values = []string{v}
+		}
+	default:
+		// body is validated with the request body, cookie and formData are not validated
+		return nil
+	}
+
+	if len(values) == 0 {
+		if required {
+			return []error{fmt.Errorf("%s parameter %s is required", in, name)}
+		}
+		return nil
+	}
+	value, err := s.parseParameter(schema, values)
+	if err != nil {
+		return []error{fmt.Errorf("%s parameter %s: %v", in, name, err)}
+	}
+	return s.validateSchema(schema, value, in+" parameter "+name, inRequest)
+}
+
+// parseParameter converts the values of a parameter to the type of its schema
+func (s *Spec) parseParameter(schema interface{}, values []string) (interface{}, error) {
+	sc := s.resolve(schema)
+	if hasType(sc, "array") {
+		if len(values) == 1 {
+			values = strings.Split(values[0], ",")
+		}
+		items := make([]interface{}, 0, len(values))
+		for _, v := range values {
+			item, err := s.parseParameter(sc["items"], []string{v})
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	}
+	v := values[0]
+	switch {
+	case hasType(sc, "integer"), hasType(sc, "number"):
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", v)
+		}
+		return f, nil
+	case hasType(sc, "boolean"):
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a boolean", v)
+		}
+		return b, nil
+	}
+	return v, nil
+}
+
+func (s *Spec) validateRequestBody(op *Operation, c Call) []error {
+	var required bool
+	var content map[string]interface{}
+	if body := s.resolve(op.def["requestBody"]); body != nil {
+		required = body["required"] == true
+		content, _ = body["content"].(map[string]interface{})
+	} else {
+		// Swagger 2
+		for _, p := range s.parameters(op) {
+			if p["in"] == "body" {
+				required = p["required"] == true
+				content = map[string]interface{}{"application/json": map[string]interface{}{"schema": p["schema"]}}
+			}
+		}
+	}
+
+	if strings.TrimSpace(c.RequestBody) == "" {
+		if required {
+			return []error{fmt.Errorf("request body is required")}
+		}
+		return nil
+	}
+	if content == nil {
+		return nil
+	}
+	contentType, _ := header(c.RequestHeaders, "Content-Type")
+	return s.validateContent(content, contentType, c.RequestBody, "request body", inRequest)
+}
+
+func (s *Spec) validateResponse(op *Operation, c Call) []error {
+	code := op.ResponseFor(c.StatusCode)
+	if code == "" {
+		return []error{fmt.Errorf("status code %d is not documented", c.StatusCode)}
+	}
+	responses, _ := op.def["responses"].(map[string]interface{})
+	resp := s.resolve(responses[code])
+	if resp == nil {
+		return nil
+	}
+
+	var errs []error
+	headers, _ := resp["headers"].(map[string]interface{})
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if s.resolve(headers[name])["required"] == true {
+			if _, ok := header(c.ResponseHeaders, name); !ok {
+				errs = append(errs, fmt.Errorf("response header %s is required", name))
+			}
+		}
+	}
+
+	if strings.TrimSpace(c.ResponseBody) == "" {
+		return errs
+	}
+	content, _ := resp["content"].(map[string]interface{})
+	if content == nil && resp["schema"] != nil {
+		// Swagger 2
+		content = map[string]interface{}{"application/json": map[string]interface{}{"schema": resp["schema"]}}
+	}
+	if content == nil {
+		return errs
+	}
+	contentType, _ := header(c.ResponseHeaders, "Content-Type")
+	return append(errs, s.validateContent(content, contentType, c.ResponseBody, "response body", inResponse)...)
+}
+
+// validateContent validates a body against the media type matching its content type
+func (s *Spec) validateContent(content map[string]interface{}, contentType, body, path string, dir direction) []error {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	var media map[string]interface{}
+	if mediaType == "" && len(content) == 1 {
+		for _, m := range content {
+			media, _ = m.(map[string]interface{})
+		}
+		for k := range content {
+			mediaType = k
+		}
+	} else {
+		candidates := []string{mediaType, strings.Split(mediaType, "/")[0] + "/*", "*/*"}
+		for _, candidate := range candidates {
+			if m, ok := content[candidate]; ok {
+				media, _ = m.(map[string]interface{})
+				break
+			}
+		}
+		if media == nil {
+			return []error{fmt.Errorf("%s: content type %q is not documented", path, contentType)}
+		}
+	}
+	if media == nil || media["schema"] == nil || !strings.Contains(mediaType, "json") {
+		return nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal([]byte(body), &value); err != nil {
+		return []error{fmt.Errorf("%s: invalid JSON: %v", path, err)}
+	}
+	return s.validateSchema(media["schema"], value, path, dir)
+}
+
+// header returns the value of a header, its name is case insensitive
+func header(headers map[string]string, name string) (string, bool) {
+	for k, v := range headers {
+		if strings.EqualFold(k, name) {
+			return v, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,178 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const petstoreContract = `
+openapi: 3.0.0
+paths:
+  /pets:
+    get:
+      parameters:
+      - name: limit
+        in: query
+        schema:
+          type: integer
+          maximum: 100
+      - name: X-Request-ID
+        in: header
+        required: true
+        schema:
+          type: string
+          format: uuid
+      responses:
+        "200":
+          description: the pets
+          headers:
+            X-Next:
+              required: true
+              schema:
+                type: string
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: '#/components/schemas/Pet'
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Pet'
+      responses:
+        "201":
+          description: created
+  /pets/{petId}:
+    parameters:
+    - name: petId
+      in: path
+      required: true
+      schema:
+        type: integer
+    get:
+      responses:
+        "200":
+          description: a pet
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pet'
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [id, name]
+      additionalProperties: false
+      properties:
+        id:
+          type: integer
+          readOnly: true
+        name:
+          type: string
+          minLength: 1
+        tag:
+          type: string
+          nullable: true
+        status:
+          type: string
+          enum: [available, sold]
+`
+
+func TestSpec_Validate(t *testing.T) {
+	spec, err := Parse([]byte(petstoreContract))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		call Call
+		want []string
+	}{
+		{
+			name: "valid list",
+			call: Call{
+				Method:          "GET",
+				URL:             "http://localhost/pets?limit=10",
+				RequestHeaders:  map[string]string{"x-request-id": "b4c7e5c4-2c4e-4c3a-9f0e-3f1f7a8c9d10"},
+				StatusCode:      200,
+				ResponseHeaders: map[string]string{"content-type": "application/json; charset=utf-8", "x-next": "2"},
+				ResponseBody:    `[{"id": 1, "name": "rex", "tag": null, "status": "sold"}]`,
+			},
+		},
+		{
+			name: "invalid list",
+			call: Call{
+				Method:          "GET",
+				URL:             "http://localhost/pets?limit=1000",
+				StatusCode:      200,
+				ResponseHeaders: map[string]string{"Content-Type": "application/json"},
+				ResponseBody:    `[{"id": 1.5, "name": "", "status": "lost", "age": 3}]`,
+			},
+			want: []string{
+				"GET /pets: query parameter limit: 1000 should be lower than 100",
+				"GET /pets: header parameter X-Request-ID is required",
+				"GET /pets: response header X-Next is required",
+				"GET /pets: response body[0]: property age is not allowed",
+				"GET /pets: response body[0].id: expected integer, got number",
+				"GET /pets: response body[0].name: length should be at least 1",
+				`GET /pets: response body[0].status: lost is not one of [available sold]`,
+			},
+		},
+		{
+			name: "valid creation, id is read only",
+			call: Call{
+				Method:         "POST",
+				URL:            "http://localhost/pets",
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				RequestBody:    `{"name": "rex"}`,
+				StatusCode:     201,
+			},
+		},
+		{
+			name: "invalid creation",
+			call: Call{
+				Method:         "POST",
+				URL:            "http://localhost/pets",
+				RequestHeaders: map[string]string{"Content-Type": "text/plain"},
+				RequestBody:    `rex`,
+				StatusCode:     500,
+			},
+			want: []string{
+				`POST /pets: request body: content type "text/plain" is not documented`,
+				"POST /pets: status code 500 is not documented",
+			},
+		},
+		{
+			name: "missing body",
+			call: Call{Method: "POST", URL: "http://localhost/pets", StatusCode: 201},
+			want: []string{"POST /pets: request body is required"},
+		},
+		{
+			name: "invalid path parameter",
+			call: Call{Method: "GET", URL: "http://localhost/pets/rex", StatusCode: 200, ResponseBody: `{"id": 1}`},
+			want: []string{
+				`GET /pets/{petId}: path parameter petId: "rex" is not a number`,
+				"GET /pets/{petId}: response body: property name is required",
+			},
+		},
+		{
+			name: "unknown operation",
+			call: Call{Method: "DELETE", URL: "http://localhost/pets", StatusCode: 204},
+			want: []string{"no operation of the OpenAPI spec matches DELETE http://localhost/pets"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, err := range spec.Validate(tt.call) {
+				got = append(got, err.Error())
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
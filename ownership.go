@@ -0,0 +1,106 @@
+package venom
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// selectedByOwnership returns true if the testsuite is owned by one of the teams of --team and one of the
+// owners of --owner, when they are given
+func (v *Venom) selectedByOwnership(ts TestSuite) bool {
+	return matchOwnership(v.Teams, ts.Team) && matchOwnership(v.Owners, ts.Owner)
+}
+
+func matchOwnership(filters []string, value string) bool {
+	if len(filters) == 0 {
+		return true
+	}
+	for _, f := range filters {
+		if f == value {
+			return true
+		}
+	}
+	return false
+}
+
+// splitTests returns the tests grouped by the team or the owner of their testsuites, the testsuites without
+// team or owner are grouped in "none"
+func splitTests(tests Tests, by string) map[string]Tests {
+	groups := map[string]Tests{}
+	for _, ts := range tests.TestSuites {
+		key := ts.Team
+		if by == "owner" {
+			key = ts.Owner
+		}
+		if key == "" {
+			key = "none"
+		}
+		g := groups[key]
+		g.addTestSuite(ts)
+		groups[key] = g
+	}
+	return groups
+}
+
+// splitKeys returns the sorted keys of the groups
+func splitKeys(groups map[string]Tests) []string {
+	keys := make([]string, 0, len(groups))
+	for k := range groups {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// splitFilename returns the file of a group of a report: test_results.<group>.<ext>
+func (v *Venom) splitFilename(group, ext string) string {
+	return filepath.Join(v.OutputDir, "test_results."+slug(group)+"."+ext)
+}
+
+// writeSplitResults writes a report by team or by owner, for each format, when --split-by is set
+func (v *Venom) writeSplitResults(tests Tests) error {
+	switch v.SplitBy {
+	case "":
+		return nil
+	case "team", "owner":
+	default:
+		return fmt.Errorf("Error: unsupported split %q, must be team or owner", v.SplitBy)
+	}
+	groups := splitTests(tests, v.SplitBy)
+	for _, format := range strings.Split(v.OutputFormat, ",") {
+		format = strings.TrimSpace(format)
+		if format == "teamcity" {
+			continue
+		}
+		for _, group := range splitKeys(groups) {
+			data, ext, err := formatResult(groups[group], format)
+			if err != nil {
+				return err
+			}
+			filename := v.splitFilename(group, ext)
+			if err := ioutil.WriteFile(filename, data, 0644); err != nil {
+				return fmt.Errorf("Error while creating file %s: %v", filename, err)
+			}
+			v.PrintFunc("Writing file %s\n", filename)
+		}
+	}
+	return nil
+}
+
+// ownership returns the team and the owner of a testsuite, to display them after its name
+func ownership(ts TestSuite) string {
+	var parts []string
+	if ts.Team != "" {
+		parts = append(parts, "team: "+ts.Team)
+	}
+	if ts.Owner != "" {
+		parts = append(parts, "owner: "+ts.Owner)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
@@ -0,0 +1,42 @@
+package venom
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitTests(t *testing.T) {
+	tests := Tests{TestSuites: []TestSuite{
+		{Name: "a", Team: "payments", Failures: 1, TestCases: []TestCase{{Name: "ko", Failures: []Failure{{Value: "boom"}}}}},
+		{Name: "b", Team: "payments", TestCases: []TestCase{{Name: "ok"}, {Name: "ok2"}}},
+		{Name: "c", Owner: "alice", TestCases: []TestCase{{Name: "ok"}}},
+	}}
+
+	groups := splitTests(tests, "team")
+	if keys := splitKeys(groups); !reflect.DeepEqual([]string{"none", "payments"}, keys) {
+		t.Fatalf("unexpected groups %v", keys)
+	}
+	if g := groups["payments"]; len(g.TestSuites) != 2 || g.Total != 3 || g.TotalKO != 1 || g.TotalOK != 2 {
+		t.Errorf("unexpected payments group %+v", g)
+	}
+
+	groups = splitTests(tests, "owner")
+	if g := groups["alice"]; len(g.TestSuites) != 1 || g.TestSuites[0].Name != "c" {
+		t.Errorf("unexpected alice group %+v", g)
+	}
+}
+
+func TestSelectedByOwnership(t *testing.T) {
+	v := New()
+	if !v.selectedByOwnership(TestSuite{}) {
+		t.Error("all the testsuites are selected without filter")
+	}
+	v.Teams = []string{"payments", "search"}
+	v.Owners = []string{"alice"}
+	if !v.selectedByOwnership(TestSuite{Team: "search", Owner: "alice"}) {
+		t.Error("expected a selected testsuite")
+	}
+	if v.selectedByOwnership(TestSuite{Team: "search", Owner: "bob"}) || v.selectedByOwnership(TestSuite{Owner: "alice"}) {
+		t.Error("expected a testsuite not selected")
+	}
+}
@@ -0,0 +1,76 @@
+package venom
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/ovh/venom/pact"
+)
+
+// recordPactInteraction records the call made by an http step as an interaction of a Pact contract.
+// The provider is the host of the url, unless --pact-provider is set.
+func (v *Venom) recordPactInteraction(e *ExecutorWrap, ts *TestSuite, tc *TestCase, stepNumber int, step TestStep, result ExecutorResult) {
+	if v.pactRecorder == nil {
+		return
+	}
+	c, ok := httpCall(e, result)
+	if !ok {
+		return
+	}
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return
+	}
+	provider := v.PactProvider
+	if provider == "" {
+		provider = u.Host
+	}
+	method := strings.ToUpper(c.Method)
+	if method == "" {
+		method = "GET"
+	}
+
+	i := pact.Interaction{
+		Description: fmt.Sprintf("%s - %s - step %d", ts.ShortName, tc.Name, stepNumber),
+		Request: pact.Request{
+			Method: method,
+			Path:   u.EscapedPath(),
+			Query:  u.RawQuery,
+			Body:   pact.Body(c.RequestBody),
+		},
+		Response: pact.Response{
+			Status: c.StatusCode,
+			Body:   pact.Body(c.ResponseBody),
+		},
+	}
+	if i.Request.Path == "" {
+		i.Request.Path = "/"
+	}
+	if state, ok := step["pact_provider_state"]; ok {
+		i.ProviderState = fmt.Sprintf("%v", state)
+	}
+	if len(c.RequestHeaders) > 0 {
+		i.Request.Headers = c.RequestHeaders
+	}
+	// only the content type of the response is expected, other headers change between calls
+	if contentType, ok := c.ResponseHeaders["content-type"]; ok {
+		i.Response.Headers = map[string]string{"Content-Type": contentType}
+	}
+	v.pactRecorder.Record(provider, i)
+}
+
+// writePacts writes the Pact contracts in the pact directory
+func (v *Venom) writePacts() error {
+	if v.pactRecorder == nil {
+		return nil
+	}
+	files, err := v.pactRecorder.Write(v.PactDir)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		v.PrintFunc("Writing file %s\n", f)
+	}
+	return nil
+}
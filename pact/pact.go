@@ -0,0 +1,148 @@
+// Package pact writes Pact consumer contracts from HTTP interactions.
+package pact
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// SpecificationVersion is the version of the Pact specification of the written contracts
+const SpecificationVersion = "2.0.0"
+
+var regexpFilename = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// Pact is a contract between a consumer and a provider
+type Pact struct {
+	Consumer     Participant   `json:"consumer"`
+	Provider     Participant   `json:"provider"`
+	Interactions []Interaction `json:"interactions"`
+	Metadata     Metadata      `json:"metadata"`
+}
+
+// Participant is the consumer or the provider of a contract
+type Participant struct {
+	Name string `json:"name"`
+}
+
+// Metadata of a contract
+type Metadata struct {
+	PactSpecification struct {
+		Version string `json:"version"`
+	} `json:"pactSpecification"`
+}
+
+// Interaction is a request sent by the consumer and the response expected from the provider
+type Interaction struct {
+	Description   string   `json:"description"`
+	ProviderState string   `json:"providerState,omitempty"`
+	Request       Request  `json:"request"`
+	Response      Response `json:"response"`
+}
+
+// Request of an interaction
+type Request struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Query   string            `json:"query,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    interface{}       `json:"body,omitempty"`
+}
+
+// Response of an interaction
+type Response struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    interface{}       `json:"body,omitempty"`
+}
+
+// Recorder records the interactions with each provider
+type Recorder struct {
+	consumer string
+	mutex    sync.Mutex
+	pacts    map[string]map[string]Interaction
+}
+
+// NewRecorder returns a new Recorder for the consumer
+func NewRecorder(consumer string) *Recorder {
+	return &Recorder{consumer: consumer, pacts: map[string]map[string]Interaction{}}
+}
+
+// Record records an interaction with the provider. An interaction with the same description
+// and provider state replaces the previous one: only the last attempt of a retried step is kept.
+func (r *Recorder) Record(provider string, i Interaction) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.pacts[provider] == nil {
+		r.pacts[provider] = map[string]Interaction{}
+	}
+	r.pacts[provider][i.Description+"\n"+i.ProviderState] = i
+}
+
+// Pacts returns the contracts with each provider, sorted by provider name
+func (r *Recorder) Pacts() []Pact {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var pacts []Pact
+	for provider, interactions := range r.pacts {
+		p := Pact{Consumer: Participant{Name: r.consumer}, Provider: Participant{Name: provider}}
+		p.Metadata.PactSpecification.Version = SpecificationVersion
+		for _, i := range interactions {
+			p.Interactions = append(p.Interactions, i)
+		}
+		sort.Slice(p.Interactions, func(i, j int) bool {
+			if p.Interactions[i].Description != p.Interactions[j].Description {
+				return p.Interactions[i].Description < p.Interactions[j].Description
+			}
+			return p.Interactions[i].ProviderState < p.Interactions[j].ProviderState
+		})
+		pacts = append(pacts, p)
+	}
+	sort.Slice(pacts, func(i, j int) bool { return pacts[i].Provider.Name < pacts[j].Provider.Name })
+	return pacts
+}
+
+// Write writes a <consumer>-<provider>.json file by provider in dir, and returns the written files
+func (r *Recorder) Write(dir string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, p := range r.Pacts() {
+		data, err := json.MarshalIndent(p, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		filename := filepath.Join(dir, Filename(p.Consumer.Name, p.Provider.Name))
+		if err := ioutil.WriteFile(filename, data, 0644); err != nil {
+			return nil, fmt.Errorf("Error while creating file %s: %v", filename, err)
+		}
+		files = append(files, filename)
+	}
+	return files, nil
+}
+
+// Filename returns the name of the contract file between the consumer and the provider
+func Filename(consumer, provider string) string {
+	name := strings.ToLower(consumer + "-" + provider)
+	return regexpFilename.ReplaceAllString(name, "_") + ".json"
+}
+
+// Body returns the body as JSON if it is valid JSON, as a string otherwise
+func Body(body string) interface{} {
+	if strings.TrimSpace(body) == "" {
+		return nil
+	}
+	var v interface{}
+	if err := json.Unmarshal([]byte(body), &v); err == nil {
+		return v
+	}
+	return body
+}
@@ -0,0 +1,58 @@
+package pact
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder_Write(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pact")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	r := NewRecorder("front")
+	r.Record("pets api", Interaction{
+		Description: "list pets",
+		Request:     Request{Method: "GET", Path: "/pets", Query: "limit=1"},
+		Response:    Response{Status: 500},
+	})
+	// a retried step replaces the previous attempt
+	r.Record("pets api", Interaction{
+		Description: "list pets",
+		Request:     Request{Method: "GET", Path: "/pets", Query: "limit=1"},
+		Response:    Response{Status: 200, Body: Body(`[{"id":1}]`)},
+	})
+	r.Record("pets api", Interaction{
+		Description:   "create a pet",
+		ProviderState: "no pet",
+		Request:       Request{Method: "POST", Path: "/pets", Body: Body(`{"name":"rex"}`)},
+		Response:      Response{Status: 201, Body: Body("created")},
+	})
+	r.Record("users", Interaction{Description: "me", Request: Request{Method: "GET", Path: "/me"}, Response: Response{Status: 200}})
+
+	files, err := r.Write(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "front-pets_api.json"), filepath.Join(dir, "front-users.json")}, files)
+
+	btes, err := ioutil.ReadFile(files[0])
+	require.NoError(t, err)
+	var p Pact
+	require.NoError(t, json.Unmarshal(btes, &p))
+	assert.Equal(t, "front", p.Consumer.Name)
+	assert.Equal(t, "pets api", p.Provider.Name)
+	assert.Equal(t, "2.0.0", p.Metadata.PactSpecification.Version)
+	require.Len(t, p.Interactions, 2)
+	assert.Equal(t, "create a pet", p.Interactions[0].Description)
+	assert.Equal(t, "no pet", p.Interactions[0].ProviderState)
+	assert.Equal(t, map[string]interface{}{"name": "rex"}, p.Interactions[0].Request.Body)
+	assert.Equal(t, "created", p.Interactions[0].Response.Body)
+	assert.Equal(t, "list pets", p.Interactions[1].Description)
+	assert.Equal(t, 200, p.Interactions[1].Response.Status)
+	assert.Equal(t, []interface{}{map[string]interface{}{"id": 1.0}}, p.Interactions[1].Response.Body)
+}
@@ -1,12 +1,16 @@
 package venom
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 
+	"github.com/ovh/venom/openapi"
+	"github.com/ovh/venom/pact"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -28,22 +32,48 @@ func (v *Venom) init() error {
 		log.SetLevel(log.WarnLevel)
 	}
 
+	// the log file of a previous run of the monitor mode is closed
+	if v.logFile != nil {
+		v.logFile.Close()
+	}
 	var err error
-	v.LogOutput, err = os.OpenFile("venom.log", os.O_CREATE|os.O_RDWR, os.FileMode(0644))
+	v.logFile, err = os.OpenFile("venom.log", os.O_CREATE|os.O_RDWR, os.FileMode(0644))
 	if err != nil {
 		return fmt.Errorf("unable to write log file: %v", err)
 	}
+	v.LogOutput = v.logFile
 
 	log.SetOutput(v.LogOutput)
 	return nil
 }
 
+// resetRun resets the state of the previous run: the monitor mode runs Process again on the same Venom
+func (v *Venom) resetRun() {
+	v.stepCacheMutex.Lock()
+	v.stepCache = map[string]ExecutorResult{}
+	v.stepCacheMutex.Unlock()
+	v.freePortsMutex.Lock()
+	v.freePorts = nil
+	v.freePortsMutex.Unlock()
+	v.debugMutex.Lock()
+	v.debugAborted = false
+	v.debugMutex.Unlock()
+}
+
 // Parse parses tests suite to check context and variables
 func (v *Venom) Parse(path []string, exclude []string) error {
+	v.runMutex.Lock()
+	defer v.runMutex.Unlock()
+	v.resources = nil
+	defer v.trackResources("parse")()
 	if err := v.init(); err != nil {
 		return err
 	}
 
+	path, err := v.resolveRemoteSources(path)
+	if err != nil {
+		return err
+	}
 	filesPath, err := getFilesPath(path, exclude)
 	if err != nil {
 		return err
@@ -124,68 +154,181 @@ func (v *Venom) Parse(path []string, exclude []string) error {
 	return nil
 }
 
-// Process runs tests suite and return a Tests result
-func (v *Venom) Process(path []string, exclude []string) (*Tests, error) {
+// Process runs tests suite and return a Tests result. When ctx is done, the running steps are stopped and the
+// testcases not run are skipped: the result is the result of the testcases run until then.
+func (v *Venom) Process(ctx context.Context, path []string, exclude []string) (*Tests, error) {
+	v.runMutex.Lock()
+	defer v.runMutex.Unlock()
+	v.resetRun()
 	if err := v.init(); err != nil {
 		return nil, err
 	}
 
+	path, err := v.resolveRemoteSources(path)
+	if err != nil {
+		return nil, err
+	}
 	filesPath, err := getFilesPath(path, exclude)
 	if err != nil {
 		return nil, err
 	}
 
+	if v.ComposeFile != "" {
+		workdir, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		vars, stop, err := startServices(Services{ComposeFile: v.ComposeFile}, workdir, filepath.Base(workdir))
+		defer stop()
+		if err != nil {
+			return nil, err
+		}
+		v.AddVariables(vars)
+	}
+
 	if err := v.readFiles(filesPath); err != nil {
 		return nil, err
 	}
 
+	if err := v.loadQuarantine(); err != nil {
+		return nil, err
+	}
+	v.rateLimiters, err = parseRateLimits(v.RateLimits)
+	if err != nil {
+		return nil, err
+	}
+	v.hostLimiter, err = parseHostConcurrency(v.HostConcurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	if v.OpenAPIValidate && v.OpenAPIFile == "" {
+		return nil, fmt.Errorf("an OpenAPI spec is needed to validate the http steps")
+	}
+	if v.OpenAPIFile != "" {
+		spec, err := openapi.Load(v.OpenAPIFile)
+		if err != nil {
+			return nil, err
+		}
+		v.openAPISpec = spec
+		v.openAPICoverage = openapi.NewCoverage(spec)
+	}
+	if v.PactDir != "" {
+		consumer := v.PactConsumer
+		if consumer == "" {
+			consumer = "venom"
+		}
+		v.pactRecorder = pact.NewRecorder(consumer)
+	}
+
+	stopTracking := v.trackResources("run")
 	chanEnd := make(chan *TestSuite, 1)
-	parallels := make(chan *TestSuite, v.Parallel) //Run testsuite in parrallel
 	wg := sync.WaitGroup{}
 	testsResult := &Tests{}
 
 	wg.Add(len(v.testsuites))
-	chanToRun := make(chan *TestSuite, len(v.testsuites)+1)
-
 	go v.computeStats(testsResult, chanEnd, &wg)
-	go func() {
-		for ts := range chanToRun {
-			parallels <- ts
-			go func(ts *TestSuite) {
-				v.runTestSuite(ts)
-				chanEnd <- ts
-				<-parallels
-			}(ts)
-		}
-	}()
-
-	for i := range v.testsuites {
-		chanToRun <- &v.testsuites[i]
-	}
+	// the testsuites are run by the workers, --parallel at once
+	runWorkers(v.Parallel, len(v.testsuites), func(i int) {
+		v.runTestSuite(ctx, &v.testsuites[i])
+		chanEnd <- &v.testsuites[i]
+	})
+	close(chanEnd)
 
 	wg.Wait()
+	stopTracking()
+	testsResult.Resources, v.resources = v.resources, nil
+
+	if err := v.writePacts(); err != nil {
+		return nil, err
+	}
 
 	return testsResult, nil
 }
 
 func (v *Venom) computeStats(testsResult *Tests, chanEnd <-chan *TestSuite, wg *sync.WaitGroup) {
 	for t := range chanEnd {
-		testsResult.TestSuites = append(testsResult.TestSuites, *t)
-		if t.Failures > 0 || t.Errors > 0 {
-			testsResult.TotalKO += (t.Failures + t.Errors)
-		} else {
-			testsResult.TotalOK += len(t.TestCases) - (t.Failures + t.Errors)
+		testsResult.addTestSuite(*t)
+		wg.Done()
+	}
+}
+
+// addTestSuite adds the testsuite to the tests and counts its testcases, the testsuite and its steps by status.
+// A testsuite fails when one of its testcases fails and is skipped when all of them are skipped. The
+// quarantined testcases are ok: their failures don't fail the run. The testcases ko with errors, an executor or
+// their setup couldn't run, are also counted in TotalErrors.
+func (testsResult *Tests) addTestSuite(t TestSuite) {
+	testsResult.TestSuites = append(testsResult.TestSuites, t)
+
+	var testcases StatusCounts
+	for _, tc := range t.TestCases {
+		status := testCaseStatus(tc)
+		if tc.Status == "quarantined" {
+			status = "SUCCESS"
 		}
-		if t.Skipped > 0 {
-			testsResult.TotalSkipped += t.Skipped
+		testcases.add(status, 1)
+		if status == "FAILURE" && len(tc.Errors) > 0 {
+			testsResult.TotalErrors++
 		}
 
-		testsResult.Total = testsResult.TotalKO + testsResult.TotalOK + testsResult.TotalSkipped
-		wg.Done()
+		steps := tc.StepsCounts
+		if steps.Total == 0 {
+			// the testcase was not run
+			steps = StatusCounts{Total: len(tc.TestSteps), Skipped: len(tc.TestSteps)}
+		}
+		testsResult.StepsCounts.merge(steps)
+	}
+
+	switch {
+	case testcases.KO > 0:
+		testsResult.TestSuitesCounts.add("FAILURE", 1)
+	case testcases.Total > 0 && testcases.Skipped == testcases.Total:
+		testsResult.TestSuitesCounts.add("SKIPPED", 1)
+	default:
+		testsResult.TestSuitesCounts.add("SUCCESS", 1)
+	}
+
+	testsResult.TotalOK += testcases.OK
+	testsResult.TotalKO += testcases.KO
+	testsResult.TotalSkipped += testcases.Skipped
+	testsResult.Total += testcases.Total
+}
+
+// add counts n items of a status: SUCCESS, FAILURE or SKIPPED
+func (c *StatusCounts) add(status string, n int) {
+	switch status {
+	case "FAILURE":
+		c.KO += n
+	case "SKIPPED":
+		c.Skipped += n
+	default:
+		c.OK += n
 	}
+	c.Total += n
+}
+
+func (c *StatusCounts) merge(o StatusCounts) {
+	c.Total += o.Total
+	c.OK += o.OK
+	c.KO += o.KO
+	c.Skipped += o.Skipped
 }
 
+// rightPad pads or truncates s to pLen columns, wide characters use 2 columns
 func rightPad(s string, padStr string, pLen int) string {
-	o := s + strings.Repeat(padStr, pLen)
-	return o[0:pLen]
+	var o strings.Builder
+	var width int
+	for _, r := range s {
+		w := runeWidth(r)
+		if width+w > pLen {
+			break
+		}
+		o.WriteRune(r)
+		width += w
+	}
+	for width < pLen && padStr != "" {
+		o.WriteString(padStr)
+		width += len(padStr)
+	}
+	return o.String()
 }
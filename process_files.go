@@ -1,7 +1,9 @@
 package venom
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -19,7 +21,7 @@ func getFilesPath(path []string, exclude []string) (filePaths []string, err erro
 
 	if len(exclude) > 0 {
 		for _, p := range exclude {
-			pe, err := filepath.Glob(p)
+			pe, err := filepath.Glob(filepath.FromSlash(strings.TrimSpace(p)))
 			if err != nil {
 				return nil, errors.Wrapf(err, "error reading files in path %q", path)
 			}
@@ -29,13 +31,14 @@ func getFilesPath(path []string, exclude []string) (filePaths []string, err erro
 	}
 
 	for _, p := range path {
-		p = strings.TrimSpace(p)
+		p = filepath.FromSlash(strings.TrimSpace(p))
 
 		// no need to check err on os.stat.
 		// if we put ./test/*.yml, it will fail and it's normal
 		fileInfo, _ := os.Stat(p)
 		if fileInfo != nil && fileInfo.IsDir() {
-			p = p + string(os.PathSeparator) + "*.yml"
+			// the extensions are checked below: .yml, .yaml and .hcl files are read
+			p = filepath.Join(p, "*")
 		}
 
 		fpaths, err := filepath.Glob(p)
@@ -47,7 +50,7 @@ func getFilesPath(path []string, exclude []string) (filePaths []string, err erro
 		for _, fp := range fpaths {
 			toExclude := false
 			for _, te := range fpathsExcluded {
-				if te == fp {
+				if filepath.Clean(te) == filepath.Clean(fp) {
 					toExclude = true
 					break
 				}
@@ -70,67 +73,120 @@ func getFilesPath(path []string, exclude []string) (filePaths []string, err erro
 }
 
 func (v *Venom) readFiles(filesPath []string) (err error) {
-	for _, f := range filesPath {
-		log.Info("Reading ", f)
-		dat, err := ioutil.ReadFile(f)
-		if err != nil {
-			return fmt.Errorf("Error while reading file %s err:%s", f, err)
+	if err := v.loadTargets(); err != nil {
+		return err
+	}
+	// the files are read by the workers of the run, their testsuites are kept in the order of the files
+	suites := make([][]TestSuite, len(filesPath))
+	errs := make([]error, len(filesPath))
+	runWorkers(v.Parallel, len(filesPath), func(i int) {
+		suites[i], errs[i] = v.readFile(filesPath[i])
+	})
+	for i := range filesPath {
+		if errs[i] != nil {
+			return errs[i]
 		}
+		v.testsuites = append(v.testsuites, suites[i]...)
+	}
+	return nil
+}
 
-		ts := TestSuite{}
-		ts.Templater = newTemplater(v.variables)
-		ts.Package = f
+// readFile returns the testsuites of a file, selected by the teams and owners of the run, with the testcases selected
+// by their tags and their names
+func (v *Venom) readFile(f string) ([]TestSuite, error) {
+	log.Info("Reading ", f)
+	dat, err := ioutil.ReadFile(f)
+	if err != nil {
+		return nil, fmt.Errorf("Error while reading file %s err:%s", f, err)
+	}
 
-		// Apply templater unitl there is no more modifications
-		// it permits to include testcase from env
-		_, out := ts.Templater.apply(dat)
-		for i := 0; i < 10; i++ {
-			_, tmp := ts.Templater.apply(out)
-			if string(tmp) == string(out) {
-				break
-			}
-			out = tmp
+	// Apply templater unitl there is no more modifications
+	// it permits to include testcase from env
+	_, out := newTemplater(v.variables).apply(dat)
+	for i := 0; i < 10; i++ {
+		_, tmp := newTemplater(v.variables).apply(out)
+		if string(tmp) == string(out) {
+			break
 		}
+		out = tmp
+	}
 
-		err = nil
-		switch ext := filepath.Ext(f); ext {
-		case ".hcl":
-			err = hcl.Unmarshal(out, &ts)
-		case ".yaml", ".yml":
-			err = yaml.Unmarshal(out, &ts)
-		default:
-			return fmt.Errorf("unsupported test suite file extension: %q", ext)
-		}
-		if err != nil {
-			return fmt.Errorf("Error while unmarshal file %s err: %v", f, err)
-		}
+	var suites []TestSuite
+	switch ext := filepath.Ext(f); ext {
+	case ".hcl":
+		ts := TestSuite{}
+		err = hcl.Unmarshal(out, &ts)
+		suites = append(suites, ts)
+	case ".yaml", ".yml":
+		suites, err = unmarshalTestSuites(out)
+	default:
+		return nil, fmt.Errorf("unsupported test suite file extension: %q", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error while unmarshal file %s err: %v", f, err)
+	}
 
+	var selected []TestSuite
+	for i := range suites {
+		ts := suites[i]
+		ts.Templater = newTemplater(v.variables)
+		ts.Package = f
+		if len(suites) > 1 {
+			// the testsuites of a multi-document file are reported separately
+			ts.Package = fmt.Sprintf("%s#%d", f, i+1)
+		}
 		ts.ShortName = ts.Name
 		ts.Name += " [" + f + "]"
 		ts.Filename = f
 
-		if ts.Version != "" && !strings.HasPrefix(ts.Version, "1") {
-			ts.WorkDir, err = filepath.Abs(filepath.Dir(f))
-			if err != nil {
-				return fmt.Errorf("Unable to get testsuite's working directory err:%s", err)
-			}
-		} else {
-			ts.WorkDir, err = os.Getwd()
-			if err != nil {
-				return fmt.Errorf("Unable to get current working directory err:%s", err)
-			}
+		if err := setupTestSuiteVersion(&ts); err != nil {
+			return nil, err
 		}
-
-		nSteps := 0
-		for _, tc := range ts.TestCases {
-			nSteps += len(tc.TestSteps)
-			if len(tc.Skipped) >= 1 {
-				ts.Skipped += len(tc.Skipped)
-			}
+		if err := v.applyTargets(&ts); err != nil {
+			return nil, err
+		}
+		applyExecutorConfig(&ts)
+		if !v.selectByTags(&ts) {
+			log.Infof("Testsuite %s has no testcase with the selected tags", ts.Package)
+			continue
+		}
+		if !v.selectByName(&ts) {
+			log.Infof("Testsuite %s has no testcase with the selected names", ts.Package)
+			continue
 		}
 		ts.Total = len(ts.TestCases)
 
-		v.testsuites = append(v.testsuites, ts)
+		if !v.selectedByOwnership(ts) {
+			log.Infof("Testsuite %s is not owned by the selected teams and owners", ts.Package)
+			continue
+		}
+		selected = append(selected, ts)
 	}
-	return nil
+	return selected, nil
+}
+
+// unmarshalTestSuites returns the testsuites of the documents of a yaml file, separated by ---.
+// The empty documents are ignored.
+func unmarshalTestSuites(btes []byte) ([]TestSuite, error) {
+	var suites []TestSuite
+	dec := yaml.NewDecoder(bytes.NewReader(btes))
+	for {
+		var ts TestSuite
+		err := dec.Decode(&ts)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if ts.Name == "" && len(ts.TestCases) == 0 {
+			continue
+		}
+		suites = append(suites, ts)
+	}
+	if len(suites) == 0 {
+		// an empty file is read as an empty testsuite
+		suites = append(suites, TestSuite{})
+	}
+	return suites, nil
 }
@@ -43,6 +43,7 @@ func Test_getFilesPath(t *testing.T) {
 		name    string
 		args    args
 		want    []string
+		wantOut []string
 		wantErr bool
 	}{
 		{
@@ -98,6 +99,26 @@ func Test_getFilesPath(t *testing.T) {
 			want:    []string{"d1.yml", "d2.yml"},
 			wantErr: false,
 		},
+		{
+			name: "Check an directory with yml, yaml and excluded files",
+			init: func(t *testing.T) ([]string, error) {
+				dir, err := tempDir(t)
+				if err != nil {
+					return nil, err
+				}
+
+				for _, f := range []string{"d1.yml", "d2.yaml", "d3.yml", "d4.txt"} {
+					if err := ioutil.WriteFile(path.Join(dir, f), []byte("hello"), 0644); err != nil {
+						return nil, err
+					}
+				}
+				return []string{dir}, nil
+			},
+			args:    args{exclude: []string{path.Join(os.TempDir(), "*", "d3.yml")}},
+			want:    []string{"d1.yml", "d2.yaml"},
+			wantOut: []string{"d3.yml", "d4.txt"},
+			wantErr: false,
+		},
 	}
 
 	for i := range tests {
@@ -125,6 +146,211 @@ func Test_getFilesPath(t *testing.T) {
 					t.Errorf("getFilesPath() error want %v got %v", f, got)
 				}
 			}
+			for _, f := range tt.wantOut {
+				for _, g := range got {
+					if strings.HasSuffix(g, f) {
+						t.Errorf("getFilesPath() error don't want %v got %v", f, got)
+					}
+				}
+			}
 		})
 	}
 }
+
+func Test_readFiles_anchors(t *testing.T) {
+	dir, err := tempDir(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	suite := `name: anchors
+x-http: &http
+  type: http
+  method: GET
+  url: https://api.example.com
+  assertions: &ok
+  - result.statuscode ShouldEqual 200
+x-login: &login
+  name: login
+  steps:
+  - <<: *http
+    method: POST
+    path: /login
+testcases:
+- *login
+- name: pets
+  steps:
+  - <<: *http
+    path: /pets
+  - <<: *http
+    path: /owners
+    assertions: *ok
+`
+	filename := path.Join(dir, "anchors.yml")
+	if err := ioutil.WriteFile(filename, []byte(suite), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	v := New()
+	if err := v.readFiles([]string{filename}); err != nil {
+		t.Fatal(err)
+	}
+	tcs := v.testsuites[0].TestCases
+	if len(tcs) != 2 || tcs[0].Name != "login" || len(tcs[1].TestSteps) != 2 {
+		t.Fatalf("unexpected testcases %+v", tcs)
+	}
+	if s := tcs[0].TestSteps[0]; s["method"] != "POST" || s["path"] != "/login" || s["url"] != "https://api.example.com" {
+		t.Errorf("the merge key should be overridden by the keys of the step, got %v", s)
+	}
+	if s := tcs[1].TestSteps[1]; s["method"] != "GET" || s["path"] != "/owners" || len(s["assertions"].([]interface{})) != 1 {
+		t.Errorf("unexpected step %v", s)
+	}
+}
+
+func Test_readFiles_multiDocuments(t *testing.T) {
+	dir, err := tempDir(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	suites := `name: first
+testcases:
+- name: a
+  steps:
+  - script: echo a
+---
+version: "2"
+name: second
+testcases:
+- name: b
+  steps:
+  - script: echo b
+---
+`
+	filename := path.Join(dir, "suites.yml")
+	if err := ioutil.WriteFile(filename, []byte(suites), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	v := New()
+	if err := v.readFiles([]string{filename}); err != nil {
+		t.Fatal(err)
+	}
+	if len(v.testsuites) != 2 {
+		t.Fatalf("expected 2 testsuites, got %d", len(v.testsuites))
+	}
+	first, second := v.testsuites[0], v.testsuites[1]
+	if first.ShortName != "first" || first.Package != filename+"#1" || first.Filename != filename || first.TestCases[0].Name != "a" {
+		t.Errorf("unexpected first testsuite %+v", first)
+	}
+	if second.ShortName != "second" || second.Package != filename+"#2" || second.WorkDir != dir || second.TestCases[0].Name != "b" {
+		t.Errorf("unexpected second testsuite %+v", second)
+	}
+	if first.Templater == second.Templater {
+		t.Error("the testsuites should have their own templater")
+	}
+}
+
+func Test_readFiles_executorConfig(t *testing.T) {
+	dir, err := tempDir(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	suite := `name: config
+config:
+  http:
+    url: https://api.example.com
+    headers:
+      Accept: application/json
+      X-Tenant: "42"
+testcases:
+- name: pets
+  steps:
+  - type: http
+    headers:
+      X-Tenant: "43"
+  - type: http
+    url: https://other.example.com
+  - script: echo pets
+`
+	filename := path.Join(dir, "config.yml")
+	if err := ioutil.WriteFile(filename, []byte(suite), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	v := New()
+	if err := v.readFiles([]string{filename}); err != nil {
+		t.Fatal(err)
+	}
+	steps := v.testsuites[0].TestCases[0].TestSteps
+	headers, _ := steps[0]["headers"].(map[string]interface{})
+	if steps[0]["url"] != "https://api.example.com" || headers["Accept"] != "application/json" || headers["X-Tenant"] != "43" {
+		t.Errorf("the config should be merged into the step, got %v", steps[0])
+	}
+	headers, _ = steps[1]["headers"].(map[string]interface{})
+	if steps[1]["url"] != "https://other.example.com" || headers["X-Tenant"] != "42" {
+		t.Errorf("the attributes of the step should override the config, got %v", steps[1])
+	}
+	if _, ok := steps[2]["url"]; ok {
+		t.Errorf("the config of http should not be merged into an exec step, got %v", steps[2])
+	}
+}
+
+func Test_readFiles_targets(t *testing.T) {
+	dir, err := tempDir(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	targets := `billing-api:
+  url: https://billing.staging.example.com
+  basic_auth_user: venom
+search-api:
+  url: https://search.staging.example.com
+`
+	suite := `name: targets
+targets:
+  search-api:
+    url: http://localhost:9200
+testcases:
+- name: invoices
+  steps:
+  - type: http
+    target: billing-api
+    path: /invoices
+  - type: http
+    target: search-api
+    path: /_search
+`
+	targetsFile := path.Join(dir, "targets.yml")
+	filename := path.Join(dir, "suite.yml")
+	if err := ioutil.WriteFile(targetsFile, []byte(targets), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filename, []byte(suite), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	v := New()
+	v.TargetsFile = targetsFile
+	if err := v.readFiles([]string{filename}); err != nil {
+		t.Fatal(err)
+	}
+	steps := v.testsuites[0].TestCases[0].TestSteps
+	if steps[0]["url"] != "https://billing.staging.example.com" || steps[0]["basic_auth_user"] != "venom" || steps[0]["path"] != "/invoices" {
+		t.Errorf("the target of the run should be merged into the step, got %v", steps[0])
+	}
+	if steps[1]["url"] != "http://localhost:9200" {
+		t.Errorf("the target of the testsuite should override the target of the run, got %v", steps[1])
+	}
+
+	v.TargetsFile = ""
+	if err := v.readFiles([]string{filename}); err == nil || !strings.Contains(err.Error(), `target "billing-api"`) {
+		t.Errorf("expected an error for an undefined target, got %v", err)
+	}
+}
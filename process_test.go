@@ -0,0 +1,263 @@
+package venom
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type sleepingExecutor struct{}
+
+func (sleepingExecutor) Run(_ TestCaseContext, _ Logger, step TestStep, _ string) (ExecutorResult, error) {
+	time.Sleep(time.Millisecond)
+	return ExecutorResult{"result.value": step["value"]}, nil
+}
+
+// TestProcess_parallel runs testsuites in parallel, it's run with -race to check the engine
+func TestProcess_parallel(t *testing.T) {
+	dir, err := ioutil.TempDir("", "parallel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var files []string
+	for i := 0; i < 8; i++ {
+		suite := fmt.Sprintf(`name: suite%d
+testcases:
+- name: first
+  context:
+    type: default
+    suite: %d
+  steps:
+  - type: sleeping
+    value: %d
+    cache: true
+    assertions:
+    - result.value ShouldEqual %d
+- name: second
+  steps:
+  - type: sleeping
+    value: "{{.first.result.value}}"
+  - type: sleeping
+    name: named
+`, i, i, i, i)
+		filename := filepath.Join(dir, fmt.Sprintf("suite%d.yml", i))
+		if err := ioutil.WriteFile(filename, []byte(suite), 0644); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, filename)
+	}
+
+	v := New()
+	v.LogLevel = "disable"
+	v.Parallel = 4
+	v.PrintFunc = func(string, ...interface{}) (int, error) { return 0, nil }
+	v.RegisterExecutor("sleeping", sleepingExecutor{})
+	v.RegisterTestCaseContext("default", &testContext{CommonTestCaseContext{Name: "default"}})
+
+	tests, err := v.Process(context.Background(), files, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tests.Total != 16 || tests.TotalOK != 16 || len(tests.TestSuites) != 8 {
+		t.Errorf("expected 16 testcases ok in 8 testsuites, got %d ok of %d in %d testsuites", tests.TotalOK, tests.Total, len(tests.TestSuites))
+	}
+	if expected := (StatusCounts{Total: 24, OK: 24}); tests.StepsCounts != expected {
+		t.Errorf("expected steps counts %+v, got %+v", expected, tests.StepsCounts)
+	}
+}
+
+func TestTests_addTestSuite(t *testing.T) {
+	var tests Tests
+	tests.addTestSuite(TestSuite{Name: "mixed", TestCases: []TestCase{
+		{Name: "ok", TestSteps: []TestStep{{}, {}}, StepsCounts: StatusCounts{Total: 2, OK: 2}},
+		{Name: "ko", TestSteps: []TestStep{{}, {}, {}}, StepsCounts: StatusCounts{Total: 3, OK: 1, KO: 1, Skipped: 1},
+			Failures: []Failure{{Value: "first"}, {Value: "second"}}, Errors: []Failure{{Value: "error"}}},
+		{Name: "skipped", TestSteps: []TestStep{{}}, Skipped: SkippedList{{Value: "one"}, {Value: "two"}}},
+		{Name: "quarantined", Status: "quarantined", TestSteps: []TestStep{{}}, StepsCounts: StatusCounts{Total: 1, KO: 1},
+			Failures: []Failure{{Value: "flaky"}}},
+	}})
+	tests.addTestSuite(TestSuite{Name: "ok", TestCases: []TestCase{{Name: "ok"}}})
+	tests.addTestSuite(TestSuite{Name: "skipped", TestCases: []TestCase{{Name: "skipped", Skipped: SkippedList{{Value: "skipped"}}}}})
+
+	if tests.Total != 6 || tests.TotalOK != 3 || tests.TotalKO != 1 || tests.TotalSkipped != 2 {
+		t.Errorf("unexpected testcases counts: %d total, %d ok, %d ko, %d skipped", tests.Total, tests.TotalOK, tests.TotalKO, tests.TotalSkipped)
+	}
+	if tests.TotalErrors != 1 {
+		t.Errorf("expected 1 testcase in error, got %d", tests.TotalErrors)
+	}
+	if expected := (StatusCounts{Total: 3, OK: 1, KO: 1, Skipped: 1}); tests.TestSuitesCounts != expected {
+		t.Errorf("expected testsuites counts %+v, got %+v", expected, tests.TestSuitesCounts)
+	}
+	if expected := (StatusCounts{Total: 7, OK: 3, KO: 2, Skipped: 2}); tests.StepsCounts != expected {
+		t.Errorf("expected steps counts %+v, got %+v", expected, tests.StepsCounts)
+	}
+}
+
+func TestRunTestCases_stopOnFailure(t *testing.T) {
+	v := New()
+	v.StopOnFailure = true
+	v.PrintFunc = func(string, ...interface{}) (int, error) { return 0, nil }
+	v.RegisterExecutor("sleeping", sleepingExecutor{})
+	v.RegisterTestCaseContext("default", &testContext{CommonTestCaseContext{Name: "default"}})
+	ts := &TestSuite{Templater: newTemplater(nil), TestCases: []TestCase{
+		{Name: "ko", TestSteps: []TestStep{{"type": "sleeping", "value": 1, "assertions": []interface{}{"result.value ShouldEqual 2"}}}},
+		{Name: "next", TestSteps: []TestStep{{"type": "sleeping"}}},
+	}}
+	v.runTestCases(context.Background(), ts, TestLogger{t})
+
+	if ts.Failures != 1 || ts.Skipped != 1 {
+		t.Errorf("expected 1 testcase in failure and 1 skipped, got %d and %d", ts.Failures, ts.Skipped)
+	}
+	if next := ts.TestCases[1]; len(next.Skipped) != 1 || next.StepsCounts.Total != 0 {
+		t.Errorf("the testcase after the failure should be skipped, got %+v", next)
+	}
+}
+
+// blockingExecutor runs its step until the step is stopped
+type blockingExecutor struct {
+	started chan struct{}
+	stopped chan struct{}
+}
+
+func (e blockingExecutor) Run(tcc TestCaseContext, _ Logger, _ TestStep, _ string) (ExecutorResult, error) {
+	e.started <- struct{}{}
+	<-tcc.Context().Done()
+	close(e.stopped)
+	return nil, tcc.Context().Err()
+}
+
+func TestProcess_interrupted(t *testing.T) {
+	dir, err := ioutil.TempDir("", "interrupted")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	var files []string
+	for i, suite := range []string{
+		"name: first\ntestcases:\n- name: ok\n  steps:\n  - type: sleeping\n- name: running\n  steps:\n  - type: blocking\n  - type: sleeping\n- name: next\n  steps:\n  - type: sleeping\n",
+		"name: second\ntestcases:\n- name: not started\n  steps:\n  - type: sleeping\n",
+	} {
+		filename := filepath.Join(dir, fmt.Sprintf("suite%d.yml", i))
+		if err := ioutil.WriteFile(filename, []byte(suite), 0644); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, filename)
+	}
+
+	v := New()
+	v.LogLevel = "disable"
+	v.Parallel = 1
+	v.PrintFunc = func(string, ...interface{}) (int, error) { return 0, nil }
+	blocking := blockingExecutor{started: make(chan struct{}), stopped: make(chan struct{})}
+	v.RegisterExecutor("sleeping", sleepingExecutor{})
+	v.RegisterExecutor("blocking", blocking)
+	v.RegisterTestCaseContext("default", &testContext{CommonTestCaseContext{Name: "default"}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-blocking.started
+		cancel()
+	}()
+	tests, err := v.Process(ctx, files, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-blocking.stopped:
+	case <-time.After(time.Second):
+		t.Error("the running step should be stopped")
+	}
+
+	if tests.Total != 4 || tests.TotalOK != 1 || tests.TotalSkipped != 3 || tests.TotalKO != 0 {
+		t.Errorf("expected 1 testcase ok and 3 skipped, got %d ok, %d ko, %d skipped of %d", tests.TotalOK, tests.TotalKO, tests.TotalSkipped, tests.Total)
+	}
+	for _, ts := range tests.TestSuites {
+		for _, tc := range ts.TestCases {
+			if tc.Name != "ok" && (len(tc.Skipped) != 1 || tc.Skipped[0].Value != "run interrupted") {
+				t.Errorf("the testcase %q should be skipped by the interruption, got %+v", tc.Name, tc.Skipped)
+			}
+		}
+	}
+}
+
+func TestProcess_resources(t *testing.T) {
+	dir, err := ioutil.TempDir("", "resources")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "suite.yml")
+	if err := ioutil.WriteFile(file, []byte("name: suite\ntestcases:\n- name: tc\n  steps:\n  - type: sleeping\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	v := New()
+	v.LogLevel = "disable"
+	v.Parallel = 1
+	v.PrintFunc = func(string, ...interface{}) (int, error) { return 0, nil }
+	v.RegisterExecutor("sleeping", sleepingExecutor{})
+	v.RegisterTestCaseContext("default", &testContext{CommonTestCaseContext{Name: "default"}})
+	if err := v.Parse([]string{file}, nil); err != nil {
+		t.Fatal(err)
+	}
+	tests, err := v.Process(context.Background(), []string{file}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tests.Resources) != 2 || tests.Resources[0].Phase != "parse" || tests.Resources[1].Phase != "run" {
+		t.Fatalf("expected the usage of the parse and run phases, got %+v", tests.Resources)
+	}
+	for _, r := range tests.Resources {
+		if r.PeakHeap == 0 || r.PeakGoroutines == 0 || r.PeakOpenFiles == 0 {
+			t.Errorf("expected the peak usage of the %s phase, got %+v", r.Phase, r)
+		}
+	}
+}
+
+// TestProcess_twice runs Process twice on the same Venom, as the monitor mode does: the results cached by the
+// first run are not seen by the second one
+func TestProcess_twice(t *testing.T) {
+	dir, err := ioutil.TempDir("", "twice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "suite.yml")
+	suite := "name: suite\ntestcases:\n- name: login\n  steps:\n  - type: recording\n    value: token\n    cache: true\n  - type: recording\n    value: token\n    cache: true\n"
+	if err := ioutil.WriteFile(file, []byte(suite), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	v := New()
+	v.LogLevel = "disable"
+	v.Parallel = 1
+	v.PrintFunc = func(string, ...interface{}) (int, error) { return 0, nil }
+	exec := &recordingExecutor{}
+	v.RegisterExecutor("recording", exec)
+	v.RegisterTestCaseContext("default", &testContext{CommonTestCaseContext{Name: "default"}})
+
+	for run := 1; run <= 2; run++ {
+		v.debugAborted = true
+		tests, err := v.Process(context.Background(), []string{file}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tests.TotalOK != 1 {
+			t.Errorf("run %d: expected the testcase ok, got %+v", run, tests)
+		}
+		// the second step of the run gets the result of the first one from the cache
+		if len(exec.steps) != run {
+			t.Errorf("run %d: expected %d steps run by the executor, got %d", run, run, len(exec.steps))
+		}
+		if len(v.freePorts) != 0 {
+			t.Errorf("run %d: expected the free ports released, got %v", run, v.freePorts)
+		}
+	}
+}
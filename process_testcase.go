@@ -1,8 +1,12 @@
 package venom
 
 import (
+	"context"
 	"fmt"
+	"io/ioutil"
+	"os"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/fsamin/go-dump"
@@ -39,7 +43,7 @@ func (v *Venom) parseTestCase(ts *TestSuite, tc *TestCase) ([]string, []string,
 	vars := []string{}
 	extractedVars := []string{}
 
-	for stepNumber, stepIn := range tc.TestSteps {
+	for stepNumber, stepIn := range ts.testCaseSteps(tc) {
 		step, erra := ts.Templater.ApplyOnStep(stepNumber, stepIn)
 		if erra != nil {
 			return nil, nil, erra
@@ -68,6 +72,15 @@ func (v *Venom) parseTestCase(ts *TestSuite, tc *TestCase) ([]string, []string,
 			}
 		}
 
+		// the attempts of the step are added to the results of all the executors
+		for _, k := range attemptsResultKeys {
+			extractedVars = append(extractedVars, tc.Name+"."+k)
+		}
+
+		if name, ok := step["name"].(string); ok && name != "" {
+			extractedVars = append(extractedVars, "steps."+name)
+		}
+
 		dumpE, err := dump.ToStringMap(step, dump.WithDefaultLowerCaseFormatter())
 		if err != nil {
 			return nil, nil, err
@@ -108,7 +121,7 @@ func (v *Venom) parseTestCase(ts *TestSuite, tc *TestCase) ([]string, []string,
 
 				s := varRegEx.FindString(v)
 
-				if strings.HasPrefix(s, "{{expandEnv ") {
+				if strings.HasPrefix(s, "{{expandEnv ") || isTemplateFunc(s) {
 					continue
 				}
 
@@ -131,20 +144,92 @@ func (v *Venom) parseTestCase(ts *TestSuite, tc *TestCase) ([]string, []string,
 	return vars, extractedVars, nil
 }
 
-func (v *Venom) runTestCase(ts *TestSuite, tc *TestCase, l Logger) {
+// validateTestCase checks that the testcase has steps, and that the executors of its steps are known
+func (v *Venom) validateTestCase(tc *TestCase) error {
+	if len(tc.TestSteps) == 0 {
+		return fmt.Errorf("testcase %q has no step", tc.Name)
+	}
+	for i, step := range tc.TestSteps {
+		if len(step) == 0 {
+			return fmt.Errorf("step %d of testcase %q is empty", i, tc.Name)
+		}
+		name := stepExecutorName(tc, step)
+		if strings.Contains(name, "{{") {
+			// the type is known once the step is templated
+			continue
+		}
+		if _, ok := v.executors[name]; !ok {
+			if v.deniedExecutors[name] {
+				return fmt.Errorf("step %d of testcase %q: type '%s' is not allowed on this run", i, tc.Name, name)
+			}
+			return fmt.Errorf("step %d of testcase %q: unknown type '%s'", i, tc.Name, name)
+		}
+		if _, ok := step["script"]; name == "exec" && !ok {
+			if _, ok := step["type"]; !ok {
+				return fmt.Errorf("step %d of testcase %q has no type and no script", i, tc.Name)
+			}
+			return fmt.Errorf("step %d of testcase %q: the exec steps need a script", i, tc.Name)
+		}
+	}
+	return nil
+}
+
+func (v *Venom) runTestCase(ctx context.Context, ts *TestSuite, tc *TestCase, l Logger) {
+	// the steps not run are skipped
+	defer func() {
+		tc.StepsCounts.Total = len(tc.TestSteps)
+		tc.StepsCounts.Skipped = tc.StepsCounts.Total - tc.StepsCounts.OK - tc.StepsCounts.KO
+	}()
 	tcc, err := v.initTestCaseContext(ts, tc)
 	if err != nil {
 		tc.Errors = append(tc.Errors, Failure{Value: RemoveNotPrintableChar(err.Error())})
 		return
 	}
 	defer tcc.Close()
+	tcc.SetContext(ctx)
 
 	if _l, ok := l.(*logrus.Entry); ok {
 		l = _l.WithField("x.testcase", tc.Name)
 	}
 
-	ts.Templater.Add("", map[string]string{"venom.testcase": tc.Name})
-	for stepNumber, stepIn := range tc.TestSteps {
+	// each testcase has its own temporary directory, the steps of parallel testsuites never collide
+	tmpdir, err := ioutil.TempDir("", "venom-"+slug(tc.Name))
+	if err != nil {
+		tc.Errors = append(tc.Errors, Failure{Value: RemoveNotPrintableChar(fmt.Sprintf("unable to create the temporary directory: %v", err))})
+		return
+	}
+	defer os.RemoveAll(tmpdir)
+	port, err := v.freePort()
+	if err != nil {
+		tc.Errors = append(tc.Errors, Failure{Value: RemoveNotPrintableChar(err.Error())})
+		return
+	}
+	defer v.releasePort(port)
+
+	ts.Templater.Add("", map[string]string{"venom.testcase": tc.Name, "venom.tmpdir": tmpdir, "venom.freeport": strconv.Itoa(port)})
+	// the results of the named steps are only seen by the steps of their testcase
+	for k := range ts.Templater.Values {
+		if strings.HasPrefix(k, "steps.") {
+			delete(ts.Templater.Values, k)
+		}
+	}
+	// the steps of the testcase are not run if a step of before_each fails, the steps of after_each are always run
+	if tc.hook || v.runTestSteps(tcc, ts, tc, ts.BeforeEach, false, l) {
+		v.runTestSteps(tcc, ts, tc, tc.TestSteps, true, l)
+	}
+	if !tc.hook {
+		v.runTestSteps(tcc, ts, tc, ts.AfterEach, false, l)
+	}
+}
+
+// runTestSteps runs the steps in the context of the testcase until one of them fails, the steps run are counted in
+// the steps of the testcase if count is true. It returns true if all the steps succeeded.
+func (v *Venom) runTestSteps(tcc TestCaseContext, ts *TestSuite, tc *TestCase, steps []TestStep, count bool, l Logger) bool {
+	nbFailuresStart, nbErrorsStart := len(tc.Failures), len(tc.Errors)
+	for stepNumber, stepIn := range steps {
+		if skipInterrupted(tcc.Context(), tc) {
+			break
+		}
 		step, erra := ts.Templater.ApplyOnStep(stepNumber, stepIn)
 		if erra != nil {
 			tc.Errors = append(tc.Errors, Failure{Value: RemoveNotPrintableChar(erra.Error())})
@@ -157,9 +242,28 @@ func (v *Venom) runTestCase(ts *TestSuite, tc *TestCase, l Logger) {
 			break
 		}
 
-		v.RunTestStep(tcc, e, ts, tc, stepNumber, step, l)
+		if !v.stepPrompt(ts, tc, stepNumber, step) {
+			if v.debugRunAborted() {
+				tc.Skipped = append(tc.Skipped, Skipped{Value: "run aborted from the prompt"})
+				break
+			}
+			continue
+		}
+
+		nbFailures, nbErrors := len(tc.Failures), len(tc.Errors)
+		result := v.RunTestStep(tcc, e, ts, tc, stepNumber, step, l)
+		if v.DebugOnFailure && (len(tc.Failures) > nbFailures || len(tc.Errors) > nbErrors) {
+			v.debugOnFailure(tcc, ts, tc, stepNumber, stepIn, result, nbFailures, nbErrors, l)
+		}
+		if count {
+			if len(tc.Failures) > nbFailures || len(tc.Errors) > nbErrors {
+				tc.StepsCounts.KO++
+			} else {
+				tc.StepsCounts.OK++
+			}
+		}
 
-		if len(tc.Failures) > 0 || len(tc.Errors) > 0 {
+		if len(tc.Failures) > nbFailuresStart || len(tc.Errors) > nbErrorsStart {
 			break
 		}
 
@@ -171,6 +275,7 @@ func (v *Venom) runTestCase(ts *TestSuite, tc *TestCase, l Logger) {
 
 		ts.Templater.Add(tc.Name, assign)
 	}
+	return len(tc.Failures) == nbFailuresStart && len(tc.Errors) == nbErrorsStart && len(tc.Skipped) == 0
 }
 
 func ProcessVariableAssigments(tcName string, tcVars H, stepIn TestStep, l Logger) (H, bool, error) {
@@ -1,7 +1,9 @@
 package venom
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -48,3 +50,87 @@ script: echo 'foo'
 	assert.Nil(t, result)
 	assert.Empty(t, result)
 }
+
+type recordingExecutor struct {
+	steps []TestStep
+}
+
+func (r *recordingExecutor) Run(_ TestCaseContext, _ Logger, step TestStep, _ string) (ExecutorResult, error) {
+	r.steps = append(r.steps, step)
+	return ExecutorResult{"result.value": step["value"]}, nil
+}
+
+type testContext struct {
+	CommonTestCaseContext
+}
+
+func (*testContext) Init() error  { return nil }
+func (*testContext) Close() error { return nil }
+
+func TestRunTestCase_tmpdir(t *testing.T) {
+	v := New()
+	exec := &recordingExecutor{}
+	v.RegisterExecutor("recording", exec)
+	v.RegisterTestCaseContext("default", &testContext{CommonTestCaseContext{Name: "default"}})
+	ts := &TestSuite{Templater: newTemplater(nil)}
+
+	for _, name := range []string{"first", "second"} {
+		tc := &TestCase{Name: name, TestSteps: []TestStep{{"type": "recording", "dir": "{{.venom.tmpdir}}", "port": "{{.venom.freeport}}"}}}
+		v.runTestCase(context.Background(), ts, tc, TestLogger{t})
+		assert.Empty(t, tc.Errors)
+	}
+
+	assert.Len(t, exec.steps, 2)
+	first, _ := exec.steps[0]["dir"].(string)
+	second, _ := exec.steps[1]["dir"].(string)
+	assert.NotEmpty(t, first)
+	assert.NotEqual(t, first, second, "each testcase should have its own temporary directory")
+	firstPort, _ := exec.steps[0]["port"].(string)
+	assert.Regexp(t, `^[0-9]+$`, firstPort)
+	assert.Empty(t, v.freePorts, "the ports should be released once their testcase has ended")
+	for _, dir := range []string{first, second} {
+		_, err := os.Stat(dir)
+		assert.True(t, os.IsNotExist(err), "the temporary directory %s should be removed", dir)
+	}
+}
+
+func TestFreePort(t *testing.T) {
+	v := New()
+	ports := map[int]bool{}
+	for i := 0; i < 20; i++ {
+		port, err := v.freePort()
+		assert.NoError(t, err)
+		assert.False(t, ports[port], "the port %d should not be given twice", port)
+		ports[port] = true
+	}
+	for port := range ports {
+		v.releasePort(port)
+	}
+	assert.Empty(t, v.freePorts)
+}
+
+func TestValidateTestCase(t *testing.T) {
+	v := New()
+	v.RegisterExecutor("exec", &recordingExecutor{})
+	v.RegisterExecutor("http", &recordingExecutor{})
+
+	tests := []struct {
+		tc  TestCase
+		err string
+	}{
+		{tc: TestCase{Name: "script", TestSteps: []TestStep{{"script": "ls"}, {"type": "http"}, {"type": "{{.executor}}"}}}},
+		{tc: TestCase{Name: "empty"}, err: `testcase "empty" has no step`},
+		{tc: TestCase{Name: "nil", TestSteps: []TestStep{nil}}, err: `step 0 of testcase "nil" is empty`},
+		{tc: TestCase{Name: "typo", TestSteps: []TestStep{{"scipt": "ls"}}}, err: `step 0 of testcase "typo" has no type and no script`},
+		{tc: TestCase{Name: "exec", TestSteps: []TestStep{{"type": "exec"}}}, err: `step 0 of testcase "exec": the exec steps need a script`},
+		{tc: TestCase{Name: "unknown", TestSteps: []TestStep{{"type": "htp"}}}, err: `step 0 of testcase "unknown": unknown type 'htp'`},
+	}
+	for _, tt := range tests {
+		err := v.validateTestCase(&tt.tc)
+		if tt.err == "" {
+			assert.NoError(t, err, tt.tc.Name)
+		} else {
+			assert.EqualError(t, err, tt.err)
+		}
+	}
+}
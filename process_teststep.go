@@ -2,47 +2,109 @@ package venom
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/acarl005/stripansi"
 	dump "github.com/fsamin/go-dump"
 	log "github.com/sirupsen/logrus"
 )
 
+// errRunInterrupted is the error of a step stopped because the run is interrupted, the testcase is skipped
+var errRunInterrupted = errors.New("run interrupted")
+
+// attemptsResultKeys are the keys of the results about the attempts of the steps, set whatever the executor
+var attemptsResultKeys = []string{"result.attempts", "result.last_error", "result.durations"}
+
 //RunTestStep executes a venom testcase is a venom context
 func (v *Venom) RunTestStep(tcc TestCaseContext, e *ExecutorWrap, ts *TestSuite, tc *TestCase, stepNumber int, step TestStep, l Logger) ExecutorResult {
 	var assertRes assertionsApplied
 
-	var retry int
+	var retry, attempts int
 	var result ExecutorResult
+	var lastError string
+	var durations []float64
+	nbFailures, nbErrors := len(tc.Failures), len(tc.Errors)
 
 	for retry = 0; retry <= e.retry && !assertRes.ok; retry++ {
 		if retry > 1 && !assertRes.ok {
-			l.Debugf("Sleep %d, it's %d attempt", e.delay, retry)
-			time.Sleep(time.Duration(e.delay) * time.Second)
+			l.Debugf("Sleep %s, it's %d attempt", e.delay, retry)
+			select {
+			case <-time.After(e.delay):
+			case <-tcc.Context().Done():
+			}
+		}
+		if skipInterrupted(tcc.Context(), tc) {
+			break
 		}
 
 		var err error
-		result, err = runTestStepExecutor(tcc, e, ts, step, l)
+		var cached bool
+		attempts++
+		start := time.Now()
+		result, cached = v.cachedResult(e, step)
+		if cached {
+			l.Debugf("Result of step %d read from cache", stepNumber)
+		} else {
+			release, errWait := v.acquireHost(tcc.Context(), step)
+			if errWait == nil {
+				if errWait = v.waitRateLimits(tcc.Context(), e); errWait != nil {
+					release()
+				}
+			}
+			if errWait != nil {
+				// the run is interrupted while the step waits for its host or its rate limit
+				err = errRunInterrupted
+			} else {
+				result, err = runTestStepExecutor(tcc, e, ts, step, l)
+				release()
+			}
+		}
+		durations = append(durations, time.Since(start).Seconds())
 
+		if err == errRunInterrupted {
+			skipInterrupted(tcc.Context(), tc)
+			break
+		}
 		if err != nil {
 			// we save the failure only if it's the last attempt
 			if retry == e.retry {
-				tc.Failures = append(tc.Failures, Failure{Value: RemoveNotPrintableChar(err.Error())})
+				tc.Errors = append(tc.Errors, Failure{Value: RemoveNotPrintableChar(err.Error()), Type: FailureTypeInfrastructure})
 			}
+			lastError = err.Error()
 			continue
 		}
+		// keep the result of the executor before the extracts to cache it
+		var executorResult ExecutorResult
+		if !cached {
+			executorResult = copyExecutorResult(result)
+			v.recordOpenAPICall(e, result)
+			v.recordPactInteraction(e, ts, tc, stepNumber, step, result)
+		}
+		if result == nil {
+			result = ExecutorResult{}
+		}
+		result["result.attempts"] = attempts
+		result["result.last_error"] = lastError
+		result["result.durations"] = append([]float64{}, durations...)
 
 		// add result in templater
-		ts.Templater.Add(tc.Name, stringifyExecutorResult(result))
+		addStepResult(ts, tc, step, result)
 
 		if h, ok := e.executor.(executorWithDefaultAssertions); ok {
 			assertRes = applyChecks(&result, *tc, stepNumber, step, h.GetDefaultAssertions())
 		} else {
 			assertRes = applyChecks(&result, *tc, stepNumber, step, nil)
 		}
+		// steps violating the OpenAPI contract fail, even if their assertions pass
+		if failures := v.validateOpenAPICall(e, *tc, stepNumber, result); len(failures) > 0 {
+			assertRes.ok = false
+			assertRes.failures = append(assertRes.failures, failures...)
+		}
 		// add result again for extracts values
-		ts.Templater.Add(tc.Name, stringifyExecutorResult(result))
+		addStepResult(ts, tc, step, result)
 
 		// then template the TestSuite vars if needed
 		var applied bool
@@ -59,20 +121,136 @@ func (v *Venom) RunTestStep(tcc TestCaseContext, e *ExecutorWrap, ts *TestSuite,
 		}
 
 		if assertRes.ok {
+			if !cached {
+				v.cacheResult(e, step, executorResult)
+			}
+			break
+		}
+		lastError = failuresMessage(assertRes)
+		if !e.retryable(assertRes) {
+			l.Debugf("Step %d is not retried, its failures are not in retry_if", stepNumber)
 			break
 		}
 	}
 	tc.Errors = append(tc.Errors, assertRes.errors...)
 	tc.Failures = append(tc.Failures, assertRes.failures...)
-	if retry > 1 && (len(assertRes.failures) > 0 || len(assertRes.errors) > 0) {
-		tc.Failures = append(tc.Failures, Failure{Value: fmt.Sprintf("It's a failure after %d attempts", retry)})
+	if attempts > 1 && (len(assertRes.failures) > 0 || len(assertRes.errors) > 0) {
+		tc.Failures = append(tc.Failures, Failure{Value: fmt.Sprintf("It's a failure after %d attempts, in %s", attempts, formatDurations(durations))})
 	}
 	tc.Systemout.Value += assertRes.systemout
 	tc.Systemerr.Value += assertRes.systemerr
+	if len(tc.Failures) > nbFailures || len(tc.Errors) > nbErrors {
+		v.writeStepAttachments(tcc, e, ts, tc, stepNumber, step, result)
+	}
+	v.collectStepArtifacts(ts, tc, stepNumber, step)
+	if v.outputStepResults() {
+		tc.StepResults = append(tc.StepResults, newStepResult(e, tc, stepNumber, step, result, assertRes, durations, nbFailures, nbErrors))
+	}
 
 	return result
 }
 
+// newStepResult describes the step run for the json-full report: the result of its last attempt and the outcome of
+// its assertions
+func newStepResult(e *ExecutorWrap, tc *TestCase, stepNumber int, step TestStep, result ExecutorResult, res assertionsApplied, durations []float64, nbFailures, nbErrors int) StepResult {
+	r := StepResult{
+		Number:     stepNumber,
+		Type:       e.name,
+		Status:     "SUCCESS",
+		Attempts:   len(durations),
+		Result:     result,
+		Assertions: []AssertionResult{},
+	}
+	r.Name, _ = step["name"].(string)
+	for _, d := range durations {
+		r.TimeSeconds += d
+	}
+	if len(tc.Failures) > nbFailures || len(tc.Errors) > nbErrors {
+		r.Status = "FAILURE"
+	}
+	for _, a := range res.assertions {
+		ar := AssertionResult{Assertion: a, OK: true}
+		for _, f := range res.failures {
+			if f.Assertion == a {
+				ar.OK = false
+				if f.Error != nil {
+					ar.Message = f.Error.Error()
+				}
+				break
+			}
+		}
+		r.Assertions = append(r.Assertions, ar)
+	}
+	// the errors of the executor and the failures of the extracts
+	for _, f := range res.failures {
+		if f.Assertion == "" {
+			r.Errors = append(r.Errors, stripansi.Strip(strings.TrimSpace(f.Value)))
+		}
+	}
+	for _, f := range tc.Errors[nbErrors:] {
+		r.Errors = append(r.Errors, stripansi.Strip(strings.TrimSpace(f.Value)))
+	}
+	return r
+}
+
+// retryable returns true if the step is retried after these failures: all of them are failures of the
+// assertions of retry_if, or retry_if is not set
+func (e *ExecutorWrap) retryable(res assertionsApplied) bool {
+	if len(e.retryIf) == 0 {
+		return true
+	}
+	if len(res.errors) > 0 || len(res.failedAssertions) < len(res.failures) {
+		return false
+	}
+	for _, a := range res.failedAssertions {
+		found := false
+		for _, r := range e.retryIf {
+			if strings.TrimSpace(a) == strings.TrimSpace(r) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// failuresMessage returns the errors and the failures of the assertions, one by line
+func failuresMessage(res assertionsApplied) string {
+	var msgs []string
+	for _, f := range append(append([]Failure{}, res.errors...), res.failures...) {
+		msgs = append(msgs, strings.TrimSpace(f.Value))
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// formatDurations returns the durations of the attempts: 0.12s, 0.10s
+func formatDurations(durations []float64) string {
+	s := make([]string, len(durations))
+	for i, d := range durations {
+		s[i] = fmt.Sprintf("%.2fs", d)
+	}
+	return strings.Join(s, ", ")
+}
+
+// addStepResult adds the result of a step to the variables of the testsuite: {{.<testcase>.result.<key>}},
+// and {{.steps.<name>.<key>}} when the step has a name, which is not overwritten by the next steps
+func addStepResult(ts *TestSuite, tc *TestCase, step TestStep, result ExecutorResult) {
+	values := stringifyExecutorResult(result)
+	ts.Templater.Add(tc.Name, values)
+	name, _ := step["name"].(string)
+	if name == "" {
+		return
+	}
+	named := make(map[string]string, len(values))
+	for k, v := range values {
+		named[strings.TrimPrefix(k, "result.")] = v
+	}
+	ts.Templater.Add("steps."+name, named)
+}
+
 func stringifyExecutorResult(e ExecutorResult) map[string]string {
 	out := make(map[string]string)
 	for k, v := range e {
@@ -81,16 +259,24 @@ func stringifyExecutorResult(e ExecutorResult) map[string]string {
 	return out
 }
 
+// runTestStepExecutor runs the step with the executor, until the step times out or the run is interrupted. The
+// context of the testcase is the context of the step while it runs.
 func runTestStepExecutor(tcc TestCaseContext, e *ExecutorWrap, ts *TestSuite, step TestStep, l Logger) (ExecutorResult, error) {
-	if e.timeout == 0 {
-		return e.executor.Run(tcc, l, step, ts.WorkDir)
+	runCtx := tcc.Context()
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if e.timeout > 0 {
+		ctx, cancel = context.WithTimeout(runCtx, e.timeout)
+	} else {
+		ctx, cancel = context.WithCancel(runCtx)
 	}
-
-	ctxTimeout, cancel := context.WithTimeout(context.Background(), time.Duration(e.timeout)*time.Second)
 	defer cancel()
+	tcc.SetContext(ctx)
+	defer tcc.SetContext(runCtx)
 
-	ch := make(chan ExecutorResult)
-	cherr := make(chan error)
+	// the executor still running when the step is stopped doesn't block on its result
+	ch := make(chan ExecutorResult, 1)
+	cherr := make(chan error, 1)
 	go func(tcc TestCaseContext, e *ExecutorWrap, step TestStep, l Logger) {
 		result, err := e.executor.Run(tcc, l, step, ts.WorkDir)
 		if err != nil {
@@ -105,7 +291,21 @@ func runTestStepExecutor(tcc TestCaseContext, e *ExecutorWrap, ts *TestSuite, st
 		return nil, err
 	case result := <-ch:
 		return result, nil
-	case <-ctxTimeout.Done():
-		return nil, fmt.Errorf("Timeout after %d second(s)", e.timeout)
+	case <-ctx.Done():
+		if runCtx.Err() != nil {
+			return nil, errRunInterrupted
+		}
+		return nil, fmt.Errorf("Timeout after %s", e.timeout)
+	}
+}
+
+// skipInterrupted skips the testcase when the run is interrupted, it returns true if the run is interrupted
+func skipInterrupted(ctx context.Context, tc *TestCase) bool {
+	if ctx.Err() == nil {
+		return false
+	}
+	if len(tc.Skipped) == 0 {
+		tc.Skipped = append(tc.Skipped, Skipped{Value: "run interrupted"})
 	}
+	return true
 }
@@ -1,7 +1,13 @@
 package venom
 
 import (
+	"errors"
 	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -34,3 +40,334 @@ func (t TestLogger) Errorf(format string, args ...interface{}) {
 func (t TestLogger) Fatalf(format string, args ...interface{}) {
 	t.t.Logf(format, args...)
 }
+
+type countingExecutor struct {
+	runs int
+}
+
+func (c *countingExecutor) Run(TestCaseContext, Logger, TestStep, string) (ExecutorResult, error) {
+	c.runs++
+	return ExecutorResult{"result.runs": c.runs}, nil
+}
+
+func TestRunTestStep_cache(t *testing.T) {
+	v := New()
+	exec := &countingExecutor{}
+	v.RegisterExecutor("counting", exec)
+	ts := &TestSuite{Templater: newTemplater(nil)}
+	tcc := &CommonTestCaseContext{Name: "default"}
+
+	run := func(step TestStep) ExecutorResult {
+		e, err := v.WrapExecutor(step, tcc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tc := &TestCase{Name: "tc"}
+		return v.RunTestStep(tcc, e, ts, tc, 0, step, TestLogger{t})
+	}
+
+	step := TestStep{"type": "counting", "cache": true, "token": "a", "assertions": []interface{}{"result.runs ShouldEqual 1"}}
+	if r := run(step); r["result.runs"] != 1 {
+		t.Fatalf("expected a first run, got %v", r)
+	}
+	// assertions do not change the key in the cache
+	if r := run(TestStep{"type": "counting", "cache": true, "token": "a"}); r["result.runs"] != 1 {
+		t.Fatalf("expected the cached result, got %v", r)
+	}
+	if r := run(TestStep{"type": "counting", "cache": true, "token": "b"}); r["result.runs"] != 2 {
+		t.Fatalf("expected a second run, got %v", r)
+	}
+	if r := run(TestStep{"type": "counting", "token": "a"}); r["result.runs"] != 3 {
+		t.Fatalf("expected a third run without cache, got %v", r)
+	}
+	if _, err := v.WrapExecutor(TestStep{"type": "counting", "cache": "yes"}, tcc); err == nil {
+		t.Fatal("expected an error for a cache which is not a boolean")
+	}
+}
+
+func TestRunTestStep_attachments(t *testing.T) {
+	v := New()
+	dir, err := ioutil.TempDir("", "attachments")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	v.OutputDir = dir
+	v.RegisterExecutor("counting", &countingExecutor{})
+	ts := &TestSuite{ShortName: "suite.yml", Templater: newTemplater(nil)}
+	tcc := &CommonTestCaseContext{Name: "default"}
+
+	step := TestStep{"type": "counting", "assertions": []interface{}{"result.runs ShouldEqual 2"}}
+	e, err := v.WrapExecutor(step, tcc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tc := &TestCase{Name: "tc"}
+	v.RunTestStep(tcc, e, ts, tc, 0, step, TestLogger{t})
+
+	expected := []string{"attachments/suite-yml.tc.step0.request.yml", "attachments/suite-yml.tc.step0.result.json"}
+	if !reflect.DeepEqual(expected, tc.Attachments) {
+		t.Fatalf("expected attachments %v, got %v", expected, tc.Attachments)
+	}
+	for _, a := range tc.Attachments {
+		if _, err := os.Stat(filepath.Join(v.OutputDir, a)); err != nil {
+			t.Error(err)
+		}
+		if !strings.Contains(tc.Systemout.Value, "[[ATTACHMENT|") || !strings.Contains(tc.Systemout.Value, filepath.Base(a)+"]]") {
+			t.Errorf("attachment %s is not referenced in system-out: %s", a, tc.Systemout.Value)
+		}
+	}
+}
+
+type snapshotContext struct {
+	CommonTestCaseContext
+}
+
+func (*snapshotContext) Snapshot(prefix string) ([]string, error) {
+	return []string{prefix + ".png"}, ioutil.WriteFile(prefix+".png", []byte("png"), 0644)
+}
+
+func TestRunTestStep_snapshot(t *testing.T) {
+	v := New()
+	dir, err := ioutil.TempDir("", "attachments")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	v.OutputDir = dir
+	v.RegisterExecutor("counting", &countingExecutor{})
+	ts := &TestSuite{ShortName: "suite.yml", Templater: newTemplater(nil)}
+	tcc := &snapshotContext{CommonTestCaseContext{Name: "default"}}
+
+	step := TestStep{"type": "counting", "assertions": []interface{}{"result.runs ShouldEqual 1"}}
+	e, err := v.WrapExecutor(step, tcc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tc := &TestCase{Name: "tc"}
+	v.RunTestStep(tcc, e, ts, tc, 0, step, TestLogger{t})
+	if len(tc.Attachments) != 0 {
+		t.Errorf("expected no snapshot for a step succeeding, got %v", tc.Attachments)
+	}
+
+	step = TestStep{"type": "counting", "assertions": []interface{}{"result.runs ShouldEqual 1"}}
+	v.RunTestStep(tcc, e, ts, tc, 1, step, TestLogger{t})
+	expected := []string{"attachments/suite-yml.tc.step1.request.yml", "attachments/suite-yml.tc.step1.result.json", "attachments/suite-yml.tc.step1.png"}
+	if !reflect.DeepEqual(expected, tc.Attachments) {
+		t.Fatalf("expected attachments %v, got %v", expected, tc.Attachments)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "attachments", "suite-yml.tc.step1.png")); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRunTestStep_namedResults(t *testing.T) {
+	v := New()
+	v.RegisterExecutor("counting", &countingExecutor{})
+	ts := &TestSuite{Templater: newTemplater(nil)}
+	tcc := &CommonTestCaseContext{Name: "default"}
+	tc := &TestCase{Name: "tc"}
+
+	for _, step := range []TestStep{{"type": "counting", "name": "login"}, {"type": "counting", "name": "list"}, {"type": "counting"}} {
+		e, err := v.WrapExecutor(step, tcc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v.RunTestStep(tcc, e, ts, tc, 0, step, TestLogger{t})
+	}
+
+	expected := map[string]string{"steps.login.runs": "1", "steps.list.runs": "2", "tc.result.runs": "3"}
+	for k, want := range expected {
+		if got := ts.Templater.Values[k]; got != want {
+			t.Errorf("expected %s=%s, got %q", k, want, got)
+		}
+	}
+}
+
+func TestRunTestStep_retryIf(t *testing.T) {
+	v := New()
+	ts := &TestSuite{Templater: newTemplater(nil)}
+	tcc := &CommonTestCaseContext{Name: "default"}
+
+	run := func(assertions ...interface{}) int {
+		exec := &countingExecutor{}
+		v.RegisterExecutor("counting", exec)
+		step := TestStep{"type": "counting", "retry": 3, "retry_if": []interface{}{"result.runs ShouldEqual 3"}, "assertions": assertions}
+		e, err := v.WrapExecutor(step, tcc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v.RunTestStep(tcc, e, ts, &TestCase{Name: "tc"}, 0, step, TestLogger{t})
+		return exec.runs
+	}
+
+	if runs := run("result.runs ShouldEqual 3"); runs != 3 {
+		t.Errorf("expected the retryable assertion to be retried until it passes, got %d runs", runs)
+	}
+	if runs := run("result.runs ShouldEqual 3", "result.missing ShouldNotBeNil"); runs != 1 {
+		t.Errorf("expected the fatal assertion to fail without retry, got %d runs", runs)
+	}
+}
+
+func TestRunTestStep_attempts(t *testing.T) {
+	v := New()
+	v.RegisterExecutor("counting", &countingExecutor{})
+	ts := &TestSuite{Templater: newTemplater(nil)}
+	tcc := &CommonTestCaseContext{Name: "default"}
+	tc := &TestCase{Name: "tc"}
+
+	step := TestStep{"type": "counting", "retry": 3, "assertions": []interface{}{"result.runs ShouldEqual 2"}}
+	e, err := v.WrapExecutor(step, tcc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := v.RunTestStep(tcc, e, ts, tc, 0, step, TestLogger{t})
+	if result["result.attempts"] != 2 {
+		t.Errorf("expected 2 attempts, got %v", result["result.attempts"])
+	}
+	if lastError, _ := result["result.last_error"].(string); !strings.Contains(lastError, "result.runs ShouldEqual 2") {
+		t.Errorf("expected the failure of the first attempt as last error, got %q", lastError)
+	}
+	if durations, _ := result["result.durations"].([]float64); len(durations) != 2 {
+		t.Errorf("expected the durations of 2 attempts, got %v", result["result.durations"])
+	}
+	if len(tc.Failures) > 0 {
+		t.Errorf("expected the step to succeed, got %v", tc.Failures)
+	}
+}
+
+// attemptsExecutor returns its own probes, like waitfor
+type attemptsExecutor struct{}
+
+func (attemptsExecutor) Run(TestCaseContext, Logger, TestStep, string) (ExecutorResult, error) {
+	return ExecutorResult{"result.probes": 5, "result.ready": true}, nil
+}
+
+func TestRunTestStep_executorAttempts(t *testing.T) {
+	v := New()
+	v.RegisterExecutor("waiting", attemptsExecutor{})
+	ts := &TestSuite{Templater: newTemplater(nil)}
+	tcc := &CommonTestCaseContext{Name: "default"}
+
+	step := TestStep{"type": "waiting", "assertions": []interface{}{"result.probes ShouldEqual 5", "result.attempts ShouldEqual 1"}}
+	e, err := v.WrapExecutor(step, tcc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tc := &TestCase{Name: "tc"}
+	result := v.RunTestStep(tcc, e, ts, tc, 0, step, TestLogger{t})
+	if result["result.probes"] != 5 {
+		t.Errorf("expected the probes of the executor, got %v", result["result.probes"])
+	}
+	if result["result.attempts"] != 1 {
+		t.Errorf("expected 1 attempt of the step, got %v", result["result.attempts"])
+	}
+	if len(tc.Failures) > 0 {
+		t.Errorf("expected the step to succeed, got %v", tc.Failures)
+	}
+}
+
+type failingExecutor struct{}
+
+func (failingExecutor) Run(TestCaseContext, Logger, TestStep, string) (ExecutorResult, error) {
+	return nil, errors.New("dial tcp 127.0.0.1:1: connect: connection refused")
+}
+
+func TestRunTestStep_failureTypes(t *testing.T) {
+	v := New()
+	v.RegisterExecutor("counting", &countingExecutor{})
+	v.RegisterExecutor("failing", failingExecutor{})
+	ts := &TestSuite{Templater: newTemplater(nil)}
+	tcc := &CommonTestCaseContext{Name: "default"}
+
+	run := func(step TestStep) *TestCase {
+		e, err := v.WrapExecutor(step, tcc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tc := &TestCase{Name: "tc"}
+		v.RunTestStep(tcc, e, ts, tc, 0, step, TestLogger{t})
+		return tc
+	}
+
+	tc := run(TestStep{"type": "failing"})
+	if len(tc.Failures) > 0 || len(tc.Errors) != 1 || tc.Errors[0].Type != FailureTypeInfrastructure {
+		t.Errorf("expected an error of the infrastructure, got failures %v and errors %v", tc.Failures, tc.Errors)
+	}
+	tc = run(TestStep{"type": "counting", "assertions": []interface{}{"result.runs ShouldEqual 0"}})
+	if len(tc.Errors) > 0 || len(tc.Failures) != 1 || tc.Failures[0].Type != FailureTypeAssertion {
+		t.Errorf("expected a failed assertion, got failures %v and errors %v", tc.Failures, tc.Errors)
+	}
+}
+
+func TestRunTestStep_artifacts(t *testing.T) {
+	v := New()
+	dir, err := ioutil.TempDir("", "artifacts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	v.OutputDir = filepath.Join(dir, "results")
+	workdir := filepath.Join(dir, "suite")
+	if err := os.MkdirAll(filepath.Join(workdir, "output"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"app.log", "db.log", "dump.bin"} {
+		if err := ioutil.WriteFile(filepath.Join(workdir, "output", name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	v.RegisterExecutor("counting", &countingExecutor{})
+	ts := &TestSuite{ShortName: "suite.yml", WorkDir: workdir, Templater: newTemplater(nil)}
+	tcc := &CommonTestCaseContext{Name: "default"}
+
+	step := TestStep{"type": "counting", "artifacts": []interface{}{"./output/*.log"}}
+	e, err := v.WrapExecutor(step, tcc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tc := &TestCase{Name: "tc"}
+	v.RunTestStep(tcc, e, ts, tc, 0, step, TestLogger{t})
+
+	expected := []string{"attachments/suite-yml.tc.step0.artifacts/output/app.log", "attachments/suite-yml.tc.step0.artifacts/output/db.log"}
+	if !reflect.DeepEqual(expected, tc.Attachments) {
+		t.Fatalf("expected artifacts %v, got %v", expected, tc.Attachments)
+	}
+	for _, a := range tc.Attachments {
+		if btes, err := ioutil.ReadFile(filepath.Join(v.OutputDir, a)); err != nil || string(btes) != filepath.Base(a) {
+			t.Errorf("unexpected artifact %s: %q %v", a, btes, err)
+		}
+	}
+}
+
+func TestRunTestStep_stepResults(t *testing.T) {
+	v := New()
+	v.OutputFormat = "xml,json-full"
+	v.RegisterExecutor("counting", &countingExecutor{})
+	ts := &TestSuite{Templater: newTemplater(nil)}
+	tcc := &CommonTestCaseContext{Name: "default"}
+	tc := &TestCase{Name: "tc"}
+
+	step := TestStep{"type": "counting", "name": "count", "assertions": []interface{}{"result.runs ShouldEqual 1", "result.runs ShouldEqual 2"}}
+	e, err := v.WrapExecutor(step, tcc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.RunTestStep(tcc, e, ts, tc, 0, step, TestLogger{t})
+	if len(tc.StepResults) != 1 {
+		t.Fatalf("expected 1 step result, got %v", tc.StepResults)
+	}
+	r := tc.StepResults[0]
+	if r.Name != "count" || r.Type != "counting" || r.Status != "FAILURE" || r.Attempts != 1 || r.Result["result.runs"] != 1 {
+		t.Errorf("unexpected step result %+v", r)
+	}
+	if len(r.Assertions) != 2 || !r.Assertions[0].OK || r.Assertions[1].OK || r.Assertions[1].Message == "" {
+		t.Errorf("unexpected assertions %+v", r.Assertions)
+	}
+
+	v.OutputFormat = "json"
+	v.RunTestStep(tcc, e, ts, tc, 1, step, TestLogger{t})
+	if len(tc.StepResults) != 1 {
+		t.Errorf("the step results are only kept for the json-full report, got %v", tc.StepResults)
+	}
+}
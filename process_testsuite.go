@@ -1,8 +1,10 @@
 package venom
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"runtime/pprof"
 	"strings"
 	"time"
@@ -12,14 +14,10 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
-func (v *Venom) runTestSuite(ts *TestSuite) {
+func (v *Venom) runTestSuite(ctx context.Context, ts *TestSuite) {
 	if v.EnableProfiling {
-		var filename, filenameCPU, filenameMem string
-		if v.OutputDir != "" {
-			filename = v.OutputDir + "/"
-		}
-		filenameCPU = filename + "pprof_cpu_profile_" + ts.Filename + ".prof"
-		filenameMem = filename + "pprof_mem_profile_" + ts.Filename + ".prof"
+		filenameCPU := filepath.Join(v.OutputDir, "pprof_cpu_profile_"+filepath.Base(ts.Filename)+".prof")
+		filenameMem := filepath.Join(v.OutputDir, "pprof_mem_profile_"+filepath.Base(ts.Filename)+".prof")
 		fCPU, errCPU := os.Create(filenameCPU)
 		fMem, errMem := os.Create(filenameMem)
 		if errCPU != nil || errMem != nil {
@@ -34,7 +32,72 @@ func (v *Venom) runTestSuite(ts *TestSuite) {
 
 	l := log.WithField("v.testsuite", ts.Name)
 	start := time.Now()
+	v.teamcityTestSuiteStarted(ts)
+	defer v.teamcityTestSuiteFinished(ts)
+
+	initTestSuiteTemplater(ts)
+
+	// the services of a testsuite are not started once the run is interrupted, its testcases are skipped
+	if ts.Services != nil && ctx.Err() == nil {
+		vars, stop, err := startServices(*ts.Services, ts.WorkDir, ts.ShortName)
+		defer stop()
+		if err != nil {
+			log.Errorf("unable to start services of testsuite %s: %v", ts.Name, err)
+			setupFailure(ts, err)
+		} else {
+			ts.Templater.Add("", vars)
+		}
+	}
+
+	if len(ts.PortForwards) > 0 && ts.Errors == 0 && ctx.Err() == nil {
+		vars, stop, err := v.startPortForwards(ts.PortForwards)
+		defer stop()
+		if err != nil {
+			log.Errorf("unable to open port-forwards of testsuite %s: %v", ts.Name, err)
+			setupFailure(ts, err)
+		} else {
+			ts.Templater.Add("", vars)
+		}
+	}
+
+	if len(ts.SSHTunnels) > 0 && ts.Errors == 0 && ctx.Err() == nil {
+		vars, rewrites, stop, err := startSSHTunnels(ts.SSHTunnels)
+		defer stop()
+		if err != nil {
+			log.Errorf("unable to open ssh tunnels of testsuite %s: %v", ts.Name, err)
+			setupFailure(ts, err)
+		} else {
+			ts.Templater.Add("", vars)
+			rewriteTunneledAddresses(ts, rewrites)
+		}
+	}
+
+	if ts.Errors == 0 {
+		v.runSetup(ctx, ts, l)
+		if ts.Errors == 0 {
+			v.runTestCases(ctx, ts, l)
+		}
+		v.runTeardown(ctx, ts, l)
+	}
+	setFailureTypes(ts)
+
+	elapsed := time.Since(start)
+	ts.Time = fmt.Sprintf("%.3f", elapsed.Seconds())
+
+	var o string
+	if ts.Failures > 0 || ts.Errors > 0 {
+		red := color.New(color.FgRed).SprintFunc()
+		o = fmt.Sprintf("%s %s", red("FAILURE"), ts.Package)
+	} else {
+		green := color.New(color.FgGreen).SprintFunc()
+		o = fmt.Sprintf("%s %s", green("SUCCESS"), ts.Package)
+	}
+	o += fmt.Sprintf("\t%.2fs", elapsed.Seconds())
+	v.PrintFunc("%v\n", o)
+}
 
+// initTestSuiteTemplater adds the vars of the testsuite to its templater
+func initTestSuiteTemplater(ts *TestSuite) {
 	d, err := dump.ToStringMap(ts.Vars)
 	if err != nil {
 		log.Errorf("err:%s", err)
@@ -57,49 +120,81 @@ func (v *Venom) runTestSuite(ts *TestSuite) {
 			break
 		}
 	}
+}
 
-	totalSteps := 0
-	for _, tc := range ts.TestCases {
-		totalSteps += len(tc.TestSteps)
+// setupFailure marks all testcases in error when the testsuite cannot be set up
+func setupFailure(ts *TestSuite, err error) {
+	for i := range ts.TestCases {
+		ts.TestCases[i].Errors = append(ts.TestCases[i].Errors, Failure{Value: RemoveNotPrintableChar(err.Error())})
+		ts.Errors++
 	}
+}
 
-	v.runTestCases(ts, l)
-
-	elapsed := time.Since(start)
-
-	var o string
-	if ts.Failures > 0 || ts.Errors > 0 {
-		red := color.New(color.FgRed).SprintFunc()
-		o = fmt.Sprintf("%s %s", red("FAILURE"), ts.Package)
-	} else {
-		green := color.New(color.FgGreen).SprintFunc()
-		o = fmt.Sprintf("%s %s", green("SUCCESS"), ts.Package)
+// setFailureTypes sets the type of the failures without one: the errors of the testcases are errors of the
+// infrastructure, their failures are failed assertions
+func setFailureTypes(ts *TestSuite) {
+	for i := range ts.TestCases {
+		tc := &ts.TestCases[i]
+		for j := range tc.Errors {
+			if tc.Errors[j].Type == "" {
+				tc.Errors[j].Type = FailureTypeInfrastructure
+			}
+		}
+		for j := range tc.Failures {
+			if tc.Failures[j].Type == "" {
+				tc.Failures[j].Type = FailureTypeAssertion
+			}
+		}
 	}
-	o += fmt.Sprintf("\t%.2fs", elapsed.Seconds())
-	v.PrintFunc("%v\n", o)
 }
 
-func (v *Venom) runTestCases(ts *TestSuite, l Logger) {
+func (v *Venom) runTestCases(ctx context.Context, ts *TestSuite, l Logger) {
+	var stopped bool
 	for i := range ts.TestCases {
 		tc := &ts.TestCases[i]
 		tc.Classname = ts.Filename
-		if len(tc.Skipped) == 0 {
-			v.runTestCase(ts, tc, l)
+		tc.IssueURL = v.issueURL(tc.Issue)
+		v.teamcityTestStarted(ts, tc)
+		start := time.Now()
+		if v.debugRunAborted() && len(tc.Skipped) == 0 {
+			tc.Skipped = append(tc.Skipped, Skipped{Value: "run aborted from the prompt"})
 		}
-
-		if len(tc.Failures) > 0 {
-			ts.Failures += len(tc.Failures)
+		skipInterrupted(ctx, tc)
+		if stopped && len(tc.Skipped) == 0 {
+			tc.Skipped = append(tc.Skipped, Skipped{Value: "not run, a previous testcase failed with --stop-on-failure"})
+		}
+		err := applySkipIf(ts, tc)
+		if err == nil && len(tc.Skipped) == 0 {
+			// a malformed testcase is in error, it's not run
+			if err = v.validateTestCase(tc); err == nil {
+				v.runTestCase(ctx, ts, tc, l)
+			}
 		}
-		if len(tc.Errors) > 0 {
-			ts.Errors += len(tc.Errors)
+		if err != nil {
+			tc.Errors = append(tc.Errors, Failure{Value: RemoveNotPrintableChar(err.Error())})
 		}
-		if len(tc.Skipped) > 0 {
-			ts.Skipped += len(tc.Skipped)
+		elapsed := time.Since(start)
+		tc.Time = fmt.Sprintf("%.3f", elapsed.Seconds())
+		v.teamcityTestFinished(ts, tc, elapsed)
+
+		if v.applyQuarantine(ts, tc) {
+			// the failures are reported but don't fail the testsuite
+			continue
+		}
+
+		// the testsuite counts its testcases in failure, in error and skipped, as JUnit does
+		switch {
+		case len(tc.Errors) > 0:
+			ts.Errors++
+		case len(tc.Failures) > 0:
+			ts.Failures++
+		case len(tc.Skipped) > 0:
+			ts.Skipped++
 		}
 
 		if v.StopOnFailure && (len(tc.Failures) > 0 || len(tc.Errors) > 0) {
-			// break TestSuite
-			return
+			// the next testcases of the testsuite are skipped
+			stopped = true
 		}
 	}
 }
@@ -112,7 +207,22 @@ func (v *Venom) parseTestSuite(ts *TestSuite) ([]string, []string, error) {
 	}
 	ts.Templater.Add("", d)
 
-	return v.parseTestCases(ts)
+	vars, extractedVars, err := v.parseTestCases(ts)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, hook := range []TestCase{hookTestCase(ts, "setup", ts.Setup), hookTestCase(ts, "teardown", ts.Teardown)} {
+		if len(hook.TestSteps) == 0 {
+			continue
+		}
+		hookVars, hookExtractedVars, err := v.parseTestCase(ts, &hook)
+		if err != nil {
+			return nil, nil, err
+		}
+		vars = append(vars, hookVars...)
+		extractedVars = append(extractedVars, hookExtractedVars...)
+	}
+	return vars, extractedVars, nil
 }
 
 //Parse the testscases to find unreplaced and extracted variables
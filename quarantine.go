@@ -0,0 +1,84 @@
+package venom
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// QuarantineEntry is a known flaky testcase: its failures are reported but don't fail the run until the expiry date.
+// The testcase is its name, or "<testsuite> / <testcase>" where the testsuite is its name or its filename.
+type QuarantineEntry struct {
+	TestCase string `json:"testcase" yaml:"testcase"`
+	Until    string `json:"until" yaml:"until"`
+	Reason   string `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
+
+// loadQuarantine reads the quarantine file, expired entries are ignored
+func (v *Venom) loadQuarantine() error {
+	v.quarantine = nil
+	if v.QuarantineFile == "" {
+		return nil
+	}
+	btes, err := ioutil.ReadFile(v.QuarantineFile)
+	if err != nil {
+		return fmt.Errorf("unable to read the quarantine file: %v", err)
+	}
+	var entries []QuarantineEntry
+	if err := yaml.Unmarshal(btes, &entries); err != nil {
+		return fmt.Errorf("invalid quarantine file %s: %v", v.QuarantineFile, err)
+	}
+	now := time.Now()
+	for _, e := range entries {
+		if e.TestCase == "" {
+			return fmt.Errorf("invalid quarantine file %s: testcase is required", v.QuarantineFile)
+		}
+		until, err := time.ParseInLocation("2006-01-02", e.Until, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid quarantine file %s: until of %s must be a date (2006-01-02): %v", v.QuarantineFile, e.TestCase, err)
+		}
+		// the entry is valid during its last day
+		if now.After(until.AddDate(0, 0, 1)) {
+			v.PrintFunc("Quarantine of %s expired on %s\n", e.TestCase, e.Until)
+			continue
+		}
+		v.quarantine = append(v.quarantine, e)
+	}
+	return nil
+}
+
+// quarantineEntry returns the entry of the quarantine matching the testcase, nil if it's not quarantined
+func (v *Venom) quarantineEntry(ts *TestSuite, tc *TestCase) *QuarantineEntry {
+	for i, e := range v.quarantine {
+		name := e.TestCase
+		if i := strings.LastIndex(name, " / "); i >= 0 {
+			suite := name[:i]
+			if suite != ts.ShortName && suite != ts.Name && suite != ts.Filename {
+				continue
+			}
+			name = name[i+3:]
+		}
+		if name == tc.Name {
+			return &v.quarantine[i]
+		}
+	}
+	return nil
+}
+
+// applyQuarantine marks a failed testcase of the quarantine: its failures are reported,
+// but they are not counted in the failures of the testsuite
+func (v *Venom) applyQuarantine(ts *TestSuite, tc *TestCase) bool {
+	if len(tc.Failures) == 0 && len(tc.Errors) == 0 {
+		return false
+	}
+	e := v.quarantineEntry(ts, tc)
+	if e == nil {
+		return false
+	}
+	tc.Status = "quarantined"
+	tc.Systemout.Value += fmt.Sprintf("Testcase in quarantine until %s: %s\n", e.Until, e.Reason)
+	return true
+}
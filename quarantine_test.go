@@ -0,0 +1,57 @@
+package venom
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestQuarantine(t *testing.T) {
+	f, err := ioutil.TempFile("", "quarantine*.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	content := "- testcase: suite / flaky\n  until: " + tomorrow + "\n  reason: known flake\n" +
+		"- testcase: everywhere\n  until: " + time.Now().Format("2006-01-02") + "\n" +
+		"- testcase: suite.yml / slow\n  until: " + tomorrow + "\n" +
+		"- testcase: expired\n  until: 2020-01-01\n"
+	if _, err := f.WriteString(content); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	v := New()
+	v.QuarantineFile = f.Name()
+	if err := v.loadQuarantine(); err != nil {
+		t.Fatal(err)
+	}
+	if len(v.quarantine) != 3 {
+		t.Fatalf("expected 3 entries, got %v", v.quarantine)
+	}
+
+	failure := []Failure{{Value: "boom"}}
+	ts := &TestSuite{Name: "suite [suite.yml]", ShortName: "suite", Filename: "suite.yml"}
+	tests := []struct {
+		tc          TestCase
+		quarantined bool
+	}{
+		{tc: TestCase{Name: "flaky", Failures: failure}, quarantined: true},
+		{tc: TestCase{Name: "everywhere", Errors: failure}, quarantined: true},
+		{tc: TestCase{Name: "flaky"}},
+		{tc: TestCase{Name: "expired", Failures: failure}},
+		{tc: TestCase{Name: "slow", Failures: failure}, quarantined: true},
+		{tc: TestCase{Name: "other", Failures: failure}},
+	}
+	for _, tt := range tests {
+		if got := v.applyQuarantine(ts, &tt.tc); got != tt.quarantined {
+			t.Errorf("%s: expected quarantined %v, got %v", tt.tc.Name, tt.quarantined, got)
+		}
+	}
+
+	if v.quarantineEntry(&TestSuite{ShortName: "other"}, &TestCase{Name: "flaky"}) != nil {
+		t.Error("flaky is only quarantined in suite")
+	}
+}
@@ -0,0 +1,63 @@
+package venom
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// parseRateLimits parses limits like 20rps (all the executors) or http=5rps (the http executor only).
+// Rates are by second (10, 10rps, 10/s), by minute (600rpm, 600/m) or by hour (3600rph, 3600/h).
+// Each limit is a token bucket shared by all the testsuites.
+func parseRateLimits(limits []string) (map[string]*rate.Limiter, error) {
+	limiters := map[string]*rate.Limiter{}
+	for _, limit := range limits {
+		if limit == "" {
+			continue
+		}
+		var name string
+		r := limit
+		if i := strings.Index(limit, "="); i >= 0 {
+			name, r = strings.TrimSpace(limit[:i]), limit[i+1:]
+		}
+		r = strings.ToLower(strings.TrimSpace(r))
+
+		unit := time.Second
+		for suffix, d := range map[string]time.Duration{
+			"rps": time.Second, "/s": time.Second,
+			"rpm": time.Minute, "/m": time.Minute, "/min": time.Minute,
+			"rph": time.Hour, "/h": time.Hour,
+		} {
+			if strings.HasSuffix(r, suffix) {
+				r, unit = strings.TrimSuffix(r, suffix), d
+				break
+			}
+		}
+		n, err := strconv.ParseFloat(strings.TrimSpace(r), 64)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid rate limit %q, expected something like 20rps or http=5rps", limit)
+		}
+		// the bucket holds the calls of one second, at least one
+		perSecond := n * float64(time.Second) / float64(unit)
+		limiters[name] = rate.NewLimiter(rate.Limit(perSecond), int(math.Max(1, math.Ceil(perSecond))))
+	}
+	return limiters, nil
+}
+
+// waitRateLimits waits for the global rate limit and for the rate limit of the executor,
+// it returns an error if the context is done before
+func (v *Venom) waitRateLimits(ctx context.Context, e *ExecutorWrap) error {
+	for _, name := range []string{"", e.name} {
+		if r, ok := v.rateLimiters[name]; ok {
+			if err := r.Wait(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package venom
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestParseRateLimits(t *testing.T) {
+	limiters, err := parseRateLimits([]string{"20rps", "http=600/m", "ssh = 2 rph", ""})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]struct {
+		limit rate.Limit
+		burst int
+	}{
+		"":     {20, 20},
+		"http": {10, 10},
+		"ssh":  {rate.Limit(2.0 / 3600), 1},
+	}
+	if len(limiters) != len(expected) {
+		t.Fatalf("expected %d limiters, got %d", len(expected), len(limiters))
+	}
+	for name, e := range expected {
+		l := limiters[name]
+		if l == nil || l.Limit() != e.limit || l.Burst() != e.burst {
+			t.Errorf("expected a limit of %v with a burst of %d for %q, got %v", e.limit, e.burst, name, l)
+		}
+	}
+
+	for _, limit := range []string{"fast", "0rps", "http=-1"} {
+		if _, err := parseRateLimits([]string{limit}); err == nil {
+			t.Errorf("expected an error for %q", limit)
+		}
+	}
+}
+
+func TestWaitRateLimits(t *testing.T) {
+	limiters, err := parseRateLimits([]string{"50rps", "http=2rps"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := &Venom{rateLimiters: limiters}
+	http := &ExecutorWrap{name: "http"}
+	start := time.Now()
+	// the burst of 2 calls is not delayed, the third one waits for a token
+	for i := 0; i < 3; i++ {
+		if err := v.waitRateLimits(context.Background(), http); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond {
+		t.Errorf("expected at least 400ms, got %v", elapsed)
+	}
+
+	// a wait is stopped when the run is interrupted
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	start = time.Now()
+	if err := v.waitRateLimits(ctx, http); err == nil {
+		t.Error("expected an error once the context is cancelled")
+	}
+	if elapsed := time.Since(start); elapsed > 300*time.Millisecond {
+		t.Errorf("expected the wait to stop with the context, it took %v", elapsed)
+	}
+}
@@ -0,0 +1,129 @@
+package venom
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// isRemoteSource returns true for the paths of testsuites fetched before the run:
+// git::https://github.com/org/repo//suites?ref=v1.2 or https://example.com/suites/smoke.yml
+func isRemoteSource(p string) bool {
+	return strings.HasPrefix(p, "git::") || strings.HasPrefix(p, "https://") || strings.HasPrefix(p, "http://")
+}
+
+// resolveRemoteSources fetches the remote testsuites in temporary directories and returns the local paths.
+// A source is fetched once by run, the directories are removed by CleanRemoteSources.
+func (v *Venom) resolveRemoteSources(paths []string) ([]string, error) {
+	local := make([]string, 0, len(paths))
+	for _, p := range paths {
+		p = strings.TrimSpace(p)
+		if !isRemoteSource(p) {
+			local = append(local, p)
+			continue
+		}
+		if l, ok := v.remoteSources[p]; ok {
+			local = append(local, l)
+			continue
+		}
+
+		dir, err := ioutil.TempDir("", "venom-remote")
+		if err != nil {
+			return nil, err
+		}
+		if v.remoteSources == nil {
+			v.remoteSources = map[string]string{}
+		}
+		var l string
+		if strings.HasPrefix(p, "git::") {
+			l, err = fetchGitSource(p, dir)
+		} else {
+			l, err = fetchHTTPSource(p, dir)
+		}
+		if err != nil {
+			os.RemoveAll(dir)
+			return nil, fmt.Errorf("unable to fetch %s: %v", p, err)
+		}
+		v.remoteDirs = append(v.remoteDirs, dir)
+		v.remoteSources[p] = l
+		local = append(local, l)
+	}
+	return local, nil
+}
+
+// CleanRemoteSources removes the testsuites fetched for the run, they are fetched again by the next run
+func (v *Venom) CleanRemoteSources() {
+	for _, dir := range v.remoteDirs {
+		os.RemoveAll(dir)
+	}
+	v.remoteDirs, v.remoteSources = nil, nil
+}
+
+// parseGitSource returns the repository, the directory in the repository and the ref of a git source:
+// git::https://github.com/org/repo//suites?ref=v1.2
+func parseGitSource(src string) (repo, subdir, ref string) {
+	repo = strings.TrimPrefix(src, "git::")
+	if i := strings.LastIndex(repo, "?"); i >= 0 {
+		if q, err := url.ParseQuery(repo[i+1:]); err == nil {
+			ref = q.Get("ref")
+		}
+		repo = repo[:i]
+	}
+	start := 0
+	if i := strings.Index(repo, "://"); i >= 0 {
+		start = i + 3
+	}
+	if i := strings.Index(repo[start:], "//"); i >= 0 {
+		repo, subdir = repo[:start+i], repo[start+i+2:]
+	}
+	return repo, subdir, ref
+}
+
+// fetchGitSource clones a git source in dir, it returns the directory of the testsuites
+func fetchGitSource(src, dir string) (string, error) {
+	repo, subdir, ref := parseGitSource(src)
+	args := []string{"clone", "--quiet", "--depth", "1"}
+	if ref != "" {
+		args = append(args, "--branch", ref)
+	}
+	out, err := exec.Command("git", append(args, repo, dir)...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git clone: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return filepath.Join(dir, filepath.FromSlash(subdir)), nil
+}
+
+// fetchHTTPSource downloads a testsuite in dir, it returns the file written
+func fetchHTTPSource(src, dir string) (string, error) {
+	u, err := url.Parse(src)
+	if err != nil {
+		return "", err
+	}
+	name := path.Base(u.Path)
+	switch path.Ext(name) {
+	case ".yml", ".yaml", ".hcl":
+	default:
+		return "", fmt.Errorf("the extension of the testsuite should be .yml, .yaml or .hcl")
+	}
+
+	resp, err := http.Get(src)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("status code %d", resp.StatusCode)
+	}
+	btes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	filename := filepath.Join(dir, name)
+	return filename, ioutil.WriteFile(filename, btes, 0644)
+}
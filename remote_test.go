@@ -0,0 +1,67 @@
+package venom
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseGitSource(t *testing.T) {
+	tests := []struct {
+		src, repo, subdir, ref string
+	}{
+		{src: "git::https://github.com/org/repo//suites?ref=v1.2", repo: "https://github.com/org/repo", subdir: "suites", ref: "v1.2"},
+		{src: "git::https://github.com/org/repo.git", repo: "https://github.com/org/repo.git"},
+		{src: "git::git@github.com:org/repo.git//a/b", repo: "git@github.com:org/repo.git", subdir: "a/b"},
+	}
+	for _, tt := range tests {
+		repo, subdir, ref := parseGitSource(tt.src)
+		if repo != tt.repo || subdir != tt.subdir || ref != tt.ref {
+			t.Errorf("parseGitSource(%q) = %q, %q, %q", tt.src, repo, subdir, ref)
+		}
+	}
+}
+
+func TestResolveRemoteSources(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path != "/suites/smoke.yml" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("name: smoke\n"))
+	}))
+	defer srv.Close()
+
+	v := New()
+	paths, err := v.resolveRemoteSources([]string{"local.yml", srv.URL + "/suites/smoke.yml"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 2 || paths[0] != "local.yml" || filepath.Base(paths[1]) != "smoke.yml" {
+		t.Fatalf("unexpected paths %v", paths)
+	}
+	if btes, err := ioutil.ReadFile(paths[1]); err != nil || string(btes) != "name: smoke\n" {
+		t.Errorf("unexpected testsuite fetched %q %v", btes, err)
+	}
+
+	// a source is fetched once by run
+	if again, err := v.resolveRemoteSources([]string{srv.URL + "/suites/smoke.yml"}); err != nil || again[0] != paths[1] || calls != 1 {
+		t.Errorf("expected the source fetched once, got %v %v after %d calls", again, err, calls)
+	}
+	v.CleanRemoteSources()
+	if _, err := os.Stat(paths[1]); !os.IsNotExist(err) {
+		t.Errorf("the testsuite fetched should be removed, got %v", err)
+	}
+
+	if _, err := v.resolveRemoteSources([]string{srv.URL + "/suites/missing.yml"}); err == nil {
+		t.Error("expected an error for a missing testsuite")
+	}
+	if _, err := v.resolveRemoteSources([]string{srv.URL + "/suites/smoke.txt"}); err == nil {
+		t.Error("expected an error for a file which is not a testsuite")
+	}
+}
@@ -0,0 +1,78 @@
+package venom
+
+import (
+	"io/ioutil"
+	"runtime"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// resourcesInterval is the interval between two samples of the usage of the resources
+const resourcesInterval = 100 * time.Millisecond
+
+// ResourceUsage is the peak usage of the resources of the venom process during a phase of the run
+type ResourceUsage struct {
+	Phase          string  `json:"phase"`
+	Duration       float64 `json:"duration"`
+	PeakHeap       uint64  `json:"peak_heap_bytes"`
+	PeakGoroutines int     `json:"peak_goroutines"`
+	// PeakOpenFiles is -1 when the open file descriptors can't be counted on the system
+	PeakOpenFiles int `json:"peak_open_files"`
+}
+
+// trackResources samples the usage of the resources until the returned function is called, the usage of the phase is
+// then kept for the report of the run
+func (v *Venom) trackResources(phase string) func() {
+	start := time.Now()
+	usage := ResourceUsage{Phase: phase, PeakOpenFiles: -1}
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(resourcesInterval)
+		defer ticker.Stop()
+		for {
+			usage.sample()
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return func() {
+		close(stop)
+		<-done
+		usage.sample()
+		usage.Duration = time.Since(start).Seconds()
+		log.Infof("Resources of the %s phase: peak heap %.1f MiB, %d goroutines, %d open files, in %.2fs",
+			phase, float64(usage.PeakHeap)/(1<<20), usage.PeakGoroutines, usage.PeakOpenFiles, usage.Duration)
+		v.resources = append(v.resources, usage)
+	}
+}
+
+func (u *ResourceUsage) sample() {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	if m.HeapAlloc > u.PeakHeap {
+		u.PeakHeap = m.HeapAlloc
+	}
+	if n := runtime.NumGoroutine(); n > u.PeakGoroutines {
+		u.PeakGoroutines = n
+	}
+	if n := openFiles(); n > u.PeakOpenFiles {
+		u.PeakOpenFiles = n
+	}
+}
+
+// openFiles counts the open file descriptors of the process, -1 if they can't be listed
+func openFiles() int {
+	for _, dir := range []string{"/proc/self/fd", "/dev/fd"} {
+		if fds, err := ioutil.ReadDir(dir); err == nil {
+			return len(fds)
+		}
+	}
+	return -1
+}
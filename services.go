@@ -0,0 +1,178 @@
+package venom
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const composeCommand = "docker-compose"
+
+// Services describes a docker-compose stack started before a testsuite
+// and removed after it.
+type Services struct {
+	ComposeFile string `json:"compose_file" yaml:"compose_file" hcl:"compose_file"`
+	Project     string `json:"project,omitempty" yaml:"project,omitempty" hcl:"project"`
+	Timeout     int    `json:"timeout,omitempty" yaml:"timeout,omitempty" hcl:"timeout"` // seconds to wait for containers to be ready
+}
+
+// composeContainer is the subset of `docker inspect` output used by venom
+type composeContainer struct {
+	Name   string `json:"Name"`
+	Config struct {
+		Labels map[string]string `json:"Labels"`
+	} `json:"Config"`
+	State struct {
+		Status string `json:"Status"`
+		Health *struct {
+			Status string `json:"Status"`
+		} `json:"Health"`
+	} `json:"State"`
+	NetworkSettings struct {
+		Ports    map[string][]composePortBinding `json:"Ports"`
+		Networks map[string]struct {
+			IPAddress string `json:"IPAddress"`
+		} `json:"Networks"`
+	} `json:"NetworkSettings"`
+}
+
+type composePortBinding struct {
+	HostIP   string `json:"HostIp"`
+	HostPort string `json:"HostPort"`
+}
+
+// startServices brings up the compose stack, waits until all containers are ready
+// and returns the variables describing the containers addresses.
+// The returned func tears the stack down, it must be called even if an error is returned.
+func startServices(s Services, workdir, defaultProject string) (map[string]string, func(), error) {
+	file := s.ComposeFile
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(workdir, file)
+	}
+	project := s.Project
+	if project == "" {
+		project = "venom-" + slug(defaultProject)
+	}
+	args := []string{"-f", file, "-p", project}
+
+	stop := func() {
+		log.Infof("Stopping services of project %s", project)
+		if _, err := runCompose(args, "down", "-v", "--remove-orphans"); err != nil {
+			log.Errorf("unable to stop services of project %s: %v", project, err)
+		}
+	}
+
+	log.Infof("Starting services from %s with project %s", file, project)
+	if _, err := runCompose(args, "up", "-d"); err != nil {
+		return nil, stop, err
+	}
+
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = 60
+	}
+	deadline := time.Now().Add(time.Duration(timeout) * time.Second)
+	for {
+		containers, err := inspectServices(args)
+		if err != nil {
+			return nil, stop, err
+		}
+		notReady := notReadyServices(containers)
+		if len(notReady) == 0 {
+			return servicesVariables(containers), stop, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, stop, fmt.Errorf("services %v are not ready after %d second(s)", notReady, timeout)
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+func runCompose(args []string, cmd ...string) ([]byte, error) {
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	c := exec.Command(composeCommand, append(args, cmd...)...)
+	c.Stdout = stdout
+	c.Stderr = stderr
+	if err := c.Run(); err != nil {
+		return nil, fmt.Errorf("%s %s failed: %v: %s", composeCommand, strings.Join(cmd, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+func inspectServices(args []string) ([]composeContainer, error) {
+	out, err := runCompose(args, "ps", "-q")
+	if err != nil {
+		return nil, err
+	}
+	ids := strings.Fields(string(out))
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no container started by %s", composeCommand)
+	}
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	c := exec.Command("docker", append([]string{"inspect"}, ids...)...)
+	c.Stdout = stdout
+	c.Stderr = stderr
+	if err := c.Run(); err != nil {
+		return nil, fmt.Errorf("docker inspect failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var containers []composeContainer
+	if err := json.Unmarshal(stdout.Bytes(), &containers); err != nil {
+		return nil, fmt.Errorf("unable to read docker inspect output: %v", err)
+	}
+	return containers, nil
+}
+
+// notReadyServices returns the name of services which are not running,
+// or not healthy if they declare a healthcheck
+func notReadyServices(containers []composeContainer) []string {
+	var res []string
+	for _, c := range containers {
+		ready := c.State.Status == "running"
+		if c.State.Health != nil {
+			ready = c.State.Health.Status == "healthy"
+		}
+		if !ready {
+			res = append(res, c.serviceName())
+		}
+	}
+	return res
+}
+
+// servicesVariables computes venom.services.<service>.host, venom.services.<service>.ip
+// and venom.services.<service>.port.<containerPort> variables
+func servicesVariables(containers []composeContainer) map[string]string {
+	vars := map[string]string{}
+	for _, c := range containers {
+		prefix := "venom.services." + c.serviceName()
+		for _, n := range c.NetworkSettings.Networks {
+			if n.IPAddress != "" {
+				vars[prefix+".ip"] = n.IPAddress
+				break
+			}
+		}
+		vars[prefix+".host"] = "localhost"
+		for port, bindings := range c.NetworkSettings.Ports {
+			if len(bindings) == 0 {
+				continue
+			}
+			containerPort := strings.Split(port, "/")[0]
+			vars[prefix+".port."+containerPort] = bindings[0].HostPort
+		}
+	}
+	return vars
+}
+
+func (c composeContainer) serviceName() string {
+	if s, ok := c.Config.Labels["com.docker.compose.service"]; ok {
+		return s
+	}
+	return strings.TrimPrefix(c.Name, "/")
+}
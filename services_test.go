@@ -0,0 +1,40 @@
+package venom
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const dockerInspectOutput = `[
+  {
+    "Name": "/venom-mysuite_web_1",
+    "Config": {"Labels": {"com.docker.compose.service": "web"}},
+    "State": {"Status": "running", "Health": {"Status": "healthy"}},
+    "NetworkSettings": {
+      "Ports": {"80/tcp": [{"HostIp": "0.0.0.0", "HostPort": "32768"}], "443/tcp": null},
+      "Networks": {"venom-mysuite_default": {"IPAddress": "172.18.0.2"}}
+    }
+  },
+  {
+    "Name": "/venom-mysuite_db_1",
+    "Config": {"Labels": {"com.docker.compose.service": "db"}},
+    "State": {"Status": "running", "Health": {"Status": "starting"}},
+    "NetworkSettings": {"Ports": {}, "Networks": {}}
+  }
+]`
+
+func Test_servicesVariables(t *testing.T) {
+	var containers []composeContainer
+	assert.NoError(t, json.Unmarshal([]byte(dockerInspectOutput), &containers))
+
+	vars := servicesVariables(containers)
+	assert.Equal(t, "172.18.0.2", vars["venom.services.web.ip"])
+	assert.Equal(t, "localhost", vars["venom.services.web.host"])
+	assert.Equal(t, "32768", vars["venom.services.web.port.80"])
+	assert.NotContains(t, vars, "venom.services.web.port.443")
+	assert.NotContains(t, vars, "venom.services.db.ip")
+
+	assert.Equal(t, []string{"db"}, notReadyServices(containers))
+}
@@ -0,0 +1,68 @@
+package venom
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ovh/venom/assertions"
+)
+
+// SkippedList is the list of the reasons a testcase is skipped. In a testsuite, it can be written as a reason,
+// skipped: "the sandbox is down", as true or as a list of reasons.
+type SkippedList []Skipped
+
+func (s *SkippedList) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var list []Skipped
+	if err := unmarshal(&list); err == nil {
+		*s = list
+		return nil
+	}
+	var reason string
+	if err := unmarshal(&reason); err != nil {
+		return fmt.Errorf("skipped should be a reason, true or a list of reasons: %v", err)
+	}
+	switch strings.ToLower(strings.TrimSpace(reason)) {
+	case "", "false", "0":
+		*s = nil
+	case "true", "1":
+		*s = SkippedList{{Value: "skipped"}}
+	default:
+		*s = SkippedList{{Value: reason}}
+	}
+	return nil
+}
+
+// SkipIf skips a testcase when its condition is true: an assertion on the variables, env ShouldEqual prod
+type SkipIf struct {
+	Condition string `json:"condition" yaml:"condition"`
+	Reason    string `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
+
+// applySkipIf skips the testcase when the condition of its skip_if is true
+func applySkipIf(ts *TestSuite, tc *TestCase) error {
+	if tc.SkipIf == nil || len(tc.Skipped) > 0 {
+		return nil
+	}
+	_, condition := ts.Templater.apply([]byte(tc.SkipIf.Condition))
+	assert := splitAssertion(string(condition))
+	if len(assert) < 2 {
+		return fmt.Errorf("skip_if: syntax error in %q", tc.SkipIf.Condition)
+	}
+	if _, ok := assertions.Get(assert[1]); !ok {
+		return fmt.Errorf("skip_if: assertion %s not supported", assert[1])
+	}
+
+	vars := ExecutorResult{}
+	for k, v := range ts.Templater.Values {
+		vars[k] = v
+	}
+	if _, failure := check(*tc, 0, string(condition), vars); failure != nil {
+		return nil
+	}
+	reason := tc.SkipIf.Reason
+	if reason == "" {
+		reason = "skip_if: " + tc.SkipIf.Condition
+	}
+	tc.Skipped = append(tc.Skipped, Skipped{Value: reason})
+	return nil
+}
@@ -0,0 +1,55 @@
+package venom
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestSkippedList_UnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		in   string
+		want SkippedList
+	}{
+		{in: `skipped: "the sandbox is down"`, want: SkippedList{{Value: "the sandbox is down"}}},
+		{in: `skipped: true`, want: SkippedList{{Value: "skipped"}}},
+		{in: `skipped: 1`, want: SkippedList{{Value: "skipped"}}},
+		{in: `skipped: false`},
+		{in: "skipped:\n- value: first\n- value: second", want: SkippedList{{Value: "first"}, {Value: "second"}}},
+	}
+	for _, tt := range tests {
+		var tc TestCase
+		if err := yaml.Unmarshal([]byte(tt.in), &tc); err != nil {
+			t.Fatalf("%s: %v", tt.in, err)
+		}
+		if !reflect.DeepEqual(tc.Skipped, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.in, tc.Skipped, tt.want)
+		}
+	}
+}
+
+func TestApplySkipIf(t *testing.T) {
+	ts := &TestSuite{Templater: newTemplater(map[string]string{"env": "prod", "target": "prod"})}
+
+	tc := &TestCase{SkipIf: &SkipIf{Condition: "env ShouldEqual {{.target}}", Reason: "not on prod"}}
+	if err := applySkipIf(ts, tc); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tc.Skipped, SkippedList{{Value: "not on prod"}}) {
+		t.Errorf("expected the testcase to be skipped, got %v", tc.Skipped)
+	}
+
+	tc = &TestCase{SkipIf: &SkipIf{Condition: "env ShouldEqual dev"}}
+	if err := applySkipIf(ts, tc); err != nil {
+		t.Fatal(err)
+	}
+	if len(tc.Skipped) > 0 {
+		t.Errorf("expected the testcase to be run, got %v", tc.Skipped)
+	}
+
+	tc = &TestCase{SkipIf: &SkipIf{Condition: "env ShouldBeProd"}}
+	if err := applySkipIf(ts, tc); err == nil {
+		t.Error("expected an error for an unknown assertion")
+	}
+}
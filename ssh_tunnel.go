@@ -0,0 +1,194 @@
+package venom
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh"
+)
+
+// SSHTunnel describes a local port forward through a SSH bastion, opened for the duration of a testsuite
+type SSHTunnel struct {
+	Name       string `json:"name" yaml:"name" hcl:"name"`
+	Bastion    string `json:"bastion" yaml:"bastion" hcl:"bastion"` // host or host:port of the jump host
+	Remote     string `json:"remote" yaml:"remote" hcl:"remote"`    // host:port of the service, as seen from the bastion
+	User       string `json:"user,omitempty" yaml:"user,omitempty" hcl:"user"`
+	Password   string `json:"password,omitempty" yaml:"password,omitempty" hcl:"password"`
+	PrivateKey string `json:"privatekey,omitempty" yaml:"privatekey,omitempty" hcl:"privatekey"`
+	Timeout    int    `json:"timeout,omitempty" yaml:"timeout,omitempty" hcl:"timeout"` // seconds to connect to the bastion
+}
+
+// startSSHTunnels opens all the tunnels and returns the variables venom.tunnel.<name>.host, venom.tunnel.<name>.port
+// and venom.tunnel.<name>.address, and the local address of each remote address.
+// The returned func closes the tunnels, it must be called even if an error is returned.
+func startSSHTunnels(tunnels []SSHTunnel) (map[string]string, map[string]string, func(), error) {
+	var closers []io.Closer
+	stop := func() {
+		for _, c := range closers {
+			_ = c.Close()
+		}
+	}
+
+	vars := map[string]string{}
+	rewrites := map[string]string{}
+	for _, t := range tunnels {
+		if t.Name == "" || t.Bastion == "" || t.Remote == "" {
+			return nil, nil, stop, fmt.Errorf("ssh_tunnels: name, bastion and remote are mandatory")
+		}
+		if _, _, err := net.SplitHostPort(t.Remote); err != nil {
+			return nil, nil, stop, fmt.Errorf("ssh tunnel %s: invalid remote %q, it must be host:port", t.Name, t.Remote)
+		}
+
+		config, err := t.clientConfig()
+		if err != nil {
+			return nil, nil, stop, fmt.Errorf("ssh tunnel %s: %v", t.Name, err)
+		}
+		bastion := t.Bastion
+		if _, _, err := net.SplitHostPort(bastion); err != nil {
+			bastion = net.JoinHostPort(bastion, "22")
+		}
+
+		log.Infof("Opening ssh tunnel %s to %s through %s", t.Name, t.Remote, bastion)
+		client, err := ssh.Dial("tcp", bastion, config)
+		if err != nil {
+			return nil, nil, stop, fmt.Errorf("unable to connect to the bastion of ssh tunnel %s: %v", t.Name, err)
+		}
+		closers = append(closers, client)
+
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			return nil, nil, stop, fmt.Errorf("ssh tunnel %s: %v", t.Name, err)
+		}
+		closers = append(closers, lis)
+		go forwardSSHTunnel(t, lis, client)
+
+		address := lis.Addr().String()
+		prefix := "venom.tunnel." + t.Name
+		vars[prefix+".host"] = "127.0.0.1"
+		vars[prefix+".port"] = strconv.Itoa(lis.Addr().(*net.TCPAddr).Port)
+		vars[prefix+".address"] = address
+		rewrites[t.Remote] = address
+	}
+	return vars, rewrites, stop, nil
+}
+
+func (t SSHTunnel) clientConfig() (*ssh.ClientConfig, error) {
+	u := t.User
+	if u == "" {
+		osUser, err := user.Current()
+		if err != nil {
+			return nil, err
+		}
+		u = osUser.Username
+	}
+
+	var auth []ssh.AuthMethod
+	if t.Password != "" {
+		auth = []ssh.AuthMethod{ssh.Password(t.Password)}
+	} else {
+		// the default private key is $HOME/.ssh/id_rsa, as for the ssh executor
+		file := t.PrivateKey
+		if file == "" {
+			usr, err := user.Current()
+			if err != nil {
+				return nil, err
+			}
+			file = filepath.Join(usr.HomeDir, ".ssh", "id_rsa")
+		}
+		buf, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		key, err := ssh.ParsePrivateKey(buf)
+		if err != nil {
+			return nil, fmt.Errorf("invalid private key %s: %v", file, err)
+		}
+		auth = []ssh.AuthMethod{ssh.PublicKeys(key)}
+	}
+
+	timeout := t.Timeout
+	if timeout <= 0 {
+		timeout = 30
+	}
+	return &ssh.ClientConfig{
+		User:            u,
+		Auth:            auth,
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		Timeout:         time.Duration(timeout) * time.Second,
+	}, nil
+}
+
+// forwardSSHTunnel forwards the connections accepted on the local listener to the remote address, through the
+// bastion, until the listener is closed
+func forwardSSHTunnel(t SSHTunnel, lis net.Listener, client *ssh.Client) {
+	for {
+		local, err := lis.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer local.Close()
+			remote, err := client.Dial("tcp", t.Remote)
+			if err != nil {
+				log.Errorf("ssh tunnel %s: unable to reach %s: %v", t.Name, t.Remote, err)
+				return
+			}
+			defer remote.Close()
+			done := make(chan struct{}, 2)
+			go func() {
+				_, _ = io.Copy(remote, local)
+				done <- struct{}{}
+			}()
+			go func() {
+				_, _ = io.Copy(local, remote)
+				done <- struct{}{}
+			}()
+			<-done
+		}()
+	}
+}
+
+// rewriteTunneledAddresses replaces the remote addresses of the tunnels by their local address in the attributes of
+// the steps, the attributes merged from the targets included
+func rewriteTunneledAddresses(ts *TestSuite, rewrites map[string]string) {
+	var pairs []string
+	for remote, local := range rewrites {
+		pairs = append(pairs, remote, local)
+	}
+	replacer := strings.NewReplacer(pairs...)
+	testCases := ts.withHooks()
+	for i := range testCases {
+		tc := &testCases[i]
+		for j := range tc.TestSteps {
+			tc.TestSteps[j] = TestStep(rewriteValue(map[string]interface{}(tc.TestSteps[j]), replacer).(map[string]interface{}))
+		}
+	}
+}
+
+func rewriteValue(value interface{}, replacer *strings.Replacer) interface{} {
+	switch v := value.(type) {
+	case string:
+		return replacer.Replace(v)
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = rewriteValue(e, replacer)
+		}
+	case map[interface{}]interface{}:
+		for k, e := range v {
+			v[k] = rewriteValue(e, replacer)
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = rewriteValue(e, replacer)
+		}
+	}
+	return value
+}
@@ -0,0 +1,133 @@
+package venom
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"io"
+	"io/ioutil"
+	"net"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+// startBastion starts a SSH server accepting the password "secret" and the direct-tcpip channels
+func startBastion(t *testing.T) net.Listener {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(key)
+	require.NoError(t, err)
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			if string(pass) != "secret" {
+				return nil, io.EOF
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(signer)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_, chans, reqs, err := ssh.NewServerConn(conn, config)
+				if err != nil {
+					return
+				}
+				go ssh.DiscardRequests(reqs)
+				for newChan := range chans {
+					var target struct {
+						Host       string
+						Port       uint32
+						OriginHost string
+						OriginPort uint32
+					}
+					if newChan.ChannelType() != "direct-tcpip" || ssh.Unmarshal(newChan.ExtraData(), &target) != nil {
+						_ = newChan.Reject(ssh.UnknownChannelType, "unsupported")
+						continue
+					}
+					remote, err := net.Dial("tcp", net.JoinHostPort(target.Host, strconv.Itoa(int(target.Port))))
+					if err != nil {
+						_ = newChan.Reject(ssh.ConnectionFailed, err.Error())
+						continue
+					}
+					ch, chReqs, err := newChan.Accept()
+					if err != nil {
+						remote.Close()
+						continue
+					}
+					go ssh.DiscardRequests(chReqs)
+					go func() {
+						defer ch.Close()
+						defer remote.Close()
+						go func() { _, _ = io.Copy(remote, ch) }()
+						_, _ = io.Copy(ch, remote)
+					}()
+				}
+			}()
+		}
+	}()
+	return lis
+}
+
+func Test_startSSHTunnels(t *testing.T) {
+	service, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer service.Close()
+	go func() {
+		for {
+			conn, err := service.Accept()
+			if err != nil {
+				return
+			}
+			_, _ = io.WriteString(conn, "hello from the service")
+			conn.Close()
+		}
+	}()
+
+	lis := startBastion(t)
+	defer lis.Close()
+	bastion := lis.Addr().String()
+	tunnels := []SSHTunnel{{Name: "api", Bastion: bastion, Remote: service.Addr().String(), User: "venom", Password: "secret"}}
+	vars, rewrites, stop, err := startSSHTunnels(tunnels)
+	defer stop()
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1", vars["venom.tunnel.api.host"])
+	assert.Equal(t, vars["venom.tunnel.api.address"], rewrites[service.Addr().String()])
+
+	conn, err := net.Dial("tcp", vars["venom.tunnel.api.address"])
+	require.NoError(t, err)
+	defer conn.Close()
+	btes, err := ioutil.ReadAll(conn)
+	require.NoError(t, err)
+	assert.Equal(t, "hello from the service", string(btes))
+
+	_, _, stop2, err := startSSHTunnels([]SSHTunnel{{Name: "api", Bastion: bastion, Remote: service.Addr().String(), User: "venom", Password: "wrong"}})
+	defer stop2()
+	assert.Error(t, err)
+}
+
+func Test_rewriteTunneledAddresses(t *testing.T) {
+	ts := &TestSuite{TestCases: []TestCase{{TestSteps: []TestStep{{
+		"type":    "http",
+		"url":     "http://db.internal:8080/health",
+		"headers": map[interface{}]interface{}{"X-Forwarded-Host": "db.internal:8080"},
+		"port":    8080,
+	}}}}}
+	rewriteTunneledAddresses(ts, map[string]string{"db.internal:8080": "127.0.0.1:40000"})
+
+	step := ts.TestCases[0].TestSteps[0]
+	assert.Equal(t, "http://127.0.0.1:40000/health", step["url"])
+	assert.Equal(t, "127.0.0.1:40000", step["headers"].(map[interface{}]interface{})["X-Forwarded-Host"])
+	assert.Equal(t, 8080, step["port"])
+}
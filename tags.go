@@ -0,0 +1,35 @@
+package venom
+
+// selectByTags keeps the testcases of the testsuite selected by --tag and --exclude-tag: a testcase is selected when
+// one of its tags, or of the tags of its testsuite, is given with --tag and none is given with --exclude-tag. It
+// returns false when no testcase of the testsuite is selected.
+func (v *Venom) selectByTags(ts *TestSuite) bool {
+	if len(v.Tags) == 0 && len(v.ExcludeTags) == 0 {
+		return true
+	}
+	var selected []TestCase
+	for _, tc := range ts.TestCases {
+		tags := append(append([]string{}, ts.Tags...), tc.Tags...)
+		if len(v.Tags) > 0 && !matchTags(v.Tags, tags) {
+			continue
+		}
+		if matchTags(v.ExcludeTags, tags) {
+			continue
+		}
+		selected = append(selected, tc)
+	}
+	ts.TestCases = selected
+	return len(selected) > 0
+}
+
+// matchTags returns true if one of the tags is in the filters
+func matchTags(filters, tags []string) bool {
+	for _, f := range filters {
+		for _, t := range tags {
+			if f == t {
+				return true
+			}
+		}
+	}
+	return false
+}
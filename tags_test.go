@@ -0,0 +1,48 @@
+package venom
+
+import (
+	"testing"
+)
+
+func TestSelectByTags(t *testing.T) {
+	suite := func() *TestSuite {
+		return &TestSuite{Tags: []string{"api"}, TestCases: []TestCase{
+			{Name: "smoke", Tags: []string{"smoke"}},
+			{Name: "slow", Tags: []string{"smoke", "slow"}},
+			{Name: "untagged"},
+		}}
+	}
+	names := func(ts *TestSuite) []string {
+		var names []string
+		for _, tc := range ts.TestCases {
+			names = append(names, tc.Name)
+		}
+		return names
+	}
+
+	v := New()
+	ts := suite()
+	if !v.selectByTags(ts) || len(ts.TestCases) != 3 {
+		t.Errorf("all the testcases are selected without filter, got %v", names(ts))
+	}
+
+	v.Tags = []string{"smoke"}
+	v.ExcludeTags = []string{"slow"}
+	ts = suite()
+	if !v.selectByTags(ts) || len(ts.TestCases) != 1 || ts.TestCases[0].Name != "smoke" {
+		t.Errorf("expected the smoke testcase, got %v", names(ts))
+	}
+
+	// the tags of the testsuite are the tags of its testcases
+	v.Tags = []string{"api"}
+	v.ExcludeTags = nil
+	ts = suite()
+	if !v.selectByTags(ts) || len(ts.TestCases) != 3 {
+		t.Errorf("expected all the testcases of the api testsuite, got %v", names(ts))
+	}
+	v.Tags = nil
+	v.ExcludeTags = []string{"api"}
+	if ts = suite(); v.selectByTags(ts) {
+		t.Errorf("expected no testcase selected, got %v", names(ts))
+	}
+}
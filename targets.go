@@ -0,0 +1,48 @@
+package venom
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// loadTargets reads the targets of the run, they are shared by all the testsuites
+func (v *Venom) loadTargets() error {
+	v.targets = nil
+	if v.TargetsFile == "" {
+		return nil
+	}
+	btes, err := ioutil.ReadFile(v.TargetsFile)
+	if err != nil {
+		return fmt.Errorf("unable to read the targets file: %v", err)
+	}
+	if err := yaml.Unmarshal(btes, &v.targets); err != nil {
+		return fmt.Errorf("invalid targets file %s: %v", v.TargetsFile, err)
+	}
+	return nil
+}
+
+// applyTargets merges the target of the steps, `target: billing-api`, into the steps: the attributes of the step
+// override it. The targets of the testsuite override the targets of the run.
+func (v *Venom) applyTargets(ts *TestSuite) error {
+	testCases := ts.withHooks()
+	for i := range testCases {
+		tc := &testCases[i]
+		for j, step := range tc.TestSteps {
+			name, _ := step["target"].(string)
+			if name == "" {
+				continue
+			}
+			target, ok := ts.Targets[name]
+			if !ok {
+				target, ok = v.targets[name]
+			}
+			if !ok {
+				return fmt.Errorf("%s: target %q of testcase %q is not defined", ts.Filename, name, tc.Name)
+			}
+			tc.TestSteps[j] = TestStep(mergeConfig(target, map[string]interface{}(step)).(map[string]interface{}))
+		}
+	}
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	log "github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v2"
 )
 
@@ -129,5 +130,10 @@ func (tmpl *Templater) apply(in []byte) (bool, []byte) {
 			return applied, []byte(out)
 		}
 	}
+
+	out, err := tmpl.applyFuncs(out)
+	if err != nil {
+		log.Errorf("%v", err)
+	}
 	return applied, []byte(out)
 }
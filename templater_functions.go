@@ -0,0 +1,299 @@
+package venom
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/hmac"
+	_ "crypto/md5" // register hash functions
+	"crypto/rand"
+	"crypto/rsa"
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// templateFunc is a function usable in testsuites: {{funcname arg1 "arg 2" .variable}}
+type templateFunc func(args ...string) (string, error)
+
+var templateFuncs = map[string]templateFunc{
+	"jwt":       jwtFunc,
+	"md5":       hashFunc(crypto.MD5),
+	"sha1":      hashFunc(crypto.SHA1),
+	"sha256":    hashFunc(crypto.SHA256),
+	"sha512":    hashFunc(crypto.SHA512),
+	"hmac":      hmacFunc,
+	"rsaSign":   rsaSignFunc,
+	"xmlEscape": xmlEscapeFunc,
+}
+
+var templateFuncRegEx = func() *regexp.Regexp {
+	names := make([]string, 0, len(templateFuncs))
+	for k := range templateFuncs {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return regexp.MustCompile(`{{\s*(` + strings.Join(names, "|") + `)\s+(.*?)\s*}}`)
+}()
+
+// isTemplateFunc returns true if s is a call to a template function
+func isTemplateFunc(s string) bool {
+	return templateFuncRegEx.MatchString(s)
+}
+
+// applyFuncs replaces all templates functions calls in s by their results.
+// Arguments starting with a dot are variables.
+func (tmpl *Templater) applyFuncs(s string) (string, error) {
+	var errs []string
+	out := templateFuncRegEx.ReplaceAllStringFunc(s, func(call string) string {
+		m := templateFuncRegEx.FindStringSubmatch(call)
+		args := splitAssertion(m[2])
+		for i, a := range args {
+			if strings.HasPrefix(a, ".") {
+				v, ok := tmpl.lookup(a[1:])
+				if !ok {
+					// the variable is not yet known, keep the call as is
+					return call
+				}
+				args[i] = v
+			}
+		}
+		res, err := templateFuncs[m[1]](args...)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", m[1], err))
+			return call
+		}
+		return res
+	})
+	if len(errs) > 0 {
+		return out, fmt.Errorf("templater> %s", strings.Join(errs, ", "))
+	}
+	return out, nil
+}
+
+// lookup returns the value of a variable. If the variable is a map
+// (ie. the templater contains name.key1, name.key2...), its JSON representation is returned.
+func (tmpl *Templater) lookup(name string) (string, bool) {
+	if v, ok := tmpl.Values[name]; ok {
+		return v, true
+	}
+	m := map[string]interface{}{}
+	prefix := name + "."
+	for k, v := range tmpl.Values {
+		if !strings.HasPrefix(k, prefix) {
+			continue
+		}
+		keys := strings.Split(strings.TrimPrefix(k, prefix), ".")
+		current := m
+		for _, key := range keys[:len(keys)-1] {
+			next, ok := current[key].(map[string]interface{})
+			if !ok {
+				next = map[string]interface{}{}
+				current[key] = next
+			}
+			current = next
+		}
+		current[keys[len(keys)-1]] = typedValue(v)
+	}
+	if len(m) == 0 {
+		return "", false
+	}
+	btes, err := json.Marshal(m)
+	if err != nil {
+		return "", false
+	}
+	return string(btes), true
+}
+
+// typedValue converts string values from templater into numbers or booleans
+func typedValue(v string) interface{} {
+	if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(v, 64); err == nil {
+		return f
+	}
+	if b, err := strconv.ParseBool(v); err == nil {
+		return b
+	}
+	return v
+}
+
+// jwtFunc mints a signed JWT: {{jwt <claims as JSON> <alg> <secret or PEM private key>}}
+func jwtFunc(args ...string) (string, error) {
+	if len(args) != 3 {
+		return "", fmt.Errorf("usage: jwt <claims> <alg> <key>")
+	}
+	claims, alg, key := args[0], strings.ToUpper(args[1]), args[2]
+
+	var c map[string]interface{}
+	if err := json.Unmarshal([]byte(claims), &c); err != nil {
+		return "", fmt.Errorf("claims must be a JSON object: %v", err)
+	}
+	header, err := json.Marshal(map[string]string{"alg": alg, "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	sig, err := sign(alg, key, []byte(signingInput))
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// hashFunc returns the hex encoded hash of its argument: {{sha256 <value>}}
+func hashFunc(h crypto.Hash) templateFunc {
+	return func(args ...string) (string, error) {
+		if len(args) != 1 {
+			return "", fmt.Errorf("usage: <hash> <value>")
+		}
+		hash := h.New()
+		hash.Write([]byte(args[0]))
+		return hex.EncodeToString(hash.Sum(nil)), nil
+	}
+}
+
+// hmacFunc computes a HMAC: {{hmac <sha1|sha256|sha512> <key> <value> [hex|base64]}}, default encoding is hex
+func hmacFunc(args ...string) (string, error) {
+	if len(args) != 3 && len(args) != 4 {
+		return "", fmt.Errorf("usage: hmac <sha1|sha256|sha512> <key> <value> [hex|base64]")
+	}
+	h, err := hashByName(args[0])
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(h.New, []byte(args[1]))
+	mac.Write([]byte(args[2]))
+	return encode(mac.Sum(nil), args[3:]...)
+}
+
+// rsaSignFunc signs with RSA PKCS1v15: {{rsaSign <sha1|sha256|sha512> <PEM private key> <value> [base64|hex]}}, default encoding is base64
+func rsaSignFunc(args ...string) (string, error) {
+	if len(args) != 3 && len(args) != 4 {
+		return "", fmt.Errorf("usage: rsaSign <sha1|sha256|sha512> <key> <value> [base64|hex]")
+	}
+	h, err := hashByName(args[0])
+	if err != nil {
+		return "", err
+	}
+	pk, err := parseRSAPrivateKey(args[1])
+	if err != nil {
+		return "", err
+	}
+	hash := h.New()
+	hash.Write([]byte(args[2]))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, pk, h, hash.Sum(nil))
+	if err != nil {
+		return "", err
+	}
+	if len(args) == 3 {
+		return base64.StdEncoding.EncodeToString(sig), nil
+	}
+	return encode(sig, args[3])
+}
+
+func hashByName(name string) (crypto.Hash, error) {
+	switch strings.ToLower(name) {
+	case "md5":
+		return crypto.MD5, nil
+	case "sha1":
+		return crypto.SHA1, nil
+	case "sha256":
+		return crypto.SHA256, nil
+	case "sha512":
+		return crypto.SHA512, nil
+	}
+	return 0, fmt.Errorf("unsupported hash %s", name)
+}
+
+// encode encodes b in hex (default) or base64
+func encode(b []byte, encoding ...string) (string, error) {
+	if len(encoding) == 0 || encoding[0] == "hex" {
+		return hex.EncodeToString(b), nil
+	}
+	if encoding[0] == "base64" {
+		return base64.StdEncoding.EncodeToString(b), nil
+	}
+	return "", fmt.Errorf("unsupported encoding %s", encoding[0])
+}
+
+// xmlEscapeFunc escapes its argument to be used as XML text or attribute value: {{xmlEscape <value>}}
+func xmlEscapeFunc(args ...string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("usage: xmlEscape <value>")
+	}
+	b := &bytes.Buffer{}
+	if err := xml.EscapeText(b, []byte(args[0])); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// sign signs data with HMAC (HS256, HS384, HS512) or RSA PKCS1v15 (RS256, RS384, RS512)
+func sign(alg, key string, data []byte) ([]byte, error) {
+	if len(alg) != 5 {
+		return nil, fmt.Errorf("unsupported algorithm %s", alg)
+	}
+	var h crypto.Hash
+	switch alg[2:] {
+	case "256":
+		h = crypto.SHA256
+	case "384":
+		h = crypto.SHA384
+	case "512":
+		h = crypto.SHA512
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %s", alg)
+	}
+
+	switch alg[:2] {
+	case "HS":
+		mac := hmac.New(h.New, []byte(key))
+		mac.Write(data)
+		return mac.Sum(nil), nil
+	case "RS":
+		pk, err := parseRSAPrivateKey(key)
+		if err != nil {
+			return nil, err
+		}
+		hash := h.New()
+		hash.Write(data)
+		return rsa.SignPKCS1v15(rand.Reader, pk, h, hash.Sum(nil))
+	}
+	return nil, fmt.Errorf("unsupported algorithm %s", alg)
+}
+
+func parseRSAPrivateKey(key string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(key))
+	if block == nil {
+		return nil, fmt.Errorf("invalid PEM private key")
+	}
+	if pk, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return pk, nil
+	}
+	k, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %v", err)
+	}
+	pk, ok := k.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key is not a RSA key")
+	}
+	return pk, nil
+}
@@ -0,0 +1,51 @@
+package venom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ovh/venom/assertions"
+)
+
+func TestTemplater_jwt(t *testing.T) {
+	tmpl := newTemplater(map[string]string{
+		"claims.sub":   "venom",
+		"claims.admin": "true",
+		"claims.exp":   "4102444800",
+		"secret":       "s3cr3t",
+	})
+
+	_, out := tmpl.apply([]byte(`token: {{jwt .claims "HS256" .secret}}`))
+	token := string(out)[len("token: "):]
+	assert.NoError(t, assertions.ShouldBeValidJWT(token, "s3cr3t"))
+	assert.NoError(t, assertions.ShouldHaveJWTClaim(token, "sub", "venom"))
+	assert.NoError(t, assertions.ShouldHaveJWTClaim(token, "exp", "4102444800"))
+
+	_, out = tmpl.apply([]byte(`token: {{jwt .unknown "HS256" .secret}}`))
+	assert.Equal(t, `token: {{jwt .unknown "HS256" .secret}}`, string(out))
+}
+
+func TestTemplater_hash(t *testing.T) {
+	tmpl := newTemplater(map[string]string{
+		"body": "hello",
+		"key":  "secret",
+	})
+
+	for in, want := range map[string]string{
+		`{{md5 .body}}`:                       "5d41402abc4b2a76b9719d911017c592",
+		`{{sha1 "hello"}}`:                    "aaf4c61ddcc5e8a2dabede0f3b482cd9aea9434d",
+		`{{sha256 .body}}`:                    "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+		`{{hmac "sha256" .key .body}}`:        "88aab3ede8d3adf94d26ab90d3bafd4a2083070c3bcce9c014ee04a443847c0b",
+		`{{hmac "sha256" .key .body base64}}`: "iKqz7ejTrflNJquQ07r9SiCDBww7zOnAFO4EpEOEfAs=",
+	} {
+		_, out := tmpl.apply([]byte(in))
+		assert.Equal(t, want, string(out), in)
+	}
+}
+
+func TestTemplater_xmlEscape(t *testing.T) {
+	tmpl := newTemplater(map[string]string{"name": `Tom & "Jerry" <cartoon>`})
+	_, out := tmpl.apply([]byte(`<name>{{xmlEscape .name}}</name>`))
+	assert.Equal(t, `<name>Tom &amp; &#34;Jerry&#34; &lt;cartoon&gt;</name>`, string(out))
+}
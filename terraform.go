@@ -0,0 +1,75 @@
+package venom
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"strings"
+
+	dump "github.com/fsamin/go-dump"
+)
+
+const terraformCommand = "terraform"
+
+// terraformOutput is an output as returned by `terraform output -json` or stored in a state file
+type terraformOutput struct {
+	Sensitive bool        `json:"sensitive"`
+	Value     interface{} `json:"value"`
+}
+
+// TerraformOutputs returns terraform outputs as variables prefixed by "terraform.".
+// Outputs are read from the state file if stateFile is set, otherwise
+// `terraform output -json` is invoked in dir, and killed when ctx is done.
+func TerraformOutputs(ctx context.Context, dir, stateFile string) (map[string]string, error) {
+	var outputs map[string]terraformOutput
+	if stateFile != "" {
+		btes, err := ioutil.ReadFile(stateFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read terraform state file: %v", err)
+		}
+		var state struct {
+			Outputs map[string]terraformOutput `json:"outputs"`
+		}
+		if err := json.Unmarshal(btes, &state); err != nil {
+			return nil, fmt.Errorf("unable to read terraform state file %s: %v", stateFile, err)
+		}
+		outputs = state.Outputs
+	} else {
+		stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+		cmd := exec.CommandContext(ctx, terraformCommand, "output", "-json")
+		cmd.Dir = dir
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
+		if err := cmd.Run(); err != nil {
+			return nil, fmt.Errorf("terraform output failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		if err := json.Unmarshal(stdout.Bytes(), &outputs); err != nil {
+			return nil, fmt.Errorf("unable to read terraform output: %v", err)
+		}
+	}
+	return terraformVariables(outputs)
+}
+
+func terraformVariables(outputs map[string]terraformOutput) (map[string]string, error) {
+	vars := map[string]string{}
+	for name, o := range outputs {
+		switch value := o.Value.(type) {
+		case map[string]interface{}, []interface{}:
+			d, err := dump.ToStringMap(value)
+			if err != nil {
+				return nil, err
+			}
+			for k, v := range d {
+				vars["terraform."+name+"."+k] = v
+			}
+		case nil:
+			vars["terraform."+name] = ""
+		default:
+			vars["terraform."+name] = fmt.Sprintf("%v", value)
+		}
+	}
+	return vars, nil
+}
@@ -0,0 +1,33 @@
+package venom
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTerraformOutputs(t *testing.T) {
+	dir, err := tempDir(t)
+	assert.NoError(t, err)
+
+	state := []byte(`{
+  "version": 4,
+  "outputs": {
+    "api_url": {"value": "https://api.example.com", "type": "string"},
+    "replicas": {"value": 3, "type": "number"},
+    "db": {"value": {"host": "db.example.com", "port": 5432}, "sensitive": true}
+  }
+}`)
+	stateFile := filepath.Join(dir, "terraform.tfstate")
+	assert.NoError(t, ioutil.WriteFile(stateFile, state, 0644))
+
+	vars, err := TerraformOutputs(context.Background(), "", stateFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://api.example.com", vars["terraform.api_url"])
+	assert.Equal(t, "3", vars["terraform.replicas"])
+	assert.Equal(t, "db.example.com", vars["terraform.db.host"])
+	assert.Equal(t, "5432", vars["terraform.db.port"])
+}
@@ -0,0 +1,37 @@
+package venom
+
+import (
+	"regexp"
+)
+
+// testCaseNameFilters returns the regexps of --testcase. The regexp of a name which is not a valid regexp, such as
+// "refund (", is nil: this name only selects the testcase with this name.
+func (v *Venom) testCaseNameFilters() []*regexp.Regexp {
+	var filters []*regexp.Regexp
+	for _, name := range v.TestCaseNames {
+		r, _ := regexp.Compile(name)
+		filters = append(filters, r)
+	}
+	return filters
+}
+
+// selectByName keeps the testcases of the testsuite selected by --testcase: a testcase is selected when its name is
+// one of the names given, or matches one of them as a regexp. It returns false when no testcase of the testsuite is
+// selected.
+func (v *Venom) selectByName(ts *TestSuite) bool {
+	if len(v.TestCaseNames) == 0 {
+		return true
+	}
+	filters := v.testCaseNameFilters()
+	var selected []TestCase
+	for _, tc := range ts.TestCases {
+		for i, f := range filters {
+			if tc.Name == v.TestCaseNames[i] || (f != nil && f.MatchString(tc.Name)) {
+				selected = append(selected, tc)
+				break
+			}
+		}
+	}
+	ts.TestCases = selected
+	return len(selected) > 0
+}
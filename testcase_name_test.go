@@ -0,0 +1,49 @@
+package venom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectByName(t *testing.T) {
+	newSuite := func() *TestSuite {
+		return &TestSuite{TestCases: []TestCase{
+			{Name: "create a payment"},
+			{Name: "refund a payment"},
+			{Name: "refund all the payments (slow)"},
+			{Name: "refund a payment (partial"},
+		}}
+	}
+	names := func(ts *TestSuite) []string {
+		var n []string
+		for _, tc := range ts.TestCases {
+			n = append(n, tc.Name)
+		}
+		return n
+	}
+
+	tests := []struct {
+		name     string
+		filters  []string
+		expected []string
+	}{
+		{name: "no filter", expected: []string{"create a payment", "refund a payment", "refund all the payments (slow)", "refund a payment (partial"}},
+		{name: "name", filters: []string{"create a payment"}, expected: []string{"create a payment"}},
+		{name: "regexp", filters: []string{"^refund"}, expected: []string{"refund a payment", "refund all the payments (slow)", "refund a payment (partial"}},
+		{name: "name with regexp characters", filters: []string{"refund all the payments (slow)"}, expected: []string{"refund all the payments (slow)"}},
+		{name: "several filters", filters: []string{"create a payment", "all"}, expected: []string{"create a payment", "refund all the payments (slow)"}},
+		{name: "no match", filters: []string{"delete"}},
+		{name: "name which is not a regexp", filters: []string{"refund a payment (partial"}, expected: []string{"refund a payment (partial"}},
+		{name: "names which are not regexps and a regexp", filters: []string{"refund (", "^create"}, expected: []string{"create a payment"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := New()
+			v.TestCaseNames = tt.filters
+			ts := newSuite()
+			assert.Equal(t, len(tt.expected) > 0, v.selectByName(ts))
+			assert.Equal(t, tt.expected, names(ts))
+		})
+	}
+}
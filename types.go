@@ -1,7 +1,10 @@
 package venom
 
 import (
+	"context"
 	"encoding/xml"
+	"sync"
+	"time"
 
 	"github.com/fatih/color"
 )
@@ -75,6 +78,14 @@ type TestCaseContext interface {
 	Close() error
 	SetTestCase(tc TestCase)
 	GetName() string
+	SetContext(ctx context.Context)
+	Context() context.Context
+}
+
+// testCaseContextWithSnapshot is a context taking a snapshot of its state when a step fails, the web context takes
+// a screenshot of the page and saves its HTML. Snapshot writes the files with the prefix and returns their paths.
+type testCaseContextWithSnapshot interface {
+	Snapshot(prefix string) ([]string, error)
 }
 
 // CommonTestCaseContext represents a Default TestCase Context
@@ -82,6 +93,9 @@ type CommonTestCaseContext struct {
 	TestCaseContext
 	TestCase TestCase
 	Name     string
+	// the executor of a step stopped can still read the context of the testcase
+	ctxMutex sync.Mutex
+	ctx      context.Context
 }
 
 // SetTestCase set testcase in context
@@ -94,12 +108,33 @@ func (tcc *CommonTestCaseContext) GetName() string {
 	return tcc.Name
 }
 
+// SetContext sets the context of the step running
+func (tcc *CommonTestCaseContext) SetContext(ctx context.Context) {
+	tcc.ctxMutex.Lock()
+	defer tcc.ctxMutex.Unlock()
+	tcc.ctx = ctx
+}
+
+// Context returns the context of the step running, it is done when the run is interrupted or the step times out.
+// The executors stop their step when it is done.
+func (tcc *CommonTestCaseContext) Context() context.Context {
+	tcc.ctxMutex.Lock()
+	defer tcc.ctxMutex.Unlock()
+	if tcc.ctx == nil {
+		return context.Background()
+	}
+	return tcc.ctx
+}
+
 // ExecutorWrap contains an executor implementation and some attributes
 type ExecutorWrap struct {
+	name     string
 	executor Executor
-	retry    int // nb retry a test case if it is in failure.
-	delay    int // delay between two retries
-	timeout  int // timeout on executor
+	retry    int           // nb retry a test case if it is in failure.
+	delay    time.Duration // delay between two retries
+	timeout  time.Duration // timeout on executor
+	cache    bool          // memoize the result for the whole run
+	retryIf  []string      // the assertions retried when they fail, the others fail the step without retry
 }
 
 // executorWithDefaultAssertions execute a testStep.
@@ -119,32 +154,64 @@ type Tests struct {
 	TotalOK      int         `xml:"-" json:"ok"`
 	TotalKO      int         `xml:"-" json:"ko"`
 	TotalSkipped int         `xml:"-" json:"skipped"`
+	TotalErrors  int         `xml:"-" json:"errors"`
 	TestSuites   []TestSuite `xml:"testsuite" json:"test_suites"`
+
+	// the Total fields count the testcases, these count the testsuites and the steps
+	TestSuitesCounts StatusCounts `xml:"-" json:"testsuites_counts"`
+	StepsCounts      StatusCounts `xml:"-" json:"steps_counts"`
+	// Resources is the peak usage of the resources of venom by phase of the run
+	Resources []ResourceUsage `xml:"-" json:"resources,omitempty"`
+}
+
+// StatusCounts counts testsuites, testcases or steps by status
+type StatusCounts struct {
+	Total   int `json:"total" yaml:"total"`
+	OK      int `json:"ok" yaml:"ok"`
+	KO      int `json:"ko" yaml:"ko"`
+	Skipped int `json:"skipped" yaml:"skipped"`
 }
 
 // TestSuite is a single JUnit test suite which may contain many
 // testcases.
 type TestSuite struct {
-	XMLName    xml.Name               `xml:"testsuite" json:"-" yaml:"-"`
-	Disabled   int                    `xml:"disabled,attr,omitempty" json:"disabled" yaml:"-"`
-	Errors     int                    `xml:"errors,attr,omitempty" json:"errors" yaml:"-"`
-	Failures   int                    `xml:"failures,attr,omitempty" json:"failures" yaml:"-"`
-	Hostname   string                 `xml:"hostname,attr,omitempty" json:"hostname" yaml:"-"`
-	ID         string                 `xml:"id,attr,omitempty" json:"id" yaml:"-"`
-	Name       string                 `xml:"name,attr" json:"name" yaml:"name"`
-	Filename   string                 `xml:"-" json:"-" yaml:"-"`
-	ShortName  string                 `xml:"-" json:"-" yaml:"-"`
-	Package    string                 `xml:"package,attr,omitempty" json:"package" yaml:"-"`
-	Properties []Property             `xml:"-" json:"properties" yaml:"-"`
-	Skipped    int                    `xml:"skipped,attr,omitempty" json:"skipped" yaml:"skipped,omitempty"`
-	Total      int                    `xml:"tests,attr" json:"total" yaml:"total,omitempty"`
-	TestCases  []TestCase             `xml:"testcase" hcl:"testcase" json:"tests" yaml:"testcases"`
-	Version    string                 `xml:"version,omitempty" hcl:"version" json:"version" yaml:"version,omitempty"`
-	Time       string                 `xml:"time,attr,omitempty" json:"time" yaml:"-"`
-	Timestamp  string                 `xml:"timestamp,attr,omitempty" json:"timestamp" yaml:"-"`
-	Vars       map[string]interface{} `xml:"-" json:"-" yaml:"vars"`
-	Templater  *Templater             `xml:"-" json:"-" yaml:"-"`
-	WorkDir    string                 `xml:"-" json:"-" yaml:"-"`
+	XMLName      xml.Name               `xml:"testsuite" json:"-" yaml:"-"`
+	Disabled     int                    `xml:"disabled,attr,omitempty" json:"disabled" yaml:"-"`
+	Errors       int                    `xml:"errors,attr,omitempty" json:"errors" yaml:"-"`
+	Failures     int                    `xml:"failures,attr,omitempty" json:"failures" yaml:"-"`
+	Hostname     string                 `xml:"hostname,attr,omitempty" json:"hostname" yaml:"-"`
+	ID           string                 `xml:"id,attr,omitempty" json:"id" yaml:"-"`
+	Name         string                 `xml:"name,attr" json:"name" yaml:"name"`
+	Filename     string                 `xml:"-" json:"-" yaml:"-"`
+	ShortName    string                 `xml:"-" json:"-" yaml:"-"`
+	Package      string                 `xml:"package,attr,omitempty" json:"package" yaml:"-"`
+	Properties   []Property             `xml:"-" json:"properties" yaml:"-"`
+	Skipped      int                    `xml:"skipped,attr,omitempty" json:"skipped" yaml:"skipped,omitempty"`
+	Total        int                    `xml:"tests,attr" json:"total" yaml:"total,omitempty"`
+	TestCases    []TestCase             `xml:"testcase" hcl:"testcase" json:"tests" yaml:"testcases"`
+	Version      string                 `xml:"version,omitempty" hcl:"version" json:"version" yaml:"version,omitempty"`
+	Time         string                 `xml:"time,attr,omitempty" json:"time" yaml:"-"`
+	Timestamp    string                 `xml:"timestamp,attr,omitempty" json:"timestamp" yaml:"-"`
+	Vars         map[string]interface{} `xml:"-" json:"-" yaml:"vars"`
+	Templater    *Templater             `xml:"-" json:"-" yaml:"-"`
+	WorkDir      string                 `xml:"-" json:"-" yaml:"-"`
+	Services     *Services              `xml:"-" hcl:"services" json:"-" yaml:"services,omitempty"`
+	PortForwards []PortForward          `xml:"-" hcl:"port_forward" json:"-" yaml:"port_forwards,omitempty"`
+	SSHTunnels   []SSHTunnel            `xml:"-" hcl:"ssh_tunnel" json:"-" yaml:"ssh_tunnels,omitempty"`
+	// Setup and Teardown are the steps run before and after the testcases, the teardown is run even if the setup or
+	// a testcase fails. BeforeEach and AfterEach are run around the steps of each testcase, in its context.
+	Setup      []TestStep `xml:"-" hcl:"setup" json:"-" yaml:"setup,omitempty"`
+	Teardown   []TestStep `xml:"-" hcl:"teardown" json:"-" yaml:"teardown,omitempty"`
+	BeforeEach []TestStep `xml:"-" hcl:"before_each" json:"-" yaml:"before_each,omitempty"`
+	AfterEach  []TestStep `xml:"-" hcl:"after_each" json:"-" yaml:"after_each,omitempty"`
+	Owner      string     `xml:"-" hcl:"owner" json:"owner,omitempty" yaml:"owner,omitempty"`
+	Team       string     `xml:"-" hcl:"team" json:"team,omitempty" yaml:"team,omitempty"`
+	Tags       []string   `xml:"-" hcl:"tags" json:"tags,omitempty" yaml:"tags,omitempty"`
+
+	// Config holds the default attributes of the steps, by executor name
+	Config map[string]map[string]interface{} `xml:"-" json:"-" yaml:"config,omitempty"`
+	// Targets holds the attributes of the endpoints, by name, merged in the steps with their target
+	Targets map[string]map[string]interface{} `xml:"-" json:"-" yaml:"targets,omitempty"`
 }
 
 // Property represents a key/value pair used to define properties.
@@ -161,13 +228,49 @@ type TestCase struct {
 	Errors    []Failure              `xml:"error,omitempty" json:"errors" yaml:"errors,omitempty"`
 	Failures  []Failure              `xml:"failure,omitempty" json:"failures" yaml:"failures,omitempty"`
 	Name      string                 `xml:"name,attr" json:"name" yaml:"name"`
-	Skipped   []Skipped              `xml:"skipped,omitempty" json:"skipped" yaml:"skipped,omitempty"`
+	Skipped   SkippedList            `xml:"skipped,omitempty" json:"skipped" yaml:"skipped,omitempty"`
+	SkipIf    *SkipIf                `xml:"-" json:"skip_if,omitempty" yaml:"skip_if,omitempty"`
 	Status    string                 `xml:"status,attr,omitempty" json:"status" yaml:"status,omitempty"`
 	Systemout InnerResult            `xml:"system-out,omitempty" json:"systemout" yaml:"systemout,omitempty"`
 	Systemerr InnerResult            `xml:"system-err,omitempty" json:"systemerr" yaml:"systemerr,omitempty"`
 	Time      string                 `xml:"time,attr,omitempty" json:"time" yaml:"time,omitempty"`
 	TestSteps []TestStep             `xml:"-" hcl:"step" json:"steps" yaml:"steps"`
 	Context   map[string]interface{} `xml:"-" json:"-" yaml:"context,omitempty"`
+	Issue     string                 `xml:"-" json:"issue,omitempty" yaml:"issue,omitempty"`
+	Doc       string                 `xml:"-" json:"doc,omitempty" yaml:"doc,omitempty"`
+	Tags      []string               `xml:"-" hcl:"tags" json:"tags,omitempty" yaml:"tags,omitempty"`
+
+	// IssueURL is the link of the issue, built with --issue-url when the issue is not an url
+	IssueURL string `xml:"-" json:"issue_url,omitempty" yaml:"-"`
+	// Attachments are the files written for the failed steps and the artifacts of the steps, relative to the output directory
+	Attachments []string `xml:"-" json:"attachments,omitempty" yaml:"attachments,omitempty"`
+	// StepsCounts counts the steps of the testcase by status, the steps not run are skipped
+	StepsCounts StatusCounts `xml:"-" json:"steps_counts" yaml:"-"`
+	// StepResults are the results of the steps run, only written in the json-full report
+	StepResults []StepResult `xml:"-" json:"step_results,omitempty" yaml:"-"`
+
+	// hook is true for the testcases running the setup and the teardown of a testsuite, they are not reported
+	hook bool
+}
+
+// StepResult is the result of a step run: the result of its executor and the outcome of its assertions
+type StepResult struct {
+	Number      int               `json:"number"`
+	Name        string            `json:"name,omitempty"`
+	Type        string            `json:"type"`
+	Status      string            `json:"status"`
+	Attempts    int               `json:"attempts"`
+	TimeSeconds float64           `json:"timeseconds"`
+	Result      ExecutorResult    `json:"result"`
+	Assertions  []AssertionResult `json:"assertions"`
+	Errors      []string          `json:"errors,omitempty"`
+}
+
+// AssertionResult is the outcome of an assertion of a step
+type AssertionResult struct {
+	Assertion string `json:"assertion"`
+	OK        bool   `json:"ok"`
+	Message   string `json:"message,omitempty"`
 }
 
 // TestStep represents a testStep
@@ -178,6 +281,13 @@ type Skipped struct {
 	Value string `xml:",cdata" json:"value" yaml:"value,omitempty"`
 }
 
+// The types of the failures: the errors of the infrastructure, an executor or the setup of a testcase couldn't run,
+// are told apart from the failed assertions
+const (
+	FailureTypeInfrastructure = "infrastructure"
+	FailureTypeAssertion      = "assertion"
+)
+
 // Failure contains data related to a failed test.
 type Failure struct {
 	TestcaseClassname  string `xml:"-" json:"-" yaml:"-"`
@@ -217,6 +327,7 @@ func newFailure(tc TestCase, stepNumber int, assertion string, err error, res Ex
 		Result:             res,
 		Error:              err,
 		Value:              value,
+		Type:               FailureTypeAssertion,
 	}
 
 	return &failure
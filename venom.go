@@ -1,9 +1,19 @@
 package venom
 
 import (
+	"bufio"
 	"fmt"
 	"io"
 	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/ovh/venom/openapi"
+	"github.com/ovh/venom/pact"
+	"golang.org/x/time/rate"
 )
 
 var (
@@ -15,10 +25,13 @@ func New() *Venom {
 	v := &Venom{
 		LogLevel:        "info",
 		LogOutput:       os.Stdout,
-		PrintFunc:       fmt.Printf,
+		DebugInput:      os.Stdin,
+		PrintFunc:       consolePrintf,
 		executors:       map[string]Executor{},
+		deniedExecutors: map[string]bool{},
 		contexts:        map[string]TestCaseContext{},
 		variables:       map[string]string{},
+		stepCache:       map[string]ExecutorResult{},
 		EnableProfiling: false,
 		IgnoreVariables: []string{},
 		OutputFormat:    "xml",
@@ -26,6 +39,11 @@ func New() *Venom {
 	return v
 }
 
+// consolePrintf is the default PrintFunc: color.Output translates the colors for the Windows consoles
+func consolePrintf(format string, a ...interface{}) (int, error) {
+	return fmt.Fprintf(color.Output, format, a...)
+}
+
 type Venom struct {
 	LogLevel  string
 	LogOutput io.Writer
@@ -42,7 +60,64 @@ type Venom struct {
 	EnableProfiling bool
 	OutputFormat    string
 	OutputDir       string
+	Annotations     string
 	StopOnFailure   bool
+	ComposeFile     string
+	OpenAPIFile     string
+	OpenAPIValidate bool
+	PactDir         string
+	PactConsumer    string
+	PactProvider    string
+	RateLimits      []string
+	HostConcurrency []string
+	EmailTo         []string
+	EmailFrom       string
+	SMTPServer      string
+	SMTPUser        string
+	SMTPPassword    string
+	Monitoring      bool
+	PagerDutyKey    string
+	OpsgenieAPIKey  string
+	HistoryStore    string
+	HistoryGitSHA   string
+	HistoryEnv      string
+	QuarantineFile  string
+	Teams           []string
+	Owners          []string
+	Tags            []string
+	ExcludeTags     []string
+	TestCaseNames   []string
+	SplitBy         string
+	IssueURL        string
+	DebugOnFailure  bool
+	StepByStep      bool
+	DebugInput      io.Reader
+	TargetsFile     string
+
+	openAPISpec     *openapi.Spec
+	openAPICoverage *openapi.Coverage
+	pactRecorder    *pact.Recorder
+	stepCache       map[string]ExecutorResult
+	stepCacheMutex  sync.Mutex
+	rateLimiters    map[string]*rate.Limiter
+	hostLimiter     *hostLimiter
+	logFile         *os.File
+	quarantine      []QuarantineEntry
+	debugMutex      sync.Mutex
+	debugReader     *bufio.Reader
+	debugAborted    bool
+	remoteSources   map[string]string
+	remoteDirs      []string
+	targets         map[string]map[string]interface{}
+	freePorts       map[int]bool
+	freePortsMutex  sync.Mutex
+	deniedExecutors map[string]bool
+	resources       []ResourceUsage
+	// the executors of the run, set by RestrictExecutors
+	allowedExecutors   []string
+	forbiddenExecutors []string
+	// runMutex serializes the runs of the Venom: Parse, Process and DryRun share its testsuites
+	runMutex sync.Mutex
 }
 
 func (v *Venom) AddVariables(variables map[string]string) {
@@ -53,14 +128,56 @@ func (v *Venom) AddVariables(variables map[string]string) {
 
 // RegisterExecutor register Test Executors
 func (v *Venom) RegisterExecutor(name string, e Executor) {
+	if !v.executorAllowed(name) {
+		v.deniedExecutors[name] = true
+		return
+	}
 	v.executors[name] = e
 }
 
+// RestrictExecutors unregisters the executors not in the allow-list, when there is one, and the executors of the
+// deny-list: their steps are in error. The executors registered later are restricted the same way.
+func (v *Venom) RestrictExecutors(allow, deny []string) error {
+	for _, name := range append(append([]string{}, allow...), deny...) {
+		if _, ok := v.executors[name]; !ok && !v.deniedExecutors[name] {
+			return fmt.Errorf("unknown executor %q", name)
+		}
+	}
+	v.allowedExecutors, v.forbiddenExecutors = allow, deny
+	for name := range v.executors {
+		if !v.executorAllowed(name) {
+			delete(v.executors, name)
+			v.deniedExecutors[name] = true
+		}
+	}
+	return nil
+}
+
+func (v *Venom) executorAllowed(name string) bool {
+	for _, n := range v.forbiddenExecutors {
+		if n == name {
+			return false
+		}
+	}
+	if len(v.allowedExecutors) == 0 {
+		return true
+	}
+	for _, n := range v.allowedExecutors {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
 // WrapExecutor initializes a test by name
 // no type -> exec is default
 func (v *Venom) WrapExecutor(t map[string]interface{}, tcc TestCaseContext) (*ExecutorWrap, error) {
 	var name string
-	var retry, delay, timeout int
+	var retry int
+	var delay, timeout time.Duration
+	var cache bool
+	var retryIf []string
 
 	if itype, ok := t["type"]; ok {
 		name = fmt.Sprintf("%s", itype)
@@ -76,25 +193,47 @@ func (v *Venom) WrapExecutor(t map[string]interface{}, tcc TestCaseContext) (*Ex
 	if errRetry != nil {
 		return nil, errRetry
 	}
-	delay, errDelay := getAttrInt(t, "delay")
+	delay, errDelay := getAttrDuration(t, "delay")
 	if errDelay != nil {
 		return nil, errDelay
 	}
-	timeout, errTimeout := getAttrInt(t, "timeout")
+	timeout, errTimeout := getAttrDuration(t, "timeout")
 	if errTimeout != nil {
 		return nil, errTimeout
 	}
 
+	if icache, ok := t["cache"]; ok {
+		if cache, ok = icache.(bool); !ok {
+			return nil, fmt.Errorf("attribute cache '%v' is not a boolean", icache)
+		}
+	}
+
+	if iretryIf, ok := t["retry_if"]; ok {
+		l, ok := iretryIf.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("attribute retry_if '%v' is not a list of assertions", iretryIf)
+		}
+		for _, a := range l {
+			retryIf = append(retryIf, fmt.Sprintf("%v", a))
+		}
+	}
+
 	if e, ok := v.executors[name]; ok {
 		ew := &ExecutorWrap{
+			name:     name,
 			executor: e,
 			retry:    retry,
 			delay:    delay,
 			timeout:  timeout,
+			cache:    cache,
+			retryIf:  retryIf,
 		}
 		return ew, nil
 	}
 
+	if v.deniedExecutors[name] {
+		return nil, fmt.Errorf("[%s] type '%s' is not allowed on this run", tcc.GetName(), name)
+	}
 	return nil, fmt.Errorf("[%s] type '%s' is not implemented", tcc.GetName(), name)
 }
 
@@ -106,19 +245,31 @@ func (v *Venom) RegisterTestCaseContext(name string, tcc TestCaseContext) {
 // ContextWrap initializes a context for a testcase
 // no type -> parent context
 func (v *Venom) ContextWrap(tc *TestCase) (TestCaseContext, error) {
-	if tc.Context == nil {
-		return v.contexts["default"], nil
-	}
-	var typeName string
-	if itype, ok := tc.Context["type"]; ok {
+	typeName := "default"
+	if itype, ok := tc.Context["type"]; ok && fmt.Sprintf("%s", itype) != "" {
 		typeName = fmt.Sprintf("%s", itype)
 	}
+	registered, ok := v.contexts[typeName]
+	if !ok {
+		return nil, fmt.Errorf("context type '%s' is not implemented", typeName)
+	}
+	// the testcases of parallel testsuites have their own copy of the registered context
+	tcc := copyTestCaseContext(registered)
+	if tc.Context != nil {
+		tcc.SetTestCase(*tc)
+	}
+	return tcc, nil
+}
 
-	if typeName == "" {
-		return v.contexts["default"], nil
+// copyTestCaseContext returns a copy of a context registered as a pointer to a struct, the others are shared
+func copyTestCaseContext(tcc TestCaseContext) TestCaseContext {
+	rv := reflect.ValueOf(tcc)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return tcc
 	}
-	v.contexts[typeName].SetTestCase(*tc)
-	return v.contexts[typeName], nil
+	c := reflect.New(rv.Elem().Type())
+	c.Elem().Set(rv.Elem())
+	return c.Interface().(TestCaseContext)
 }
 
 func getAttrInt(t map[string]interface{}, name string) (int, error) {
@@ -135,3 +286,27 @@ func getAttrInt(t map[string]interface{}, name string) (int, error) {
 	}
 	return out, nil
 }
+
+// getAttrDuration returns a duration attribute: a number of seconds, 30 or 0.5, or a duration string, 1m30s or 500ms
+func getAttrDuration(t map[string]interface{}, name string) (time.Duration, error) {
+	var out time.Duration
+	if i, ok := t[name]; ok {
+		switch v := i.(type) {
+		case int:
+			out = time.Duration(v) * time.Second
+		case float64:
+			out = time.Duration(v * float64(time.Second))
+		case string:
+			var err error
+			if out, err = time.ParseDuration(strings.TrimSpace(v)); err != nil {
+				return -1, fmt.Errorf("attribute %s '%s' is not a number of seconds or a duration like 1m30s or 500ms", name, v)
+			}
+		default:
+			return -1, fmt.Errorf("attribute %s '%v' is not a number of seconds or a duration like 1m30s or 500ms", name, i)
+		}
+	}
+	if out < 0 {
+		out = 0
+	}
+	return out, nil
+}
@@ -0,0 +1,111 @@
+package venom
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// URLs of the alerting APIs
+var (
+	pagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+	opsgenieAlertsURL  = "https://api.opsgenie.com/v2/alerts"
+)
+
+// failedTestCases returns the names of the failed testcases: "<testsuite> / <testcase>"
+func failedTestCases(tests Tests) []string {
+	var names []string
+	for _, ts := range tests.TestSuites {
+		for _, tc := range ts.TestCases {
+			if testCaseStatus(tc) == "FAILURE" {
+				names = append(names, ts.Name+" / "+tc.Name)
+			}
+		}
+	}
+	return names
+}
+
+// sendAlerts opens an alert on PagerDuty and/or Opsgenie when a run flagged with --monitoring fails.
+// The alerts of the same failed testcases are deduplicated.
+func (v *Venom) sendAlerts(tests Tests) error {
+	if !v.Monitoring || tests.TotalKO == 0 {
+		return nil
+	}
+	names := failedTestCases(tests)
+	summary := fmt.Sprintf("venom: %d testcases failed: %s", len(names), strings.Join(names, ", "))
+	if len(summary) > 1024 {
+		summary = summary[:1021] + "..."
+	}
+	h := sha256.Sum256([]byte(strings.Join(names, "\n")))
+	key := "venom-" + hex.EncodeToString(h[:])[:16]
+
+	var errs []string
+	if v.PagerDutyKey != "" {
+		event := map[string]interface{}{
+			"routing_key":  v.PagerDutyKey,
+			"event_action": "trigger",
+			"dedup_key":    key,
+			"payload": map[string]interface{}{
+				"summary":        summary,
+				"source":         "venom",
+				"severity":       "critical",
+				"custom_details": map[string]interface{}{"failed_testcases": names},
+			},
+		}
+		if err := postAlert(pagerDutyEventsURL, nil, event); err != nil {
+			errs = append(errs, fmt.Sprintf("PagerDuty: %v", err))
+		} else {
+			v.PrintFunc("PagerDuty alert %s triggered\n", key)
+		}
+	}
+	if v.OpsgenieAPIKey != "" {
+		alert := map[string]interface{}{
+			"message":     summary,
+			"alias":       key,
+			"description": strings.Join(names, "\n"),
+			"source":      "venom",
+			"priority":    "P1",
+		}
+		if err := postAlert(opsgenieAlertsURL, map[string]string{"Authorization": "GenieKey " + v.OpsgenieAPIKey}, alert); err != nil {
+			errs = append(errs, fmt.Sprintf("Opsgenie: %v", err))
+		} else {
+			v.PrintFunc("Opsgenie alert %s created\n", key)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("Error while sending the alerts: %s", strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+func postAlert(url string, headers map[string]string, body interface{}) error {
+	btes, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(btes))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		content, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(content)))
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package venom
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSendAlerts(t *testing.T) {
+	var events []map[string]interface{}
+	var authorization string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Error(err)
+		}
+		if r.URL.Path == "/opsgenie" {
+			authorization = r.Header.Get("Authorization")
+		}
+		events = append(events, event)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+	defer func(pagerDuty, opsgenie string) {
+		pagerDutyEventsURL, opsgenieAlertsURL = pagerDuty, opsgenie
+	}(pagerDutyEventsURL, opsgenieAlertsURL)
+	pagerDutyEventsURL, opsgenieAlertsURL = srv.URL+"/pagerduty", srv.URL+"/opsgenie"
+
+	v := New()
+	v.PagerDutyKey = "routing"
+	v.OpsgenieAPIKey = "key"
+	tests := Tests{Total: 1, TotalKO: 1, TestSuites: []TestSuite{{
+		Name:      "suite",
+		TestCases: []TestCase{{Name: "ko", Errors: []Failure{{Value: "boom"}}}},
+	}}}
+
+	// a run which is not a monitor does not alert
+	if err := v.sendAlerts(tests); err != nil || len(events) != 0 {
+		t.Fatalf("unexpected alerts: %v %v", err, events)
+	}
+
+	v.Monitoring = true
+	if err := v.sendAlerts(tests); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 alerts, got %v", events)
+	}
+	if events[0]["routing_key"] != "routing" || events[0]["payload"].(map[string]interface{})["summary"] != "venom: 1 testcases failed: suite / ko" {
+		t.Errorf("unexpected PagerDuty event: %v", events[0])
+	}
+	if events[1]["alias"] != events[0]["dedup_key"] || authorization != "GenieKey key" {
+		t.Errorf("unexpected Opsgenie alert: %v %s", events[1], authorization)
+	}
+}
@@ -0,0 +1,150 @@
+package venom
+
+import (
+	"bufio"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/acarl005/stripansi"
+)
+
+// annotation is a failure located in a testsuite file
+type annotation struct {
+	File     string
+	Line     int
+	Title    string
+	Message  string
+	Severity string
+}
+
+// annotations returns the failures and the errors of the testcases, located in their testsuite file
+func annotations(tests Tests) []annotation {
+	var as []annotation
+	for _, ts := range tests.TestSuites {
+		for _, tc := range ts.TestCases {
+			tcLine := findTestCaseLine(ts.Filename, tc.Name)
+			add := func(fs []Failure, severity string) {
+				for _, f := range fs {
+					line := f.TestcaseLineNumber
+					if line == 0 {
+						line = tcLine
+					}
+					as = append(as, annotation{
+						File:     filepath.ToSlash(ts.Filename),
+						Line:     line,
+						Title:    ts.Name + " / " + tc.Name,
+						Message:  strings.TrimSpace(stripansi.Strip(f.Value)),
+						Severity: severity,
+					})
+				}
+			}
+			add(tc.Errors, "critical")
+			add(tc.Failures, "major")
+		}
+	}
+	return as
+}
+
+// findTestCaseLine returns the line of the name of the testcase in the testsuite file, 1 if not found
+func findTestCaseLine(filename, testcase string) int {
+	file, err := os.Open(filename)
+	if err != nil {
+		return 1
+	}
+	defer file.Close()
+
+	var countLine int
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		countLine++
+		line := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(scanner.Text()), "-"))
+		if !strings.HasPrefix(line, "name:") {
+			continue
+		}
+		if strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "name:")), `"'`) == testcase {
+			return countLine
+		}
+	}
+	return 1
+}
+
+// outputGithubAnnotations prints the failures as GitHub Actions workflow commands,
+// GitHub displays them on the testsuite files of the pull requests
+func (v *Venom) outputGithubAnnotations(tests Tests) {
+	for _, a := range annotations(tests) {
+		v.PrintFunc("::error file=%s,line=%d,title=%s::%s\n",
+			escapeGithubProperty(a.File), a.Line, escapeGithubProperty(a.Title), escapeGithubData(a.Message))
+	}
+}
+
+func escapeGithubData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+func escapeGithubProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+// gitlabIssue is an issue of a GitLab code quality report
+type gitlabIssue struct {
+	Description string         `json:"description"`
+	CheckName   string         `json:"check_name"`
+	Fingerprint string         `json:"fingerprint"`
+	Severity    string         `json:"severity"`
+	Location    gitlabLocation `json:"location"`
+}
+
+type gitlabLocation struct {
+	Path  string `json:"path"`
+	Lines struct {
+		Begin int `json:"begin"`
+	} `json:"lines"`
+}
+
+// writeGitlabAnnotations writes the failures in a GitLab code quality report,
+// GitLab displays them on the testsuite files of the merge requests
+func (v *Venom) writeGitlabAnnotations(tests Tests) error {
+	issues := []gitlabIssue{}
+	for _, a := range annotations(tests) {
+		h := md5.Sum([]byte(a.File + "\n" + a.Title + "\n" + a.Message))
+		issue := gitlabIssue{
+			Description: a.Title + ": " + a.Message,
+			CheckName:   "venom",
+			Fingerprint: hex.EncodeToString(h[:]),
+			Severity:    a.Severity,
+		}
+		issue.Location.Path = a.File
+		issue.Location.Lines.Begin = a.Line
+		issues = append(issues, issue)
+	}
+	data, err := json.MarshalIndent(issues, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Error: cannot format gitlab code quality report (%s)", err)
+	}
+	filename := filepath.Join(v.OutputDir, "gl-code-quality-report.json")
+	if err := ioutil.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("Error while creating file %s: %v", filename, err)
+	}
+	v.PrintFunc("Writing file %s\n", filename)
+	return nil
+}
+
+// outputAnnotations outputs the failures for the CI given with --annotations: github or gitlab
+func (v *Venom) outputAnnotations(tests Tests) error {
+	switch v.Annotations {
+	case "":
+		return nil
+	case "github":
+		v.outputGithubAnnotations(tests)
+		return nil
+	case "gitlab":
+		return v.writeGitlabAnnotations(tests)
+	}
+	return fmt.Errorf("Error: unsupported annotations %q, must be github or gitlab", v.Annotations)
+}
@@ -0,0 +1,43 @@
+package venom
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOutputGithubAnnotations(t *testing.T) {
+	dir, err := ioutil.TempDir("", "annotations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "suite.yml")
+	content := "name: suite\ntestcases:\n- name: first\n  steps:\n  - type: exec\n- name: \"second\"\n  steps:\n  - type: exec\n"
+	if err := ioutil.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	v := New()
+	v.PrintFunc = func(format string, a ...interface{}) (int, error) {
+		return fmt.Fprintf(&out, format, a...)
+	}
+	v.outputGithubAnnotations(Tests{TestSuites: []TestSuite{{
+		Name:     "suite",
+		Filename: filename,
+		TestCases: []TestCase{
+			{Name: "first", Failures: []Failure{{Value: "Failure in step 0, field: a\n100%", TestcaseLineNumber: 5}}},
+			{Name: "second", Errors: []Failure{{Value: "Timeout"}}},
+		},
+	}}})
+
+	expected := "::error file=" + filepath.ToSlash(filename) + ",line=5,title=suite / first::Failure in step 0, field: a%0A100%25\n" +
+		"::error file=" + filepath.ToSlash(filename) + ",line=6,title=suite / second::Timeout\n"
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
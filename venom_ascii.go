@@ -0,0 +1,44 @@
+package venom
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/fatih/color"
+)
+
+// asciiReplacer replaces the common typographic characters by their ASCII equivalent
+var asciiReplacer = strings.NewReplacer("’", "'", "‘", "'", "“", `"`, "”", `"`, "–", "-", "—", "-", "…", "...", "°", "o")
+
+// ToASCII returns s with only ASCII characters, the other characters are replaced by ?
+func ToASCII(s string) string {
+	s = asciiReplacer.Replace(s)
+	return strings.Map(func(r rune) rune {
+		if r > unicode.MaxASCII {
+			return '?'
+		}
+		return r
+	}, s)
+}
+
+// ASCIIPrintf is a PrintFunc writing only ASCII characters on the standard output, with --ascii
+// for the consoles and the CI log viewers which can't display unicode
+func ASCIIPrintf(format string, a ...interface{}) (int, error) {
+	return fmt.Fprint(color.Output, ToASCII(fmt.Sprintf(format, a...)))
+}
+
+// runeWidth returns the number of columns of a character in a console: 2 for the wide characters
+// (East Asian ideographs, emoji...), 0 for the combining marks
+func runeWidth(r rune) int {
+	switch {
+	case unicode.Is(unicode.Mn, r), unicode.Is(unicode.Me, r), r == '\u200b':
+		return 0
+	case r >= 0x1100 && r <= 0x115f, r >= 0x2e80 && r <= 0xa4cf, r >= 0xac00 && r <= 0xd7a3,
+		r >= 0xf900 && r <= 0xfaff, r >= 0xfe30 && r <= 0xfe4f, r >= 0xff00 && r <= 0xff60,
+		r >= 0xffe0 && r <= 0xffe6, r >= 0x1f300 && r <= 0x1faff, r >= 0x20000 && r <= 0x3fffd,
+		r >= 0x2600 && r <= 0x27bf:
+		return 2
+	}
+	return 1
+}
@@ -0,0 +1,26 @@
+package venom
+
+import "testing"
+
+func TestToASCII(t *testing.T) {
+	if s := ToASCII("✅ test “ok” – n° 1 日本"); s != `? test "ok" - no 1 ??` {
+		t.Errorf("unexpected %q", s)
+	}
+}
+
+func TestRightPad(t *testing.T) {
+	tests := []struct {
+		s, want string
+		len     int
+	}{
+		{s: "abc", len: 5, want: "abc  "},
+		{s: "abcdef", len: 3, want: "abc"},
+		{s: "日本語", len: 5, want: "日本 "},
+		{s: "❌ ko", len: 6, want: "❌ ko "},
+	}
+	for _, tt := range tests {
+		if got := rightPad(tt.s, " ", tt.len); got != tt.want {
+			t.Errorf("rightPad(%q, %d): expected %q, got %q", tt.s, tt.len, tt.want, got)
+		}
+	}
+}
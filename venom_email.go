@@ -0,0 +1,100 @@
+package venom
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// sendEmailSummary emails a summary of the run to --email-to, with the HTML report attached, when testcases failed
+func (v *Venom) sendEmailSummary(tests Tests, elapsed time.Duration) error {
+	if len(v.EmailTo) == 0 || tests.TotalKO == 0 {
+		return nil
+	}
+	if v.SMTPServer == "" {
+		return fmt.Errorf("Error: --smtp-server is required to send the summary to %s", strings.Join(v.EmailTo, ", "))
+	}
+	from := v.EmailFrom
+	if from == "" {
+		from = "venom@localhost"
+	}
+
+	report, err := outputHTMLFormat(tests)
+	if err != nil {
+		return fmt.Errorf("Error: cannot format output html (%s)", err)
+	}
+	message, err := emailSummary(tests, elapsed, from, v.EmailTo, report)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if v.SMTPUser != "" {
+		host, _, _ := net.SplitHostPort(v.SMTPServer)
+		auth = smtp.PlainAuth("", v.SMTPUser, v.SMTPPassword, host)
+	}
+	if err := smtp.SendMail(v.SMTPServer, auth, from, v.EmailTo, message); err != nil {
+		return fmt.Errorf("Error while sending the summary to %s: %v", strings.Join(v.EmailTo, ", "), err)
+	}
+	v.PrintFunc("Summary sent to %s\n", strings.Join(v.EmailTo, ", "))
+	return nil
+}
+
+// emailSummary returns the email: the failed testcases in the body and the HTML report as attachment
+func emailSummary(tests Tests, elapsed time.Duration, from string, to []string, report []byte) ([]byte, error) {
+	var body strings.Builder
+	fmt.Fprintf(&body, "%d testcases in %s: %d ok, %d ko, %d skipped\r\n", tests.Total, elapsed.Round(time.Millisecond), tests.TotalOK, tests.TotalKO, tests.TotalSkipped)
+	for _, ts := range tests.TestSuites {
+		for _, tc := range ts.TestCases {
+			if testCaseStatus(tc) == "FAILURE" {
+				fmt.Fprintf(&body, "\r\nFAILURE %s / %s\r\n", ts.Name, tc.Name)
+				for _, f := range append(append([]Failure{}, tc.Errors...), tc.Failures...) {
+					fmt.Fprintf(&body, "%s\r\n", strings.Replace(strings.TrimSpace(f.Value), "\n", "\r\n", -1))
+				}
+			}
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	w := multipart.NewWriter(buf)
+	fmt.Fprintf(buf, "From: %s\r\n", from)
+	fmt.Fprintf(buf, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(buf, "Subject: [venom] %d testcases failed\r\n", tests.TotalKO)
+	fmt.Fprintf(buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(buf, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", w.Boundary())
+
+	part, err := w.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/plain; charset=utf-8"}})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := part.Write([]byte(body.String())); err != nil {
+		return nil, err
+	}
+
+	part, err = w.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/html; charset=utf-8"},
+		"Content-Transfer-Encoding": {"base64"},
+		"Content-Disposition":       {`attachment; filename="test_results.html"`},
+	})
+	if err != nil {
+		return nil, err
+	}
+	encoded := base64.StdEncoding.EncodeToString(report)
+	for len(encoded) > 76 {
+		fmt.Fprintf(part, "%s\r\n", encoded[:76])
+		encoded = encoded[76:]
+	}
+	fmt.Fprintf(part, "%s\r\n", encoded)
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,53 @@
+package venom
+
+import (
+	"bytes"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEmailSummary(t *testing.T) {
+	tests := Tests{Total: 2, TotalOK: 1, TotalKO: 1, TestSuites: []TestSuite{{
+		Name:      "suite",
+		TestCases: []TestCase{{Name: "ok"}, {Name: "ko", Failures: []Failure{{Value: "boom"}}}},
+	}}}
+	message, err := emailSummary(tests, time.Second, "venom@localhost", []string{"a@example.com", "b@example.com"}, []byte("<html></html>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := mail.ReadMessage(bytes.NewReader(message))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Header.Get("To") != "a@example.com, b@example.com" || m.Header.Get("Subject") != "[venom] 1 testcases failed" {
+		t.Fatalf("unexpected headers: %v", m.Header)
+	}
+	_, params, err := mime.ParseMediaType(m.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := multipart.NewReader(m.Body, params["boundary"])
+
+	part, err := r.NextPart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(part)
+	if !strings.Contains(string(body), "FAILURE suite / ko\r\nboom") || strings.Contains(string(body), "suite / ok") {
+		t.Errorf("unexpected body: %s", body)
+	}
+
+	part, err = r.NextPart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if part.FileName() != "test_results.html" {
+		t.Errorf("unexpected attachment %s", part.FileName())
+	}
+}
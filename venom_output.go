@@ -6,6 +6,7 @@ import (
 	"encoding/xml"
 	"fmt"
 	"io/ioutil"
+	"path/filepath"
 	"reflect"
 	"regexp"
 	"strings"
@@ -15,7 +16,6 @@ import (
 	"github.com/fatih/color"
 	dump "github.com/fsamin/go-dump"
 	tap "github.com/mndrix/tap-go"
-	log "github.com/sirupsen/logrus"
 	yaml "gopkg.in/yaml.v2"
 )
 
@@ -27,46 +27,44 @@ func slug(s string) string {
 
 // OutputResult output result to sdtout, files...
 func (v *Venom) OutputResult(tests Tests, elapsed time.Duration) error {
-	var data []byte
-	var err error
 	v.outputResume(tests, elapsed)
+	v.outputOpenAPICoverage()
 	cleanOutputColors(&tests)
-	switch v.OutputFormat {
-	case "json":
-		data, err = json.MarshalIndent(tests, "", "  ")
-		if err != nil {
-			log.Fatalf("Error: cannot format output json (%s)", err)
-		}
-	case "tap":
-		data, err = outputTapFormat(tests)
-		if err != nil {
-			log.Fatalf("Error: cannot format output tap (%s)", err)
-		}
-	case "yml", "yaml":
-		data, err = yaml.Marshal(tests)
-		if err != nil {
-			log.Fatalf("Error: cannot format output yaml (%s)", err)
-		}
-	default:
-		dataxml, errm := xml.MarshalIndent(tests, "", "  ")
-		if errm != nil {
-			log.Fatalf("Error: cannot format xml output: %s", errm)
-		}
-		data = append([]byte(`<?xml version="1.0" encoding="utf-8"?>`), dataxml...)
+	if err := v.outputAnnotations(tests); err != nil {
+		return err
 	}
 
 	if v.OutputDir != "" {
 		v.PrintFunc("\n") // new line to display files written
-		filename := v.OutputDir + "/test_results." + v.OutputFormat
-		if err := ioutil.WriteFile(filename, data, 0644); err != nil {
-			return fmt.Errorf("Error while creating file %s: %v", filename, err)
+		// several formats can be written at once: --format xml,json,html
+		for _, format := range strings.Split(v.OutputFormat, ",") {
+			if strings.TrimSpace(format) == "teamcity" {
+				// the service messages are printed during the execution
+				continue
+			}
+			data, ext, err := formatResult(tests, strings.TrimSpace(format))
+			if err != nil {
+				return err
+			}
+			filename := filepath.Join(v.OutputDir, "test_results."+ext)
+			if err := ioutil.WriteFile(filename, data, 0644); err != nil {
+				return fmt.Errorf("Error while creating file %s: %v", filename, err)
+			}
+			v.PrintFunc("Writing file %s\n", filename)
+		}
+
+		if err := v.writeSplitResults(tests); err != nil {
+			return err
+		}
+
+		if err := v.writeOpenAPICoverage(); err != nil {
+			return err
 		}
-		v.PrintFunc("Writing file %s\n", filename)
 
 		for _, ts := range tests.TestSuites {
 			for _, tc := range ts.TestCases {
 				for _, f := range tc.Failures {
-					filename := v.OutputDir + "/" + slug(ts.ShortName) + "." + slug(tc.Name) + ".dump"
+					filename := filepath.Join(v.OutputDir, slug(ts.ShortName)+"."+slug(tc.Name)+".dump")
 
 					sdump := &bytes.Buffer{}
 					dumpEncoder := dump.NewEncoder(sdump)
@@ -102,7 +100,106 @@ func (v *Venom) OutputResult(tests Tests, elapsed time.Duration) error {
 			}
 		}
 	}
-	return nil
+	if err := v.saveHistory(tests, elapsed); err != nil {
+		return err
+	}
+	if err := v.sendEmailSummary(tests, elapsed); err != nil {
+		return err
+	}
+	return v.sendAlerts(tests)
+}
+
+// formatResult returns the tests formatted in the format, and the extension of the file
+func formatResult(tests Tests, format string) ([]byte, string, error) {
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(withoutStepResults(tests), "", "  ")
+		if err != nil {
+			return nil, "", fmt.Errorf("Error: cannot format output json (%s)", err)
+		}
+		return data, "json", nil
+	case "json-full":
+		data, err := json.MarshalIndent(tests, "", "  ")
+		if err != nil {
+			return nil, "", fmt.Errorf("Error: cannot format output json-full (%s)", err)
+		}
+		return data, "full.json", nil
+	case "tap":
+		data, err := outputTapFormat(tests)
+		if err != nil {
+			return nil, "", fmt.Errorf("Error: cannot format output tap (%s)", err)
+		}
+		return data, "tap", nil
+	case "yml", "yaml":
+		data, err := yaml.Marshal(tests)
+		if err != nil {
+			return nil, "", fmt.Errorf("Error: cannot format output yaml (%s)", err)
+		}
+		return data, format, nil
+	case "html":
+		data, err := outputHTMLFormat(tests)
+		if err != nil {
+			return nil, "", fmt.Errorf("Error: cannot format output html (%s)", err)
+		}
+		return data, "html", nil
+	case "sonarqube":
+		data, err := outputSonarQubeFormat(tests)
+		if err != nil {
+			return nil, "", fmt.Errorf("Error: cannot format output sonarqube (%s)", err)
+		}
+		return data, "sonarqube.xml", nil
+	case "badge":
+		data, err := outputBadgeFormat(tests)
+		if err != nil {
+			return nil, "", fmt.Errorf("Error: cannot format output badge (%s)", err)
+		}
+		return data, "badge.json", nil
+	case "csv":
+		data, err := outputCSVFormat(tests)
+		if err != nil {
+			return nil, "", fmt.Errorf("Error: cannot format output csv (%s)", err)
+		}
+		return data, "csv", nil
+	case "xunit2":
+		data, err := outputXunit2Format(tests)
+		if err != nil {
+			return nil, "", fmt.Errorf("Error: cannot format output xunit2 (%s)", err)
+		}
+		return data, "xunit2.xml", nil
+	case "xml", "junit", "":
+		dataxml, err := xml.MarshalIndent(tests, "", "  ")
+		if err != nil {
+			return nil, "", fmt.Errorf("Error: cannot format xml output: %s", err)
+		}
+		return append([]byte(`<?xml version="1.0" encoding="utf-8"?>`), dataxml...), "xml", nil
+	}
+	return nil, "", fmt.Errorf("Error: unsupported output format %q, must be xml (or junit), json, json-full, yaml, tap, csv, html, sonarqube, teamcity, xunit2 or badge", format)
+}
+
+// outputStepResults returns true if the results of the steps are kept for the report: --format json-full
+func (v *Venom) outputStepResults() bool {
+	for _, format := range strings.Split(v.OutputFormat, ",") {
+		if strings.TrimSpace(format) == "json-full" {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutStepResults returns a copy of the tests without the results of the steps, as written in the json report
+func withoutStepResults(tests Tests) Tests {
+	testSuites := make([]TestSuite, 0, len(tests.TestSuites))
+	for _, ts := range tests.TestSuites {
+		testCases := make([]TestCase, 0, len(ts.TestCases))
+		for _, tc := range ts.TestCases {
+			tc.StepResults = nil
+			testCases = append(testCases, tc)
+		}
+		ts.TestCases = testCases
+		testSuites = append(testSuites, ts)
+	}
+	tests.TestSuites = testSuites
+	return tests
 }
 
 func outputTapFormat(tests Tests) ([]byte, error) {
@@ -114,7 +211,7 @@ func outputTapFormat(tests Tests) ([]byte, error) {
 		for _, tc := range ts.TestCases {
 			name := ts.Name + " / " + tc.Name
 			if len(tc.Skipped) > 0 {
-				tapValue.Skip(1, name)
+				tapValue.Skip(1, name+": "+strings.TrimSpace(tc.Skipped[0].Value))
 				continue
 			}
 
@@ -143,6 +240,7 @@ func outputTapFormat(tests Tests) ([]byte, error) {
 
 func (v *Venom) outputResume(tests Tests, elapsed time.Duration) {
 	red := color.New(color.FgRed).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
 	totalTestCases := 0
 	totalTestSteps := 0
 	v.PrintFunc("\n")
@@ -152,8 +250,14 @@ func (v *Venom) outputResume(tests Tests, elapsed time.Duration) {
 			totalTestSteps += len(tc.TestSteps)
 		}
 
+		for _, tc := range t.TestCases {
+			if tc.Status == "quarantined" {
+				v.PrintFunc("%s %s / %s\n", yellow("QUARANTINED"), t.Name, tc.Name)
+			}
+		}
+
 		if t.Failures > 0 || t.Errors > 0 {
-			v.PrintFunc("%s %s\n", red("FAILED"), t.Name)
+			v.PrintFunc("%s %s%s\n", red("FAILED"), t.Name, ownership(t))
 
 			for _, tc := range t.TestCases {
 				for _, f := range tc.Failures {
@@ -0,0 +1,33 @@
+package venom
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// shieldsBadge is a shields.io endpoint badge: https://shields.io/endpoint
+type shieldsBadge struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Label         string `json:"label"`
+	Message       string `json:"message"`
+	Color         string `json:"color"`
+}
+
+// outputBadgeFormat returns a shields.io badge with the pass rate and the number of testcases, the skipped
+// testcases are not counted in the pass rate
+func outputBadgeFormat(tests Tests) ([]byte, error) {
+	badge := shieldsBadge{SchemaVersion: 1, Label: "venom", Message: "no tests", Color: "lightgrey"}
+	if run := tests.TotalOK + tests.TotalKO; run > 0 {
+		rate := 100 * tests.TotalOK / run
+		badge.Message = fmt.Sprintf("%d%% passed (%d/%d)", rate, tests.TotalOK, run)
+		switch {
+		case tests.TotalKO == 0:
+			badge.Color = "brightgreen"
+		case rate >= 80:
+			badge.Color = "yellow"
+		default:
+			badge.Color = "red"
+		}
+	}
+	return json.MarshalIndent(badge, "", "  ")
+}
@@ -0,0 +1,32 @@
+package venom
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+)
+
+// outputCSVFormat returns a line by testcase: suite, case, status, duration and failure message
+func outputCSVFormat(tests Tests) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	w := csv.NewWriter(buf)
+	if err := w.Write([]string{"suite", "case", "status", "duration", "message"}); err != nil {
+		return nil, err
+	}
+	for _, ts := range tests.TestSuites {
+		for _, tc := range ts.TestCases {
+			var messages []string
+			for _, f := range append(append([]Failure{}, tc.Errors...), tc.Failures...) {
+				messages = append(messages, strings.TrimSpace(f.Value))
+			}
+			for _, s := range tc.Skipped {
+				messages = append(messages, strings.TrimSpace(s.Value))
+			}
+			if err := w.Write([]string{ts.Name, tc.Name, testCaseStatus(tc), tc.Time, strings.Join(messages, "\n")}); err != nil {
+				return nil, err
+			}
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
@@ -0,0 +1,80 @@
+package venom
+
+import (
+	"bytes"
+	"html/template"
+	"net/url"
+	"strings"
+)
+
+var htmlReport = template.Must(template.New("report").Funcs(template.FuncMap{
+	"status": testCaseStatus,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Venom report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 2em; }
+th, td { text-align: left; padding: 4px 8px; border-bottom: 1px solid #ddd; vertical-align: top; }
+.SUCCESS { color: #2e7d32; }
+.FAILURE { color: #c62828; }
+.SKIPPED { color: #757575; }
+pre { white-space: pre-wrap; margin: 4px 0; background: #f5f5f5; padding: 4px; }
+</style>
+</head>
+<body>
+<h1>Venom report</h1>
+<p>{{.Total}} testcases: <span class="SUCCESS">{{.TotalOK}} ok</span>, <span class="FAILURE">{{.TotalKO}} ko</span>, <span class="SKIPPED">{{.TotalSkipped}} skipped</span></p>
+{{range .TestSuites}}
+<h2>{{.Name}}</h2>
+<table>
+<tr><th>Testcase</th><th>Status</th><th>Time</th><th>Details</th></tr>
+{{range .TestCases}}{{$status := status .}}
+<tr>
+<td>{{.Name}}{{if .IssueURL}} <a href="{{.IssueURL}}">{{.Issue}}</a>{{else if .Issue}} {{.Issue}}{{end}}{{if .Doc}} <a href="{{.Doc}}">doc</a>{{end}}</td>
+<td class="{{$status}}">{{$status}}</td>
+<td>{{.Time}}</td>
+<td>{{range .Errors}}<pre>{{.Value}}</pre>{{end}}{{range .Failures}}<pre>{{.Value}}</pre>{{end}}{{range .Skipped}}<pre>{{.Value}}</pre>{{end}}{{range .Attachments}}<a href="{{.}}">{{.}}</a><br>{{end}}{{if .Systemout.Value}}<details><summary>system-out</summary><pre>{{.Systemout.Value}}</pre></details>{{end}}{{if .Systemerr.Value}}<details><summary>system-err</summary><pre>{{.Systemerr.Value}}</pre></details>{{end}}</td>
+</tr>
+{{end}}
+</table>
+{{end}}
+</body>
+</html>
+`))
+
+// testCaseStatus returns SUCCESS, FAILURE or SKIPPED
+func testCaseStatus(tc TestCase) string {
+	switch {
+	case len(tc.Errors) > 0 || len(tc.Failures) > 0:
+		return "FAILURE"
+	case len(tc.Skipped) > 0:
+		return "SKIPPED"
+	}
+	return "SUCCESS"
+}
+
+// outputHTMLFormat returns a standalone HTML report of the tests
+func outputHTMLFormat(tests Tests) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := htmlReport.Execute(buf, tests); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// issueURL returns the link of an issue of a testcase: the issue if it's an url,
+// or the --issue-url template where {issue} is replaced by the issue
+func (v *Venom) issueURL(issue string) string {
+	switch {
+	case issue == "":
+		return ""
+	case strings.HasPrefix(issue, "http://"), strings.HasPrefix(issue, "https://"):
+		return issue
+	case v.IssueURL != "":
+		return strings.Replace(v.IssueURL, "{issue}", url.PathEscape(issue), -1)
+	}
+	return ""
+}
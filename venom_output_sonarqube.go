@@ -0,0 +1,79 @@
+package venom
+
+import (
+	"encoding/xml"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// sonarTestExecutions is a SonarQube generic test execution report
+type sonarTestExecutions struct {
+	XMLName xml.Name    `xml:"testExecutions"`
+	Version int         `xml:"version,attr"`
+	Files   []sonarFile `xml:"file"`
+}
+
+type sonarFile struct {
+	Path      string          `xml:"path,attr"`
+	TestCases []sonarTestCase `xml:"testCase"`
+}
+
+type sonarTestCase struct {
+	Name     string        `xml:"name,attr"`
+	Duration int64         `xml:"duration,attr"`
+	Skipped  *sonarMessage `xml:"skipped,omitempty"`
+	Failure  *sonarMessage `xml:"failure,omitempty"`
+	Error    *sonarMessage `xml:"error,omitempty"`
+}
+
+type sonarMessage struct {
+	Message    string `xml:"message,attr"`
+	Stacktrace string `xml:",chardata"`
+}
+
+// outputSonarQubeFormat returns the tests as a SonarQube generic test execution report,
+// a file is written for each testsuite file
+func outputSonarQubeFormat(tests Tests) ([]byte, error) {
+	report := sonarTestExecutions{Version: 1}
+	for _, ts := range tests.TestSuites {
+		f := sonarFile{Path: filepath.ToSlash(ts.Filename)}
+		if f.Path == "" {
+			f.Path = ts.Name
+		}
+		for _, tc := range ts.TestCases {
+			stc := sonarTestCase{Name: tc.Name}
+			// the duration is in milliseconds
+			if seconds, err := strconv.ParseFloat(tc.Time, 64); err == nil {
+				stc.Duration = int64(seconds * 1000)
+			}
+			switch {
+			case len(tc.Errors) > 0:
+				stc.Error = newSonarMessage(tc.Errors)
+			case len(tc.Failures) > 0:
+				stc.Failure = newSonarMessage(tc.Failures)
+			case len(tc.Skipped) > 0:
+				stc.Skipped = &sonarMessage{Message: strings.TrimSpace(tc.Skipped[0].Value)}
+			}
+			f.TestCases = append(f.TestCases, stc)
+		}
+		report.Files = append(report.Files, f)
+	}
+	data, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+// newSonarMessage returns the first line of the first failure as message, and all the failures as stacktrace
+func newSonarMessage(failures []Failure) *sonarMessage {
+	values := make([]string, 0, len(failures))
+	for _, f := range failures {
+		values = append(values, strings.TrimSpace(f.Value))
+	}
+	return &sonarMessage{
+		Message:    strings.TrimSpace(strings.SplitN(values[0], "\n", 2)[0]),
+		Stacktrace: strings.Join(values, "\n"),
+	}
+}
@@ -0,0 +1,82 @@
+package venom
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatResult(t *testing.T) {
+	tests := Tests{Total: 1, TotalKO: 1, TestSuites: []TestSuite{{
+		Name:      "suite",
+		TestCases: []TestCase{{Name: "<case>", Failures: []Failure{{Value: "boom"}}, Issue: "PAY-1", IssueURL: "https://jira.example.com/browse/PAY-1"}},
+	}}}
+	for format, ext := range map[string]string{"xml": "xml", "junit": "xml", "json": "json", "json-full": "full.json", "yaml": "yaml", "yml": "yml", "tap": "tap", "csv": "csv", "html": "html", "sonarqube": "sonarqube.xml", "xunit2": "xunit2.xml", "badge": "badge.json"} {
+		data, gotExt, err := formatResult(tests, format)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if gotExt != ext {
+			t.Errorf("%s: expected extension %s, got %s", format, ext, gotExt)
+		}
+		if len(data) == 0 {
+			t.Errorf("%s: empty output", format)
+		}
+	}
+
+	data, _, _ := formatResult(tests, "html")
+	if !strings.Contains(string(data), "&lt;case&gt;") || !strings.Contains(string(data), `<td class="FAILURE">FAILURE</td>`) ||
+		!strings.Contains(string(data), `<a href="https://jira.example.com/browse/PAY-1">PAY-1</a>`) {
+		t.Errorf("unexpected html report: %s", data)
+	}
+
+	data, _, _ = formatResult(tests, "sonarqube")
+	if !strings.Contains(string(data), `<testCase name="&lt;case&gt;" duration="0">`) || !strings.Contains(string(data), `<failure message="boom">boom</failure>`) {
+		t.Errorf("unexpected sonarqube report: %s", data)
+	}
+
+	data, _, _ = formatResult(tests, "xunit2")
+	if !strings.Contains(string(data), `<test name="suite.&lt;case&gt;" type="suite" method="&lt;case&gt;" time="0.000" result="Fail">`) {
+		t.Errorf("unexpected xunit2 report: %s", data)
+	}
+
+	data, _, _ = formatResult(tests, "csv")
+	if string(data) != "suite,case,status,duration,message\nsuite,<case>,FAILURE,,boom\n" {
+		t.Errorf("unexpected csv report: %s", data)
+	}
+
+	data, _, _ = formatResult(Tests{Total: 5, TotalOK: 3, TotalKO: 1, TotalSkipped: 1}, "badge")
+	if !strings.Contains(string(data), `"message": "75% passed (3/4)"`) || !strings.Contains(string(data), `"color": "red"`) {
+		t.Errorf("unexpected badge: %s", data)
+	}
+
+	tests.TestSuites[0].TestCases[0].StepResults = []StepResult{{Type: "exec", Status: "FAILURE", Result: ExecutorResult{"result.code": 1}}}
+	data, _, _ = formatResult(tests, "json")
+	if strings.Contains(string(data), "step_results") {
+		t.Errorf("unexpected step results in the json report: %s", data)
+	}
+	data, _, _ = formatResult(tests, "json-full")
+	if !strings.Contains(string(data), `"step_results"`) || !strings.Contains(string(data), `"result.code": 1`) {
+		t.Errorf("expected the step results in the json-full report: %s", data)
+	}
+	if len(tests.TestSuites[0].TestCases[0].StepResults) != 1 {
+		t.Error("the json report must not change the tests")
+	}
+
+	if _, _, err := formatResult(tests, "pdf"); err == nil {
+		t.Error("expected an error for an unsupported format")
+	}
+}
+
+func TestIssueURL(t *testing.T) {
+	v := New()
+	if u := v.issueURL("PAY-1"); u != "" {
+		t.Errorf("expected no link without --issue-url, got %s", u)
+	}
+	v.IssueURL = "https://jira.example.com/browse/{issue}"
+	if u := v.issueURL("PAY-1"); u != "https://jira.example.com/browse/PAY-1" {
+		t.Errorf("unexpected link %s", u)
+	}
+	if u := v.issueURL("https://github.com/ovh/venom/issues/1"); u != "https://github.com/ovh/venom/issues/1" {
+		t.Errorf("unexpected link %s", u)
+	}
+}
@@ -0,0 +1,119 @@
+package venom
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// xunit2Assemblies is a report in the xUnit.net v2 XML format
+type xunit2Assemblies struct {
+	XMLName    xml.Name         `xml:"assemblies"`
+	Assemblies []xunit2Assembly `xml:"assembly"`
+}
+
+type xunit2Assembly struct {
+	Name        string             `xml:"name,attr"`
+	RunDate     string             `xml:"run-date,attr"`
+	RunTime     string             `xml:"run-time,attr"`
+	Total       int                `xml:"total,attr"`
+	Passed      int                `xml:"passed,attr"`
+	Failed      int                `xml:"failed,attr"`
+	Skipped     int                `xml:"skipped,attr"`
+	Errors      int                `xml:"errors,attr"`
+	Time        string             `xml:"time,attr"`
+	Collections []xunit2Collection `xml:"collection"`
+}
+
+type xunit2Collection struct {
+	Name    string       `xml:"name,attr"`
+	Total   int          `xml:"total,attr"`
+	Passed  int          `xml:"passed,attr"`
+	Failed  int          `xml:"failed,attr"`
+	Skipped int          `xml:"skipped,attr"`
+	Time    string       `xml:"time,attr"`
+	Tests   []xunit2Test `xml:"test"`
+}
+
+type xunit2Test struct {
+	Name    string         `xml:"name,attr"`
+	Type    string         `xml:"type,attr"`
+	Method  string         `xml:"method,attr"`
+	Time    string         `xml:"time,attr"`
+	Result  string         `xml:"result,attr"`
+	Failure *xunit2Failure `xml:"failure,omitempty"`
+	Reason  *xunit2CDATA   `xml:"reason,omitempty"`
+}
+
+type xunit2Failure struct {
+	ExceptionType string      `xml:"exception-type,attr"`
+	Message       xunit2CDATA `xml:"message"`
+}
+
+type xunit2CDATA struct {
+	Value string `xml:",cdata"`
+}
+
+// outputXunit2Format returns the tests in the xUnit.net v2 XML format: an assembly with a collection by testsuite
+func outputXunit2Format(tests Tests) ([]byte, error) {
+	now := time.Now()
+	assembly := xunit2Assembly{
+		Name:    "venom",
+		RunDate: now.Format("2006-01-02"),
+		RunTime: now.Format("15:04:05"),
+	}
+	var total float64
+	for _, ts := range tests.TestSuites {
+		c := xunit2Collection{Name: ts.Name}
+		var collectionTime float64
+		for _, tc := range ts.TestCases {
+			seconds, _ := strconv.ParseFloat(tc.Time, 64)
+			collectionTime += seconds
+			t := xunit2Test{
+				Name:   ts.Name + "." + tc.Name,
+				Type:   ts.Name,
+				Method: tc.Name,
+				Time:   strconv.FormatFloat(seconds, 'f', 3, 64),
+				Result: "Pass",
+			}
+			switch {
+			case len(tc.Errors) > 0 || len(tc.Failures) > 0:
+				var values []string
+				for _, f := range append(append([]Failure{}, tc.Errors...), tc.Failures...) {
+					values = append(values, strings.TrimSpace(f.Value))
+				}
+				exceptionType := "AssertionFailure"
+				if len(tc.Errors) > 0 {
+					exceptionType = "Error"
+				}
+				t.Result = "Fail"
+				t.Failure = &xunit2Failure{ExceptionType: exceptionType, Message: xunit2CDATA{strings.Join(values, "\n")}}
+				c.Failed++
+			case len(tc.Skipped) > 0:
+				t.Result = "Skip"
+				t.Reason = &xunit2CDATA{strings.TrimSpace(tc.Skipped[0].Value)}
+				c.Skipped++
+			default:
+				c.Passed++
+			}
+			c.Tests = append(c.Tests, t)
+			c.Total++
+		}
+		c.Time = strconv.FormatFloat(collectionTime, 'f', 3, 64)
+		total += collectionTime
+
+		assembly.Total += c.Total
+		assembly.Passed += c.Passed
+		assembly.Failed += c.Failed
+		assembly.Skipped += c.Skipped
+		assembly.Collections = append(assembly.Collections, c)
+	}
+	assembly.Time = strconv.FormatFloat(total, 'f', 3, 64)
+
+	data, err := xml.MarshalIndent(xunit2Assemblies{Assemblies: []xunit2Assembly{assembly}}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
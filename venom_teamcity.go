@@ -0,0 +1,69 @@
+package venom
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/acarl005/stripansi"
+)
+
+// teamcityEscaper escapes the values of the TeamCity service messages
+var teamcityEscaper = strings.NewReplacer("|", "||", "'", "|'", "\n", "|n", "\r", "|r", "[", "|[", "]", "|]")
+
+// streamTeamCity returns true if the TeamCity service messages are printed during the execution: --format teamcity
+func (v *Venom) streamTeamCity() bool {
+	for _, format := range strings.Split(v.OutputFormat, ",") {
+		if strings.TrimSpace(format) == "teamcity" {
+			return true
+		}
+	}
+	return false
+}
+
+// teamcityMessage prints a TeamCity service message, the flowId separates the testsuites running in parallel
+func (v *Venom) teamcityMessage(ts *TestSuite, message string, attrs ...string) {
+	var sb strings.Builder
+	sb.WriteString("##teamcity[" + message)
+	for i := 0; i+1 < len(attrs); i += 2 {
+		sb.WriteString(" " + attrs[i] + "='" + teamcityEscaper.Replace(attrs[i+1]) + "'")
+	}
+	sb.WriteString(" flowId='" + teamcityEscaper.Replace(ts.Package) + "']")
+	v.PrintFunc("%s\n", sb.String())
+}
+
+func (v *Venom) teamcityTestSuiteStarted(ts *TestSuite) {
+	if v.streamTeamCity() {
+		v.teamcityMessage(ts, "testSuiteStarted", "name", ts.Name)
+	}
+}
+
+func (v *Venom) teamcityTestSuiteFinished(ts *TestSuite) {
+	if v.streamTeamCity() {
+		v.teamcityMessage(ts, "testSuiteFinished", "name", ts.Name)
+	}
+}
+
+func (v *Venom) teamcityTestStarted(ts *TestSuite, tc *TestCase) {
+	if v.streamTeamCity() {
+		v.teamcityMessage(ts, "testStarted", "name", tc.Name)
+	}
+}
+
+func (v *Venom) teamcityTestFinished(ts *TestSuite, tc *TestCase, elapsed time.Duration) {
+	if !v.streamTeamCity() {
+		return
+	}
+	switch {
+	case len(tc.Skipped) > 0:
+		v.teamcityMessage(ts, "testIgnored", "name", tc.Name, "message", strings.TrimSpace(tc.Skipped[0].Value))
+	case len(tc.Errors) > 0 || len(tc.Failures) > 0:
+		var details []string
+		for _, f := range append(append([]Failure{}, tc.Errors...), tc.Failures...) {
+			details = append(details, strings.TrimSpace(stripansi.Strip(f.Value)))
+		}
+		message := strings.SplitN(details[0], "\n", 2)[0]
+		v.teamcityMessage(ts, "testFailed", "name", tc.Name, "message", message, "details", strings.Join(details, "\n"))
+	}
+	v.teamcityMessage(ts, "testFinished", "name", tc.Name, "duration", strconv.FormatInt(int64(elapsed/time.Millisecond), 10))
+}
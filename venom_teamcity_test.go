@@ -0,0 +1,28 @@
+package venom
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTeamcityTestFinished(t *testing.T) {
+	var out strings.Builder
+	v := New()
+	v.OutputFormat = "xml,teamcity"
+	v.PrintFunc = func(format string, a ...interface{}) (int, error) {
+		return fmt.Fprintf(&out, format, a...)
+	}
+	ts := &TestSuite{Name: "suite", Package: "suite.yml"}
+	tc := &TestCase{Name: "it's [1]", Failures: []Failure{{Value: "boom\nat step 0"}}}
+	v.teamcityTestStarted(ts, tc)
+	v.teamcityTestFinished(ts, tc, 1500*time.Millisecond)
+
+	expected := "##teamcity[testStarted name='it|'s |[1|]' flowId='suite.yml']\n" +
+		"##teamcity[testFailed name='it|'s |[1|]' message='boom' details='boom|nat step 0' flowId='suite.yml']\n" +
+		"##teamcity[testFinished name='it|'s |[1|]' duration='1500' flowId='suite.yml']\n"
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
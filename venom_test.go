@@ -0,0 +1,95 @@
+package venom
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetAttrDuration(t *testing.T) {
+	tests := []struct {
+		value   interface{}
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: 2, want: 2 * time.Second},
+		{value: 0.5, want: 500 * time.Millisecond},
+		{value: "500ms", want: 500 * time.Millisecond},
+		{value: "1m30s", want: 90 * time.Second},
+		{value: -1, want: 0},
+		{value: "soon", wantErr: true},
+		{value: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := getAttrDuration(map[string]interface{}{"delay": tt.value}, "delay")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("getAttrDuration(%v) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("getAttrDuration(%v) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestRestrictExecutors(t *testing.T) {
+	v := New()
+	v.RegisterExecutor("exec", &countingExecutor{})
+	v.RegisterExecutor("http", &countingExecutor{})
+	v.RegisterExecutor("ssh", &countingExecutor{})
+	if err := v.RestrictExecutors([]string{"http", "ssh"}, []string{"ssh"}); err != nil {
+		t.Fatal(err)
+	}
+	v.RegisterExecutor("readfile", &countingExecutor{})
+
+	tcc := &CommonTestCaseContext{Name: "default"}
+	if _, err := v.WrapExecutor(TestStep{"type": "http"}, tcc); err != nil {
+		t.Errorf("expected http to be allowed, got %v", err)
+	}
+	for _, name := range []string{"exec", "ssh", "readfile"} {
+		_, err := v.WrapExecutor(TestStep{"type": name}, tcc)
+		if err == nil || !strings.Contains(err.Error(), "is not allowed on this run") {
+			t.Errorf("expected %s not to be allowed, got %v", name, err)
+		}
+	}
+	if _, err := v.WrapExecutor(TestStep{"script": "rm -rf /"}, tcc); err == nil {
+		t.Error("expected the script steps not to be allowed")
+	}
+
+	if err := New().RestrictExecutors([]string{"htp"}, nil); err == nil {
+		t.Error("expected an error for an unknown executor")
+	}
+}
+
+func TestContextWrap(t *testing.T) {
+	v := New()
+	registered := &testContext{CommonTestCaseContext{Name: "default"}}
+	v.RegisterTestCaseContext("default", registered)
+
+	tc1 := &TestCase{Name: "first", Context: map[string]interface{}{"type": "default", "k": "1"}}
+	tc2 := &TestCase{Name: "second"}
+	tcc1, err := v.ContextWrap(tc1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tcc2, err := v.ContextWrap(tc2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tcc1 == tcc2 || tcc1 == TestCaseContext(registered) {
+		t.Fatal("each testcase should have its own copy of the context")
+	}
+	if got := tcc1.(*testContext).TestCase.Name; got != "first" {
+		t.Errorf("expected the testcase in its context, got %q", got)
+	}
+	if tcc2.(*testContext).TestCase.Name != "" || registered.TestCase.Name != "" {
+		t.Error("the testcase should not be set in the other contexts")
+	}
+	if tcc2.GetName() != "default" {
+		t.Errorf("the copy should keep the fields of the registered context, got %q", tcc2.GetName())
+	}
+
+	if _, err := v.ContextWrap(&TestCase{Context: map[string]interface{}{"type": "unknown"}}); err == nil {
+		t.Error("expected an error for an unknown context type")
+	}
+}
@@ -0,0 +1,30 @@
+package venom
+
+import "sync"
+
+// runWorkers runs the jobs 0 to n-1 on a pool of workers, at least one. The jobs are handed to the workers one by
+// one: there are never more goroutines than workers, whatever the number of jobs.
+func runWorkers(workers, n int, job func(i int)) {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > n {
+		workers = n
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				job(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
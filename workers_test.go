@@ -0,0 +1,41 @@
+package venom
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRunWorkers(t *testing.T) {
+	for _, workers := range []int{0, 1, 3, 20} {
+		var mutex sync.Mutex
+		var running, peak int
+		done := make([]bool, 10)
+		runWorkers(workers, len(done), func(i int) {
+			mutex.Lock()
+			running++
+			if running > peak {
+				peak = running
+			}
+			mutex.Unlock()
+			time.Sleep(time.Millisecond)
+			mutex.Lock()
+			running--
+			done[i] = true
+			mutex.Unlock()
+		})
+
+		max := workers
+		if max < 1 {
+			max = 1
+		}
+		if peak > max {
+			t.Errorf("%d workers: %d jobs were run at once", workers, peak)
+		}
+		for i, d := range done {
+			if !d {
+				t.Errorf("%d workers: job %d was not run", workers, i)
+			}
+		}
+	}
+}